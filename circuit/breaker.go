@@ -2,6 +2,8 @@ package circuit
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -49,6 +51,11 @@ type BreakerSettings struct {
 	Timeout          time.Duration `yaml:"timeout"`
 	HalfOpenRequests int           `yaml:"half-open-requests"`
 	IdleTTL          time.Duration `yaml:"idle-ttl"`
+
+	// FailureStatusCodes, when set, contains the comma separated list of the backend response status
+	// codes that are counted as failures, instead of the status codes >=500. Use ParseStatusCodes to
+	// get the normalized representation.
+	FailureStatusCodes string `yaml:"failure-status-codes"`
 }
 
 type breakerImplementation interface {
@@ -61,9 +68,10 @@ type voidBreaker struct{}
 //
 // Use the Get() method of the Registry to request fully initialized breakers.
 type Breaker struct {
-	settings BreakerSettings
-	ts       time.Time
-	impl     breakerImplementation
+	settings       BreakerSettings
+	ts             time.Time
+	impl           breakerImplementation
+	failureIfCodes map[int]bool
 }
 
 func (to BreakerSettings) mergeSettings(from BreakerSettings) BreakerSettings {
@@ -92,9 +100,39 @@ func (to BreakerSettings) mergeSettings(from BreakerSettings) BreakerSettings {
 		to.IdleTTL = from.IdleTTL
 	}
 
+	if to.FailureStatusCodes == "" {
+		to.FailureStatusCodes = from.FailureStatusCodes
+	}
+
 	return to
 }
 
+// ParseStatusCodes parses a comma separated list of HTTP status codes, and returns its normalized, sorted
+// representation that can be used as the FailureStatusCodes field of the settings.
+func ParseStatusCodes(s string) (string, error) {
+	var codes []int
+	seen := make(map[int]bool)
+	for _, si := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(si))
+		if err != nil || code < 100 || code > 599 {
+			return "", fmt.Errorf("invalid status code: %s", si)
+		}
+
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	sort.Ints(codes)
+	ss := make([]string, len(codes))
+	for i, c := range codes {
+		ss[i] = strconv.Itoa(c)
+	}
+
+	return strings.Join(ss, ","), nil
+}
+
 // String returns the string representation of a particular set of settings.
 //
 //lint:ignore ST1016 "s" makes sense here and mergeSettings has "to"
@@ -136,6 +174,10 @@ func (s BreakerSettings) String() string {
 		ss = append(ss, "idle-ttl="+s.IdleTTL.String())
 	}
 
+	if s.FailureStatusCodes != "" {
+		ss = append(ss, "failure-status-codes="+s.FailureStatusCodes)
+	}
+
 	return strings.Join(ss, ",")
 }
 
@@ -143,21 +185,32 @@ func (b voidBreaker) Allow() (func(bool), bool) {
 	return func(bool) {}, true
 }
 
-func newBreaker(s BreakerSettings) *Breaker {
+func newBreaker(s BreakerSettings, n *stateChangeNotifier) *Breaker {
 	var impl breakerImplementation
 	switch s.Type {
 	case ConsecutiveFailures:
-		impl = newConsecutive(s)
+		impl = newConsecutive(s, n)
 	case FailureRate:
-		impl = newRate(s)
+		impl = newRate(s, n)
 	default:
 		impl = voidBreaker{}
 	}
 
-	return &Breaker{
+	b := &Breaker{
 		settings: s,
 		impl:     impl,
 	}
+
+	if s.FailureStatusCodes != "" {
+		b.failureIfCodes = make(map[int]bool)
+		for _, si := range strings.Split(s.FailureStatusCodes, ",") {
+			if code, err := strconv.Atoi(strings.TrimSpace(si)); err == nil {
+				b.failureIfCodes[code] = true
+			}
+		}
+	}
+
+	return b
 }
 
 // Allow returns true if the breaker is in the closed state and a callback function for reporting the outcome of
@@ -167,6 +220,16 @@ func (b *Breaker) Allow() (func(bool), bool) {
 	return b.impl.Allow()
 }
 
+// Failure tells whether a backend response with the given status code should be reported as a failure. By
+// default, the status codes >=500 are failures, unless the FailureStatusCodes are set in the settings.
+func (b *Breaker) Failure(statusCode int) bool {
+	if b.failureIfCodes != nil {
+		return b.failureIfCodes[statusCode]
+	}
+
+	return statusCode >= http.StatusInternalServerError
+}
+
 func (b *Breaker) idle(now time.Time) bool {
 	return now.Sub(b.ts) > b.settings.IdleTTL
 }
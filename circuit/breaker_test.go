@@ -58,24 +58,24 @@ func TestConsecutiveFailures(t *testing.T) {
 	}
 
 	t.Run("new breaker closed", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		checkClosed(t, b)
 	})
 
 	t.Run("does not open on not enough failures", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(s.Failures-1, fail(t, b))
 		checkClosed(t, b)
 	})
 
 	t.Run("open on failures", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(s.Failures, fail(t, b))
 		checkOpen(t, b)
 	})
 
 	t.Run("go half open, close after required successes", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(s.Failures, fail(t, b))
 		waitTimeout()
 		times(s.HalfOpenRequests, succeed(t, b))
@@ -83,7 +83,7 @@ func TestConsecutiveFailures(t *testing.T) {
 	})
 
 	t.Run("go half open, reopen after a fail within the required successes", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(s.Failures, fail(t, b))
 		waitTimeout()
 		times(s.HalfOpenRequests-1, succeed(t, b))
@@ -102,12 +102,12 @@ func TestRateBreaker(t *testing.T) {
 	}
 
 	t.Run("new breaker closed", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		checkClosed(t, b)
 	})
 
 	t.Run("doesn't open if failure count is not within a window", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(1, fail(t, b))
 		times(2, succeed(t, b))
 		checkClosed(t, b)
@@ -120,7 +120,7 @@ func TestRateBreaker(t *testing.T) {
 	})
 
 	t.Run("opens on reaching the rate", func(t *testing.T) {
-		b := newBreaker(s)
+		b := newBreaker(s, nil)
 		times(s.Window, succeed(t, b))
 		times(s.Failures, fail(t, b))
 		checkOpen(t, b)
@@ -148,7 +148,7 @@ func TestRateBreakerFuzzy(t *testing.T) {
 		Timeout:          3 * time.Millisecond,
 	}
 
-	b := newBreaker(s)
+	b := newBreaker(s, nil)
 
 	stop := make(chan struct{})
 
@@ -203,3 +203,92 @@ func TestSettingsString(t *testing.T) {
 		t.Logf("expected: %s", expect)
 	}
 }
+
+func TestStateChangeHandler(t *testing.T) {
+	s := BreakerSettings{
+		Type:             ConsecutiveFailures,
+		Host:             "www.example.org",
+		Failures:         3,
+		HalfOpenRequests: 1,
+		Timeout:          15 * time.Millisecond,
+	}
+
+	type change struct {
+		host     string
+		from, to State
+	}
+
+	changes := make(chan change, 3)
+	r := NewRegistry()
+	r.OnStateChange(func(host string, from, to State) {
+		changes <- change{host: host, from: from, to: to}
+	})
+
+	b := r.Get(s)
+	times(s.Failures, fail(t, b))
+	checkOpen(t, b)
+	time.Sleep(s.Timeout)
+	succeed(t, b)()
+	checkClosed(t, b)
+
+	for _, expected := range []change{
+		{host: s.Host, from: StateClosed, to: StateOpen},
+		{host: s.Host, from: StateOpen, to: StateHalfOpen},
+		{host: s.Host, from: StateHalfOpen, to: StateClosed},
+	} {
+		select {
+		case c := <-changes:
+			if c != expected {
+				t.Errorf("unexpected state change, got: %+v, expected: %+v", c, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("state change not received: %+v", expected)
+		}
+	}
+}
+
+func TestFailureStatusCodes(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		for _, tc := range []struct {
+			codes    string
+			expected string
+			err      bool
+		}{
+			{codes: "429", expected: "429"},
+			{codes: "503, 429,429", expected: "429,503"},
+			{codes: "", err: true},
+			{codes: "429,", err: true},
+			{codes: "foo", err: true},
+			{codes: "600", err: true},
+		} {
+			codes, err := ParseStatusCodes(tc.codes)
+			if tc.err {
+				if err == nil {
+					t.Errorf("failed to fail for: %q", tc.codes)
+				}
+
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", tc.codes, err)
+			} else if codes != tc.expected {
+				t.Errorf("unexpected result for %q, got: %s, expected: %s", tc.codes, codes, tc.expected)
+			}
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		b := newBreaker(BreakerSettings{Type: ConsecutiveFailures, Failures: 3}, nil)
+		if b.Failure(429) || !b.Failure(500) || !b.Failure(503) {
+			t.Error("failed to count the 5xx status codes as failures")
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		b := newBreaker(BreakerSettings{Type: ConsecutiveFailures, Failures: 3, FailureStatusCodes: "429,503"}, nil)
+		if !b.Failure(429) || b.Failure(500) || !b.Failure(503) {
+			t.Error("failed to count the configured status codes as failures")
+		}
+	})
+}
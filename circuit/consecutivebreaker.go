@@ -1,7 +1,6 @@
 package circuit
 
 import (
-	log "github.com/sirupsen/logrus"
 	"github.com/sony/gobreaker"
 )
 
@@ -10,19 +9,17 @@ type consecutiveBreaker struct {
 	gb       *gobreaker.TwoStepCircuitBreaker
 }
 
-func newConsecutive(s BreakerSettings) *consecutiveBreaker {
+func newConsecutive(s BreakerSettings, n *stateChangeNotifier) *consecutiveBreaker {
 	b := &consecutiveBreaker{
 		settings: s,
 	}
 
 	b.gb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
-		Name:        s.Host,
-		MaxRequests: uint32(s.HalfOpenRequests),
-		Timeout:     s.Timeout,
-		ReadyToTrip: b.readyToTrip,
-		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			log.Infof("circuit breaker %v went from %v to %v", name, from.String(), to.String())
-		},
+		Name:          s.Host,
+		MaxRequests:   uint32(s.HalfOpenRequests),
+		Timeout:       s.Timeout,
+		ReadyToTrip:   b.readyToTrip,
+		OnStateChange: n.onStateChange(),
 	})
 
 	return b
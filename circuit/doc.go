@@ -110,6 +110,13 @@ Defines the idle timeout after which a circuit breaker gets recycled, if it hasn
 Command line name: idle-ttl. Possible command line values: any positive integer as milliseconds or a duration
 string, e.g. 15m30s.
 
+Settings - Failure Status Codes
+
+By default, the connection failures and the backend responses with status code >=500 count as failures. The
+failure status codes, a comma separated list, e.g. 429,503, overrides which backend response status codes are
+counted as failures. The connection failures always count as failures. It can be set only with the breaker
+filters.
+
 Filters
 
 The following circuit breaker filters are supported: consecutiveBreaker(), rateBreaker() and disableBreaker().
@@ -124,6 +131,11 @@ of the sliding window. It accepts the following optional arguments: timeout, hal
 
 	rateBreaker(30, 300, "1m", 12, "30m")
 
+Both filters accept a last optional argument, the failure status codes. Empty strings can be used for the optional
+arguments to keep their default values:
+
+	consecutiveBreaker(5, "", "", "", "429,503")
+
 The disableBreaker filter doesn't expect any arguments, and it disables the circuit breaker, if any, for the
 route that it appears in.
 
@@ -148,5 +160,18 @@ go open after an unreasonably low number of recent failures. The registry also m
 circuit breakers that are not requested anymore by the proxy. This happens in a passive way, whenever a new
 circuit breaker is created. The cleanup prevents storing circuit breakers for inaccessible backend hosts
 infinitely in those scenarios where the route configuration is continuously changing.
+
+State Change Handler
+
+A handler can be set on the registry, that is called whenever any of the circuit breakers changes its state, e.g.
+to push the events to an external incident system:
+
+	r.OnStateChange(func(host string, from, to circuit.State) {
+		log.Printf("circuit breaker for %s went from %v to %v", host, from, to)
+	})
+
+The handler is called asynchronously, off the path of the requests, in a separate goroutine. The calls happen one
+at a time, in the same order as the state changes happened. The pending state changes are queued while the
+handler is running.
 */
 package circuit
@@ -1,7 +1,6 @@
 package circuit
 
 import (
-	log "github.com/sirupsen/logrus"
 	"sync"
 
 	"github.com/sony/gobreaker"
@@ -19,20 +18,18 @@ type rateBreaker struct {
 	gb       *gobreaker.TwoStepCircuitBreaker
 }
 
-func newRate(s BreakerSettings) *rateBreaker {
+func newRate(s BreakerSettings, n *stateChangeNotifier) *rateBreaker {
 	b := &rateBreaker{
 		settings: s,
 		mx:       &sync.Mutex{},
 	}
 
 	b.gb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
-		Name:        s.Host,
-		MaxRequests: uint32(s.HalfOpenRequests),
-		Timeout:     s.Timeout,
-		ReadyToTrip: func(gobreaker.Counts) bool { return b.readyToTrip() },
-		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-			log.Infof("circuit breaker %v went from %v to %v", name, from.String(), to.String())
-		},
+		Name:          s.Host,
+		MaxRequests:   uint32(s.HalfOpenRequests),
+		Timeout:       s.Timeout,
+		ReadyToTrip:   func(gobreaker.Counts) bool { return b.readyToTrip() },
+		OnStateChange: n.onStateChange(),
 	})
 
 	return b
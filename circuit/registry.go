@@ -14,6 +14,7 @@ type Registry struct {
 	hostSettings map[string]BreakerSettings
 	lookup       map[BreakerSettings]*Breaker
 	mx           *sync.Mutex
+	notifier     *stateChangeNotifier
 }
 
 // NewRegistry initializes a registry with the provided default settings. Settings with an empty Host field are
@@ -51,9 +52,19 @@ func NewRegistry(settings ...BreakerSettings) *Registry {
 		hostSettings: hs,
 		lookup:       make(map[BreakerSettings]*Breaker),
 		mx:           &sync.Mutex{},
+		notifier:     &stateChangeNotifier{},
 	}
 }
 
+// OnStateChange sets a handler that is called when any of the circuit breakers in the registry changes its
+// state. The handler is called asynchronously, in a separate goroutine, so that it doesn't block the requests.
+// The calls happen one at a time, in the same order as the state changes happened. The state changes are
+// queued while the handler is running, therefore a slow handler delays the subsequent calls, but it doesn't
+// block the proxy. Setting the handler to nil disables the notifications.
+func (r *Registry) OnStateChange(h StateChangeHandler) {
+	r.notifier.setHandler(h)
+}
+
 func (r *Registry) mergeDefaults(s BreakerSettings) BreakerSettings {
 	defaults, ok := r.hostSettings[s.Host]
 	if !ok {
@@ -83,7 +94,7 @@ func (r *Registry) get(s BreakerSettings) *Breaker {
 		r.dropIdle(now)
 
 		// create a new one
-		b = newBreaker(s)
+		b = newBreaker(s, r.notifier)
 		r.lookup[s] = b
 	}
 
@@ -0,0 +1,108 @@
+package circuit
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sony/gobreaker"
+)
+
+// State represents the state of a circuit breaker: closed, half-open or open.
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String returns the string representation of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChangeHandler is called when a circuit breaker changes its state.
+type StateChangeHandler func(host string, from, to State)
+
+type stateChange struct {
+	host     string
+	from, to State
+}
+
+// stateChangeNotifier calls the state change handler asynchronously, in a
+// separate goroutine, in the order in which the state changes happened.
+type stateChangeNotifier struct {
+	mx      sync.Mutex
+	handler StateChangeHandler
+	events  []stateChange
+	running bool
+}
+
+func fromGobreakerState(s gobreaker.State) State {
+	switch s {
+	case gobreaker.StateHalfOpen:
+		return StateHalfOpen
+	case gobreaker.StateOpen:
+		return StateOpen
+	default:
+		return StateClosed
+	}
+}
+
+func (n *stateChangeNotifier) setHandler(h StateChangeHandler) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	n.handler = h
+}
+
+// onStateChange returns the function passed to the gobreaker settings. It
+// can be called with a nil notifier, in which case it only logs the change.
+func (n *stateChangeNotifier) onStateChange() func(string, gobreaker.State, gobreaker.State) {
+	return func(name string, from gobreaker.State, to gobreaker.State) {
+		log.Infof("circuit breaker %v went from %v to %v", name, from.String(), to.String())
+		if n != nil {
+			n.notify(stateChange{host: name, from: fromGobreakerState(from), to: fromGobreakerState(to)})
+		}
+	}
+}
+
+func (n *stateChangeNotifier) notify(e stateChange) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	if n.handler == nil {
+		return
+	}
+
+	n.events = append(n.events, e)
+	if !n.running {
+		n.running = true
+		go n.run()
+	}
+}
+
+func (n *stateChangeNotifier) run() {
+	for {
+		n.mx.Lock()
+		if len(n.events) == 0 || n.handler == nil {
+			n.events = nil
+			n.running = false
+			n.mx.Unlock()
+			return
+		}
+
+		e, h := n.events[0], n.handler
+		n.events = n.events[1:]
+		n.mx.Unlock()
+
+		h(e.host, e.from, e.to)
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +17,7 @@ import (
 	"github.com/zalando/skipper"
 	"github.com/zalando/skipper/dataclients/kubernetes"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/metrics"
 	"github.com/zalando/skipper/net"
 	"github.com/zalando/skipper/proxy"
 	routesrv "github.com/zalando/skipper/routesrv"
@@ -28,12 +27,22 @@ import (
 type Config struct {
 	ConfigFile string
 
+	// DeprecatedOptionHandler, when set, is called for each deprecated option
+	// used either as a command line flag or in the config file, after logging
+	// the warning. The replacement is the name of the option to be used
+	// instead, or empty. It can be used e.g. to count the deprecated options
+	// in use across a fleet.
+	DeprecatedOptionHandler func(name, replacement, usage string) `yaml:"-"`
+
 	// generic:
 	Address                         string         `yaml:"address"`
 	EnableTCPQueue                  bool           `yaml:"enable-tcp-queue"`
 	ExpectedBytesPerRequest         int            `yaml:"expected-bytes-per-request"`
 	MaxTCPListenerConcurrency       int            `yaml:"max-tcp-listener-concurrency"`
 	MaxTCPListenerQueue             int            `yaml:"max-tcp-listener-queue"`
+	ProxyProtocol                   bool           `yaml:"proxy-protocol"`
+	ProxyProtocolTrustedCIDRList    *listFlag      `yaml:"proxy-protocol-trusted-cidrs"`
+	ProxyProtocolTrustedCIDRs       net.IPNets     `yaml:"-"`
 	IgnoreTrailingSlash             bool           `yaml:"ignore-trailing-slash"`
 	Insecure                        bool           `yaml:"insecure"`
 	ProxyPreserveHost               bool           `yaml:"proxy-preserve-host"`
@@ -42,21 +51,26 @@ type Config struct {
 	DebugListener                   string         `yaml:"debug-listener"`
 	CertPathTLS                     string         `yaml:"tls-cert"`
 	KeyPathTLS                      string         `yaml:"tls-key"`
+	ClientCAPathTLS                 string         `yaml:"tls-client-ca"`
 	StatusChecks                    *listFlag      `yaml:"status-checks"`
 	PrintVersion                    bool           `yaml:"version"`
 	MaxLoopbacks                    int            `yaml:"max-loopbacks"`
 	DefaultHTTPStatus               int            `yaml:"default-http-status"`
 	PluginDir                       string         `yaml:"plugindir"`
 	LoadBalancerHealthCheckInterval time.Duration  `yaml:"lb-healthcheck-interval"`
+	Zone                            string         `yaml:"zone"`
 	ReverseSourcePredicate          bool           `yaml:"reverse-source-predicate"`
+	GeoIPDatabase                   string         `yaml:"geoip-database"`
 	RemoveHopHeaders                bool           `yaml:"remove-hop-headers"`
 	RfcPatchPath                    bool           `yaml:"rfc-patch-path"`
+	NormalizePath                   bool           `yaml:"normalize-path"`
 	MaxAuditBody                    int            `yaml:"max-audit-body"`
 	EnableBreakers                  bool           `yaml:"enable-breakers"`
 	Breakers                        breakerFlags   `yaml:"breaker"`
 	EnableRatelimiters              bool           `yaml:"enable-ratelimits"`
 	Ratelimits                      ratelimitFlags `yaml:"ratelimits"`
 	EnableRouteLIFOMetrics          bool           `yaml:"enable-route-lifo-metrics"`
+	EnableRouteInflightMetrics      bool           `yaml:"enable-route-inflight-metrics"`
 	MetricsFlavour                  *listFlag      `yaml:"metrics-flavour"`
 	FilterPlugins                   *pluginFlag    `yaml:"filter-plugin"`
 	PredicatePlugins                *pluginFlag    `yaml:"predicate-plugin"`
@@ -97,6 +111,12 @@ type Config struct {
 	MetricsUseExpDecaySample            bool      `yaml:"metrics-exp-decay-sample"`
 	HistogramMetricBucketsString        string    `yaml:"histogram-metric-buckets"`
 	HistogramMetricBuckets              []float64 `yaml:"-"`
+	RoutingHistogramMetricBucketsString string    `yaml:"routing-histogram-metric-buckets"`
+	RoutingHistogramMetricBuckets       []float64 `yaml:"-"`
+	BackendHistogramMetricBucketsString string    `yaml:"backend-histogram-metric-buckets"`
+	BackendHistogramMetricBuckets       []float64 `yaml:"-"`
+	FilterHistogramMetricBucketsString  string    `yaml:"filter-histogram-metric-buckets"`
+	FilterHistogramMetricBuckets        []float64 `yaml:"-"`
 	DisableMetricsCompat                bool      `yaml:"disable-metrics-compat"`
 	ApplicationLog                      string    `yaml:"application-log"`
 	ApplicationLogLevel                 log.Level `yaml:"-"`
@@ -107,6 +127,8 @@ type Config struct {
 	AccessLogDisabled                   bool      `yaml:"access-log-disabled"`
 	AccessLogJSONEnabled                bool      `yaml:"access-log-json-enabled"`
 	AccessLogStripQuery                 bool      `yaml:"access-log-strip-query"`
+	AccessLogJSONFields                 *listFlag `yaml:"access-log-json-fields"`
+	AccessLogJSONAdditionalFields       *listFlag `yaml:"access-log-json-additional-fields"`
 	SuppressRouteUpdateLogs             bool      `yaml:"suppress-route-update-logs"`
 
 	// route sources:
@@ -117,17 +139,23 @@ type Config struct {
 	EtcdOAuthToken            string               `yaml:"etcd-oauth-token"`
 	EtcdUsername              string               `yaml:"etcd-username"`
 	EtcdPassword              string               `yaml:"etcd-password"`
+	ConsulAddress             string               `yaml:"consul-address"`
+	ConsulKVPrefix            string               `yaml:"consul-kv-prefix"`
+	ConsulToken               string               `yaml:"consul-token"`
+	ConsulTimeout             time.Duration        `yaml:"consul-timeout"`
 	InnkeeperURL              string               `yaml:"innkeeper-url"`
 	InnkeeperAuthToken        string               `yaml:"innkeeper-auth-token"`
 	InnkeeperPreRouteFilters  string               `yaml:"innkeeper-pre-route-filters"`
 	InnkeeperPostRouteFilters string               `yaml:"innkeeper-post-route-filters"`
 	RoutesFile                string               `yaml:"routes-file"`
+	RoutesDir                 string               `yaml:"routes-dir"`
 	RoutesURLs                *listFlag            `yaml:"routes-urls"`
 	InlineRoutes              string               `yaml:"inline-routes"`
 	AppendFilters             *defaultFiltersFlags `yaml:"default-filters-append"`
 	PrependFilters            *defaultFiltersFlags `yaml:"default-filters-prepend"`
 	EditRoute                 *routeChangerConfig  `yaml:"edit-route"`
 	CloneRoute                *routeChangerConfig  `yaml:"clone-route"`
+	RouteTemplateVariables    *routeTemplateFlags  `yaml:"route-template-variables"`
 	SourcePollTimeout         int64                `yaml:"source-poll-timeout"`
 	WaitFirstRouteLoad        bool                 `yaml:"wait-first-route-load"`
 
@@ -136,6 +164,8 @@ type Config struct {
 	ForwardedHeaders                net.ForwardedHeaders `yaml:"-"`
 	ForwardedHeadersExcludeCIDRList *listFlag            `yaml:"forwarded-headers-exclude-cidrs"`
 	ForwardedHeadersExcludeCIDRs    net.IPNets           `yaml:"-"`
+	TrustedProxiesCIDRList          *listFlag            `yaml:"trusted-proxies"`
+	TrustedProxies                  net.IPNets           `yaml:"-"`
 
 	// host patch:
 	NormalizeHost bool          `yaml:"normalize-host"`
@@ -151,6 +181,7 @@ type Config struct {
 	KubernetesHTTPSRedirect                 bool                `yaml:"kubernetes-https-redirect"`
 	KubernetesHTTPSRedirectCode             int                 `yaml:"kubernetes-https-redirect-code"`
 	KubernetesIngressV1                     bool                `yaml:"kubernetes-ingress-v1"`
+	KubernetesEnableEndpointSlices          bool                `yaml:"enable-kubernetes-endpointslices"`
 	KubernetesIngressClass                  string              `yaml:"kubernetes-ingress-class"`
 	KubernetesRouteGroupClass               string              `yaml:"kubernetes-routegroup-class"`
 	WhitelistedHealthCheckCIDR              string              `yaml:"whitelisted-healthcheck-cidr"`
@@ -230,6 +261,7 @@ type Config struct {
 	ExpectContinueTimeoutBackend time.Duration `yaml:"expect-continue-timeout-backend"`
 	MaxIdleConnsBackend          int           `yaml:"max-idle-connection-backend"`
 	DisableHTTPKeepalives        bool          `yaml:"disable-http-keepalives"`
+	IdempotentRetries            int           `yaml:"idempotent-retries"`
 
 	// swarm:
 	EnableSwarm bool `yaml:"enable-swarm"`
@@ -253,7 +285,8 @@ type Config struct {
 	SwarmStaticSelf                   string        `yaml:"swarm-static-self"`
 	SwarmStaticOther                  string        `yaml:"swarm-static-other"`
 
-	ClusterRatelimitMaxGroupShards int `yaml:"cluster-ratelimit-max-group-shards"`
+	ClusterRatelimitMaxGroupShards   int  `yaml:"cluster-ratelimit-max-group-shards"`
+	EnableRatelimitResetMillisHeader bool `yaml:"enable-ratelimit-reset-ms-header"`
 }
 
 const (
@@ -278,10 +311,15 @@ func NewConfig() *Config {
 	cfg.PrependFilters = &defaultFiltersFlags{}
 	cfg.CloneRoute = &routeChangerConfig{}
 	cfg.EditRoute = &routeChangerConfig{}
+	cfg.RouteTemplateVariables = newRouteTemplateFlags()
 	cfg.KubernetesEastWestRangeDomains = commaListFlag()
 	cfg.RoutesURLs = commaListFlag()
+	cfg.AccessLogJSONFields = commaListFlag()
+	cfg.AccessLogJSONAdditionalFields = commaListFlag()
 	cfg.ForwardedHeadersList = commaListFlag()
 	cfg.ForwardedHeadersExcludeCIDRList = commaListFlag()
+	cfg.ProxyProtocolTrustedCIDRList = commaListFlag()
+	cfg.TrustedProxiesCIDRList = commaListFlag()
 	cfg.CompressEncodings = commaListFlag("gzip", "deflate", "br")
 
 	flag.StringVar(&cfg.ConfigFile, "config-file", "", "if provided the flags will be loaded/overwritten by the values on the file (yaml)")
@@ -292,6 +330,8 @@ func NewConfig() *Config {
 	flag.IntVar(&cfg.ExpectedBytesPerRequest, "expected-bytes-per-request", 50*1024, "bytes per request, that is used to calculate concurrency limits to buffer connection spikes")
 	flag.IntVar(&cfg.MaxTCPListenerConcurrency, "max-tcp-listener-concurrency", 0, "sets hardcoded max for TCP listener concurrency, normally calculated based on available memory cgroups with max TODO")
 	flag.IntVar(&cfg.MaxTCPListenerQueue, "max-tcp-listener-queue", 0, "sets hardcoded max queue size for TCP listener, normally calculated 10x concurrency with max TODO:50k")
+	flag.BoolVar(&cfg.ProxyProtocol, "proxy-protocol", false, "accept the PROXY protocol header, version 1 or 2, on the listener, to get the client address from L4 load balancers. Requires proxy-protocol-trusted-cidrs")
+	flag.Var(cfg.ProxyProtocolTrustedCIDRList, "proxy-protocol-trusted-cidrs", "comma separated list of the CIDRs of the load balancers, that are allowed to send the PROXY protocol header")
 	flag.BoolVar(&cfg.IgnoreTrailingSlash, "ignore-trailing-slash", false, "flag indicating to ignore trailing slashes in paths when routing")
 	flag.BoolVar(&cfg.Insecure, "insecure", false, "flag indicating to ignore the verification of the TLS certificates of the backend services")
 	flag.BoolVar(&cfg.ProxyPreserveHost, "proxy-preserve-host", false, "flag indicating to preserve the incoming request 'Host' header in the outgoing requests")
@@ -300,21 +340,26 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.DebugListener, "debug-listener", "", "when this address is set, skipper starts an additional listener returning the original and transformed requests")
 	flag.StringVar(&cfg.CertPathTLS, "tls-cert", "", "the path on the local filesystem to the certificate file(s) (including any intermediates), multiple may be given comma separated")
 	flag.StringVar(&cfg.KeyPathTLS, "tls-key", "", "the path on the local filesystem to the certificate's private key file(s), multiple keys may be given comma separated - the order must match the certs")
+	flag.StringVar(&cfg.ClientCAPathTLS, "tls-client-ca", "", "the path on the local filesystem to the CA certificate bundle used to verify the client certificates, when set, the clients may present a certificate that must be valid")
 	flag.Var(cfg.StatusChecks, "status-checks", "experimental URLs to check before reporting healthy on startup")
 	flag.BoolVar(&cfg.PrintVersion, "version", false, "print Skipper version")
 	flag.IntVar(&cfg.MaxLoopbacks, "max-loopbacks", proxy.DefaultMaxLoopbacks, "maximum number of loopbacks for an incoming request, set to -1 to disable loopbacks")
 	flag.IntVar(&cfg.DefaultHTTPStatus, "default-http-status", http.StatusNotFound, "default HTTP status used when no route is found for a request")
 	flag.StringVar(&cfg.PluginDir, "plugindir", "", "set the directory to load plugins from, default is ./")
 	flag.DurationVar(&cfg.LoadBalancerHealthCheckInterval, "lb-healthcheck-interval", 0, "use to set the health checker interval to check healthiness of former dead or unhealthy routes")
+	flag.StringVar(&cfg.Zone, "zone", "", "topology zone of the skipper instance, e.g. the availability zone, used by the zoneAware load balancer algorithm")
 	flag.BoolVar(&cfg.ReverseSourcePredicate, "reverse-source-predicate", false, "reverse the order of finding the client IP from X-Forwarded-For header")
+	flag.StringVar(&cfg.GeoIPDatabase, "geoip-database", "", "the path on the local filesystem to the MaxMind GeoIP2 or GeoLite2 country or city database, enables the ClientCountry predicate. The database is reloaded on SIGHUP")
 	flag.BoolVar(&cfg.RemoveHopHeaders, "remove-hop-headers", false, "enables removal of Hop-Headers according to RFC-2616")
 	flag.BoolVar(&cfg.RfcPatchPath, "rfc-patch-path", false, "patches the incoming request path to preserve uncoded reserved characters according to RFC 2616 and RFC 3986")
+	flag.BoolVar(&cfg.NormalizePath, "normalize-path", false, "normalizes the incoming request path before route matching: normalizes the percent-encoding, collapses duplicate slashes and removes dot segments according to RFC 3986")
 	flag.IntVar(&cfg.MaxAuditBody, "max-audit-body", 1024, "sets the max body to read to log in the audit log body")
 	flag.BoolVar(&cfg.EnableBreakers, "enable-breakers", false, enableBreakersUsage)
 	flag.Var(&cfg.Breakers, "breaker", breakerUsage)
 	flag.BoolVar(&cfg.EnableRatelimiters, "enable-ratelimits", false, enableRatelimitsUsage)
 	flag.Var(&cfg.Ratelimits, "ratelimits", ratelimitsUsage)
 	flag.BoolVar(&cfg.EnableRouteLIFOMetrics, "enable-route-lifo-metrics", false, "enable metrics for the individual route LIFO queues")
+	flag.BoolVar(&cfg.EnableRouteInflightMetrics, "enable-route-inflight-metrics", false, "enable sampling the number of requests currently handled for each route, for diagnostic purpose")
 	flag.Var(cfg.MetricsFlavour, "metrics-flavour", "Metrics flavour is used to change the exposed metrics format. Supported metric formats: 'codahale' and 'prometheus', you can select both of them")
 	flag.Var(cfg.FilterPlugins, "filter-plugin", "set a custom filter plugins to load, a comma separated list of name and arguments")
 	flag.Var(cfg.PredicatePlugins, "predicate-plugin", "set a custom predicate plugins to load, a comma separated list of name and arguments")
@@ -354,6 +399,9 @@ func NewConfig() *Config {
 	flag.BoolVar(&cfg.RouteCreationMetrics, "route-creation-metrics", false, "enables reporting for route creation times")
 	flag.BoolVar(&cfg.MetricsUseExpDecaySample, "metrics-exp-decay-sample", false, "use exponentially decaying sample in metrics")
 	flag.StringVar(&cfg.HistogramMetricBucketsString, "histogram-metric-buckets", "", "use custom buckets for prometheus histograms, must be a comma-separated list of numbers")
+	flag.StringVar(&cfg.RoutingHistogramMetricBucketsString, "routing-histogram-metric-buckets", "", "use custom buckets for the prometheus route lookup histograms, overriding histogram-metric-buckets, must be a comma-separated list of numbers")
+	flag.StringVar(&cfg.BackendHistogramMetricBucketsString, "backend-histogram-metric-buckets", "", "use custom buckets for the prometheus backend histograms, overriding histogram-metric-buckets, must be a comma-separated list of numbers")
+	flag.StringVar(&cfg.FilterHistogramMetricBucketsString, "filter-histogram-metric-buckets", "", "use custom buckets for the prometheus filter histograms, overriding histogram-metric-buckets, must be a comma-separated list of numbers")
 	flag.BoolVar(&cfg.DisableMetricsCompat, "disable-metrics-compat", false, "disables the default true value for all-filters-metrics, route-response-metrics, route-backend-errorCounters and route-stream-error-counters")
 	flag.StringVar(&cfg.ApplicationLog, "application-log", "", "output file for the application log. When not set, /dev/stderr is used")
 	flag.StringVar(&cfg.ApplicationLogLevelString, "application-log-level", "INFO", "log level for application logs, possible values: PANIC, FATAL, ERROR, WARN, INFO, DEBUG")
@@ -363,6 +411,8 @@ func NewConfig() *Config {
 	flag.BoolVar(&cfg.AccessLogDisabled, "access-log-disabled", false, "when this flag is set, no access log is printed")
 	flag.BoolVar(&cfg.AccessLogJSONEnabled, "access-log-json-enabled", false, "when this flag is set, log in JSON format is used")
 	flag.BoolVar(&cfg.AccessLogStripQuery, "access-log-strip-query", false, "when this flag is set, the access log strips the query strings from the access log")
+	flag.Var(cfg.AccessLogJSONFields, "access-log-json-fields", "comma separated list of the fields of the JSON access log entries, in the order of the output, e.g. method,uri,status,duration,route-id,backend-host. Requires access-log-json-enabled")
+	flag.Var(cfg.AccessLogJSONAdditionalFields, "access-log-json-additional-fields", "comma separated list of the keys of the additional data set by filters, e.g. by logStateBag, that are accepted in access-log-json-fields")
 	flag.BoolVar(&cfg.SuppressRouteUpdateLogs, "suppress-route-update-logs", false, "print only summaries on route updates/deletes")
 
 	// route sources:
@@ -373,11 +423,16 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.EtcdOAuthToken, "etcd-oauth-token", "", "optional token for OAuth authentication with etcd")
 	flag.StringVar(&cfg.EtcdUsername, "etcd-username", "", "optional username for basic authentication with etcd")
 	flag.StringVar(&cfg.EtcdPassword, "etcd-password", "", "optional password for basic authentication with etcd")
+	flag.StringVar(&cfg.ConsulAddress, "consul-address", "", "address of a Consul agent, enables loading route definitions from the Consul KV store")
+	flag.StringVar(&cfg.ConsulKVPrefix, "consul-kv-prefix", "skipper/routes", "Consul KV path where the route definitions are stored")
+	flag.StringVar(&cfg.ConsulToken, "consul-token", "", "optional Consul ACL token")
+	flag.DurationVar(&cfg.ConsulTimeout, "consul-timeout", 3*time.Second, "http client timeout duration for Consul")
 	flag.StringVar(&cfg.InnkeeperURL, "innkeeper-url", "", "API endpoint of the Innkeeper service, storing route definitions")
 	flag.StringVar(&cfg.InnkeeperAuthToken, "innkeeper-auth-token", "", "fixed token for innkeeper authentication")
 	flag.StringVar(&cfg.InnkeeperPreRouteFilters, "innkeeper-pre-route-filters", "", "filters to be prepended to each route loaded from Innkeeper")
 	flag.StringVar(&cfg.InnkeeperPostRouteFilters, "innkeeper-post-route-filters", "", "filters to be appended to each route loaded from Innkeeper")
 	flag.StringVar(&cfg.RoutesFile, "routes-file", "", "file containing route definitions")
+	flag.StringVar(&cfg.RoutesDir, "routes-dir", "", "directory containing route definitions in files with the .eskip extension, watched for changes")
 	flag.Var(cfg.RoutesURLs, "routes-urls", "comma separated URLs to route definitions in eskip format")
 	flag.StringVar(&cfg.InlineRoutes, "inline-routes", "", "inline routes in eskip format")
 	flag.Int64Var(&cfg.SourcePollTimeout, "source-poll-timeout", int64(3000), "polling timeout of the routing data sources, in milliseconds")
@@ -385,6 +440,7 @@ func NewConfig() *Config {
 	flag.Var(cfg.PrependFilters, "default-filters-prepend", "set of default filters to apply to prepend to all filters of all routes")
 	flag.Var(cfg.EditRoute, "edit-route", "match and edit filters and predicates of all routes")
 	flag.Var(cfg.CloneRoute, "clone-route", "clone all matching routes and replace filters and predicates of all matched routes")
+	flag.Var(cfg.RouteTemplateVariables, "route-template-variables", "variable of the route templates in the format name=value1,value2, the routes containing ${name} are expanded into one route for each value. Can be set multiple times")
 	flag.BoolVar(&cfg.WaitFirstRouteLoad, "wait-first-route-load", false, "prevent starting the listener before the first batch of routes were loaded")
 
 	// Forwarded headers
@@ -394,6 +450,7 @@ func NewConfig() *Config {
 		"X-Forwarded-Port=<port> sets X-Forwarded-Port value\n"+
 		"X-Forwarded-Proto=<http|https> sets X-Forwarded-Proto value")
 	flag.Var(cfg.ForwardedHeadersExcludeCIDRList, "forwarded-headers-exclude-cidrs", "disables addition of forwarded headers for the remote host IPs from the comma separated list of CIDRs")
+	flag.Var(cfg.TrustedProxiesCIDRList, "trusted-proxies", "comma separated list of the CIDRs of the trusted proxies. When set, the client IP is the rightmost X-Forwarded-For address that is not a trusted proxy, and the X-Forwarded-For header of requests from untrusted remote hosts is ignored")
 
 	flag.BoolVar(&cfg.NormalizeHost, "normalize-host", false, "converts request host to lowercase and removes port and trailing dot if any")
 
@@ -407,6 +464,7 @@ func NewConfig() *Config {
 	flag.BoolVar(&cfg.KubernetesHTTPSRedirect, "kubernetes-https-redirect", true, "automatic HTTP->HTTPS redirect route; valid only with kubernetes")
 	flag.IntVar(&cfg.KubernetesHTTPSRedirectCode, "kubernetes-https-redirect-code", 308, "overrides the default redirect code (308) when used together with -kubernetes-https-redirect")
 	flag.BoolVar(&cfg.KubernetesIngressV1, "kubernetes-ingress-v1", false, "enable kubernetes ingress version v1, defaults to version v1beta1")
+	flag.BoolVar(&cfg.KubernetesEnableEndpointSlices, "enable-kubernetes-endpointslices", false, "enables reading the backend endpoints from the kubernetes EndpointSlice resources, instead of the Endpoints")
 	flag.StringVar(&cfg.KubernetesIngressClass, "kubernetes-ingress-class", "", "ingress class regular expression used to filter ingress resources for kubernetes")
 	flag.StringVar(&cfg.KubernetesRouteGroupClass, "kubernetes-routegroup-class", "", "route group class regular expression used to filter route group resources for kubernetes")
 	flag.StringVar(&cfg.WhitelistedHealthCheckCIDR, "whitelisted-healthcheck-cidr", "", "sets the iprange/CIDRS to be whitelisted during healthcheck")
@@ -442,7 +500,7 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.Oauth2TokenCookieName, "oauth2-token-cookie-name", "oauth2-grant", "sets the name of the cookie where the encrypted token is stored")
 	flag.DurationVar(&cfg.WebhookTimeout, "webhook-timeout", 2*time.Second, "sets the webhook request timeout duration")
 	flag.StringVar(&cfg.OidcSecretsFile, "oidc-secrets-file", "", "file storing the encryption key of the OID Connect token")
-	flag.Var(cfg.CredentialPaths, "credentials-paths", "directories or files to watch for credentials to use by bearerinjector filter")
+	flag.Var(cfg.CredentialPaths, "credentials-paths", "directories or files to watch for credentials to use by bearerinjector and signRequest filters")
 	flag.DurationVar(&cfg.CredentialsUpdateInterval, "credentials-update-interval", 10*time.Minute, "sets the interval to update secrets")
 
 	// TLS client certs
@@ -483,6 +541,7 @@ func NewConfig() *Config {
 	flag.DurationVar(&cfg.ExpectContinueTimeoutBackend, "expect-continue-timeout-backend", 30*time.Second, "sets the HTTP expect continue timeout for backend connections")
 	flag.IntVar(&cfg.MaxIdleConnsBackend, "max-idle-connection-backend", 0, "sets the maximum idle connections for all backend connections")
 	flag.BoolVar(&cfg.DisableHTTPKeepalives, "disable-http-keepalives", false, "forces backend to always create a new connection")
+	flag.IntVar(&cfg.IdempotentRetries, "idempotent-retries", 0, "sets the maximum retries of idempotent requests to load balanced backends, when the connection fails before a response was received")
 
 	// Swarm:
 	flag.BoolVar(&cfg.EnableSwarm, "enable-swarm", false, "enable swarm communication between nodes in a skipper fleet")
@@ -504,6 +563,7 @@ func NewConfig() *Config {
 	flag.StringVar(&cfg.SwarmStaticOther, "swarm-static-other", "", "set static swarm all nodes, for example 127.0.0.1:9002,127.0.0.1:9003")
 
 	flag.IntVar(&cfg.ClusterRatelimitMaxGroupShards, "cluster-ratelimit-max-group-shards", 1, "sets the maximum number of group shards for the clusterRatelimit filter")
+	flag.BoolVar(&cfg.EnableRatelimitResetMillisHeader, "enable-ratelimit-reset-ms-header", false, "enables the X-Rate-Limit-Reset-Ms header with millisecond precision in the responses of the ratelimit filters")
 
 	return cfg
 }
@@ -533,11 +593,11 @@ func (c *Config) Parse() error {
 		flag.Parse()
 	}
 
-	checkDeprecated(configKeys,
-		"enable-prometheus-metrics",
-		"api-usage-monitoring-default-client-tracking-pattern",
-		"enable-kubernetes-east-west",
-		"kubernetes-east-west-domain",
+	checkDeprecated(configKeys, c.DeprecatedOptionHandler,
+		deprecatedOption{name: "enable-prometheus-metrics", replacement: "metrics-flavour"},
+		deprecatedOption{name: "api-usage-monitoring-default-client-tracking-pattern"},
+		deprecatedOption{name: "enable-kubernetes-east-west", replacement: "kubernetes-east-west-range-domains"},
+		deprecatedOption{name: "kubernetes-east-west-domain", replacement: "kubernetes-east-west-range-domains"},
 	)
 
 	logLevel, err := log.ParseLevel(c.ApplicationLogLevelString)
@@ -560,10 +620,28 @@ func (c *Config) Parse() error {
 		return err
 	}
 
+	routingHistogramBuckets, err := metrics.ParseHistogramBuckets(c.RoutingHistogramMetricBucketsString)
+	if err != nil {
+		return fmt.Errorf("unable to parse routing-histogram-metric-buckets: %w", err)
+	}
+
+	backendHistogramBuckets, err := metrics.ParseHistogramBuckets(c.BackendHistogramMetricBucketsString)
+	if err != nil {
+		return fmt.Errorf("unable to parse backend-histogram-metric-buckets: %w", err)
+	}
+
+	filterHistogramBuckets, err := metrics.ParseHistogramBuckets(c.FilterHistogramMetricBucketsString)
+	if err != nil {
+		return fmt.Errorf("unable to parse filter-histogram-metric-buckets: %w", err)
+	}
+
 	c.ApplicationLogLevel = logLevel
 	c.KubernetesPathMode = kubernetesPathMode
 	c.KubernetesEastWestRangePredicates = kubernetesEastWestRangePredicates
 	c.HistogramMetricBuckets = histogramBuckets
+	c.RoutingHistogramMetricBuckets = routingHistogramBuckets
+	c.BackendHistogramMetricBuckets = backendHistogramBuckets
+	c.FilterHistogramMetricBuckets = filterHistogramBuckets
 
 	if c.ClientKeyFile != "" && c.ClientCertFile != "" {
 		certsFiles := strings.Split(c.ClientCertFile, ",")
@@ -587,6 +665,16 @@ func (c *Config) Parse() error {
 		return err
 	}
 
+	c.ProxyProtocolTrustedCIDRs, err = net.ParseCIDRs(c.ProxyProtocolTrustedCIDRList.values)
+	if err != nil {
+		return fmt.Errorf("invalid proxy protocol trusted CIDRs: %v", err)
+	}
+
+	c.TrustedProxies, err = net.ParseCIDRs(c.TrustedProxiesCIDRList.values)
+	if err != nil {
+		return fmt.Errorf("invalid trusted proxies: %v", err)
+	}
+
 	if c.NormalizeHost || c.KubernetesIngress {
 		c.HostPatch = net.HostPatch{
 			ToLower:           true,
@@ -615,6 +703,7 @@ func (c *Config) ToRouteSrvOptions() routesrv.Options {
 		KubernetesHTTPSRedirect:            c.KubernetesHTTPSRedirect,
 		KubernetesHTTPSRedirectCode:        c.KubernetesHTTPSRedirectCode,
 		KubernetesIngressV1:                c.KubernetesIngressV1,
+		KubernetesEnableEndpointSlices:     c.KubernetesEnableEndpointSlices,
 		KubernetesIngressClass:             c.KubernetesIngressClass,
 		KubernetesRouteGroupClass:          c.KubernetesRouteGroupClass,
 		KubernetesPathMode:                 c.KubernetesPathMode,
@@ -653,22 +742,28 @@ func (c *Config) ToOptions() skipper.Options {
 		ExpectedBytesPerRequest:         c.ExpectedBytesPerRequest,
 		MaxTCPListenerConcurrency:       c.MaxTCPListenerConcurrency,
 		MaxTCPListenerQueue:             c.MaxTCPListenerQueue,
+		ProxyProtocol:                   c.ProxyProtocol,
+		ProxyProtocolTrustedNetworks:    c.ProxyProtocolTrustedCIDRs,
 		IgnoreTrailingSlash:             c.IgnoreTrailingSlash,
 		DevMode:                         c.DevMode,
 		SupportListener:                 c.SupportListener,
 		DebugListener:                   c.DebugListener,
 		CertPathTLS:                     c.CertPathTLS,
 		KeyPathTLS:                      c.KeyPathTLS,
+		ClientCAPathTLS:                 c.ClientCAPathTLS,
 		MaxLoopbacks:                    c.MaxLoopbacks,
 		DefaultHTTPStatus:               c.DefaultHTTPStatus,
 		LoadBalancerHealthCheckInterval: c.LoadBalancerHealthCheckInterval,
+		Zone:                            c.Zone,
 		ReverseSourcePredicate:          c.ReverseSourcePredicate,
+		GeoIPDatabase:                   c.GeoIPDatabase,
 		MaxAuditBody:                    c.MaxAuditBody,
 		EnableBreakers:                  c.EnableBreakers,
 		BreakerSettings:                 c.Breakers,
 		EnableRatelimiters:              c.EnableRatelimiters,
 		RatelimitSettings:               c.Ratelimits,
 		EnableRouteLIFOMetrics:          c.EnableRouteLIFOMetrics,
+		EnableRouteInflightMetrics:      c.EnableRouteInflightMetrics,
 		MetricsFlavours:                 c.MetricsFlavour.values,
 		FilterPlugins:                   c.FilterPlugins.values,
 		PredicatePlugins:                c.PredicatePlugins.values,
@@ -707,6 +802,9 @@ func (c *Config) ToOptions() skipper.Options {
 		EnableRouteCreationMetrics:          c.RouteCreationMetrics,
 		MetricsUseExpDecaySample:            c.MetricsUseExpDecaySample,
 		HistogramMetricBuckets:              c.HistogramMetricBuckets,
+		RoutingHistogramMetricBuckets:       c.RoutingHistogramMetricBuckets,
+		BackendHistogramMetricBuckets:       c.BackendHistogramMetricBuckets,
+		FilterHistogramMetricBuckets:        c.FilterHistogramMetricBuckets,
 		DisableMetricsCompatibilityDefaults: c.DisableMetricsCompat,
 		ApplicationLogOutput:                c.ApplicationLog,
 		ApplicationLogPrefix:                c.ApplicationLogPrefix,
@@ -715,6 +813,8 @@ func (c *Config) ToOptions() skipper.Options {
 		AccessLogDisabled:                   c.AccessLogDisabled,
 		AccessLogJSONEnabled:                c.AccessLogJSONEnabled,
 		AccessLogStripQuery:                 c.AccessLogStripQuery,
+		AccessLogJSONFields:                 c.AccessLogJSONFields.values,
+		AccessLogJSONAdditionalFields:       c.AccessLogJSONAdditionalFields.values,
 		SuppressRouteUpdateLogs:             c.SuppressRouteUpdateLogs,
 
 		// route sources:
@@ -725,11 +825,16 @@ func (c *Config) ToOptions() skipper.Options {
 		EtcdOAuthToken:            c.EtcdOAuthToken,
 		EtcdUsername:              c.EtcdUsername,
 		EtcdPassword:              c.EtcdPassword,
+		ConsulAddress:             c.ConsulAddress,
+		ConsulKVPrefix:            c.ConsulKVPrefix,
+		ConsulToken:               c.ConsulToken,
+		ConsulTimeout:             c.ConsulTimeout,
 		InnkeeperUrl:              c.InnkeeperURL,
 		InnkeeperAuthToken:        c.InnkeeperAuthToken,
 		InnkeeperPreRouteFilters:  c.InnkeeperPreRouteFilters,
 		InnkeeperPostRouteFilters: c.InnkeeperPostRouteFilters,
 		WatchRoutesFile:           c.RoutesFile,
+		WatchRoutesDir:            c.RoutesDir,
 		RoutesURLs:                c.RoutesURLs.values,
 		InlineRoutes:              c.InlineRoutes,
 		DefaultFilters: &eskip.DefaultFilters{
@@ -738,6 +843,7 @@ func (c *Config) ToOptions() skipper.Options {
 		},
 		CloneRoute:         eskip.NewClone(c.CloneRoute.Reg, c.CloneRoute.Repl),
 		EditRoute:          eskip.NewEditor(c.EditRoute.Reg, c.EditRoute.Repl),
+		RouteTemplates:     eskip.NewRouteTemplates(c.RouteTemplateVariables.values),
 		SourcePollTimeout:  time.Duration(c.SourcePollTimeout) * time.Millisecond,
 		WaitFirstRouteLoad: c.WaitFirstRouteLoad,
 
@@ -749,6 +855,7 @@ func (c *Config) ToOptions() skipper.Options {
 		KubernetesHTTPSRedirect:            c.KubernetesHTTPSRedirect,
 		KubernetesHTTPSRedirectCode:        c.KubernetesHTTPSRedirectCode,
 		KubernetesIngressV1:                c.KubernetesIngressV1,
+		KubernetesEnableEndpointSlices:     c.KubernetesEnableEndpointSlices,
 		KubernetesIngressClass:             c.KubernetesIngressClass,
 		KubernetesRouteGroupClass:          c.KubernetesRouteGroupClass,
 		WhitelistedHealthCheckCIDR:         whitelistCIDRS,
@@ -817,6 +924,7 @@ func (c *Config) ToOptions() skipper.Options {
 		ExpectContinueTimeoutBackend: c.ExpectContinueTimeoutBackend,
 		MaxIdleConnsBackend:          c.MaxIdleConnsBackend,
 		DisableHTTPKeepalives:        c.DisableHTTPKeepalives,
+		IdempotentRetries:            c.IdempotentRetries,
 
 		// swarm:
 		EnableSwarm: c.EnableSwarm,
@@ -841,7 +949,8 @@ func (c *Config) ToOptions() skipper.Options {
 		SwarmStaticSelf:  c.SwarmStaticSelf,
 		SwarmStaticOther: c.SwarmStaticOther,
 
-		ClusterRatelimitMaxGroupShards: c.ClusterRatelimitMaxGroupShards,
+		ClusterRatelimitMaxGroupShards:   c.ClusterRatelimitMaxGroupShards,
+		EnableRatelimitResetMillisHeader: c.EnableRatelimitResetMillisHeader,
 	}
 
 	if c.PluginDir != "" {
@@ -864,6 +973,10 @@ func (c *Config) ToOptions() skipper.Options {
 		options.ProxyFlags |= proxy.PatchPath
 	}
 
+	if c.NormalizePath {
+		options.ProxyFlags |= proxy.NormalizePath
+	}
+
 	if c.Certificates != nil && len(c.Certificates) > 0 {
 		options.ClientTLS = &tls.Config{
 			Certificates: c.Certificates,
@@ -889,6 +1002,17 @@ func (c *Config) ToOptions() skipper.Options {
 		})
 	}
 
+	// wraps the forwarded headers handler, to get the client IP before
+	// the remote address is appended to the X-Forwarded-For header
+	if len(c.TrustedProxies) > 0 {
+		wrappers = append(wrappers, func(handler http.Handler) http.Handler {
+			return &net.TrustedProxiesHandler{
+				Trusted: c.TrustedProxies,
+				Handler: handler,
+			}
+		})
+	}
+
 	if c.HostPatch != (net.HostPatch{}) {
 		wrappers = append(wrappers, func(handler http.Handler) http.Handler {
 			return &net.HostPatchHandler{
@@ -933,17 +1057,12 @@ func (c *Config) parseHistogramBuckets() ([]float64, error) {
 		return prometheus.DefBuckets, nil
 	}
 
-	var result []float64
-	thresholds := strings.Split(c.HistogramMetricBucketsString, ",")
-	for _, v := range thresholds {
-		bucket, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse histogram-metric-buckets: %v", err)
-		}
-		result = append(result, bucket)
+	buckets, err := metrics.ParseHistogramBuckets(c.HistogramMetricBucketsString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse histogram-metric-buckets: %w", err)
 	}
-	sort.Float64s(result)
-	return result, nil
+
+	return buckets, nil
 }
 
 func (c *Config) parseForwardedHeaders() error {
@@ -980,16 +1099,36 @@ func (c *Config) parseEnv() {
 	}
 }
 
-func checkDeprecated(configKeys map[string]interface{}, options ...string) {
+// deprecatedOption names a deprecated flag, and optionally the flag that
+// should be used instead.
+type deprecatedOption struct {
+	name        string
+	replacement string
+}
+
+// checkDeprecated logs a warning for each of the deprecated options that was
+// set either as a command line flag or in the config file, and calls the
+// handler, too, when it is not nil. It looks up the flags by name, and therefore it
+// works for the flags of any type, e.g. registered with flag.IntVar or
+// flag.DurationVar, too.
+func checkDeprecated(configKeys map[string]interface{}, handler func(name, replacement, usage string), options ...deprecatedOption) {
 	flagKeys := make(map[string]bool)
 	flag.Visit(func(f *flag.Flag) { flagKeys[f.Name] = true })
 
-	for _, name := range options {
-		_, ck := configKeys[name]
-		_, fk := flagKeys[name]
+	for _, o := range options {
+		_, ck := configKeys[o.name]
+		_, fk := flagKeys[o.name]
 		if ck || fk {
-			f := flag.Lookup(name)
-			log.Warnf("%s: %s", f.Name, f.Usage)
+			f := flag.Lookup(o.name)
+			if o.replacement == "" {
+				log.Warnf("%s: %s", f.Name, f.Usage)
+			} else {
+				log.Warnf("flag %s is deprecated, use %s instead: %s", f.Name, o.replacement, f.Usage)
+			}
+
+			if handler != nil {
+				handler(f.Name, o.replacement, f.Usage)
+			}
 		}
 	}
 }
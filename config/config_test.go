@@ -111,10 +111,13 @@ func Test_NewConfig(t *testing.T) {
 				ApplicationLogPrefix:                    "[APP]",
 				EtcdPrefix:                              "/skipper",
 				EtcdTimeout:                             2 * time.Second,
+				ConsulKVPrefix:                          "skipper/routes",
+				ConsulTimeout:                           3 * time.Second,
 				AppendFilters:                           &defaultFiltersFlags{},
 				PrependFilters:                          &defaultFiltersFlags{},
 				CloneRoute:                              &routeChangerConfig{},
 				EditRoute:                               &routeChangerConfig{},
+				RouteTemplateVariables:                  newRouteTemplateFlags(),
 				SourcePollTimeout:                       3000,
 				KubernetesEastWestRangeDomains:          commaListFlag(),
 				KubernetesHealthcheck:                   true,
@@ -163,8 +166,12 @@ func Test_NewConfig(t *testing.T) {
 				SwarmLeaveTimeout:                       5 * time.Second,
 				TLSMinVersion:                           defaultMinTLSVersion,
 				RoutesURLs:                              commaListFlag(),
+				AccessLogJSONFields:                     commaListFlag(),
+				AccessLogJSONAdditionalFields:           commaListFlag(),
 				ForwardedHeadersList:                    commaListFlag(),
 				ForwardedHeadersExcludeCIDRList:         commaListFlag(),
+				TrustedProxiesCIDRList:                  commaListFlag(),
+				ProxyProtocolTrustedCIDRList:            commaListFlag(),
 				ClusterRatelimitMaxGroupShards:          1,
 				RefusePayload:                           multiFlag{"foo", "bar", "baz"},
 			},
@@ -290,4 +297,60 @@ func TestDeprecatedFlags(t *testing.T) {
 			t.Errorf("Deprecated marker expected for %q", message)
 		}
 	}
+
+	for _, expected := range []string{
+		"flag enable-prometheus-metrics is deprecated, use metrics-flavour instead",
+		"flag enable-kubernetes-east-west is deprecated, use kubernetes-east-west-range-domains instead",
+		"flag kubernetes-east-west-domain is deprecated, use kubernetes-east-west-range-domains instead",
+		"api-usage-monitoring-default-client-tracking-pattern: *Deprecated*",
+	} {
+		found := false
+		for message := range formatter.messages {
+			if strings.HasPrefix(message, expected) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Errorf("expected deprecation warning starting with %q", expected)
+		}
+	}
+}
+
+func TestDeprecatedOptionHandler(t *testing.T) {
+	o := log.StandardLogger().Out
+	f := log.StandardLogger().Formatter
+	defer func() {
+		log.SetOutput(o)
+		log.SetFormatter(f)
+	}()
+
+	formatter := &testFormatter{messages: make(map[string]log.Level)}
+	log.SetOutput(os.Stdout)
+	log.SetFormatter(formatter)
+
+	used := make(map[string]string)
+	replacements := make(map[string]string)
+	checkDeprecated(
+		map[string]interface{}{"enable-prometheus-metrics": false},
+		func(name, replacement, usage string) {
+			used[name] = usage
+			replacements[name] = replacement
+		},
+		deprecatedOption{name: "enable-prometheus-metrics", replacement: "metrics-flavour"},
+		deprecatedOption{name: "kubernetes-east-west-domain"},
+	)
+
+	if len(used) != 1 || !strings.Contains(used["enable-prometheus-metrics"], "*Deprecated*") {
+		t.Errorf("expected the handler to be called once for enable-prometheus-metrics, got: %v", used)
+	}
+
+	if replacements["enable-prometheus-metrics"] != "metrics-flavour" {
+		t.Errorf("expected the replacement metrics-flavour, got: %q", replacements["enable-prometheus-metrics"])
+	}
+
+	if len(formatter.messages) != 1 {
+		t.Errorf("expected the warning to be logged when the handler is set, got: %v", formatter.messages)
+	}
 }
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const routeTemplateFormatError = "invalid route template variable, expected format name=value1,value2 but got: '%v'"
+
+// routeTemplateFlags are the variables used to expand the route
+// templates. The flag can be repeated, once for every variable.
+type routeTemplateFlags struct {
+	values map[string][]string
+}
+
+func newRouteTemplateFlags() *routeTemplateFlags {
+	return &routeTemplateFlags{
+		values: make(map[string][]string),
+	}
+}
+
+func (r *routeTemplateFlags) String() string {
+	var variables []string
+	for name, values := range r.values {
+		variables = append(variables, name+"="+strings.Join(values, ","))
+	}
+
+	sort.Strings(variables)
+	return strings.Join(variables, " ")
+}
+
+func (r *routeTemplateFlags) Set(value string) error {
+	if r == nil {
+		return nil
+	}
+
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf(routeTemplateFormatError, value)
+	}
+
+	name := strings.TrimSpace(kv[0])
+	if name == "" {
+		return fmt.Errorf(routeTemplateFormatError, value)
+	}
+
+	var values []string
+	for _, v := range strings.Split(kv[1], ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return fmt.Errorf(routeTemplateFormatError, value)
+		}
+
+		values = append(values, v)
+	}
+
+	if r.values == nil {
+		r.values = make(map[string][]string)
+	}
+
+	r.values[name] = values
+	return nil
+}
+
+func (r *routeTemplateFlags) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var values map[string][]string
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+
+	r.values = values
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestRouteTemplateFlags(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		for _, tt := range []struct {
+			name     string
+			args     []string
+			expected map[string][]string
+			wantErr  bool
+		}{{
+			name:    "missing values",
+			args:    []string{"tenant"},
+			wantErr: true,
+		}, {
+			name:    "missing name",
+			args:    []string{"=acme"},
+			wantErr: true,
+		}, {
+			name:    "empty value",
+			args:    []string{"tenant=acme,,globex"},
+			wantErr: true,
+		}, {
+			name:     "single value",
+			args:     []string{"tenant=acme"},
+			expected: map[string][]string{"tenant": {"acme"}},
+		}, {
+			name:     "multiple values",
+			args:     []string{" tenant = acme, globex "},
+			expected: map[string][]string{"tenant": {"acme", "globex"}},
+		}, {
+			name:     "multiple variables",
+			args:     []string{"tenant=acme,globex", "stage=live,test"},
+			expected: map[string][]string{"tenant": {"acme", "globex"}, "stage": {"live", "test"}},
+		}, {
+			name:     "repeated variable",
+			args:     []string{"tenant=acme", "tenant=globex"},
+			expected: map[string][]string{"tenant": {"globex"}},
+		}} {
+			t.Run(tt.name, func(t *testing.T) {
+				f := newRouteTemplateFlags()
+
+				var err error
+				for _, a := range tt.args {
+					if err = f.Set(a); err != nil {
+						break
+					}
+				}
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				if err == nil && !reflect.DeepEqual(f.values, tt.expected) {
+					t.Errorf("parse failed, got: %v, expected: %v", f.values, tt.expected)
+				}
+			})
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		f := newRouteTemplateFlags()
+		f.Set("tenant=acme,globex")
+		f.Set("stage=live")
+
+		if s := f.String(); s != "stage=live tenant=acme,globex" {
+			t.Errorf("unexpected string: %s", s)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		f := newRouteTemplateFlags()
+		if err := yaml.Unmarshal([]byte("tenant: [acme, globex]\nstage: [live]\n"), f); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string][]string{"tenant": {"acme", "globex"}, "stage": {"live"}}
+		if !reflect.DeepEqual(f.values, expected) {
+			t.Errorf("parse failed, got: %v, expected: %v", f.values, expected)
+		}
+	})
+}
@@ -0,0 +1,403 @@
+/*
+Package consul implements a DataClient for reading the skipper route
+definitions from the Consul KV store, using the Consul service catalog
+to resolve the backend endpoints.
+
+(See the DataClient interface in the skipper/routing package.)
+
+The route definitions are stored under individual keys below a
+configured prefix, as eskip route expressions without the route id.
+When loaded from Consul, the routes get the path of the key relative to
+the prefix as id, where the path separators of the nested keys are
+replaced with double underscores, e.g. the key skipper/routes/team/foo
+results in the id team__foo.
+
+Routes can reference a service registered in the Consul catalog with a
+network backend, or with the single endpoint of a load balanced backend,
+of the form:
+
+    "consul://<service-name>"
+
+Such backends are replaced with a load balanced backend, containing the
+endpoints of the service instances that pass all their Consul health
+checks. When a service has no healthy instances, the route responds
+with 502 Bad Gateway.
+
+Example route stored in the key skipper/routes/foo:
+
+    Path("/foo") -> <roundRobin, "consul://foo">
+
+The client polls Consul for changes and provides only the changed and
+deleted routes on update, the same way as the other data clients.
+*/
+package consul
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	defaultAddress  = "http://127.0.0.1:8500"
+	defaultPrefix   = "skipper/routes"
+	defaultTimeout  = 3 * time.Second
+	tokenHeader     = "X-Consul-Token"
+	serviceScheme   = "consul"
+	endpointsScheme = "http"
+)
+
+// Options is used to initialize the Consul data client.
+type Options struct {
+
+	// Address of the Consul agent, e.g. http://127.0.0.1:8500.
+	Address string
+
+	// KVPrefix is the Consul KV path, where the route definitions are
+	// stored. Defaults to skipper/routes.
+	KVPrefix string
+
+	// Token is an optional Consul ACL token.
+	Token string
+
+	// Timeout of the requests to Consul. Defaults to 3 seconds.
+	Timeout time.Duration
+}
+
+// Client loads the routes from Consul.
+type Client struct {
+	address string
+	prefix  string
+	token   string
+	client  *http.Client
+	current map[string]*eskip.Route
+}
+
+type (
+	kvPair struct {
+		Key   string `json:"Key"`
+		Value []byte `json:"Value"`
+	}
+
+	serviceEntry struct {
+		Node struct {
+			Address string `json:"Address"`
+		} `json:"Node"`
+		Service struct {
+			Address string `json:"Address"`
+			Port    int    `json:"Port"`
+		} `json:"Service"`
+	}
+)
+
+var (
+	errNotFound               = errors.New("not found")
+	errUnexpectedHTTPResponse = errors.New("unexpected http response")
+)
+
+// New creates a Consul data client.
+func New(o Options) (*Client, error) {
+	if o.Address == "" {
+		o.Address = defaultAddress
+	}
+
+	if _, err := url.Parse(o.Address); err != nil {
+		return nil, fmt.Errorf("invalid consul address: %w", err)
+	}
+
+	if o.KVPrefix == "" {
+		o.KVPrefix = defaultPrefix
+	}
+
+	if o.Timeout == 0 {
+		o.Timeout = defaultTimeout
+	}
+
+	return &Client{
+		address: strings.TrimSuffix(o.Address, "/"),
+		prefix:  strings.Trim(o.KVPrefix, "/"),
+		token:   o.Token,
+		client:  &http.Client{Timeout: o.Timeout},
+	}, nil
+}
+
+func (c *Client) get(p string, q url.Values, v interface{}) error {
+	u := c.address + p
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.token != "" {
+		req.Header.Set(tokenHeader, c.token)
+	}
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d", errUnexpectedHTTPResponse, rsp.StatusCode)
+	}
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// keyPrefix returns the prefix of the route keys. It ends with a slash,
+// because Consul matches the keys by string prefix, and e.g. the prefix
+// routes should not match the keys below routes-old.
+func (c *Client) keyPrefix() string {
+	if c.prefix == "" {
+		return ""
+	}
+
+	return c.prefix + "/"
+}
+
+func (c *Client) loadKV() ([]kvPair, error) {
+	var pairs []kvPair
+	err := c.get("/v1/kv/"+c.keyPrefix(), url.Values{"recurse": []string{"true"}}, &pairs)
+	if err == errNotFound {
+		return nil, nil
+	}
+
+	return pairs, err
+}
+
+func (c *Client) loadEndpoints(service string) ([]string, error) {
+	var entries []serviceEntry
+	err := c.get(
+		"/v1/health/service/"+url.PathEscape(service),
+		url.Values{"passing": []string{"true"}},
+		&entries,
+	)
+	if err != nil && err != errNotFound {
+		return nil, err
+	}
+
+	var endpoints []string
+	for _, e := range entries {
+		address := e.Service.Address
+		if address == "" {
+			address = e.Node.Address
+		}
+
+		if address == "" || e.Service.Port == 0 {
+			continue
+		}
+
+		endpoints = append(endpoints, fmt.Sprintf(
+			"%s://%s",
+			endpointsScheme,
+			net.JoinHostPort(address, strconv.Itoa(e.Service.Port)),
+		))
+	}
+
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+func serviceName(r *eskip.Route) (string, bool) {
+	var backend string
+	switch r.BackendType {
+	case eskip.NetworkBackend:
+		backend = r.Backend
+	case eskip.LBBackend:
+		if len(r.LBEndpoints) != 1 {
+			return "", false
+		}
+
+		backend = r.LBEndpoints[0]
+	default:
+		return "", false
+	}
+
+	u, err := url.Parse(backend)
+	if err != nil || u.Scheme != serviceScheme || u.Host == "" {
+		return "", false
+	}
+
+	return u.Host, true
+}
+
+func shuntRoute(r *eskip.Route) {
+	r.Filters = append(r.Filters,
+		&eskip.Filter{
+			Name: filters.StatusName,
+			Args: []interface{}{502.0},
+		},
+		&eskip.Filter{
+			Name: filters.InlineContentName,
+			Args: []interface{}{"no endpoints"},
+		},
+	)
+
+	r.BackendType = eskip.ShuntBackend
+	r.Backend = ""
+	r.LBAlgorithm = ""
+	r.LBEndpoints = nil
+}
+
+// resolves the consul:// backends from the service catalog. The
+// endpoints of each service are loaded only once per cycle.
+func (c *Client) resolveServices(routes []*eskip.Route) error {
+	endpoints := make(map[string][]string)
+	for _, r := range routes {
+		service, ok := serviceName(r)
+		if !ok {
+			continue
+		}
+
+		eps, ok := endpoints[service]
+		if !ok {
+			var err error
+			eps, err = c.loadEndpoints(service)
+			if err != nil {
+				return err
+			}
+
+			endpoints[service] = eps
+		}
+
+		if len(eps) == 0 {
+			log.Warnf("consul: no healthy endpoints for service %s in route %s", service, r.Id)
+			shuntRoute(r)
+			continue
+		}
+
+		r.BackendType = eskip.LBBackend
+		r.Backend = ""
+		r.LBEndpoints = eps
+	}
+
+	return nil
+}
+
+// routeID returns the route id from the path of the key relative to the
+// prefix.
+func routeID(relativeKey string) string {
+	return strings.ReplaceAll(relativeKey, "/", "__")
+}
+
+func (c *Client) loadAndConvert() (map[string]*eskip.Route, error) {
+	pairs, err := c.loadKV()
+	if err != nil {
+		return nil, err
+	}
+
+	// sorting the keys to get the same routes in case of conflicting ids
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+
+	var routes []*eskip.Route
+	ids := make(map[string]string)
+	prefix := c.keyPrefix()
+	for _, p := range pairs {
+		if len(p.Value) == 0 || strings.HasSuffix(p.Key, "/") || !strings.HasPrefix(p.Key, prefix) {
+			continue
+		}
+
+		id := routeID(strings.TrimPrefix(p.Key, prefix))
+		if key, ok := ids[id]; ok {
+			log.Errorf("consul: route id %s of the key %s conflicts with the key %s", id, p.Key, key)
+			continue
+		}
+
+		r, err := eskip.Parse(string(p.Value))
+		if err != nil || len(r) != 1 {
+			log.Errorf("consul: failed to parse route %s: %v", p.Key, err)
+			continue
+		}
+
+		ids[id] = p.Key
+		r[0].Id = id
+		routes = append(routes, r[0])
+	}
+
+	if err := c.resolveServices(routes); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]*eskip.Route)
+	for _, r := range routes {
+		m[r.Id] = r
+	}
+
+	return m, nil
+}
+
+// LoadAll returns all the routes stored below the configured KV prefix.
+func (c *Client) LoadAll() ([]*eskip.Route, error) {
+	next, err := c.loadAndConvert()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]*eskip.Route, 0, len(next))
+	for _, r := range next {
+		routes = append(routes, r)
+	}
+
+	c.current = next
+	return routes, nil
+}
+
+// LoadUpdate returns the routes that changed, either in the KV store or
+// by a change of the healthy service endpoints, and the ids of the
+// deleted routes since the last load.
+func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
+	next, err := c.loadAndConvert()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		updatedRoutes []*eskip.Route
+		deletedIDs    []string
+	)
+
+	for id, r := range c.current {
+		if nr, ok := next[id]; !ok {
+			deletedIDs = append(deletedIDs, id)
+		} else if nr.String() != r.String() {
+			updatedRoutes = append(updatedRoutes, nr)
+		}
+	}
+
+	for id, r := range next {
+		if _, ok := c.current[id]; !ok {
+			updatedRoutes = append(updatedRoutes, r)
+		}
+	}
+
+	c.current = next
+	return updatedRoutes, deletedIDs, nil
+}
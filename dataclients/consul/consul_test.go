@@ -0,0 +1,261 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+)
+
+type testConsul struct {
+	mu       sync.Mutex
+	kv       map[string]string
+	otherKV  map[string]string
+	services map[string][]serviceEntry
+	token    string
+}
+
+func newTestConsul() *testConsul {
+	return &testConsul{
+		kv:       make(map[string]string),
+		otherKV:  make(map[string]string),
+		services: make(map[string][]serviceEntry),
+	}
+}
+
+func entry(address string, port int) serviceEntry {
+	var e serviceEntry
+	e.Service.Address = address
+	e.Service.Port = port
+	return e
+}
+
+func (tc *testConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.token = r.Header.Get(tokenHeader)
+
+	const (
+		kvPath     = "/v1/kv/"
+		healthPath = "/v1/health/service/"
+	)
+
+	var v interface{}
+	switch {
+	case strings.HasPrefix(r.URL.Path, kvPath):
+		// consul matches the keys by string prefix
+		prefix := strings.TrimPrefix(r.URL.Path, kvPath)
+
+		var pairs []kvPair
+		for k, v := range tc.kv {
+			if key := "skipper/routes/" + k; strings.HasPrefix(key, prefix) {
+				pairs = append(pairs, kvPair{Key: key, Value: []byte(v)})
+			}
+		}
+
+		for k, v := range tc.otherKV {
+			if strings.HasPrefix(k, prefix) {
+				pairs = append(pairs, kvPair{Key: k, Value: []byte(v)})
+			}
+		}
+
+		if len(pairs) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		v = pairs
+	case len(r.URL.Path) > len(healthPath) && r.URL.Path[:len(healthPath)] == healthPath:
+		if r.URL.Query().Get("passing") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		v = tc.services[r.URL.Path[len(healthPath):]]
+		if v == nil {
+			v = []serviceEntry{}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (tc *testConsul) set(f func(*testConsul)) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	f(tc)
+}
+
+func checkRoutes(t *testing.T, got []*eskip.Route, expected string) {
+	t.Helper()
+
+	e, err := eskip.Parse(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i].Id < got[j].Id })
+	sort.Slice(e, func(i, j int) bool { return e[i].Id < e[j].Id })
+	if eskip.String(got...) != eskip.String(e...) {
+		t.Errorf("invalid routes, got:\n%s\nexpected:\n%s", eskip.Print(eskip.PrettyPrintInfo{Pretty: true}, got...), eskip.Print(eskip.PrettyPrintInfo{Pretty: true}, e...))
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	tc := newTestConsul()
+	tc.kv["foo"] = `Path("/foo") -> <roundRobin, "consul://foo">`
+	tc.kv["bar"] = `Path("/bar") -> "consul://bar"`
+	tc.kv["baz"] = `Path("/baz") -> "https://www.example.org"`
+	tc.kv["invalid"] = `Path("/invalid") ->`
+	tc.services["foo"] = []serviceEntry{entry("10.0.0.2", 8080), entry("10.0.0.1", 8080)}
+
+	s := httptest.NewServer(tc)
+	defer s.Close()
+
+	c, err := New(Options{Address: s.URL, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, routes, `
+		foo: Path("/foo") -> <roundRobin, "http://10.0.0.1:8080", "http://10.0.0.2:8080">;
+		bar: Path("/bar") -> status(502) -> inlineContent("no endpoints") -> <shunt>;
+		baz: Path("/baz") -> "https://www.example.org";
+	`)
+
+	if tc.token != "secret" {
+		t.Error("failed to send the token")
+	}
+}
+
+func TestLoadAllNestedKeys(t *testing.T) {
+	tc := newTestConsul()
+	tc.kv["team1/foo"] = `Path("/team1/foo") -> "https://team1.example.org"`
+	tc.kv["team2/foo"] = `Path("/team2/foo") -> "https://team2.example.org"`
+	tc.kv["team1__foo"] = `Path("/conflict") -> "https://conflict.example.org"`
+	tc.otherKV["skipper/routes-old/bar"] = `Path("/bar") -> "https://old.example.org"`
+
+	s := httptest.NewServer(tc)
+	defer s.Close()
+
+	c, err := New(Options{Address: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, routes, `
+		team1__foo: Path("/team1/foo") -> "https://team1.example.org";
+		team2__foo: Path("/team2/foo") -> "https://team2.example.org";
+	`)
+}
+
+func TestLoadAllEmpty(t *testing.T) {
+	s := httptest.NewServer(newTestConsul())
+	defer s.Close()
+
+	c, err := New(Options{Address: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes) != 0 {
+		t.Error("unexpected routes")
+	}
+}
+
+func TestLoadUpdate(t *testing.T) {
+	tc := newTestConsul()
+	tc.kv["foo"] = `Path("/foo") -> "consul://foo"`
+	tc.kv["bar"] = `Path("/bar") -> "https://bar.example.org"`
+	tc.kv["baz"] = `Path("/baz") -> "https://baz.example.org"`
+	tc.services["foo"] = []serviceEntry{entry("10.0.0.1", 8080)}
+
+	s := httptest.NewServer(tc)
+	defer s.Close()
+
+	c, err := New(Options{Address: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, deleted, err := c.LoadUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes) != 0 || len(deleted) != 0 {
+		t.Fatal("unexpected update")
+	}
+
+	tc.set(func(tc *testConsul) {
+		tc.services["foo"] = append(tc.services["foo"], entry("10.0.0.2", 8080))
+		tc.kv["bar"] = `Path("/bar") -> "https://bar2.example.org"`
+		tc.kv["qux"] = `Path("/qux") -> <shunt>`
+		delete(tc.kv, "baz")
+	})
+
+	routes, deleted, err = c.LoadUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRoutes(t, routes, `
+		foo: Path("/foo") -> <"http://10.0.0.1:8080", "http://10.0.0.2:8080">;
+		bar: Path("/bar") -> "https://bar2.example.org";
+		qux: Path("/qux") -> <shunt>;
+	`)
+
+	if len(deleted) != 1 || deleted[0] != "baz" {
+		t.Errorf("invalid deleted ids: %v", deleted)
+	}
+}
+
+func TestLoadFails(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	c, err := New(Options{Address: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.LoadAll(); err == nil {
+		t.Error("failed to fail")
+	}
+
+	if _, _, err := c.LoadUpdate(); err == nil {
+		t.Error("failed to fail")
+	}
+}
@@ -31,12 +31,14 @@ const (
 	routeGroupClassKey         = "zalando.org/routegroup.class"
 	ServicesClusterURI         = "/api/v1/services"
 	EndpointsClusterURI        = "/api/v1/endpoints"
+	EndpointSlicesClusterURI   = "/apis/discovery.k8s.io/v1/endpointslices"
 	defaultKubernetesURL       = "http://localhost:8001"
 	IngressesNamespaceFmt      = "/apis/extensions/v1beta1/namespaces/%s/ingresses"
 	IngressesV1NamespaceFmt    = "/apis/networking.k8s.io/v1/namespaces/%s/ingresses"
 	routeGroupsNamespaceFmt    = "/apis/zalando.org/v1/namespaces/%s/routegroups"
 	ServicesNamespaceFmt       = "/api/v1/namespaces/%s/services"
 	EndpointsNamespaceFmt      = "/api/v1/namespaces/%s/endpoints"
+	EndpointSlicesNamespaceFmt = "/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices"
 	serviceAccountDir          = "/var/run/secrets/kubernetes.io/serviceaccount/"
 	serviceAccountTokenKey     = "token"
 	serviceAccountRootCAKey    = "ca.crt"
@@ -57,6 +59,7 @@ type clusterClient struct {
 	ingressClass    *regexp.Regexp
 	httpClient      *http.Client
 	ingressV1       bool
+	endpointSlices  bool
 
 	loggedMissingRouteGroups bool
 }
@@ -136,12 +139,18 @@ func newClusterClient(o Options, apiURL, ingCls, rgCls string, quit <-chan struc
 	if o.KubernetesIngressV1 {
 		ingressURI = IngressesV1ClusterURI
 	}
+
+	endpointsURI := EndpointsClusterURI
+	if o.KubernetesEnableEndpointSlices {
+		endpointsURI = EndpointSlicesClusterURI
+	}
 	c := &clusterClient{
 		ingressV1:       o.KubernetesIngressV1,
+		endpointSlices:  o.KubernetesEnableEndpointSlices,
 		ingressesURI:    ingressURI,
 		routeGroupsURI:  routeGroupsClusterURI,
 		servicesURI:     ServicesClusterURI,
-		endpointsURI:    EndpointsClusterURI,
+		endpointsURI:    endpointsURI,
 		ingressClass:    ingClsRx,
 		routeGroupClass: rgClsRx,
 		httpClient:      httpClient,
@@ -178,7 +187,11 @@ func (c *clusterClient) setNamespace(namespace string) {
 	}
 	c.routeGroupsURI = fmt.Sprintf(routeGroupsNamespaceFmt, namespace)
 	c.servicesURI = fmt.Sprintf(ServicesNamespaceFmt, namespace)
-	c.endpointsURI = fmt.Sprintf(EndpointsNamespaceFmt, namespace)
+	if c.endpointSlices {
+		c.endpointsURI = fmt.Sprintf(EndpointSlicesNamespaceFmt, namespace)
+	} else {
+		c.endpointsURI = fmt.Sprintf(EndpointsNamespaceFmt, namespace)
+	}
 }
 
 func (c *clusterClient) createRequest(uri string, body io.Reader) (*http.Request, error) {
@@ -416,6 +429,19 @@ func (c *clusterClient) loadEndpoints() (map[definitions.ResourceID]*endpoint, e
 	return result, nil
 }
 
+// loadEndpointSlices loads the EndpointSlices and merges them per service,
+// see endpointsFromSlices.
+func (c *clusterClient) loadEndpointSlices() (map[definitions.ResourceID]*endpoint, error) {
+	var slices endpointSliceList
+	if err := c.getJSON(c.endpointsURI, &slices); err != nil {
+		log.Debugf("requesting all endpointslices failed: %v", err)
+		return nil, err
+	}
+
+	log.Debugf("all endpointslices received: %d", len(slices.Items))
+	return endpointsFromSlices(slices.Items), nil
+}
+
 func (c *clusterClient) logMissingRouteGroupsOnce() {
 	if c.loggedMissingRouteGroups {
 		return
@@ -457,7 +483,12 @@ func (c *clusterClient) fetchClusterState() (*clusterState, error) {
 		return nil, err
 	}
 
-	endpoints, err := c.loadEndpoints()
+	var endpoints map[definitions.ResourceID]*endpoint
+	if c.endpointSlices {
+		endpoints, err = c.loadEndpointSlices()
+	} else {
+		endpoints, err = c.loadEndpoints()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -14,6 +14,7 @@ type Metadata struct {
 	Created     time.Time         `json:"creationTimestamp"`
 	Uid         string            `json:"uid"`
 	Annotations map[string]string `json:"annotations"`
+	Labels      map[string]string `json:"labels"`
 }
 
 func (meta *Metadata) ToResourceID() ResourceID {
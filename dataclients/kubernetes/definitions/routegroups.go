@@ -32,6 +32,7 @@ var (
 	errMissingBackendReference  = errors.New("missing backend reference")
 	errUnnamedBackend           = errors.New("unnamed backend")
 	errUnnamedBackendReference  = errors.New("unnamed backend reference")
+	errInvalidTrafficStickiness = errors.New("invalid traffic stickiness")
 )
 
 type RouteGroupList struct {
@@ -63,6 +64,23 @@ type RouteGroupSpec struct {
 	// and predicates. It defaults to catchall, if there are no
 	// routes.
 	Routes []*RouteSpec `json:"routes,omitempty"`
+
+	// TrafficStickiness, when set, pins the clients to the backend
+	// chosen by the traffic split with a cookie, so that the
+	// subsequent requests of the same client go to the same backend.
+	TrafficStickiness *TrafficStickiness `json:"trafficStickiness,omitempty"`
+}
+
+// TrafficStickiness defines the cookie used to pin the clients to the
+// backends of a traffic split.
+type TrafficStickiness struct {
+	// CookieName is the name of the cookie storing the name of the
+	// chosen backend.
+	CookieName string `json:"cookieName"`
+
+	// MaxAge is the max age of the cookie in seconds. Once the cookie
+	// expired, the backend is chosen again. 0 means session cookie.
+	MaxAge int `json:"maxAge,omitempty"`
 }
 
 // SkipperBackend is the type safe version of skipperBackendParser
@@ -351,6 +369,10 @@ func (rg *RouteGroupSpec) validate() error {
 		return errMissingBackendReference
 	}
 
+	if ts := rg.TrafficStickiness; ts != nil && (ts.CookieName == "" || ts.MaxAge < 0) {
+		return errInvalidTrafficStickiness
+	}
+
 	for i, r := range rg.Routes {
 		if err := r.validate(hasDefault, backends); err != nil {
 			return invalidRoute(i, err)
@@ -101,6 +101,28 @@ given ingress.
               serviceName: app-svc
               servicePort: 80
 
+Example - Ingress with cluster client ratelimiting
+
+The ratelimit annotation also accepts the arguments of the
+clusterClientRatelimit filter. The example shows 10 calls per hour per
+client are allowed for the given ingress across all skipper instances.
+Invalid annotation values are logged and ignored.
+
+    apiVersion: extensions/v1beta1
+    kind: Ingress
+    Metadata:
+      annotations:
+        zalando.org/ratelimit: '"groupSvcApp", 10, "1h"'
+      name: app
+    spec:
+      rules:
+      - host: app-default.example.org
+        http:
+          paths:
+          - backend:
+              serviceName: app-svc
+              servicePort: 80
+
 Example - Ingress with custom skipper filter configuration
 
 The example shows the use of 2 filters from skipper for the implicitly
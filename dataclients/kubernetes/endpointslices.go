@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+)
+
+// endpointSliceServiceLabel is the label of the EndpointSlices, naming the
+// service that they belong to.
+const endpointSliceServiceLabel = "kubernetes.io/service-name"
+
+type endpointSlice struct {
+	Meta        *definitions.Metadata    `json:"Metadata"`
+	AddressType string                   `json:"addressType"`
+	Endpoints   []*endpointSliceEndpoint `json:"endpoints"`
+	Ports       []*endpointSlicePort     `json:"ports"`
+}
+
+type endpointSliceList struct {
+	Items []*endpointSlice `json:"items"`
+}
+
+type endpointSliceEndpoint struct {
+	Addresses  []string                 `json:"addresses"`
+	Conditions *endpointSliceConditions `json:"conditions"`
+	Node       string                   `json:"nodeName"`
+	Zone       string                   `json:"zone"`
+}
+
+// endpointSliceConditions, when a condition is not set, it needs to be
+// interpreted as ready and not terminating.
+type endpointSliceConditions struct {
+	Ready       *bool `json:"ready"`
+	Terminating *bool `json:"terminating"`
+}
+
+type endpointSlicePort struct {
+	Name     *string `json:"name"`
+	Port     *int    `json:"port"`
+	Protocol string  `json:"protocol"`
+}
+
+// serviceID returns the resource ID of the service, that the slice belongs
+// to, and false if the slice is not owned by a service.
+func (s *endpointSlice) serviceID() (definitions.ResourceID, bool) {
+	if s.Meta == nil || s.Meta.Labels[endpointSliceServiceLabel] == "" {
+		return definitions.ResourceID{}, false
+	}
+
+	return newResourceID(s.Meta.Namespace, s.Meta.Labels[endpointSliceServiceLabel]), true
+}
+
+// serving returns true when the endpoint can receive traffic: it is ready,
+// and it is not terminating, e.g. it doesn't belong to a draining pod.
+func (e *endpointSliceEndpoint) serving() bool {
+	if e.Conditions == nil {
+		return true
+	}
+
+	if e.Conditions.Ready != nil && !*e.Conditions.Ready {
+		return false
+	}
+
+	return e.Conditions.Terminating == nil || !*e.Conditions.Terminating
+}
+
+func (s *endpointSlice) ports() []*port {
+	var ports []*port
+	for _, p := range s.Ports {
+		// a port without a number means all the ports
+		if p == nil || p.Port == nil {
+			continue
+		}
+
+		pi := &port{Port: *p.Port, Protocol: p.Protocol}
+		if p.Name != nil {
+			pi.Name = *p.Name
+		}
+
+		ports = append(ports, pi)
+	}
+
+	return ports
+}
+
+func (s *endpointSlice) addresses() []*address {
+	var addresses []*address
+	for _, e := range s.Endpoints {
+		if e == nil || !e.serving() {
+			continue
+		}
+
+		for _, a := range e.Addresses {
+			addresses = append(addresses, &address{IP: a, Node: e.Node, Zone: e.Zone})
+		}
+	}
+
+	return addresses
+}
+
+func portsKey(ports []*port) string {
+	keys := make([]string, 0, len(ports))
+	for _, p := range ports {
+		keys = append(keys, fmt.Sprintf("%s/%d/%s", p.Name, p.Port, p.Protocol))
+	}
+
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// endpointsFromSlices merges the EndpointSlices of each service into the
+// same structure as the legacy Endpoints resources have. The addresses of
+// the slices having the same ports are merged into a single subset. Only
+// the ready and not terminating endpoints are included. FQDN type slices
+// are ignored. The topology zone of the endpoints is kept, to be passed on
+// to the zoneAware load balancer algorithm.
+func endpointsFromSlices(slices []*endpointSlice) map[definitions.ResourceID]*endpoint {
+	result := make(map[definitions.ResourceID]*endpoint)
+	subsets := make(map[definitions.ResourceID]map[string]*subset)
+	for _, s := range slices {
+		if s == nil || s.AddressType == "FQDN" {
+			continue
+		}
+
+		id, ok := s.serviceID()
+		if !ok {
+			continue
+		}
+
+		ep, ok := result[id]
+		if !ok {
+			ep = &endpoint{Meta: &definitions.Metadata{Namespace: id.Namespace, Name: id.Name}}
+			result[id] = ep
+			subsets[id] = make(map[string]*subset)
+		}
+
+		ports := s.ports()
+		addresses := s.addresses()
+		if len(ports) == 0 || len(addresses) == 0 {
+			continue
+		}
+
+		key := portsKey(ports)
+		if ss, ok := subsets[id][key]; ok {
+			ss.Addresses = append(ss.Addresses, addresses...)
+			continue
+		}
+
+		ss := &subset{Addresses: addresses, Ports: ports}
+		subsets[id][key] = ss
+		ep.Subsets = append(ep.Subsets, ss)
+	}
+
+	return result
+}
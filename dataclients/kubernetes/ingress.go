@@ -7,10 +7,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/predicates"
 )
 
@@ -236,29 +238,107 @@ func countPathRoutes(r *eskip.Route) int {
 	return i
 }
 
-// parse filter and ratelimit annotation
-func annotationFilter(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
-	var annotationFilter string
-	if ratelimitAnnotationValue, ok := m.Annotations[ratelimitAnnotationKey]; ok {
-		annotationFilter = ratelimitAnnotationValue
+var ratelimitAnnotationFilters = map[string]bool{
+	filters.RatelimitName:                   true,
+	filters.ClientRatelimitName:             true,
+	filters.ClusterRatelimitName:            true,
+	filters.ClusterClientRatelimitName:      true,
+	filters.BackendRateLimitName:            true,
+	filters.LeakyBucketRatelimitName:        true,
+	filters.ClusterLeakyBucketRatelimitName: true,
+	filters.DisableRatelimitName:            true,
+	filters.LocalRatelimitName:              true,
+}
+
+// validateClusterClientRatelimitArgs checks the arguments of a
+// clusterClientRatelimit filter: group, max hits, time window and the
+// optional sources of the client key.
+func validateClusterClientRatelimitArgs(args []interface{}) error {
+	if len(args) < 3 {
+		return fmt.Errorf("invalid number of arguments: %d", len(args))
+	}
+
+	if group, ok := args[0].(string); !ok || group == "" {
+		return errors.New("invalid group name")
+	}
+
+	if hits, ok := args[1].(float64); !ok || hits <= 0 {
+		return errors.New("invalid max hits")
 	}
-	if val, ok := m.Annotations[skipperfilterAnnotationKey]; ok {
-		if annotationFilter != "" {
-			annotationFilter += " -> "
+
+	switch w := args[2].(type) {
+	case string:
+		if _, err := time.ParseDuration(w); err != nil {
+			return fmt.Errorf("invalid time window: %w", err)
+		}
+	case float64:
+		if w <= 0 {
+			return errors.New("invalid time window")
 		}
-		annotationFilter += val
+	default:
+		return errors.New("invalid time window")
 	}
 
-	if annotationFilter != "" {
-		annotationFilters, err := eskip.ParseFilters(annotationFilter)
-		if err == nil {
-			return annotationFilters
+	for _, a := range args[3:] {
+		if _, ok := a.(string); !ok {
+			return errors.New("invalid client key source")
 		}
-		logger.Errorf("Can not parse annotation filters: %v", err)
 	}
+
 	return nil
 }
 
+// parse the ratelimit annotation. The value can either be a chain of
+// filters, typically ratelimit filters, or the arguments of a
+// clusterClientRatelimit filter. The filter chains are accepted as they
+// are, and their arguments are checked when the routes are created.
+func ratelimitAnnotationFilter(value string, logger *log.Entry) ([]*eskip.Filter, error) {
+	if fs, err := eskip.ParseFilters(value); err == nil {
+		for _, f := range fs {
+			if !ratelimitAnnotationFilters[f.Name] {
+				logger.Warnf("Filter %s in the ratelimit annotation is not a ratelimit filter", f.Name)
+			}
+		}
+
+		return fs, nil
+	}
+
+	fs, err := eskip.ParseFilters(fmt.Sprintf("%s(%s)", filters.ClusterClientRatelimitName, value))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateClusterClientRatelimitArgs(fs[0].Args); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// parse filter and ratelimit annotation
+func annotationFilter(m *definitions.Metadata, logger *log.Entry) []*eskip.Filter {
+	var ratelimitFilters []*eskip.Filter
+	if val, ok := m.Annotations[ratelimitAnnotationKey]; ok && val != "" {
+		var err error
+		ratelimitFilters, err = ratelimitAnnotationFilter(val, logger)
+		if err != nil {
+			logger.Errorf("Can not parse ratelimit annotation, skipping it: %v", err)
+		}
+	}
+
+	if val, ok := m.Annotations[skipperfilterAnnotationKey]; ok && val != "" {
+		annotationFilters, err := eskip.ParseFilters(val)
+		if err != nil {
+			logger.Errorf("Can not parse annotation filters: %v", err)
+			return nil
+		}
+
+		return append(ratelimitFilters, annotationFilters...)
+	}
+
+	return ratelimitFilters
+}
+
 // parse predicate annotation
 func annotationPredicate(m *definitions.Metadata) string {
 	var annotationPredicate string
@@ -2,9 +2,11 @@ package kubernetes
 
 import (
 	"fmt"
+	"net/url"
 	"strconv"
 
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
+	"github.com/zalando/skipper/loadbalancer"
 )
 
 type servicePort struct {
@@ -83,6 +85,10 @@ type endpointList struct {
 }
 
 func formatEndpoint(a *address, p *port, protocol string) string {
+	if a.Zone != "" {
+		return fmt.Sprintf("%s://%s:%d?%s=%s", protocol, a.IP, p.Port, loadbalancer.ZoneParam, url.QueryEscape(a.Zone))
+	}
+
 	return fmt.Sprintf("%s://%s:%d", protocol, a.IP, p.Port)
 }
 
@@ -145,6 +151,9 @@ type subset struct {
 type address struct {
 	IP   string `json:"ip"`
 	Node string `json:"nodeName"`
+
+	// Zone is only available from the EndpointSlices
+	Zone string `json:"-"`
 }
 
 type port struct {
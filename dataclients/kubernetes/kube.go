@@ -91,6 +91,11 @@ type Options struct {
 	// for v1beta1, so we have to provide a migration path and this will someday become the default.
 	KubernetesIngressV1 bool
 
+	// KubernetesEnableEndpointSlices is used to read the backend endpoints from the
+	// discovery.k8s.io/v1 EndpointSlice resources, instead of the legacy Endpoints.
+	// Only the ready and not terminating endpoints are used.
+	KubernetesEnableEndpointSlices bool
+
 	// *DEPRECATED* KubernetesEnableEastWest if set adds automatically routes
 	// with "%s.%s.skipper.cluster.local" domain pattern
 	KubernetesEnableEastWest bool
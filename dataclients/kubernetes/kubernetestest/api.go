@@ -22,10 +22,11 @@ type TestAPIOptions struct {
 }
 
 type namespace struct {
-	services    []byte
-	ingresses   []byte
-	routeGroups []byte
-	endpoints   []byte
+	services       []byte
+	ingresses      []byte
+	routeGroups    []byte
+	endpoints      []byte
+	endpointSlices []byte
 }
 
 type api struct {
@@ -41,7 +42,7 @@ func NewAPI(o TestAPIOptions, specs ...io.Reader) (*api, error) {
 	a := &api{
 		namespaces: make(map[string]namespace),
 		pathRx: regexp.MustCompile(
-			"(/namespaces/([^/]+))?/(services|ingresses|routegroups|endpoints)",
+			"(/namespaces/([^/]+))?/(services|ingresses|routegroups|endpointslices|endpoints)",
 		),
 	}
 
@@ -160,6 +161,8 @@ func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		b = ns.routeGroups
 	case "endpoints":
 		b = ns.endpoints
+	case "endpointslices":
+		b = ns.endpointSlices
 	default:
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -185,6 +188,10 @@ func initNamespace(kinds map[string][]interface{}) (ns namespace, err error) {
 		return
 	}
 
+	if err = itemsJSON(&ns.endpointSlices, kinds["EndpointSlice"]); err != nil {
+		return
+	}
+
 	return
 }
 
@@ -32,6 +32,7 @@ type fixtureSet struct {
 
 type kubeOptionsParser struct {
 	IngressV1                bool               `yaml:"ingressv1"`
+	EndpointSlices           bool               `yaml:"endpointSlices"`
 	EastWest                 bool               `yaml:"eastWest"`
 	EastWestDomain           string             `yaml:"eastWestDomain"`
 	EastWestRangeDomains     []string           `yaml:"eastWestRangeDomains"`
@@ -209,6 +210,7 @@ func testFixture(t *testing.T, f fixtureSet) {
 		}
 
 		o.KubernetesIngressV1 = kop.IngressV1
+		o.KubernetesEnableEndpointSlices = kop.EndpointSlices
 		o.KubernetesEnableEastWest = kop.EastWest
 		o.KubernetesEastWestDomain = kop.EastWestDomain
 		o.KubernetesEastWestRangeDomains = kop.EastWestRangeDomains
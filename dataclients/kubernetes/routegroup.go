@@ -9,6 +9,7 @@ import (
 
 	"github.com/zalando/skipper/dataclients/kubernetes/definitions"
 	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters/cookie"
 	"github.com/zalando/skipper/loadbalancer"
 )
 
@@ -51,6 +52,10 @@ type routeContext struct {
 type calculatedTraffic struct {
 	value   float64
 	balance int
+
+	// split is set when the traffic is split between multiple backends
+	split       bool
+	backendName string
 }
 
 func eskipError(typ, e string, err error) error {
@@ -194,6 +199,8 @@ func calculateTraffic(b []*definitions.BackendReference) map[string]*calculatedT
 
 		sum -= weights[i]
 		t[bi.BackendName].balance = len(b) - i - 2
+		t[bi.BackendName].split = len(b) > 1
+		t[bi.BackendName].backendName = bi.BackendName
 	}
 
 	return t
@@ -213,12 +220,26 @@ func trafficBalance(t *calculatedTraffic) []*eskip.Predicate {
 	return balance
 }
 
-func configureTraffic(r *eskip.Route, t *calculatedTraffic) {
+// configureTraffic sets the Traffic predicates of the route. With traffic
+// stickiness, the Traffic predicates match the clients already pinned to
+// the backend by the cookie, and the routes set the cookie, unless the
+// backend doesn't receive traffic.
+func configureTraffic(r *eskip.Route, t *calculatedTraffic, s *definitions.TrafficStickiness) {
+	sticky := s != nil && t.split && t.value > 0
+	if sticky {
+		r.Filters = appendFilter(r.Filters, "responseCookie", s.CookieName, t.backendName, float64(s.MaxAge), cookie.ChangeOnlyArg)
+	}
+
 	if t.value == 1 {
 		return
 	}
 
-	r.Predicates = appendPredicate(r.Predicates, "Traffic", t.value)
+	if sticky {
+		r.Predicates = appendPredicate(r.Predicates, "Traffic", t.value, s.CookieName, t.backendName)
+	} else {
+		r.Predicates = appendPredicate(r.Predicates, "Traffic", t.value)
+	}
+
 	r.Predicates = append(r.Predicates, trafficBalance(t)...)
 }
 
@@ -406,7 +427,7 @@ func implicitGroupRoutes(ctx *routeGroupContext) ([]*eskip.Route, error) {
 			ri.Predicates = appendPredicate(ri.Predicates, "Host", ctx.hostRx)
 		}
 
-		configureTraffic(ri, ctx.defaultBackendTraffic[beref.BackendName])
+		configureTraffic(ri, ctx.defaultBackendTraffic[beref.BackendName], rg.Spec.TrafficStickiness)
 		if be.Type == definitions.ServiceBackend {
 			if err := applyDefaultFilters(ctx, be.ServiceName, ri); err != nil {
 				log.Errorf("[routegroup]: failed to retrieve default filters: %v.", err)
@@ -515,7 +536,7 @@ func explicitGroupRoutes(ctx *routeGroupContext) ([]*eskip.Route, error) {
 					return nil, err
 				}
 
-				configureTraffic(r, backendTraffic[bref.BackendName])
+				configureTraffic(r, backendTraffic[bref.BackendName], rg.Spec.TrafficStickiness)
 				storeHostRoute(ctx, r)
 				routes = append(routes, r)
 				routes = appendEastWest(ctx, routes, r)
@@ -57,9 +57,15 @@ func Copy(r *Route) *Route {
 		return nil
 	}
 
+	comments := r.Comments
 	r = Canonical(r)
 	c := &Route{}
 	c.Id = r.Id
+	if len(comments) > 0 {
+		c.Comments = make([]string, len(comments))
+		copy(c.Comments, comments)
+	}
+
 	c.Predicates = CopyPredicates(r.Predicates)
 	c.Filters = CopyFilters(r.Filters)
 	c.BackendType = r.BackendType
@@ -226,15 +226,24 @@ must set the target url explicitly.
 
 Comments
 
-An eskip document can contain comments. The rule for comments is simple:
-everything is a comment that starts with '//' and ends with a new-line
-character.
+An eskip document can contain comments. Line comments start with '//' and
+end with a new-line character, while block comments start with a slash and
+an asterisk, end with an asterisk and a slash, and they can span multiple
+lines.
 
 Example with comments:
 
 	// forwards to the API endpoint
 	route1: Path("/api") -> "https://api.example.org";
-	route2: * -> <shunt> // everything else 404
+
+	// everything else
+	// returns 404
+	route2: * -> <shunt> // not found
+
+The comments starting on their own line before a route ID are stored in the
+Comments field of the parsed route, and they are printed before the route
+definition when serializing a routing table. Other comments are dropped
+during parsing.
 
 
 Regular expressions
@@ -254,9 +263,10 @@ without the matcher and backend part of a full route expression.
 Parsing
 
 Parsing a routing table or a route expression happens with the
-eskip.Parse function. In case of grammar error, it returns an error with
-the approximate position of the invalid syntax element, otherwise it
-returns a list of structured, in-memory route definitions.
+eskip.Parse function. In case of grammar error, it returns an error of type
+*eskip.ParseError, with the line, the column and a snippet of the invalid
+syntax element, otherwise it returns a list of structured, in-memory route
+definitions.
 
 The eskip parser does not validate the routes against all semantic rules,
 e.g., whether a filter or a custom predicate implementation is available.
@@ -269,6 +279,11 @@ Serializing a single route happens by calling its String method.
 Serializing a complete routing table happens by calling the
 eskip.String method.
 
+The eskip.PrettyPrint method serializes a routing table in a stable,
+multiline format, that is more suitable for storing in version control:
+the routes are ordered by their IDs, and each predicate and filter is
+printed on its own, indented line.
+
 
 JSON
 
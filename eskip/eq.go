@@ -16,6 +16,13 @@ func comparePredicateName(p []*Predicate) func(int, int) bool {
 	}
 }
 
+func sortedPredicates(p []*Predicate) []*Predicate {
+	s := make([]*Predicate, len(p))
+	copy(s, p)
+	sort.SliceStable(s, comparePredicateName(s))
+	return s
+}
+
 func hasDuplicateID(r []*Route) bool {
 	for i := 1; i < len(r); i++ {
 		if r[i-1].Id == r[i].Id {
@@ -73,8 +80,11 @@ func eq2(left, right *Route) bool {
 		return false
 	}
 
-	for i := range lc.Predicates {
-		lp, rp := lc.Predicates[i], rc.Predicates[i]
+	// the order of the predicates with different names doesn't change
+	// whether a route matches, only the order of their evaluation:
+	lps, rps := sortedPredicates(lc.Predicates), sortedPredicates(rc.Predicates)
+	for i := range lps {
+		lp, rp := lps[i], rps[i]
 		if lp.Name != rp.Name || !eqArgs(lp.Args, rp.Args) {
 			return false
 		}
@@ -176,6 +186,9 @@ func EqLists(r ...[]*Route) bool {
 // Canonical creates a copy of the route, but doesn't necessarily creates a
 // copy of every field. See also Copy().
 //
+// The non-legacy predicates keep their order, because the routing
+// evaluates them from left to right.
+//
 func Canonical(r *Route) *Route {
 	if r == nil {
 		return nil
@@ -243,7 +256,6 @@ func Canonical(r *Route) *Route {
 		c.Predicates = nil
 	}
 
-	sort.Slice(c.Predicates, comparePredicateName(c.Predicates))
 	c.Filters = r.Filters
 
 	c.BackendType = r.BackendType
@@ -126,6 +126,13 @@ func TestEq(t *testing.T) {
 			LBEndpoints: []string{"https://one.example.org", "https://two.example.org"},
 		}},
 		expect: true,
+	}, {
+		title: "eq predicates in different order",
+		routes: []*Route{
+			{Predicates: []*Predicate{{Name: "Foo"}, {Name: "Bar"}}},
+			{Predicates: []*Predicate{{Name: "Bar"}, {Name: "Foo"}}},
+		},
+		expect: true,
 	}, {
 		title:  "one out of 3 non-eq",
 		routes: []*Route{{Id: "foo"}, {Id: "foo"}, {Id: "bar"}},
@@ -272,6 +279,24 @@ func TestCanonical(t *testing.T) {
 				{Name: "HeaderRegexp", Args: []interface{}{"X-Foo", "foo"}},
 			},
 		},
+	}, {
+		title: "predicates keep their order",
+		route: &Route{
+			Method: "GET",
+			Predicates: []*Predicate{
+				{Name: "Traffic", Args: []interface{}{0.1}},
+				{Name: "Cookie", Args: []interface{}{"foo"}},
+				{Name: "Auth", Args: []interface{}{"bar"}},
+			},
+		},
+		expect: &Route{
+			Predicates: []*Predicate{
+				{Name: "Method", Args: []interface{}{"GET"}},
+				{Name: "Traffic", Args: []interface{}{0.1}},
+				{Name: "Cookie", Args: []interface{}{"foo"}},
+				{Name: "Auth", Args: []interface{}{"bar"}},
+			},
+		},
 	}, {
 		title:  "legacy shunt",
 		route:  &Route{Shunt: true},
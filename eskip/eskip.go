@@ -226,6 +226,7 @@ var errMixedProtocols = errors.New("loadbalancer endpoints cannot have mixed pro
 // document.
 type parsedRoute struct {
 	id          string
+	comments    []string
 	matchers    []*matcher
 	filters     []*Filter
 	shunt       bool
@@ -335,6 +336,13 @@ type Route struct {
 
 	// Namespace is deprecated and not used.
 	Namespace string
+
+	// Comments contains the comments preceding the route definition
+	// in an eskip document, including the comment markers, e.g.
+	// "// route to the new API". The comments are printed before the
+	// route definitions, but they are ignored by the comparison
+	// functions, and they are not part of the JSON representation.
+	Comments []string
 }
 
 type RoutePredicate func(*Route) bool
@@ -566,6 +574,7 @@ func newRouteDefinition(r *parsedRoute) (*Route, error) {
 
 	rd := &Route{}
 	rd.Id = r.id
+	rd.Comments = r.comments
 	rd.Filters = r.filters
 	rd.Shunt = r.shunt
 	rd.Backend = r.backend
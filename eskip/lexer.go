@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type token struct {
@@ -24,13 +25,51 @@ func (sf scannerFunc) scan(code string) (token, string, error) { return sf(code)
 
 type eskipLex struct {
 	code          string
+	initialCode   string
+	tokenStart    int
 	lastToken     *token
 	lastRouteID   string
 	err           error
 	initialLength int
 	routes        []*parsedRoute
+
+	// comments holds the comments since the last token, starting on
+	// their own line, and lastTokenComments the comments before the
+	// last token. The parser attaches them to the route IDs.
+	comments          []string
+	lastTokenComments []string
+}
+
+// ParseError is returned when parsing eskip fails with a syntax error. It
+// contains the position of the invalid syntax element.
+type ParseError struct {
+	// Offset is the byte offset of the invalid syntax element.
+	Offset int
+
+	// Line is the line number of the invalid syntax element, starting
+	// from 1.
+	Line int
+
+	// Column is the column of the invalid syntax element in characters,
+	// starting from 1.
+	Column int
+
+	// Snippet is the text around the invalid syntax element, from the
+	// same line.
+	Snippet string
+
+	// LastRouteID is the ID of the last route that the parser found
+	// before the error.
+	LastRouteID string
+
+	// Reason describes the error.
+	Reason string
+
+	lastToken *token
 }
 
+const maxSnippetContext = 24
+
 type fixedScanner string
 
 const (
@@ -98,6 +137,7 @@ func (fs fixedScanner) scan(code string) (t token, rest string, err error) {
 func newLexer(code string) *eskipLex {
 	return &eskipLex{
 		code:          code,
+		initialCode:   code,
 		initialLength: len(code)}
 }
 
@@ -235,6 +275,21 @@ func scanRegexpOrComment(code string) (t token, rest string, err error) {
 
 	if code[1] == '/' {
 		rest = scanComment(code)
+		t.val = code[:len(code)-len(rest)]
+		err = void
+		return
+	}
+
+	if code[1] == '*' {
+		end := strings.Index(code[2:], "*/")
+		if end < 0 {
+			rest = code
+			err = incompleteToken
+			return
+		}
+
+		rest = code[end+4:]
+		t.val = code[:end+4]
 		err = void
 		return
 	}
@@ -340,7 +395,9 @@ func selectScanner(code string) scanner {
 }
 
 func (l *eskipLex) next() (t token, err error) {
+	code := l.code
 	l.code = scanWhitespace(l.code)
+	l.tokenStart = l.initialLength - len(l.code)
 	if len(l.code) == 0 {
 		err = eof
 		return
@@ -352,13 +409,28 @@ func (l *eskipLex) next() (t token, err error) {
 		return
 	}
 
-	t, l.code, err = s.scan(l.code)
+	var rest string
+	t, rest, err = s.scan(l.code)
+	if err != nil && err != void {
+		// keep the start of the invalid token, so that the error
+		// position points to it and not to the end of the input
+		return
+	}
+
+	l.code = rest
 	if err == void {
+		// only the comments starting on their own line are kept,
+		// the trailing comments of a line are dropped
+		if l.lastToken == nil || strings.ContainsRune(code[:len(code)-len(l.code)-len(t.val)], newlineChar) {
+			l.comments = append(l.comments, t.val)
+		}
+
 		return l.next()
 	}
 
 	if err == nil {
 		l.lastToken = &t
+		l.lastTokenComments, l.comments = l.comments, nil
 	}
 
 	return
@@ -376,11 +448,49 @@ func (l *eskipLex) Lex(lval *eskipSymType) int {
 	}
 
 	lval.token = token.val
+	lval.comments = l.lastTokenComments
 	return token.id
 }
 
 func (l *eskipLex) Error(err string) {
-	l.err = fmt.Errorf(
-		"parse failed after token %v, last route id: %v, position %d: %s",
-		l.lastToken, l.lastRouteID, l.initialLength-len(l.code), err)
+	l.err = newParseError(l.initialCode, l.tokenStart, l.lastToken, l.lastRouteID, err)
+}
+
+func newParseError(code string, offset int, lastToken *token, lastRouteID, reason string) *ParseError {
+	if offset > len(code) {
+		offset = len(code)
+	}
+
+	lineStart := strings.LastIndexByte(code[:offset], newlineChar) + 1
+	lineEnd := strings.IndexByte(code[offset:], newlineChar)
+	if lineEnd < 0 {
+		lineEnd = len(code)
+	} else {
+		lineEnd += offset
+	}
+
+	snippetStart, snippetEnd := lineStart, lineEnd
+	if offset-snippetStart > maxSnippetContext {
+		snippetStart = offset - maxSnippetContext
+	}
+
+	if snippetEnd-offset > maxSnippetContext {
+		snippetEnd = offset + maxSnippetContext
+	}
+
+	return &ParseError{
+		Offset:      offset,
+		Line:        strings.Count(code[:offset], string(newlineChar)) + 1,
+		Column:      utf8.RuneCountInString(code[lineStart:offset]) + 1,
+		Snippet:     strings.ToValidUTF8(strings.TrimSpace(code[snippetStart:snippetEnd]), ""),
+		LastRouteID: lastRouteID,
+		Reason:      reason,
+		lastToken:   lastToken,
+	}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf(
+		"parse failed after token %v, last route id: %v, position %d, line %d, column %d, near %q: %s",
+		e.lastToken, e.LastRouteID, e.Offset, e.Line, e.Column, e.Snippet, e.Reason)
 }
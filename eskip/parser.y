@@ -30,6 +30,7 @@ func convertNumber(s string) float64 {
 
 %union {
 	token string
+	comments []string
 	route *parsedRoute
 	routes []*parsedRoute
 	matchers []*matcher
@@ -101,11 +102,13 @@ routedef:
 	routeid colon route {
 		$$.route = $3.route
 		$$.route.id = $1.token
+		$$.route.comments = $1.comments
 	}
 
 routeid:
 	symbol {
 		$$.token = $1.token
+		$$.comments = $1.comments
 		eskiplex.(*eskipLex).lastRouteID = $1.token
 	}
 
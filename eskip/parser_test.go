@@ -15,6 +15,7 @@
 package eskip
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -88,6 +89,191 @@ func TestReturnsLexerErrors(t *testing.T) {
 	}
 }
 
+func TestParseErrorPosition(t *testing.T) {
+	for _, tc := range []struct {
+		msg     string
+		code    string
+		line    int
+		column  int
+		snippet string
+	}{{
+		msg:     "missing semicolon",
+		code:    "route0: Method(\"GET\") -> <shunt>;\nroute1: Path(\"/\") -> <shunt>\nroute2: * -> <shunt>;",
+		line:    3,
+		column:  1,
+		snippet: "route2: * -> <shunt>;",
+	}, {
+		msg:     "invalid character",
+		code:    "route0: * -> <shunt>;\n\troute1: Path(\"/\") -> $ -> <shunt>;",
+		line:    2,
+		column:  23,
+		snippet: "route1: Path(\"/\") -> $ -> <shunt>;",
+	}, {
+		msg:     "unclosed string",
+		code:    "route0: * -> <shunt>;\nroute1: Path(\"/) -> <shunt>;",
+		line:    2,
+		column:  14,
+		snippet: "route1: Path(\"/) -> <shunt>;",
+	}, {
+		msg:     "unexpected end",
+		code:    "route0: * -> <shunt>;\nroute1: * ->",
+		line:    2,
+		column:  13,
+		snippet: "route1: * ->",
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			_, err := Parse(tc.code)
+			perr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected parse error, got: %v", err)
+			}
+
+			if perr.Line != tc.line || perr.Column != tc.column {
+				t.Errorf(
+					"unexpected position, got: %d:%d, expected: %d:%d",
+					perr.Line, perr.Column, tc.line, tc.column,
+				)
+			}
+
+			if perr.Snippet != tc.snippet {
+				t.Errorf("unexpected snippet, got: %q, expected: %q", perr.Snippet, tc.snippet)
+			}
+		})
+	}
+}
+
+func TestParseErrorSnippet(t *testing.T) {
+	code := "route0: Path(\"/" + strings.Repeat("a", 40) + "\") -> $ -> " + strings.Repeat("b", 40)
+	_, err := Parse(code)
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected parse error, got: %v", err)
+	}
+
+	if expected := strings.Repeat("a", 18) + "\") -> $ -> " + strings.Repeat("b", 19); perr.Snippet != expected {
+		t.Errorf("unexpected snippet, got: %q, expected: %q", perr.Snippet, expected)
+	}
+
+	if !strings.Contains(err.Error(), "line 1, column 62") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	const doc = `// the first route
+// with two lines of comments
+route1: Path("/foo") // trailing comment
+  /* inline comment */ -> setPath("/bar") -> <shunt>;
+
+/*
+  block comment
+*/
+route2: * -> "https://www.example.org"; // trailing comment, dropped
+
+route3: * -> <loopback>;`
+
+	routes, err := Parse(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"route1": {"// the first route", "// with two lines of comments"},
+		"route2": {"/*\n  block comment\n*/"},
+		"route3": nil,
+	}
+
+	if len(routes) != len(expected) {
+		t.Fatalf("unexpected number of routes: %d", len(routes))
+	}
+
+	for _, r := range routes {
+		if !cmp.Equal(r.Comments, expected[r.Id]) {
+			t.Errorf("unexpected comments of %s: %s", r.Id, cmp.Diff(expected[r.Id], r.Comments))
+		}
+	}
+
+	printed := Print(PrettyPrintInfo{Pretty: true, IndentStr: "  "}, routes...)
+	reparsed, err := Parse(printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !EqLists(routes, reparsed) {
+		t.Error("failed to round-trip the routes")
+	}
+
+	for i := range routes {
+		if !cmp.Equal(routes[i].Comments, reparsed[i].Comments) {
+			t.Errorf("failed to round-trip the comments: %s", cmp.Diff(routes[i].Comments, reparsed[i].Comments))
+		}
+	}
+}
+
+func TestParseCommentPlacement(t *testing.T) {
+	for _, test := range []struct {
+		title    string
+		doc      string
+		expected map[string][]string
+	}{{
+		title: "comment between routes",
+		doc: `// first
+route1: * -> <shunt>
+// between the route and the semicolon
+;
+// second
+route2: * -> <shunt>;`,
+		expected: map[string][]string{
+			"route1": {"// first"},
+			"route2": {"// second"},
+		},
+	}, {
+		title: "comment after the last route",
+		doc: `// first
+route1: * -> <shunt>;
+// second
+route2: * -> <shunt>;
+// after the last route`,
+		expected: map[string][]string{
+			"route1": {"// first"},
+			"route2": {"// second"},
+		},
+	}, {
+		title: "comment after the last route without semicolon",
+		doc: `route1: * -> <shunt>;
+/* second */
+route2: * -> <shunt>
+/* after the last route */`,
+		expected: map[string][]string{
+			"route1": nil,
+			"route2": {"/* second */"},
+		},
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			routes, err := Parse(test.doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(routes) != len(test.expected) {
+				t.Fatalf("unexpected number of routes: %d", len(routes))
+			}
+
+			for _, r := range routes {
+				if !cmp.Equal(r.Comments, test.expected[r.Id]) {
+					t.Errorf("unexpected comments of %s: %s", r.Id, cmp.Diff(test.expected[r.Id], r.Comments))
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnclosedBlockComment(t *testing.T) {
+	if _, err := Parse(`route1: * -> /* unclosed <shunt>;`); err == nil {
+		t.Error("failed to fail")
+	}
+}
+
 func TestParseSingleRoute(t *testing.T) {
 	r, err := parse(singleRouteExample)
 
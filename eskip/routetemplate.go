@@ -0,0 +1,180 @@
+package eskip
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var invalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// NewRouteTemplates creates a RouteTemplates PreProcessor, that expands
+// the routes containing ${name} placeholders of the provided variables
+// into one concrete route for each value of the variables. For example
+// with the variable tenant=acme,globex:
+//
+//        # input
+//        r0: Path("/${tenant}/api") -> setRequestHeader("X-Tenant", "${tenant}") -> "https://${tenant}.backend.example.org";
+//        # actual routes
+//        r0_acme: Path("/acme/api") -> setRequestHeader("X-Tenant", "acme") -> "https://acme.backend.example.org";
+//        r0_globex: Path("/globex/api") -> setRequestHeader("X-Tenant", "globex") -> "https://globex.backend.example.org";
+//
+// The placeholders are expanded in the string arguments of the predicates
+// and the filters, in the network backend address and in the load
+// balanced endpoints. When a route references multiple variables, a route
+// is created for every combination of their values. The placeholders not
+// matching any of the variables, e.g. the ones evaluated by the filters
+// during request processing, are left unchanged, and the routes without
+// placeholders of the variables are not modified. The routes referencing
+// a variable without values are dropped.
+func NewRouteTemplates(variables map[string][]string) *RouteTemplates {
+	return &RouteTemplates{variables: variables}
+}
+
+type RouteTemplates struct {
+	variables map[string][]string
+}
+
+func (t *RouteTemplates) expand(s string, values map[string]string) string {
+	return placeholderRegexp.ReplaceAllStringFunc(s, func(p string) string {
+		if v, ok := values[p[2:len(p)-1]]; ok {
+			return v
+		}
+
+		return p
+	})
+}
+
+func (t *RouteTemplates) collect(s string, names map[string]bool) {
+	for _, m := range placeholderRegexp.FindAllStringSubmatch(s, -1) {
+		if _, ok := t.variables[m[1]]; ok {
+			names[m[1]] = true
+		}
+	}
+}
+
+func collectArgs(args []interface{}, f func(string)) {
+	for _, a := range args {
+		if s, ok := a.(string); ok {
+			f(s)
+		}
+	}
+}
+
+func expandArgs(args []interface{}, f func(string) string) {
+	for i, a := range args {
+		if s, ok := a.(string); ok {
+			args[i] = f(s)
+		}
+	}
+}
+
+// referenced returns the sorted names of the variables used by the route.
+func (t *RouteTemplates) referenced(r *Route) []string {
+	names := make(map[string]bool)
+	collect := func(s string) { t.collect(s, names) }
+	for _, p := range r.Predicates {
+		collectArgs(p.Args, collect)
+	}
+
+	for _, f := range r.Filters {
+		collectArgs(f.Args, collect)
+	}
+
+	collect(r.Backend)
+	for _, ep := range r.LBEndpoints {
+		collect(ep)
+	}
+
+	var sorted []string
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+
+	sort.Strings(sorted)
+	return sorted
+}
+
+func (t *RouteTemplates) hasValues(names []string) bool {
+	for _, n := range names {
+		if len(t.variables[n]) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *RouteTemplates) instance(r *Route, names []string, values map[string]string) *Route {
+	c := Copy(r)
+
+	var suffix []string
+	for _, n := range names {
+		suffix = append(suffix, invalidIDChars.ReplaceAllString(values[n], "_"))
+	}
+
+	c.Id = r.Id + "_" + strings.Join(suffix, "_")
+
+	expand := func(s string) string { return t.expand(s, values) }
+	for _, p := range c.Predicates {
+		expandArgs(p.Args, expand)
+	}
+
+	for _, f := range c.Filters {
+		expandArgs(f.Args, expand)
+	}
+
+	c.Backend = expand(c.Backend)
+	for i, ep := range c.LBEndpoints {
+		c.LBEndpoints[i] = expand(ep)
+	}
+
+	return c
+}
+
+func (t *RouteTemplates) Do(routes []*Route) []*Route {
+	if len(t.variables) == 0 {
+		return routes
+	}
+
+	result := make([]*Route, 0, len(routes))
+	for _, r := range routes {
+		names := t.referenced(Canonical(r))
+		if len(names) == 0 {
+			result = append(result, r)
+			continue
+		}
+
+		if !t.hasValues(names) {
+			continue
+		}
+
+		// iterating over all the combinations of the values, like an
+		// odometer, the last variable changing the fastest
+		index := make([]int, len(names))
+		for {
+			values := make(map[string]string)
+			for i, n := range names {
+				values[n] = t.variables[n][index[i]]
+			}
+
+			result = append(result, t.instance(r, names, values))
+
+			i := len(index) - 1
+			for ; i >= 0; i-- {
+				index[i]++
+				if index[i] < len(t.variables[names[i]]) {
+					break
+				}
+
+				index[i] = 0
+			}
+
+			if i < 0 {
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,108 @@
+package eskip
+
+import (
+	"testing"
+)
+
+func TestRouteTemplates(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		variables map[string][]string
+		routes    string
+		expected  string
+	}{{
+		name:     "no variables",
+		routes:   `r0: Path("/${tenant}") -> "https://${tenant}.example.org";`,
+		expected: `r0: Path("/${tenant}") -> "https://${tenant}.example.org";`,
+	}, {
+		name:      "route without placeholders",
+		variables: map[string][]string{"tenant": {"acme", "globex"}},
+		routes:    `r0: Path("/foo") -> "https://www.example.org";`,
+		expected:  `r0: Path("/foo") -> "https://www.example.org";`,
+	}, {
+		name:      "path, filter and backend",
+		variables: map[string][]string{"tenant": {"acme", "globex"}},
+		routes:    `r0: Path("/${tenant}/api") -> setRequestHeader("X-Tenant", "${tenant}") -> "https://${tenant}.example.org";`,
+		expected: `r0_acme: Path("/acme/api") -> setRequestHeader("X-Tenant", "acme") -> "https://acme.example.org";
+			r0_globex: Path("/globex/api") -> setRequestHeader("X-Tenant", "globex") -> "https://globex.example.org";`,
+	}, {
+		name:      "multiple variables",
+		variables: map[string][]string{"tenant": {"acme", "globex"}, "stage": {"live", "test"}},
+		routes:    `r0: Path("/${tenant}") && Header("X-Stage", "${stage}") -> "https://${tenant}.${stage}.example.org";`,
+		expected: `r0_live_acme: Path("/acme") && Header("X-Stage", "live") -> "https://acme.live.example.org";
+			r0_live_globex: Path("/globex") && Header("X-Stage", "live") -> "https://globex.live.example.org";
+			r0_test_acme: Path("/acme") && Header("X-Stage", "test") -> "https://acme.test.example.org";
+			r0_test_globex: Path("/globex") && Header("X-Stage", "test") -> "https://globex.test.example.org";`,
+	}, {
+		name:      "unknown placeholders are preserved",
+		variables: map[string][]string{"tenant": {"acme"}},
+		routes:    `r0: Path("/${tenant}") -> setRequestHeader("X-Host", "${request.host}") -> "https://www.example.org";`,
+		expected:  `r0_acme: Path("/acme") -> setRequestHeader("X-Host", "${request.host}") -> "https://www.example.org";`,
+	}, {
+		name:      "values invalid in route ids",
+		variables: map[string][]string{"tenant": {"acme-eu.1"}},
+		routes:    `r0: Path("/${tenant}") -> "https://www.example.org";`,
+		expected:  `r0_acme_eu_1: Path("/acme-eu.1") -> "https://www.example.org";`,
+	}, {
+		name:      "variable without values",
+		variables: map[string][]string{"tenant": {}},
+		routes: `r0: Path("/${tenant}") -> "https://www.example.org";
+			r1: Path("/foo") -> "https://www.example.org";`,
+		expected: `r1: Path("/foo") -> "https://www.example.org";`,
+	}, {
+		name:      "the input routes are not modified",
+		variables: map[string][]string{"tenant": {"acme"}},
+		routes: `r0: Path("/${tenant}") -> "https://${tenant}.example.org";
+			r1: Path("/foo") -> "https://www.example.org";`,
+		expected: `r0_acme: Path("/acme") -> "https://acme.example.org";
+			r1: Path("/foo") -> "https://www.example.org";`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := Parse(tt.routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected, err := Parse(tt.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			original := String(CopyRoutes(routes)...)
+			result := NewRouteTemplates(tt.variables).Do(routes)
+			if !EqLists(result, expected) {
+				t.Errorf("unexpected routes, expected:\n%s\ngot:\n%s", String(expected...), String(result...))
+			}
+
+			if String(CopyRoutes(routes)...) != original {
+				t.Error("the input routes were modified")
+			}
+		})
+	}
+}
+
+func TestRouteTemplatesLBEndpoints(t *testing.T) {
+	// the parser rejects the placeholders in the endpoint addresses,
+	// but the data clients can provide such routes
+	r := &Route{
+		Id:          "r0",
+		Predicates:  []*Predicate{{Name: "Path", Args: []interface{}{"/${tenant}"}}},
+		BackendType: LBBackend,
+		LBAlgorithm: "roundRobin",
+		LBEndpoints: []string{"https://${tenant}-1.example.org", "https://${tenant}-2.example.org"},
+	}
+
+	expected, err := Parse(`r0_acme: Path("/acme") -> <roundRobin, "https://acme-1.example.org", "https://acme-2.example.org">;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRouteTemplates(map[string][]string{"tenant": {"acme"}}).Do([]*Route{r})
+	if !EqLists(result, expected) {
+		t.Errorf("unexpected routes, expected:\n%s\ngot:\n%s", String(expected...), String(result...))
+	}
+
+	if r.LBEndpoints[0] != "https://${tenant}-1.example.org" {
+		t.Error("the input route was modified")
+	}
+}
@@ -5,12 +5,20 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sort"
 	"strings"
 )
 
 type PrettyPrintInfo struct {
 	Pretty    bool
 	IndentStr string
+
+	// SplitPredicates, when used with Pretty, prints each predicate
+	// of a route on its own line.
+	SplitPredicates bool
+
+	// SortRoutes prints the route definitions ordered by their IDs.
+	SortRoutes bool
 }
 
 func escape(s string, chars string) string {
@@ -78,7 +86,29 @@ func argsString(args []interface{}) string {
 	return strings.Join(sargs, ", ")
 }
 
-func (r *Route) predicateString() string {
+// sortedKeys returns the sorted keys of the headers.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedListKeys returns the sorted keys of the header regexps.
+func sortedListKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *Route) predicateString(prettyPrintInfo PrettyPrintInfo) string {
 	var predicates []string
 
 	if r.Path != "" {
@@ -97,12 +127,12 @@ func (r *Route) predicateString() string {
 		predicates = appendFmtEscape(predicates, `Method("%s")`, `"`, r.Method)
 	}
 
-	for k, v := range r.Headers {
-		predicates = appendFmtEscape(predicates, `Header("%s", "%s")`, `"`, k, v)
+	for _, k := range sortedKeys(r.Headers) {
+		predicates = appendFmtEscape(predicates, `Header("%s", "%s")`, `"`, k, r.Headers[k])
 	}
 
-	for k, rxs := range r.HeaderRegexps {
-		for _, rx := range rxs {
+	for _, k := range sortedListKeys(r.HeaderRegexps) {
+		for _, rx := range r.HeaderRegexps[k] {
 			predicates = appendFmt(predicates, `HeaderRegexp("%s", /%s/)`, escape(k, `"`), escape(rx, "/"))
 		}
 	}
@@ -117,6 +147,10 @@ func (r *Route) predicateString() string {
 		predicates = append(predicates, "*")
 	}
 
+	if prettyPrintInfo.Pretty && prettyPrintInfo.SplitPredicates {
+		return strings.Join(predicates, "\n"+prettyPrintInfo.IndentStr+"&& ")
+	}
+
 	return strings.Join(predicates, " && ")
 }
 
@@ -181,7 +215,7 @@ func (r *Route) String() string {
 }
 
 func (r *Route) Print(prettyPrintInfo PrettyPrintInfo) string {
-	s := []string{r.predicateString()}
+	s := []string{r.predicateString(prettyPrintInfo)}
 
 	fs := r.filterString(prettyPrintInfo)
 	if fs != "" {
@@ -220,10 +254,19 @@ func fprintExpression(w io.Writer, route *Route, prettyPrintInfo PrettyPrintInfo
 }
 
 func fprintDefinition(w io.Writer, route *Route, prettyPrintInfo PrettyPrintInfo) {
+	for _, c := range route.Comments {
+		fmt.Fprintln(w, c)
+	}
+
 	fmt.Fprintf(w, "%s: %s", route.Id, route.Print(prettyPrintInfo))
 }
 
 func fprintDefinitions(w io.Writer, routes []*Route, prettyPrintInfo PrettyPrintInfo) {
+	if prettyPrintInfo.SortRoutes {
+		routes = append([]*Route(nil), routes...)
+		sort.SliceStable(routes, func(i, j int) bool { return routes[i].Id < routes[j].Id })
+	}
+
 	for i, r := range routes {
 		if i > 0 {
 			fmt.Fprint(w, "\n")
@@ -237,6 +280,19 @@ func fprintDefinitions(w io.Writer, routes []*Route, prettyPrintInfo PrettyPrint
 	}
 }
 
+// PrettyPrint serializes a set of routes into a string, in a stable format
+// that is suitable for storing in version control: the routes are ordered
+// by their IDs, and each predicate and filter is printed on its own,
+// indented line.
+func PrettyPrint(routes ...*Route) string {
+	return Print(PrettyPrintInfo{
+		Pretty:          true,
+		IndentStr:       "  ",
+		SplitPredicates: true,
+		SortRoutes:      true,
+	}, routes...)
+}
+
 func Fprint(w io.Writer, prettyPrintInfo PrettyPrintInfo, routes ...*Route) {
 	if len(routes) == 0 {
 		return
@@ -215,6 +215,56 @@ func TestPrintMultiRouteNonPretty(t *testing.T) {
 		t, 0, PrettyPrintInfo{Pretty: false, IndentStr: ""}, true)
 }
 
+func TestPrettyPrint(t *testing.T) {
+	routes, err := Parse(`route2: Path("/some/path") && Method("GET") -> "https://www.example.org";` + "\n" +
+		`route1: * -> filter("expression") -> <shunt>;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `route1: *` + "\n" +
+		`  -> filter("expression")` + "\n" +
+		`  -> <shunt>;` + "\n\n" +
+		`route2: Path("/some/path")` + "\n" +
+		`  && Method("GET")` + "\n" +
+		`  -> "https://www.example.org";`
+
+	if got := PrettyPrint(routes...); got != expected {
+		pos, printed, expected := findDiffPos(got, expected)
+		t.Error(got, expected, pos, printed, expected)
+	}
+
+	if routes[0].Id != "route2" {
+		t.Error("the original order of the routes was changed")
+	}
+
+	reparsed, err := Parse(PrettyPrint(routes...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !EqLists(routes, reparsed) {
+		t.Error("failed to round-trip the pretty printed routes")
+	}
+}
+
+func TestPrintSortedHeaders(t *testing.T) {
+	r := &Route{
+		Headers:       map[string]string{"X-C": "c", "X-A": "a", "X-B": "b"},
+		HeaderRegexps: map[string][]string{"X-Z": {"z"}, "X-Y": {"y1", "y2"}},
+		BackendType:   ShuntBackend,
+	}
+
+	expected := `Header("X-A", "a") && Header("X-B", "b") && Header("X-C", "c")` +
+		` && HeaderRegexp("X-Y", /y1/) && HeaderRegexp("X-Y", /y2/) && HeaderRegexp("X-Z", /z/) -> <shunt>`
+
+	for i := 0; i < 10; i++ {
+		if got := r.String(); got != expected {
+			t.Fatalf("unexpected output, got: %s, expected: %s", got, expected)
+		}
+	}
+}
+
 func testPrinting(routestr string, expected string, t *testing.T, i int, prettyPrintInfo PrettyPrintInfo, multi bool) {
 	routes, err := Parse(routestr)
 	if err != nil {
@@ -0,0 +1,195 @@
+package eskipfile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/eskip"
+)
+
+const eskipExtension = ".eskip"
+
+// DirWatchClient implements a route configuration client watching a directory of eskip files. Use the WatchDir
+// function to initialize instances of it.
+type DirWatchClient struct {
+	dirName    string
+	routes     map[string]*eskip.Route
+	files      map[string][]*eskip.Route
+	getAll     chan (chan<- watchResponse)
+	getUpdates chan (chan<- watchResponse)
+	quit       chan struct{}
+}
+
+// WatchDir creates a route configuration client watching the files with the .eskip extension in a directory.
+// The routes of all the files are merged. When the same route id appears in multiple files, the route from the
+// file with the alphabetically last name is used. Subdirectories are not watched.
+//
+// When a file cannot be parsed, the error is logged and the file is skipped. On updates, the routes of the last
+// successfully parsed version of the file are kept, until the file is fixed or removed.
+func WatchDir(name string) *DirWatchClient {
+	c := &DirWatchClient{
+		dirName:    name,
+		getAll:     make(chan (chan<- watchResponse)),
+		getUpdates: make(chan (chan<- watchResponse)),
+		quit:       make(chan struct{}),
+	}
+
+	go c.watch()
+	return c
+}
+
+func (c *DirWatchClient) listFiles() ([]string, error) {
+	entries, err := os.ReadDir(c.dirName)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), eskipExtension) {
+			continue
+		}
+
+		names = append(names, filepath.Join(c.dirName, e.Name()))
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *DirWatchClient) readFiles() ([]*eskip.Route, error) {
+	names, err := c.listFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]*eskip.Route)
+	byID := make(map[string]*eskip.Route)
+	var ids []string
+	for _, name := range names {
+		r, err := readFile(name)
+		if err != nil {
+			log.Errorf("Failed to read routes file %s: %v", name, err)
+			if previous, ok := c.files[name]; ok {
+				r = previous
+			} else {
+				continue
+			}
+		}
+
+		files[name] = r
+		for _, ri := range r {
+			if _, exists := byID[ri.Id]; exists {
+				log.Warnf("Duplicate route id %s, using the route from %s", ri.Id, name)
+			} else {
+				ids = append(ids, ri.Id)
+			}
+
+			byID[ri.Id] = ri
+		}
+	}
+
+	c.files = files
+
+	routes := make([]*eskip.Route, len(ids))
+	for i, id := range ids {
+		routes[i] = byID[id]
+	}
+
+	return routes, nil
+}
+
+func readFile(name string) ([]*eskip.Route, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return eskip.Parse(string(content))
+}
+
+func (c *DirWatchClient) loadAll() watchResponse {
+	c.files = nil
+	r, err := c.readFiles()
+	if err != nil {
+		return watchResponse{err: err}
+	}
+
+	c.routes = mapRoutes(r)
+	return watchResponse{routes: cloneRoutes(r)}
+}
+
+func (c *DirWatchClient) loadUpdates() watchResponse {
+	r, err := c.readFiles()
+	if err != nil {
+		if os.IsNotExist(err) {
+			var ids []string
+			for id := range c.routes {
+				ids = append(ids, id)
+			}
+
+			c.routes = nil
+			c.files = nil
+			return watchResponse{deletedIDs: ids}
+		}
+
+		return watchResponse{err: err}
+	}
+
+	var upsert []*eskip.Route
+	for i := range r {
+		if previous, ok := c.routes[r[i].Id]; !ok || r[i].String() != previous.String() {
+			upsert = append(upsert, r[i])
+		}
+	}
+
+	m := mapRoutes(r)
+	var deletedIDs []string
+	for id := range c.routes {
+		if _, keep := m[id]; !keep {
+			deletedIDs = append(deletedIDs, id)
+		}
+	}
+
+	c.routes = m
+	return watchResponse{routes: cloneRoutes(upsert), deletedIDs: deletedIDs}
+}
+
+func (c *DirWatchClient) watch() {
+	for {
+		select {
+		case req := <-c.getAll:
+			req <- c.loadAll()
+		case req := <-c.getUpdates:
+			req <- c.loadUpdates()
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// LoadAll returns the parsed route definitions found in the eskip files of the directory.
+func (c *DirWatchClient) LoadAll() ([]*eskip.Route, error) {
+	req := make(chan watchResponse)
+	c.getAll <- req
+	rsp := <-req
+	return rsp.routes, rsp.err
+}
+
+// LoadUpdate returns differential updates when the eskip files in the watched directory have changed, or when
+// files were added or removed.
+func (c *DirWatchClient) LoadUpdate() ([]*eskip.Route, []string, error) {
+	req := make(chan watchResponse)
+	c.getUpdates <- req
+	rsp := <-req
+	return rsp.routes, rsp.deletedIDs, rsp.err
+}
+
+// Close stops watching the configured directory and providing updates.
+func (c *DirWatchClient) Close() {
+	close(c.quit)
+}
@@ -0,0 +1,141 @@
+package eskipfile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+)
+
+func writeDirFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func routeIDs(r []*eskip.Route) []string {
+	var ids []string
+	for _, ri := range r {
+		ids = append(ids, ri.Id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func checkIDs(t *testing.T, got, expected []string) {
+	t.Helper()
+	sort.Strings(got)
+	if len(got) != len(expected) {
+		t.Fatalf("invalid ids, got: %v, expected: %v", got, expected)
+	}
+
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Fatalf("invalid ids, got: %v, expected: %v", got, expected)
+		}
+	}
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	writeDirFile(t, dir, "a.eskip", `
+		foo: Path("/foo") -> "https://foo.example.org";
+		bar: Path("/bar") -> "https://bar.example.org";
+	`)
+	writeDirFile(t, dir, "b.eskip", `baz: Path("/baz") -> "https://baz.example.org";`)
+	writeDirFile(t, dir, "c.eskip", `invalid eskip`)
+	writeDirFile(t, dir, "d.txt", `qux: Path("/qux") -> "https://qux.example.org";`)
+
+	c := WatchDir(dir)
+	defer c.Close()
+
+	r, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkIDs(t, routeIDs(r), []string{"bar", "baz", "foo"})
+
+	r, deleted, err := c.LoadUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkIDs(t, routeIDs(r), nil)
+	checkIDs(t, deleted, nil)
+
+	t.Run("update and add files", func(t *testing.T) {
+		writeDirFile(t, dir, "a.eskip", `foo: Path("/foo") -> "https://foo-new.example.org";`)
+		writeDirFile(t, dir, "c.eskip", `qux: Path("/qux") -> "https://qux.example.org";`)
+
+		r, deleted, err := c.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkIDs(t, routeIDs(r), []string{"foo", "qux"})
+		checkIDs(t, deleted, []string{"bar"})
+	})
+
+	t.Run("invalid file keeps the previous routes", func(t *testing.T) {
+		writeDirFile(t, dir, "b.eskip", `invalid eskip`)
+
+		r, deleted, err := c.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkIDs(t, routeIDs(r), nil)
+		checkIDs(t, deleted, nil)
+	})
+
+	t.Run("remove file", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "c.eskip")); err != nil {
+			t.Fatal(err)
+		}
+
+		r, deleted, err := c.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkIDs(t, routeIDs(r), nil)
+		checkIDs(t, deleted, []string{"qux"})
+	})
+
+	t.Run("remove directory", func(t *testing.T) {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		r, deleted, err := c.LoadUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkIDs(t, routeIDs(r), nil)
+		checkIDs(t, deleted, []string{"baz", "foo"})
+	})
+}
+
+func TestWatchDirDuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeDirFile(t, dir, "a.eskip", `foo: Path("/foo") -> "https://a.example.org";`)
+	writeDirFile(t, dir, "b.eskip", `foo: Path("/foo") -> "https://b.example.org";`)
+
+	c := WatchDir(dir)
+	defer c.Close()
+
+	r, err := c.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r) != 1 || r[0].Backend != "https://b.example.org" {
+		t.Errorf("invalid routes: %s", eskip.String(r...))
+	}
+}
@@ -7,5 +7,8 @@ format in the skipper/eskip package.)
 
 The package provides two implementations: one without file watch (legacy version) and one with file watch. When
 running the skipper command, the one with watch is used.
+
+The package also provides a client watching a directory, that merges the routes of all the files with the .eskip
+extension in it. Files that fail to parse are skipped, without affecting the routes from the other files.
 */
 package eskipfile
@@ -37,6 +37,10 @@ const (
 	routesPath      = "/routes"
 	etcdIndexHeader = "X-Etcd-Index"
 	defaultTimeout  = time.Second
+
+	// etcd error code returned when the requested watch index has been
+	// removed from the event history
+	etcdErrorIndexCleared = 401
 )
 
 // etcd serialization objects
@@ -54,6 +58,11 @@ type (
 		Action    string `json:"action"`
 		Node      *node  `json:"node"`
 	}
+
+	errorResponse struct {
+		ErrorCode int    `json:"errorCode"`
+		Message   string `json:"message"`
+	}
 )
 
 // common error object for errors coming from multiple
@@ -123,6 +132,7 @@ var (
 	unexpectedHttpResponse  = errors.New("unexpected http response")
 	notFound                = errors.New("not found")
 	invalidResponseDocument = errors.New("invalid response document")
+	indexCleared            = errors.New("watch index cleared")
 )
 
 // Creates a new Client with the provided options.
@@ -235,6 +245,17 @@ func parseResponse(rsp *http.Response) (*response, error) {
 	return r, err
 }
 
+// Checks whether an etcd error response reports that the requested watch
+// index is outdated and was cleared from the event history.
+func isIndexCleared(rsp *http.Response) bool {
+	var er errorResponse
+	if err := json.NewDecoder(rsp.Body).Decode(&er); err != nil {
+		return false
+	}
+
+	return er.ErrorCode == etcdErrorIndexCleared
+}
+
 // Converts a non-success http status code into an in-memory error object.
 // As the first argument, returns true in case of error.
 func httpError(code int) (bool, error) {
@@ -285,6 +306,10 @@ func (c *Client) etcdRequest(method, path, data string) (*response, error) {
 	defer rsp.Body.Close()
 
 	if hasErr, err := httpError(rsp.StatusCode); hasErr {
+		if rsp.StatusCode == http.StatusBadRequest && isIndexCleared(rsp) {
+			return nil, indexCleared
+		}
+
 		return nil, err
 	}
 
@@ -423,6 +448,12 @@ func (c *Client) LoadAll() ([]*eskip.Route, error) {
 // It uses etcd's watch functionality that results in blocking this call
 // until the next change is detected in etcd or reaches the configured hard
 // timeout.
+//
+// When none of the etcd endpoints can be reached, it returns the updates
+// received so far without an error, and the next call resumes the watch
+// from the last processed index. Only when the last processed index was
+// already cleared from the etcd event history, it returns an error, so
+// that the whole set of routes gets reloaded.
 func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 	updates := make(map[string]string)
 	deletes := make(map[string]bool)
@@ -431,6 +462,12 @@ func (c *Client) LoadUpdate() ([]*eskip.Route, []string, error) {
 		response, err := c.etcdGetUpdates()
 		if isTimeout(err) {
 			break
+		} else if _, ok := err.(*endpointErrors); ok {
+			log.Warnf("Failed to watch etcd, resuming from index %d: %v", c.etcdIndex, err)
+			break
+		} else if err == indexCleared {
+			log.Infof("etcd index %d cleared, reloading all routes", c.etcdIndex)
+			return nil, nil, err
 		} else if err != nil {
 			return nil, nil, err
 		} else if response.Node.Dir {
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/etcd/etcdtest"
@@ -617,3 +618,95 @@ func TestRequestWithBasicAuth(t *testing.T) {
 		t.Fatal("invalid token not set")
 	}
 }
+
+func TestResumesWatchAfterConnectionFailure(t *testing.T) {
+	var waitIndex string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "true" {
+			w.Header().Set("X-Etcd-Index", "42")
+			w.Write([]byte(`{"node": {"key": "/skippertest/routes", "dir": true, "nodes": [{
+				"key": "/skippertest/routes/foo",
+				"value": "Path(\"/foo\") -> \"https://foo.example.org\"",
+				"modifiedIndex": 40
+			}]}}`))
+			return
+		}
+
+		if waitIndex != "" {
+			time.Sleep(300 * time.Millisecond)
+			return
+		}
+
+		waitIndex = r.URL.Query().Get("waitIndex")
+		w.Header().Set("X-Etcd-Index", "43")
+		w.Write([]byte(`{"action": "set", "node": {
+			"key": "/skippertest/routes/bar",
+			"value": "Path(\"/bar\") -> \"https://bar.example.org\"",
+			"modifiedIndex": 43
+		}}`))
+	}))
+	defer s.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	c, err := New(Options{Endpoints: []string{s.URL}, Prefix: "/skippertest", Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.endpoints = []string{closed.URL}
+	routes, deleted, err := c.LoadUpdate()
+	if err != nil {
+		t.Fatalf("failed to resume after connection failure: %v", err)
+	}
+
+	if len(routes) != 0 || len(deleted) != 0 {
+		t.Fatal("unexpected updates")
+	}
+
+	c.endpoints = []string{s.URL}
+	routes, _, err = c.LoadUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if waitIndex != "43" {
+		t.Errorf("failed to resume watch from the last index, got: %s", waitIndex)
+	}
+
+	if len(routes) != 1 || routes[0].Id != "bar" {
+		t.Error("failed to receive update")
+	}
+}
+
+func TestIndexClearedForcesReload(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "true" {
+			w.Header().Set("X-Etcd-Index", "42")
+			w.Write([]byte(`{"node": {"key": "/skippertest/routes", "dir": true}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorCode": 401, "message": "The event in requested index is outdated and cleared"}`))
+	}))
+	defer s.Close()
+
+	c, err := New(Options{Endpoints: []string{s.URL}, Prefix: "/skippertest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := c.LoadUpdate(); err != indexCleared {
+		t.Errorf("failed to report cleared index, got: %v", err)
+	}
+}
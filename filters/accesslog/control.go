@@ -14,6 +14,14 @@ const (
 
 	// AccessLogAdditionalDataKey is the key used in the state bag to pass extra data to access log
 	AccessLogAdditionalDataKey = "statebag:access_log:additional"
+
+	// AccessLogSampledKey is the key used in the state bag to pass the sampling decision of the access log
+	// to the proxy.
+	AccessLogSampledKey = "statebag:access_log:proxy:sampled"
+
+	// AccessLogRedactKey is the key used in the state bag to pass the headers and query parameters masked in
+	// the access log to the proxy.
+	AccessLogRedactKey = "statebag:access_log:proxy:redact"
 )
 
 // Common filter struct for holding access log state
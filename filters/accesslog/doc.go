@@ -8,10 +8,18 @@ present access log entries for this route won't be produced even if global Acces
 "enableAccessLog" filter is present access log entries for this route will be produced even if global AccessLogDisabled
 is true.
 
+The "logStateBag" filter adds the values of the given state bag keys to the access log entries of the
+route, as structured fields.
+
+The "accessLogSampling" filter logs only a sample of the successful requests of the route, with the given rate,
+while the responses with status codes 4xx and 5xx are always logged.
+
 Usage
 
     enableAccessLog()
     disableAccessLog()
+    logStateBag("ratelimit-key")
+    accessLogSampling(0.01)
 
 Note: accessLogDisabled("true") filter is deprecated in favor of "disableAccessLog" and "enableAccessLog"
 */
@@ -0,0 +1,129 @@
+package accesslog
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	redactQueryPrefix = "query:"
+	redactedValue     = "REDACTED"
+)
+
+type (
+	accessLogRedactSpec struct{}
+
+	accessLogRedact struct {
+		headers []string
+		query   map[string]bool
+	}
+)
+
+// NewAccessLogRedact creates a filter spec to mask the values of request
+// headers and query parameters in the access log entry of a specific route.
+// The arguments are header names, or query parameter names prefixed with
+// "query:". The masked values are replaced with REDACTED. The proxied request
+// is not changed.
+//
+//  	accessLogRedact("Referer", "query:token")
+func NewAccessLogRedact() filters.Spec {
+	return &accessLogRedactSpec{}
+}
+
+func (*accessLogRedactSpec) Name() string { return filters.AccessLogRedactName }
+
+func (*accessLogRedactSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &accessLogRedact{query: make(map[string]bool)}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if strings.HasPrefix(s, redactQueryPrefix) {
+			s = strings.TrimPrefix(s, redactQueryPrefix)
+			if s == "" {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			f.query[s] = true
+			continue
+		}
+
+		if s == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.headers = append(f.headers, http.CanonicalHeaderKey(s))
+	}
+
+	return f, nil
+}
+
+func (f *accessLogRedact) Request(ctx filters.FilterContext) {
+	bag := ctx.StateBag()
+	redact, _ := bag[AccessLogRedactKey].([]*accessLogRedact)
+	bag[AccessLogRedactKey] = append(redact, f)
+}
+
+func (*accessLogRedact) Response(filters.FilterContext) {}
+
+// masks the values of the query parameters in the request URI, keeping the
+// rest of it unchanged
+func redactQuery(uri string, names map[string]bool) string {
+	i := strings.IndexByte(uri, '?')
+	if i < 0 || len(names) == 0 {
+		return uri
+	}
+
+	params := strings.Split(uri[i+1:], "&")
+	for j, p := range params {
+		key := p
+		if k := strings.IndexByte(p, '='); k >= 0 {
+			key = p[:k]
+		}
+
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+
+		if names[name] {
+			params[j] = key + "=" + redactedValue
+		}
+	}
+
+	return uri[:i+1] + strings.Join(params, "&")
+}
+
+// Redact returns a copy of the request for the access log, where the headers
+// and query parameters configured by the accessLogRedact filters in the state
+// bag are masked. When no masking is configured, it returns the original
+// request.
+func Redact(r *http.Request, stateBag map[string]interface{}) *http.Request {
+	redact, ok := stateBag[AccessLogRedactKey].([]*accessLogRedact)
+	if !ok || len(redact) == 0 || r == nil {
+		return r
+	}
+
+	rr := *r
+	rr.Header = r.Header.Clone()
+	for _, f := range redact {
+		for _, h := range f.headers {
+			if _, ok := rr.Header[h]; ok {
+				rr.Header[h] = []string{redactedValue}
+			}
+		}
+
+		rr.RequestURI = redactQuery(rr.RequestURI, f.query)
+	}
+
+	return &rr
+}
@@ -0,0 +1,127 @@
+package accesslog
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestAccessLogRedactArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg     string
+		args    []interface{}
+		isError bool
+	}{{
+		msg:     "no args",
+		isError: true,
+	}, {
+		msg:     "not a string",
+		args:    []interface{}{42.0},
+		isError: true,
+	}, {
+		msg:     "empty header",
+		args:    []interface{}{""},
+		isError: true,
+	}, {
+		msg:     "empty query parameter",
+		args:    []interface{}{"query:"},
+		isError: true,
+	}, {
+		msg:  "headers and query parameters",
+		args: []interface{}{"Authorization", "query:token"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewAccessLogRedact().CreateFilter(ti.args)
+			if ti.isError && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.isError && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAccessLogRedact(t *testing.T) {
+	for _, ti := range []struct {
+		msg            string
+		args           [][]interface{}
+		uri            string
+		header         http.Header
+		expectedURI    string
+		expectedHeader http.Header
+	}{{
+		msg:            "no redaction",
+		uri:            "/foo?token=secret",
+		header:         http.Header{"Authorization": {"Bearer secret"}},
+		expectedURI:    "/foo?token=secret",
+		expectedHeader: http.Header{"Authorization": {"Bearer secret"}},
+	}, {
+		msg:            "header",
+		args:           [][]interface{}{{"authorization"}},
+		uri:            "/foo",
+		header:         http.Header{"Authorization": {"Bearer secret"}, "Accept": {"text/plain"}},
+		expectedURI:    "/foo",
+		expectedHeader: http.Header{"Authorization": {"REDACTED"}, "Accept": {"text/plain"}},
+	}, {
+		msg:            "missing header not added",
+		args:           [][]interface{}{{"Authorization"}},
+		uri:            "/foo",
+		header:         http.Header{},
+		expectedURI:    "/foo",
+		expectedHeader: http.Header{},
+	}, {
+		msg:            "query parameters",
+		args:           [][]interface{}{{"query:token", "query:api key"}},
+		uri:            "/foo?a=1&token=secret&api%20key=secret&b&token",
+		header:         http.Header{},
+		expectedURI:    "/foo?a=1&token=REDACTED&api%20key=REDACTED&b&token=REDACTED",
+		expectedHeader: http.Header{},
+	}, {
+		msg:            "multiple filters",
+		args:           [][]interface{}{{"Authorization"}, {"query:token"}},
+		uri:            "/foo?token=secret",
+		header:         http.Header{"Authorization": {"Bearer secret"}},
+		expectedURI:    "/foo?token=REDACTED",
+		expectedHeader: http.Header{"Authorization": {"REDACTED"}},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			req := &http.Request{RequestURI: ti.uri, Header: ti.header}
+			ctx := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+			for _, args := range ti.args {
+				f, err := NewAccessLogRedact().CreateFilter(args)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				f.Request(ctx)
+			}
+
+			original := req.Header.Clone()
+			logged := Redact(req, ctx.StateBag())
+			if logged.RequestURI != ti.expectedURI {
+				t.Errorf("unexpected request URI: %s, expected: %s", logged.RequestURI, ti.expectedURI)
+			}
+
+			if len(logged.Header) != len(ti.expectedHeader) {
+				t.Fatalf("unexpected headers: %v, expected: %v", logged.Header, ti.expectedHeader)
+			}
+
+			for k := range ti.expectedHeader {
+				if logged.Header.Get(k) != ti.expectedHeader.Get(k) {
+					t.Errorf("unexpected header %s: %s, expected: %s", k, logged.Header.Get(k), ti.expectedHeader.Get(k))
+				}
+			}
+
+			if req.RequestURI != ti.uri {
+				t.Error("the request URI of the original request was changed")
+			}
+
+			for k := range original {
+				if req.Header.Get(k) != original.Get(k) {
+					t.Error("the headers of the original request were changed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package accesslog
+
+import (
+	"math/rand"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	accessLogSamplingSpec struct{}
+
+	accessLogSampling struct {
+		rate float64
+	}
+)
+
+// NewAccessLogSampling creates a filter spec to sample the access log entries of
+// a specific route. It takes the sampling rate as an argument, between 0 and 1.
+// The responses with status codes 4xx and 5xx are always logged, only the rest of
+// the access log entries are sampled.
+//
+//  	accessLogSampling(0.01)  to log 1% of the successful requests
+func NewAccessLogSampling() filters.Spec {
+	return &accessLogSamplingSpec{}
+}
+
+func (*accessLogSamplingSpec) Name() string { return filters.AccessLogSamplingName }
+
+func (*accessLogSamplingSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	rate, ok := args[0].(float64)
+	if !ok || rate < 0 || rate > 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &accessLogSampling{rate: rate}, nil
+}
+
+func (f *accessLogSampling) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[AccessLogSampledKey] = rand.Float64() < f.rate
+}
+
+func (*accessLogSampling) Response(filters.FilterContext) {}
+
+// SampledOut tells whether the access log entry of a response with the given
+// status code should be omitted, based on the sampling decision stored in the
+// state bag. Responses with status codes 4xx and 5xx are never sampled out.
+func SampledOut(statusCode int, stateBag map[string]interface{}) bool {
+	sampled, ok := stateBag[AccessLogSampledKey].(bool)
+	return ok && !sampled && statusCode < 400
+}
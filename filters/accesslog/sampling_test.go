@@ -0,0 +1,91 @@
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestAccessLogSamplingArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg     string
+		args    []interface{}
+		isError bool
+	}{{
+		msg:     "no args",
+		isError: true,
+	}, {
+		msg:     "too many args",
+		args:    []interface{}{0.1, 0.2},
+		isError: true,
+	}, {
+		msg:     "not a number",
+		args:    []interface{}{"0.1"},
+		isError: true,
+	}, {
+		msg:     "negative",
+		args:    []interface{}{-0.1},
+		isError: true,
+	}, {
+		msg:     "greater than one",
+		args:    []interface{}{1.1},
+		isError: true,
+	}, {
+		msg:  "rate",
+		args: []interface{}{0.01},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewAccessLogSampling().CreateFilter(ti.args)
+			if ti.isError && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.isError && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	for _, ti := range []struct {
+		msg        string
+		rate       float64
+		statusCode int
+		sampledOut bool
+	}{{
+		msg:        "always sampled",
+		rate:       1,
+		statusCode: 200,
+	}, {
+		msg:        "never sampled",
+		rate:       0,
+		statusCode: 200,
+		sampledOut: true,
+	}, {
+		msg:        "client error never sampled out",
+		rate:       0,
+		statusCode: 404,
+	}, {
+		msg:        "server error never sampled out",
+		rate:       0,
+		statusCode: 503,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewAccessLogSampling().CreateFilter([]interface{}{ti.rate})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+			f.Request(ctx)
+			if s := SampledOut(ti.statusCode, ctx.FStateBag); s != ti.sampledOut {
+				t.Errorf("unexpected sampling, got: %t, expected: %t", s, ti.sampledOut)
+			}
+		})
+	}
+}
+
+func TestNotSampledOutWithoutFilter(t *testing.T) {
+	if SampledOut(200, make(map[string]interface{})) {
+		t.Error("unexpected sampling without the filter")
+	}
+}
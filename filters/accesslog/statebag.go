@@ -0,0 +1,71 @@
+package accesslog
+
+import (
+	"fmt"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	logStateBagSpec struct{}
+
+	logStateBag struct {
+		keys []string
+	}
+)
+
+// NewLogStateBag creates a filter spec to add the values of the given state
+// bag keys to the access log entry, as structured fields named by the keys.
+// Values that are not strings are rendered with the %v format. Missing keys
+// are not logged.
+//
+// The values are collected in the response phase, so that the values set by
+// the subsequent filters of the route are included, too.
+//
+//  	logStateBag("ratelimit-key", "lifo-group")
+func NewLogStateBag() filters.Spec {
+	return &logStateBagSpec{}
+}
+
+func (*logStateBagSpec) Name() string { return filters.LogStateBagName }
+
+func (*logStateBagSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	keys := make([]string, 0, len(args))
+	for _, a := range args {
+		k, ok := a.(string)
+		if !ok || k == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		keys = append(keys, k)
+	}
+
+	return &logStateBag{keys: keys}, nil
+}
+
+func (*logStateBag) Request(filters.FilterContext) {}
+
+func (f *logStateBag) Response(ctx filters.FilterContext) {
+	bag := ctx.StateBag()
+	additional, ok := bag[AccessLogAdditionalDataKey].(map[string]interface{})
+	if !ok {
+		additional = make(map[string]interface{})
+	}
+
+	for _, k := range f.keys {
+		v, ok := bag[k]
+		if !ok {
+			continue
+		}
+
+		additional[k] = fmt.Sprintf("%v", v)
+	}
+
+	if len(additional) > 0 {
+		bag[AccessLogAdditionalDataKey] = additional
+	}
+}
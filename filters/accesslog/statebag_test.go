@@ -0,0 +1,94 @@
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestLogStateBagArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg     string
+		args    []interface{}
+		isError bool
+	}{{
+		msg:     "no args",
+		isError: true,
+	}, {
+		msg:     "not a string",
+		args:    []interface{}{"foo", 42},
+		isError: true,
+	}, {
+		msg:     "empty key",
+		args:    []interface{}{""},
+		isError: true,
+	}, {
+		msg:  "keys",
+		args: []interface{}{"foo", "bar"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewLogStateBag().CreateFilter(ti.args)
+			if ti.isError && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.isError && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLogStateBag(t *testing.T) {
+	for _, ti := range []struct {
+		msg      string
+		args     []interface{}
+		stateBag map[string]interface{}
+		expected map[string]interface{}
+	}{{
+		msg:      "no values",
+		args:     []interface{}{"foo"},
+		stateBag: map[string]interface{}{},
+	}, {
+		msg:      "string value",
+		args:     []interface{}{"foo"},
+		stateBag: map[string]interface{}{"foo": "bar", "baz": "qux"},
+		expected: map[string]interface{}{"foo": "bar"},
+	}, {
+		msg:      "non-string values",
+		args:     []interface{}{"foo", "bar"},
+		stateBag: map[string]interface{}{"foo": 42, "bar": []string{"baz"}},
+		expected: map[string]interface{}{"foo": "42", "bar": "[baz]"},
+	}, {
+		msg:  "existing additional data",
+		args: []interface{}{"foo"},
+		stateBag: map[string]interface{}{
+			"foo":                      "bar",
+			AccessLogAdditionalDataKey: map[string]interface{}{"baz": "qux"},
+		},
+		expected: map[string]interface{}{"foo": "bar", "baz": "qux"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewLogStateBag().CreateFilter(ti.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FStateBag: ti.stateBag}
+			f.Request(ctx)
+			f.Response(ctx)
+
+			additional, _ := ctx.FStateBag[AccessLogAdditionalDataKey].(map[string]interface{})
+			if ti.expected == nil {
+				if additional != nil {
+					t.Errorf("unexpected additional data: %v", additional)
+				}
+
+				return
+			}
+
+			if !cmp.Equal(additional, ti.expected) {
+				t.Error(cmp.Diff(additional, ti.expected))
+			}
+		})
+	}
+}
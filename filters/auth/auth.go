@@ -46,6 +46,7 @@ const (
 	invalidClaim       rejectReason = "invalid-claim"
 	invalidFilter      rejectReason = "invalid-filter"
 	invalidAccess      rejectReason = "invalid-access"
+	missingClientCert  rejectReason = "missing-client-cert"
 )
 
 const (
@@ -1,9 +1,14 @@
 package auth
 
 import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"sync"
+
 	auth "github.com/abbot/go-http-auth"
 	"github.com/zalando/skipper/filters"
-	"net/http"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -17,6 +22,13 @@ const (
 
 type basicSpec struct{}
 
+var (
+	unknownUserSecretMx sync.Mutex
+	unknownUserSecret   string
+
+	randRead = rand.Read
+)
+
 type basic struct {
 	authenticator   *auth.BasicAuth
 	realmDefinition string
@@ -44,6 +56,44 @@ func (a *basic) Request(ctx filters.FilterContext) {
 	}
 }
 
+// getUnknownUserSecret returns a bcrypt hash of a random password, that
+// no password matches. It fails when the random password cannot be
+// created, instead of using a predictable one.
+func getUnknownUserSecret() (string, error) {
+	unknownUserSecretMx.Lock()
+	defer unknownUserSecretMx.Unlock()
+
+	if unknownUserSecret != "" {
+		return unknownUserSecret, nil
+	}
+
+	p := make([]byte, 32)
+	if _, err := randRead(p); err != nil {
+		return "", fmt.Errorf("failed to create random password: %w", err)
+	}
+
+	h, err := bcrypt.GenerateFromPassword(p, bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to create unknown user secret: %w", err)
+	}
+
+	unknownUserSecret = string(h)
+	return unknownUserSecret, nil
+}
+
+// withUnknownUserSecret returns the unknown secret for the users not found
+// in the htpasswd file, too, such that the password is checked for them,
+// and the response time doesn't reveal, whether the user exists
+func withUnknownUserSecret(secrets auth.SecretProvider, unknown string) auth.SecretProvider {
+	return func(user, realm string) string {
+		if s := secrets(user, realm); s != "" {
+			return s
+		}
+
+		return unknown
+	}
+}
+
 // Creates out basicAuth Filter
 // The first params specifies the used htpasswd file
 // The second is optional and defines the realm name
@@ -65,8 +115,13 @@ func (spec *basicSpec) CreateFilter(config []interface{}) (filters.Filter, error
 		}
 	}
 
+	unknown, err := getUnknownUserSecret()
+	if err != nil {
+		return nil, err
+	}
+
 	htpasswd := auth.HtpasswdFileProvider(configFile)
-	authenticator := auth.NewBasicAuthenticator(realmName, htpasswd)
+	authenticator := auth.NewBasicAuthenticator(realmName, withUnknownUserSecret(htpasswd, unknown))
 
 	return &basic{
 		authenticator:   authenticator,
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -71,6 +73,69 @@ func TestWithSuccessfulAuth(t *testing.T) {
 	}
 }
 
+func TestWithUnknownUser(t *testing.T) {
+	spec := NewBasicAuth()
+	f, err := spec.CreateFilter([]interface{}{"testdata/htpasswd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("GET", "https://www.example.org/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.SetBasicAuth("unknown", "")
+	ctx := &filtertest.Context{FRequest: req}
+	f.Request(ctx)
+	if !ctx.Served() || ctx.Response().StatusCode != http.StatusUnauthorized {
+		t.Error("Unknown user authenticated")
+	}
+}
+
+func TestUnknownUserSecret(t *testing.T) {
+	unknown, err := getUnknownUserSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := withUnknownUserSecret(func(user, realm string) string {
+		if user == "myName" {
+			return "{SHA}secret"
+		}
+
+		return ""
+	}, unknown)
+
+	if s := secrets("myName", ""); s != "{SHA}secret" {
+		t.Errorf("Unexpected secret for existing user: %s", s)
+	}
+
+	if s := secrets("unknown", ""); s == "" || s != unknown {
+		t.Errorf("Unexpected secret for unknown user: %s", s)
+	}
+}
+
+func TestUnknownUserSecretRandomFailure(t *testing.T) {
+	unknownUserSecretMx.Lock()
+	secret := unknownUserSecret
+	unknownUserSecret = ""
+	unknownUserSecretMx.Unlock()
+
+	defer func() {
+		randRead = rand.Read
+		unknownUserSecretMx.Lock()
+		unknownUserSecret = secret
+		unknownUserSecretMx.Unlock()
+	}()
+
+	randRead = func([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+	if _, err := NewBasicAuth().CreateFilter([]interface{}{"testdata/htpasswd"}); err == nil {
+		t.Error("Failed to fail when the random password cannot be created")
+	}
+}
+
 func TestCreateFilterBasicAuthErrorCases(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
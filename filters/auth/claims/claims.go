@@ -0,0 +1,32 @@
+// Package claims provides access to the claims of the JWT token, that were
+// stored in the state bag by the auth filters, without depending on the
+// auth filters.
+package claims
+
+// StateBagKey is the key used in the state bag by the jwtValidation and
+// the OpenID Connect filters to store the parsed token.
+const StateBagKey = "oidcclaimscachekey"
+
+// Container is implemented by the values stored in the state bag with
+// StateBagKey.
+type Container interface {
+	TokenClaims() map[string]interface{}
+}
+
+// Get returns the claims of the JWT token parsed and validated by the
+// jwtValidation filter or by the OpenID Connect filters earlier in the
+// filter chain, as stored in the state bag. It returns false, when no
+// token was parsed.
+func Get(stateBag map[string]interface{}) (map[string]interface{}, bool) {
+	c, ok := stateBag[StateBagKey].(Container)
+	if !ok {
+		return nil, false
+	}
+
+	claims := c.TokenClaims()
+	if claims == nil {
+		return nil, false
+	}
+
+	return claims, true
+}
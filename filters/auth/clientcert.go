@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	clientCertSubjectSpec struct{}
+
+	// all the attributes of a pattern need to match
+	clientCertPattern map[string]string
+
+	clientCertSubjectFilter struct {
+		patterns []clientCertPattern
+	}
+)
+
+var clientCertAttributes = map[string]bool{
+	"CN":           true,
+	"O":            true,
+	"OU":           true,
+	"C":            true,
+	"L":            true,
+	"ST":           true,
+	"STREET":       true,
+	"POSTALCODE":   true,
+	"SERIALNUMBER": true,
+	"DNS":          true,
+}
+
+// NewRequireClientCertSubject creates a filter specification, whose
+// instances allow only the requests with a verified client certificate,
+// whose subject or SAN DNS names match one of the configured patterns.
+// Other requests are rejected with 403.
+//
+// A pattern is a comma separated list of attributes, where each
+// attribute of the pattern needs to match. The supported attributes are
+// CN, O, OU, C, L, ST, STREET, POSTALCODE, SERIALNUMBER, and DNS, which
+// matches the SAN DNS names. The values may contain the wildcards
+// supported by path.Match.
+//
+//     * -> requireClientCertSubject("CN=payments,O=acme", "DNS=*.payments.internal") -> "https://payments.internal"
+//
+// The client certificates are verified only when the CA of the clients
+// is configured for the server.
+func NewRequireClientCertSubject() filters.Spec { return clientCertSubjectSpec{} }
+
+func (clientCertSubjectSpec) Name() string { return filters.RequireClientCertSubjectName }
+
+func parseClientCertPattern(s string) (clientCertPattern, error) {
+	p := make(clientCertPattern)
+	for _, a := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(a), "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid client certificate pattern: %s", s)
+		}
+
+		k := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if !clientCertAttributes[k] {
+			return nil, fmt.Errorf("unsupported client certificate attribute: %s", kv[0])
+		}
+
+		v := strings.TrimSpace(kv[1])
+		if _, err := path.Match(v, ""); err != nil {
+			return nil, fmt.Errorf("invalid client certificate pattern: %s: %w", s, err)
+		}
+
+		p[k] = v
+	}
+
+	return p, nil
+}
+
+func (clientCertSubjectSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	sargs, err := getStrings(args)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &clientCertSubjectFilter{}
+	for _, s := range sargs {
+		p, err := parseClientCertPattern(s)
+		if err != nil {
+			return nil, err
+		}
+
+		f.patterns = append(f.patterns, p)
+	}
+
+	return f, nil
+}
+
+func matchAnyValue(pattern string, values []string) bool {
+	for _, v := range values {
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func clientCertValues(cert *x509.Certificate, attribute string) []string {
+	s := cert.Subject
+	switch attribute {
+	case "CN":
+		return []string{s.CommonName}
+	case "O":
+		return s.Organization
+	case "OU":
+		return s.OrganizationalUnit
+	case "C":
+		return s.Country
+	case "L":
+		return s.Locality
+	case "ST":
+		return s.Province
+	case "STREET":
+		return s.StreetAddress
+	case "POSTALCODE":
+		return s.PostalCode
+	case "SERIALNUMBER":
+		return []string{s.SerialNumber}
+	case "DNS":
+		return cert.DNSNames
+	default:
+		return nil
+	}
+}
+
+func (p clientCertPattern) match(cert *x509.Certificate) bool {
+	for k, v := range p {
+		if !matchAnyValue(v, clientCertValues(cert, k)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *clientCertSubjectFilter) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+
+	// only the verified chains are accepted, not just the presented
+	// certificates
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		forbidden(ctx, "", missingClientCert, "")
+		return
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	subject := leaf.Subject.String()
+	for _, p := range f.patterns {
+		if p.match(leaf) {
+			authorized(ctx, subject)
+			return
+		}
+	}
+
+	forbidden(ctx, subject, invalidAccess, "client certificate subject mismatch")
+}
+
+func (f *clientCertSubjectFilter) Response(filters.FilterContext) {}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRequireClientCertSubjectArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+	}{{
+		name: "no args",
+	}, {
+		name: "not a string",
+		args: []interface{}{42.0},
+	}, {
+		name: "missing value",
+		args: []interface{}{"CN=payments,O"},
+	}, {
+		name: "unsupported attribute",
+		args: []interface{}{"UID=payments"},
+	}, {
+		name: "invalid pattern",
+		args: []interface{}{"CN=[payments"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRequireClientCertSubject().CreateFilter(tt.args); err == nil {
+				t.Error("failed to fail")
+			}
+		})
+	}
+}
+
+func TestRequireClientCertSubject(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   "payments",
+			Organization: []string{"acme", "acme-eu"},
+		},
+		DNSNames: []string{"payments.svc.internal"},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		args     []interface{}
+		tls      *tls.ConnectionState
+		expected int
+	}{{
+		name:     "no tls",
+		args:     []interface{}{"CN=payments"},
+		expected: http.StatusForbidden,
+	}, {
+		name:     "presented but not verified",
+		args:     []interface{}{"CN=payments"},
+		tls:      &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		expected: http.StatusForbidden,
+	}, {
+		name:     "subject matches",
+		args:     []interface{}{"CN=payments,O=acme-eu"},
+		tls:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		expected: http.StatusOK,
+	}, {
+		name:     "subject doesn't match",
+		args:     []interface{}{"CN=payments,O=example"},
+		tls:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		expected: http.StatusForbidden,
+	}, {
+		name:     "dns name matches wildcard",
+		args:     []interface{}{"CN=orders", "DNS=*.svc.internal"},
+		tls:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		expected: http.StatusOK,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewRequireClientCertSubject().CreateFilter(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", "https://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.TLS = tt.tls
+			ctx := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+			f.Request(ctx)
+
+			status := http.StatusOK
+			if ctx.FServed {
+				status = ctx.FResponse.StatusCode
+			}
+
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, status)
+			}
+		})
+	}
+}
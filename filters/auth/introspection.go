@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	// tokenintrospectionScopesKey is the state bag key of the scopes of
+	// the token validated by the oauthIntrospection filter
+	tokenintrospectionScopesKey = "tokenintrospection.scopes"
+
+	defaultIntrospectionCacheTTL  = time.Minute
+	defaultIntrospectionCacheSize = 10000
+)
+
+type (
+	introspectionSpec struct {
+		options TokenintrospectionOptions
+
+		mx      sync.Mutex
+		clients map[string]*authClient
+		cache   *introspectionCache
+	}
+
+	introspectionFilter struct {
+		authClient    *authClient
+		cache         *introspectionCache
+		failureStatus int
+		maxTTL        time.Duration
+	}
+
+	introspectionCacheEntry struct {
+		info    tokenIntrospectionInfo
+		expires time.Time
+	}
+
+	// introspectionCache stores the introspection results by the
+	// introspection endpoint and the token, shared by all the filter
+	// instances, so that the results survive the route updates
+	introspectionCache struct {
+		mx         sync.Mutex
+		entries    map[string]introspectionCacheEntry
+		maxEntries int
+	}
+)
+
+// NewOAuthIntrospection creates a filter specification, whose instances
+// validate the bearer token of the incoming requests by calling an RFC
+// 7662 compliant introspection endpoint. The introspection results are
+// cached by the token, until the exp claim of the token, but at most for
+// the configured cache TTL, which defaults to 1 minute.
+//
+// The first argument is the URL of the introspection endpoint, which may
+// contain the client credentials as user info. The optional second
+// argument is the status code of the response, when the introspection
+// endpoint is not available, either 401 (default) or 503. The optional
+// third argument is the maximum TTL of the cached results.
+//
+//     * -> oauthIntrospection("https://auth.example.org/introspect", 503, "30s") -> "https://www.example.org"
+//
+// The introspection result of an active token is stored in the state
+// bag, where e.g. the forwardToken filter can use it, and the scopes of
+// the token are stored separately.
+func NewOAuthIntrospection(o TokenintrospectionOptions) filters.Spec {
+	if o.Tracer == nil {
+		o.Tracer = opentracing.NoopTracer{}
+	}
+
+	return &introspectionSpec{
+		options: o,
+		clients: make(map[string]*authClient),
+		cache:   newIntrospectionCache(defaultIntrospectionCacheSize),
+	}
+}
+
+func (s *introspectionSpec) Name() string { return filters.OAuthIntrospectionName }
+
+func (s *introspectionSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	endpoint, ok := args[0].(string)
+	if !ok || endpoint == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &introspectionFilter{
+		cache:         s.cache,
+		failureStatus: http.StatusUnauthorized,
+		maxTTL:        defaultIntrospectionCacheTTL,
+	}
+
+	if len(args) > 1 {
+		status, ok := args[1].(float64)
+		if !ok || int(status) != http.StatusUnauthorized && int(status) != http.StatusServiceUnavailable {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.failureStatus = int(status)
+	}
+
+	if len(args) > 2 {
+		ttl, ok := args[2].(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.maxTTL = d
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	ac, ok := s.clients[endpoint]
+	if !ok {
+		var err error
+		ac, err = newAuthClient(endpoint, tokenIntrospectionSpanName, s.options.Timeout, s.options.MaxIdleConns, s.options.Tracer)
+		if err != nil {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		s.clients[endpoint] = ac
+	}
+
+	f.authClient = ac
+	return f, nil
+}
+
+func newIntrospectionCache(maxEntries int) *introspectionCache {
+	return &introspectionCache{
+		entries:    make(map[string]introspectionCacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *introspectionCache) get(key string, now time.Time) (tokenIntrospectionInfo, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if now.After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return e.info, true
+}
+
+func (c *introspectionCache) set(key string, info tokenIntrospectionInfo, expires time.Time, now time.Time) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	// when no entry expired, an arbitrary one is evicted
+	if len(c.entries) >= c.maxEntries {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[key] = introspectionCacheEntry{info: info, expires: expires}
+}
+
+// returns until when the result can be cached, honoring the exp claim
+func (f *introspectionFilter) expires(info tokenIntrospectionInfo, now time.Time) time.Time {
+	expires := now.Add(f.maxTTL)
+	if exp, ok := info["exp"].(float64); ok {
+		if t := time.Unix(int64(exp), 0); t.Before(expires) {
+			expires = t
+		}
+	}
+
+	return expires
+}
+
+func (info tokenIntrospectionInfo) scopes() []string {
+	s, ok := info[scopeKey].(string)
+	if !ok {
+		return nil
+	}
+
+	return strings.Fields(s)
+}
+
+func (f *introspectionFilter) Request(ctx filters.FilterContext) {
+	token, ok := getToken(ctx.Request())
+	if !ok || token == "" {
+		unauthorized(ctx, "", missingToken, f.authClient.url.Hostname(), "")
+		return
+	}
+
+	now := time.Now()
+	key := f.authClient.url.String() + " " + token
+	info, ok := f.cache.get(key, now)
+	if !ok {
+		var err error
+		info, err = f.authClient.getTokenintrospect(token, ctx)
+		if err != nil {
+			log.Errorf("Error while calling token introspection: %v.", err)
+			reject(ctx, f.failureStatus, "", authServiceAccess, f.authClient.url.Hostname(), "")
+			return
+		}
+
+		if expires := f.expires(info, now); expires.After(now) {
+			f.cache.set(key, info, expires, now)
+		}
+	}
+
+	sub, _ := info.Sub()
+	if !info.Active() {
+		unauthorized(ctx, sub, inactiveToken, f.authClient.url.Hostname(), "")
+		return
+	}
+
+	authorized(ctx, sub)
+	ctx.StateBag()[tokenintrospectionCacheKey] = info
+	ctx.StateBag()[tokenintrospectionScopesKey] = info.scopes()
+}
+
+func (f *introspectionFilter) Response(filters.FilterContext) {}
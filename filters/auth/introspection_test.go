@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestOAuthIntrospectionArgs(t *testing.T) {
+	spec := NewOAuthIntrospection(TokenintrospectionOptions{})
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "endpoint",
+		args: []interface{}{"https://auth.example.org/introspect"},
+	}, {
+		name: "failure status",
+		args: []interface{}{"https://auth.example.org/introspect", 503.0},
+	}, {
+		name: "invalid failure status",
+		args: []interface{}{"https://auth.example.org/introspect", 500.0},
+		fail: true,
+	}, {
+		name: "cache ttl",
+		args: []interface{}{"https://auth.example.org/introspect", 401.0, "30s"},
+	}, {
+		name: "invalid cache ttl",
+		args: []interface{}{"https://auth.example.org/introspect", 401.0, "foo"},
+		fail: true,
+	}, {
+		name: "too many args",
+		args: []interface{}{"https://auth.example.org/introspect", 401.0, "30s", "foo"},
+		fail: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := spec.CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestOAuthIntrospection(t *testing.T) {
+	var calls int32
+	exp := time.Now().Add(time.Hour).Unix()
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		user, password, _ := r.BasicAuth()
+		if user != "client" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		info := tokenIntrospectionInfo{"active": false}
+		switch r.FormValue(tokenKey) {
+		case "active-token":
+			info = tokenIntrospectionInfo{"active": true, "sub": "jdoe", "scope": "read write", "exp": exp}
+		case "expired-token":
+			info = tokenIntrospectionInfo{"active": true, "sub": "jdoe", "exp": time.Now().Add(-time.Second).Unix()}
+		case "failing-token":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(info)
+	}))
+	defer authServer.Close()
+
+	endpoint := "http://client:secret@" + authServer.Listener.Addr().String()
+	spec := NewOAuthIntrospection(TokenintrospectionOptions{Timeout: time.Second})
+
+	request := func(f filters.Filter, token string) *filtertest.Context {
+		req, err := http.NewRequest("GET", "https://www.example.org", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set(authHeaderName, authHeaderPrefix+token)
+		ctx := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		return ctx
+	}
+
+	t.Run("active token is cached", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		f, err := spec.CreateFilter([]interface{}{endpoint})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 3; i++ {
+			ctx := request(f, "active-token")
+			if ctx.FServed {
+				t.Fatalf("unexpected rejection: %d", ctx.FResponse.StatusCode)
+			}
+
+			scopes, _ := ctx.FStateBag[tokenintrospectionScopesKey].([]string)
+			if len(scopes) != 2 || scopes[0] != "read" || scopes[1] != "write" {
+				t.Errorf("unexpected scopes: %v", scopes)
+			}
+
+			if _, ok := ctx.FStateBag[tokenintrospectionCacheKey].(tokenIntrospectionInfo); !ok {
+				t.Error("missing introspection result")
+			}
+		}
+
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("unexpected number of introspection calls: %d", n)
+		}
+	})
+
+	t.Run("expired token is not cached", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		f, err := spec.CreateFilter([]interface{}{endpoint})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request(f, "expired-token")
+		request(f, "expired-token")
+		if n := atomic.LoadInt32(&calls); n != 2 {
+			t.Errorf("unexpected number of introspection calls: %d", n)
+		}
+	})
+
+	t.Run("inactive token", func(t *testing.T) {
+		f, err := spec.CreateFilter([]interface{}{endpoint})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := request(f, "inactive-token")
+		if !ctx.FServed || ctx.FResponse.StatusCode != http.StatusUnauthorized {
+			t.Error("failed to reject inactive token")
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		f, err := spec.CreateFilter([]interface{}{endpoint})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := request(f, "")
+		if !ctx.FServed || ctx.FResponse.StatusCode != http.StatusUnauthorized {
+			t.Error("failed to reject missing token")
+		}
+	})
+
+	t.Run("introspection failure", func(t *testing.T) {
+		for _, status := range []int{http.StatusUnauthorized, http.StatusServiceUnavailable} {
+			f, err := spec.CreateFilter([]interface{}{endpoint, float64(status)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := request(f, "failing-token")
+			if !ctx.FServed || ctx.FResponse.StatusCode != status {
+				t.Errorf("failed to reject with %d", status)
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	jwt "github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+var errJWKSExpired = errors.New("the JWKS expired")
+
+// jwksCache holds the keys fetched from a JWKS URL. The keys are
+// refreshed in the background before they expire, and the token
+// verification never waits for a fetch. When the refresh fails, the
+// stale keys are used for a bounded grace period. A token with an unknown
+// key ID triggers an asynchronous, rate limited refresh.
+type jwksCache struct {
+	url       string
+	client    *http.Client
+	ttl       time.Duration
+	grace     time.Duration
+	rateLimit time.Duration
+
+	mx          sync.RWMutex
+	jwks        *keyfunc.JWKS
+	lastSuccess time.Time
+	lastFetch   time.Time
+
+	trigger chan struct{}
+}
+
+// newJWKSCache fetches the keys synchronously, so that invalid URLs are
+// detected when the filter is created, and starts the background
+// refresh.
+func newJWKSCache(url string, ttl, grace, rateLimit, timeout time.Duration) (*jwksCache, error) {
+	c := &jwksCache{
+		url:       url,
+		client:    &http.Client{Timeout: timeout},
+		ttl:       ttl,
+		grace:     grace,
+		rateLimit: rateLimit,
+		trigger:   make(chan struct{}, 1),
+	}
+
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+
+	go c.run()
+	return c, nil
+}
+
+func (c *jwksCache) fetch() (*keyfunc.JWKS, error) {
+	rsp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, rsp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d", rsp.StatusCode)
+	}
+
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyfunc.NewJSON(json.RawMessage(b))
+}
+
+func (c *jwksCache) refresh() error {
+	jwks, err := c.fetch()
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.lastFetch = time.Now()
+	if err != nil {
+		return err
+	}
+
+	c.jwks = jwks
+	c.lastSuccess = c.lastFetch
+	return nil
+}
+
+// the keys are refreshed when three quarters of the TTL elapsed, and
+// after a failure, the refresh is retried after the rate limit
+func (c *jwksCache) run() {
+	next := c.ttl * 3 / 4
+	for {
+		select {
+		case <-time.After(next):
+		case <-c.trigger:
+			c.mx.RLock()
+			wait := c.rateLimit - time.Since(c.lastFetch)
+			c.mx.RUnlock()
+
+			if wait > 0 {
+				next = wait
+				continue
+			}
+		}
+
+		if err := c.refresh(); err != nil {
+			log.Errorf("There was an error on key refresh for the given URL %s\nError:%s\n", c.url, err.Error())
+			next = c.rateLimit
+		} else {
+			next = c.ttl * 3 / 4
+		}
+	}
+}
+
+func (c *jwksCache) triggerRefresh() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Keyfunc can be used as the jwt.Keyfunc, returning the key identified
+// by the kid header of the token.
+func (c *jwksCache) Keyfunc(token *jwt.Token) (interface{}, error) {
+	c.mx.RLock()
+	jwks, lastSuccess := c.jwks, c.lastSuccess
+	c.mx.RUnlock()
+
+	if time.Since(lastSuccess) > c.ttl+c.grace {
+		c.triggerRefresh()
+		return nil, errJWKSExpired
+	}
+
+	key, err := jwks.Keyfunc(token)
+	if refreshUnknownKID && errors.Is(err, keyfunc.ErrKIDNotFound) {
+		c.triggerRefresh()
+	}
+
+	return key, err
+}
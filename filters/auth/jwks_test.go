@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+type testJWKSServer struct {
+	*httptest.Server
+
+	mx   sync.Mutex
+	kid  string
+	fail bool
+}
+
+func newTestJWKSServer(kid string) *testJWKSServer {
+	s := &testJWKSServer{kid: kid}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+
+		if s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA", "alg":"RS256", "kid": "%s", "n":"%s","e":"AQAB"}]}`,
+			s.kid, base64.RawURLEncoding.EncodeToString(privateKey.PublicKey.N.Bytes()))
+	}))
+
+	return s
+}
+
+func (s *testJWKSServer) set(kid string, fail bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.kid, s.fail = kid, fail
+}
+
+func testKeyToken(kid string) *jwt.Token {
+	return &jwt.Token{
+		Method: jwt.SigningMethodRS256,
+		Header: map[string]interface{}{"alg": "RS256", "kid": kid},
+	}
+}
+
+func TestJWKSCacheUnknownKID(t *testing.T) {
+	s := newTestJWKSServer("old")
+	defer s.Close()
+
+	c, err := newJWKSCache(s.URL, time.Hour, time.Hour, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.set("new", false)
+	if _, err := c.Keyfunc(testKeyToken("new")); err == nil {
+		t.Fatal("unexpected key for the unknown kid")
+	}
+
+	// the refresh happens in the background
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := c.Keyfunc(testKeyToken("new")); err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("failed to refresh the keys")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJWKSCacheStaleKeys(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		grace time.Duration
+		fail  bool
+	}{{
+		name:  "within the grace period",
+		grace: time.Hour,
+	}, {
+		name:  "after the grace period",
+		grace: 50 * time.Millisecond,
+		fail:  true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestJWKSServer(kid)
+			defer s.Close()
+
+			c, err := newJWKSCache(s.URL, 50*time.Millisecond, tt.grace, 10*time.Millisecond, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s.set(kid, true)
+			time.Sleep(200 * time.Millisecond)
+
+			_, err = c.Keyfunc(testKeyToken(kid))
+			if tt.fail && err != errJWKSExpired {
+				t.Errorf("unexpected error: %v", err)
+			} else if !tt.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestJWKSCacheInitialFetchFails(t *testing.T) {
+	s := newTestJWKSServer(kid)
+	defer s.Close()
+
+	s.set(kid, true)
+	if _, err := newJWKSCache(s.URL, time.Hour, time.Hour, 0, time.Second); err == nil {
+		t.Error("failed to fail")
+	}
+}
@@ -5,10 +5,10 @@ import (
 	"sync"
 	"time"
 
-	"github.com/MicahParks/keyfunc"
 	jwt "github.com/golang-jwt/jwt/v4"
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/auth/claims"
 )
 
 const (
@@ -33,8 +33,11 @@ var refreshRateLimit = time.Minute * 5
 var refreshTimeout = time.Second * 10
 var refreshUnknownKID = true
 
-//the map of jwks keyfunctions stored per jwksUri
-var jwksMap map[string]*keyfunc.JWKS = make(map[string]*keyfunc.JWKS)
+// the stale keys are used for this period, when the refresh fails
+var refreshGracePeriod = time.Hour
+
+// the map of jwks caches stored per jwksUri
+var jwksMap map[string]*jwksCache = make(map[string]*jwksCache)
 
 func NewJwtValidationWithOptions(o TokenintrospectionOptions) filters.Spec {
 	return &jwtValidationSpec{
@@ -82,7 +85,7 @@ func hasKeyFunction(url string) bool {
 	return ok
 }
 
-func putKeyFunction(url string, jwks *keyfunc.JWKS) {
+func putKeyFunction(url string, jwks *jwksCache) {
 	m.Lock()
 	defer m.Unlock()
 
@@ -94,17 +97,7 @@ func registerKeyFunction(url string) (err error) {
 		return nil
 	}
 
-	options := keyfunc.Options{
-		RefreshErrorHandler: func(err error) {
-			log.Errorf("There was an error on key refresh for the given URL %s\nError:%s\n", url, err.Error())
-		},
-		RefreshInterval:   refreshInterval,
-		RefreshRateLimit:  refreshRateLimit,
-		RefreshTimeout:    refreshTimeout,
-		RefreshUnknownKID: refreshUnknownKID,
-	}
-
-	jwks, err := keyfunc.Get(url, options)
+	jwks, err := newJWKSCache(url, refreshInterval, refreshGracePeriod, refreshRateLimit, refreshTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to get the JWKS from the given URL %s Error:%w", url, err)
 	}
@@ -113,7 +106,7 @@ func registerKeyFunction(url string) (err error) {
 	return nil
 }
 
-func getKeyFunction(url string) (jwks *keyfunc.JWKS) {
+func getKeyFunction(url string) (jwks *jwksCache) {
 	m.RLock()
 	defer m.RUnlock()
 
@@ -157,6 +150,18 @@ func (f *jwtValidationFilter) Request(ctx filters.FilterContext) {
 
 func (f *jwtValidationFilter) Response(filters.FilterContext) {}
 
+// Claims returns the claims of the JWT token parsed and validated by the jwtValidation filter or by the OpenID
+// Connect filters earlier in the filter chain, as stored in the state bag. It returns false, when no token
+// was parsed.
+func Claims(stateBag map[string]interface{}) (map[string]interface{}, bool) {
+	return claims.Get(stateBag)
+}
+
+// TokenClaims implements claims.Container.
+func (c tokenContainer) TokenClaims() map[string]interface{} {
+	return c.Claims
+}
+
 func parseToken(token string, jwksUri string) (map[string]interface{}, error) {
 	jwks := getKeyFunction(jwksUri)
 
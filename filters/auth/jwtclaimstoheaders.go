@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	"golang.org/x/net/http/httpguts"
+)
+
+type (
+	jwtClaimsToHeadersSpec struct{}
+
+	claimHeader struct {
+		claim  string
+		header string
+	}
+
+	jwtClaimsToHeadersFilter struct {
+		mappings []claimHeader
+	}
+)
+
+// NewJwtClaimsToHeaders creates a filter specification, whose instances
+// copy the claims of the JWT token validated by an auth filter earlier in
+// the filter chain, e.g. jwtValidation or oauthOidcAnyClaims, to request
+// headers. It expects one or more mappings in the form of
+// <claim>=<header>:
+//
+//     * -> jwtValidation("https://login.example.org") -> jwtClaimsToHeaders("sub=X-User-Id", "tenant=X-Tenant") -> "https://www.example.org"
+//
+// The incoming headers with the mapped names are always removed, to
+// prevent spoofing. The missing claims are skipped. The string claims are
+// set as they are, the other claims are set as JSON.
+func NewJwtClaimsToHeaders() filters.Spec { return jwtClaimsToHeadersSpec{} }
+
+func (jwtClaimsToHeadersSpec) Name() string { return filters.JwtClaimsToHeadersName }
+
+func (jwtClaimsToHeadersSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var (
+		mappings []claimHeader
+		headers  = make(map[string]bool)
+	)
+
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid claim to header mapping: %s", s)
+		}
+
+		claim, header := kv[0], kv[1]
+		if !httpguts.ValidHeaderFieldName(header) {
+			return nil, fmt.Errorf("header name %s is invalid", header)
+		}
+
+		header = http.CanonicalHeaderKey(header)
+		if headers[header] {
+			return nil, fmt.Errorf("duplicate header in claim to header mapping: %s", header)
+		}
+
+		headers[header] = true
+		mappings = append(mappings, claimHeader{claim: claim, header: header})
+	}
+
+	return &jwtClaimsToHeadersFilter{mappings: mappings}, nil
+}
+
+func claimHeaderValue(v interface{}) (string, bool) {
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+func (f *jwtClaimsToHeadersFilter) Request(ctx filters.FilterContext) {
+	h := ctx.Request().Header
+	for _, m := range f.mappings {
+		h.Del(m.header)
+	}
+
+	claims, ok := Claims(ctx.StateBag())
+	if !ok {
+		return
+	}
+
+	for _, m := range f.mappings {
+		c, ok := claims[m.claim]
+		if !ok || c == nil {
+			continue
+		}
+
+		v, ok := claimHeaderValue(c)
+		if !ok || !httpguts.ValidHeaderFieldValue(v) {
+			log.Errorf("Invalid value of claim %s for header %s", m.claim, m.header)
+			continue
+		}
+
+		h.Set(m.header, v)
+	}
+}
+
+func (*jwtClaimsToHeadersFilter) Response(filters.FilterContext) {}
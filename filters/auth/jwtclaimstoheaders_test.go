@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestJwtClaimsToHeadersArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "invalid type",
+		args: []interface{}{42.0},
+		fail: true,
+	}, {
+		name: "missing header",
+		args: []interface{}{"sub"},
+		fail: true,
+	}, {
+		name: "missing claim",
+		args: []interface{}{"=X-User-Id"},
+		fail: true,
+	}, {
+		name: "invalid header",
+		args: []interface{}{"sub=X User Id"},
+		fail: true,
+	}, {
+		name: "duplicate header",
+		args: []interface{}{"sub=X-User-Id", "uid=x-user-id"},
+		fail: true,
+	}, {
+		name: "mappings",
+		args: []interface{}{"sub=X-User-Id", "tenant=X-Tenant"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJwtClaimsToHeaders().CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestJwtClaimsToHeaders(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		header   http.Header
+		stateBag map[string]interface{}
+		expected http.Header
+	}{{
+		name:     "no token",
+		header:   http.Header{"X-User-Id": []string{"spoofed"}, "X-Foo": []string{"foo"}},
+		stateBag: map[string]interface{}{},
+		expected: http.Header{"X-Foo": []string{"foo"}},
+	}, {
+		name: "claims",
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"sub": "jdoe", "tenant": "acme"}},
+		},
+		expected: http.Header{"X-User-Id": []string{"jdoe"}, "X-Tenant": []string{"acme"}},
+	}, {
+		name:   "spoofed headers replaced",
+		header: http.Header{"X-User-Id": []string{"admin"}, "X-Tenant": []string{"globex"}},
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"sub": "jdoe", "tenant": "acme"}},
+		},
+		expected: http.Header{"X-User-Id": []string{"jdoe"}, "X-Tenant": []string{"acme"}},
+	}, {
+		name:   "missing claim skipped",
+		header: http.Header{"X-Tenant": []string{"globex"}},
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"sub": "jdoe"}},
+		},
+		expected: http.Header{"X-User-Id": []string{"jdoe"}},
+	}, {
+		name: "non-string claims",
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{
+				"sub":    42.0,
+				"tenant": []interface{}{"acme", "globex"},
+			}},
+		},
+		expected: http.Header{"X-User-Id": []string{"42"}, "X-Tenant": []string{`["acme","globex"]`}},
+	}, {
+		name: "invalid header value skipped",
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"sub": "jdoe\r\nX-Admin: true"}},
+		},
+		expected: http.Header{},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewJwtClaimsToHeaders().CreateFilter([]interface{}{"sub=X-User-Id", "tenant=X-Tenant"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header := make(http.Header)
+			for k, v := range tt.header {
+				header[k] = v
+			}
+
+			ctx := &filtertest.Context{FRequest: &http.Request{Header: header}, FStateBag: tt.stateBag}
+			f.Request(ctx)
+
+			if len(header) != len(tt.expected) {
+				t.Fatalf("expected headers %v, got %v", tt.expected, header)
+			}
+
+			for k := range tt.expected {
+				if header.Get(k) != tt.expected.Get(k) {
+					t.Errorf("expected header %s: %s, got: %s", k, tt.expected.Get(k), header.Get(k))
+				}
+			}
+		})
+	}
+}
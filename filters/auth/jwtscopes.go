@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+	logfilter "github.com/zalando/skipper/filters/log"
+)
+
+type (
+	jwtScopesSpec struct{}
+
+	jwtScopesFilter struct {
+		expr scopeExpr
+	}
+
+	// scopeExpr is a node of a parsed scope expression. When the
+	// expression doesn't match, missing returns the nodes that were not
+	// satisfied.
+	scopeExpr interface {
+		missing(scopes map[string]bool) []scopeExpr
+		leaves() []string
+		String() string
+	}
+
+	scopeLeaf string
+	scopeAnd  []scopeExpr
+	scopeOr   []scopeExpr
+
+	scopeParser struct {
+		tokens []string
+		pos    int
+	}
+)
+
+// NewJwtScopes creates a filter specification, whose instances check the
+// scopes of the token validated by an auth filter earlier in the filter
+// chain, e.g. jwtValidation or oauthIntrospection, against a scope
+// expression. The expression may contain scope names combined with the
+// AND and OR operators, and parentheses. AND binds stronger than OR.
+//
+//     * -> jwtValidation("https://login.example.org") -> jwtScopes("read AND (write OR admin)") -> "https://www.example.org"
+//
+// When the token doesn't have the required scopes, the request is
+// rejected with 403, naming the missing scopes.
+func NewJwtScopes() filters.Spec { return jwtScopesSpec{} }
+
+func (jwtScopesSpec) Name() string { return filters.JwtScopesName }
+
+func (jwtScopesSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	expr, err := parseScopeExpr(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwtScopesFilter{expr: expr}, nil
+}
+
+func tokenizeScopeExpr(s string) []string {
+	var tokens []string
+	for _, f := range strings.Fields(s) {
+		for f != "" {
+			i := strings.IndexAny(f, "()")
+			switch {
+			case i < 0:
+				tokens = append(tokens, f)
+				f = ""
+			case i == 0:
+				tokens = append(tokens, f[:1])
+				f = f[1:]
+			default:
+				tokens = append(tokens, f[:i])
+				f = f[i:]
+			}
+		}
+	}
+
+	return tokens
+}
+
+func parseScopeExpr(s string) (scopeExpr, error) {
+	p := &scopeParser{tokens: tokenizeScopeExpr(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty scope expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in scope expression: %s", p.tokens[p.pos], s)
+	}
+
+	return expr, nil
+}
+
+func (p *scopeParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *scopeParser) parseOr() (scopeExpr, error) {
+	var or scopeOr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		or = append(or, expr)
+		if p.next() != "OR" {
+			break
+		}
+
+		p.pos++
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+
+	return or, nil
+}
+
+func (p *scopeParser) parseAnd() (scopeExpr, error) {
+	var and scopeAnd
+	for {
+		expr, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		and = append(and, expr)
+		if p.next() != "AND" {
+			break
+		}
+
+		p.pos++
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+
+	return and, nil
+}
+
+func (p *scopeParser) parsePrimary() (scopeExpr, error) {
+	t := p.next()
+	switch t {
+	case "":
+		return nil, fmt.Errorf("unexpected end of scope expression")
+	case "AND", "OR", ")":
+		return nil, fmt.Errorf("unexpected %q in scope expression", t)
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in scope expression")
+		}
+
+		p.pos++
+		return expr, nil
+	default:
+		p.pos++
+		return scopeLeaf(t), nil
+	}
+}
+
+func (l scopeLeaf) missing(scopes map[string]bool) []scopeExpr {
+	if scopes[string(l)] {
+		return nil
+	}
+
+	return []scopeExpr{l}
+}
+
+func (l scopeLeaf) leaves() []string { return []string{string(l)} }
+func (l scopeLeaf) String() string   { return string(l) }
+
+func (a scopeAnd) missing(scopes map[string]bool) []scopeExpr {
+	var m []scopeExpr
+	for _, expr := range a {
+		m = append(m, expr.missing(scopes)...)
+	}
+
+	return m
+}
+
+func (a scopeAnd) leaves() []string {
+	var l []string
+	for _, expr := range a {
+		l = append(l, expr.leaves()...)
+	}
+
+	return l
+}
+
+func (a scopeAnd) String() string { return joinScopeExpr(a, " AND ") }
+
+func (o scopeOr) missing(scopes map[string]bool) []scopeExpr {
+	for _, expr := range o {
+		if len(expr.missing(scopes)) == 0 {
+			return nil
+		}
+	}
+
+	return []scopeExpr{o}
+}
+
+func (o scopeOr) leaves() []string {
+	var l []string
+	for _, expr := range o {
+		l = append(l, expr.leaves()...)
+	}
+
+	return l
+}
+
+func (o scopeOr) String() string { return joinScopeExpr(o, " OR ") }
+
+func joinScopeExpr(exprs []scopeExpr, op string) string {
+	s := make([]string, len(exprs))
+	for i, expr := range exprs {
+		s[i] = expr.String()
+		if _, ok := expr.(scopeLeaf); !ok {
+			s[i] = "(" + s[i] + ")"
+		}
+	}
+
+	return strings.Join(s, op)
+}
+
+func scopeStrings(v interface{}) ([]string, bool) {
+	switch s := v.(type) {
+	case string:
+		return strings.Fields(s), true
+	case []string:
+		return s, true
+	case []interface{}:
+		var scopes []string
+		for _, si := range s {
+			if ss, ok := si.(string); ok {
+				scopes = append(scopes, ss)
+			}
+		}
+
+		return scopes, true
+	default:
+		return nil, false
+	}
+}
+
+// tokenScopes returns the scopes of the token validated by the auth
+// filters earlier in the chain, from the JWT claims, the token
+// introspection or the tokeninfo result
+func tokenScopes(stateBag map[string]interface{}) ([]string, bool) {
+	if claims, ok := Claims(stateBag); ok {
+		for _, k := range []string{scopeKey, "scp"} {
+			if scopes, ok := scopeStrings(claims[k]); ok {
+				return scopes, true
+			}
+		}
+
+		return nil, true
+	}
+
+	if scopes, ok := stateBag[tokenintrospectionScopesKey].([]string); ok {
+		return scopes, true
+	}
+
+	if info, ok := stateBag[tokeninfoCacheKey].(map[string]interface{}); ok {
+		scopes, _ := scopeStrings(info[scopeKey])
+		return scopes, true
+	}
+
+	return nil, false
+}
+
+func (f *jwtScopesFilter) Request(ctx filters.FilterContext) {
+	scopes, ok := tokenScopes(ctx.StateBag())
+	if !ok {
+		unauthorized(ctx, "", missingToken, "", "")
+		return
+	}
+
+	has := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		has[s] = true
+	}
+
+	missing := f.expr.missing(has)
+	if len(missing) == 0 {
+		return
+	}
+
+	var names, leaves []string
+	for _, m := range missing {
+		names = append(names, m.String())
+		leaves = append(leaves, m.leaves()...)
+	}
+
+	msg := "missing scope: " + strings.Join(names, ", ")
+	log.Debugf("Rejected: status: %d, reason: %s, info: %s.", http.StatusForbidden, invalidScope, msg)
+
+	ctx.StateBag()[logfilter.AuthRejectReasonKey] = string(invalidScope)
+	rsp := &http.Response{
+		StatusCode:    http.StatusForbidden,
+		Header:        make(http.Header),
+		ContentLength: int64(len(msg)),
+		Body:          io.NopCloser(bytes.NewBufferString(msg)),
+	}
+
+	// https://tools.ietf.org/html/rfc6750#section-3
+	rsp.Header.Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(leaves, " ")))
+	rsp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.Serve(rsp)
+}
+
+func (f *jwtScopesFilter) Response(filters.FilterContext) {}
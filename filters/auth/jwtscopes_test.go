@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestParseScopeExpr(t *testing.T) {
+	for _, tt := range []struct {
+		expr     string
+		expected string
+		fail     bool
+	}{{
+		expr:     "read",
+		expected: "read",
+	}, {
+		expr:     "read AND write OR admin",
+		expected: "(read AND write) OR admin",
+	}, {
+		expr:     "read AND (write OR admin)",
+		expected: "read AND (write OR admin)",
+	}, {
+		expr:     "((read))",
+		expected: "read",
+	}, {
+		expr: "",
+		fail: true,
+	}, {
+		expr: "read AND",
+		fail: true,
+	}, {
+		expr: "read write",
+		fail: true,
+	}, {
+		expr: "(read OR write",
+		fail: true,
+	}, {
+		expr: "read)",
+		fail: true,
+	}} {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parseScopeExpr(tt.expr)
+			if tt.fail {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expr.String() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, expr.String())
+			}
+		})
+	}
+}
+
+func TestJwtScopes(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		stateBag map[string]interface{}
+		status   int
+		message  string
+	}{{
+		name:     "no token",
+		stateBag: map[string]interface{}{},
+		status:   http.StatusUnauthorized,
+	}, {
+		name: "jwt claims with all scopes",
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"scope": "read write"}},
+		},
+		status: http.StatusOK,
+	}, {
+		name: "jwt claims with scope list",
+		stateBag: map[string]interface{}{
+			oidcClaimsCacheKey: tokenContainer{Claims: map[string]interface{}{"scp": []interface{}{"read", "admin"}}},
+		},
+		status: http.StatusOK,
+	}, {
+		name: "introspection scopes, missing alternative",
+		stateBag: map[string]interface{}{
+			tokenintrospectionScopesKey: []string{"read"},
+		},
+		status:  http.StatusForbidden,
+		message: "missing scope: write OR admin",
+	}, {
+		name: "tokeninfo scopes, missing all",
+		stateBag: map[string]interface{}{
+			tokeninfoCacheKey: map[string]interface{}{"scope": []interface{}{"uid"}},
+		},
+		status:  http.StatusForbidden,
+		message: "missing scope: read, write OR admin",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewJwtScopes().CreateFilter([]interface{}{"read AND (write OR admin)"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FStateBag: tt.stateBag}
+			f.Request(ctx)
+
+			status := http.StatusOK
+			if ctx.FServed {
+				status = ctx.FResponse.StatusCode
+			}
+
+			if status != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, status)
+			}
+
+			if tt.message != "" {
+				b, err := io.ReadAll(ctx.FResponse.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(b) != tt.message {
+					t.Errorf("expected message %q, got %q", tt.message, string(b))
+				}
+			}
+		})
+	}
+}
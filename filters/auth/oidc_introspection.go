@@ -10,13 +10,14 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/auth/claims"
 )
 
 const (
 	// Deprecated, use filters.OidcClaimsQueryName instead
 	OidcClaimsQueryName = filters.OidcClaimsQueryName
 
-	oidcClaimsCacheKey = "oidcclaimscachekey"
+	oidcClaimsCacheKey = claims.StateBagKey
 )
 
 var gjsonModifierMutex = sync.RWMutex{}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/secrets"
+)
+
+const (
+	// ContentHashHeader is set by the signRequest filter to the hex
+	// encoded SHA256 hash of the request body, or to UnsignedPayload.
+	ContentHashHeader = "X-Content-Sha256"
+
+	// UnsignedPayload is used instead of the hash of the request body,
+	// when the body is not signed, because it is too large or its size is
+	// not known.
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// the bodies up to this size are buffered to be signed
+	signRequestMaxBodySize = 1 << 20
+)
+
+type (
+	signRequestSpec struct {
+		secretsReader secrets.SecretsReader
+	}
+
+	signRequestFilter struct {
+		secretName    string
+		header        string
+		hash          func() hash.Hash
+		signedHeaders []string
+		secretsReader secrets.SecretsReader
+	}
+)
+
+var signRequestAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// NewSignRequest creates a filter specification, whose instances sign the
+// requests sent to the backend with an HMAC, using a secret read from the
+// credentials paths. It expects the name of the secret, the name of the
+// header to set the hex encoded signature in, the hash algorithm (sha256,
+// sha384 or sha512), and optionally the names of the headers to sign.
+//
+//     * -> signRequest("backend-secret", "X-Signature", "sha256", "Date", "Content-Type") -> "https://backend.example.org"
+//
+// The signature is calculated over the lines of the method, the escaped
+// path, the raw query, the signed headers in the "name:value" format with
+// lower case names, and the hash of the body, which is also set in the
+// X-Content-Sha256 header. The bodies larger than 1MB, or with unknown
+// length are streamed without buffering, and UNSIGNED-PAYLOAD is used
+// instead of their hash. The filter should be the last one changing the
+// request in the filter chain.
+func NewSignRequest(sr secrets.SecretsReader) filters.Spec {
+	return &signRequestSpec{secretsReader: sr}
+}
+
+func (*signRequestSpec) Name() string { return filters.SignRequestName }
+
+func (s *signRequestSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	sargs, err := getStrings(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if sargs[0] == "" || sargs[1] == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	h, ok := signRequestAlgorithms[strings.ToLower(sargs[2])]
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &signRequestFilter{
+		secretName:    sargs[0],
+		header:        sargs[1],
+		hash:          h,
+		secretsReader: s.secretsReader,
+	}
+
+	for _, sh := range sargs[3:] {
+		f.signedHeaders = append(f.signedHeaders, http.CanonicalHeaderKey(sh))
+	}
+
+	return f, nil
+}
+
+// contentHash returns the hash of the body, or UnsignedPayload, when the
+// body is too large or its size is not known. The read body is replaced
+// in the request.
+func contentHash(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		h := sha256.Sum256(nil)
+		return hex.EncodeToString(h[:]), nil
+	}
+
+	if r.ContentLength < 0 || r.ContentLength > signRequestMaxBodySize {
+		return UnsignedPayload, nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, r.ContentLength))
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+func (f *signRequestFilter) canonicalRequest(r *http.Request, host, contentHash string) string {
+	lines := []string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery}
+	for _, h := range f.signedHeaders {
+		var v string
+		if h == "Host" {
+			v = host
+		} else {
+			v = strings.Join(r.Header.Values(h), ",")
+		}
+
+		lines = append(lines, strings.ToLower(h)+":"+strings.TrimSpace(v))
+	}
+
+	lines = append(lines, contentHash)
+	return strings.Join(lines, "\n")
+}
+
+func (f *signRequestFilter) Request(ctx filters.FilterContext) {
+	secret, ok := f.secretsReader.GetSecret(f.secretName)
+	if !ok {
+		log.Errorf("Failed to sign request, secret not found: %s", f.secretName)
+		return
+	}
+
+	r := ctx.Request()
+	ch, err := contentHash(r)
+	if err != nil {
+		log.Errorf("Failed to sign request, failed to read the body: %v", err)
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	mac := hmac.New(f.hash, secret)
+	mac.Write([]byte(f.canonicalRequest(r, ctx.OutgoingHost(), ch)))
+
+	r.Header.Set(ContentHashHeader, ch)
+	r.Header.Set(f.header, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (*signRequestFilter) Response(filters.FilterContext) {}
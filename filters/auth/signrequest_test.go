@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestSignRequest(t *testing.T) {
+	sr := &testSecretsReader{name: "backend-secret", secret: "s3cr3t"}
+	emptyHash := sha256.Sum256(nil)
+	bodyHash := sha256.Sum256([]byte("hello"))
+
+	for _, tt := range []struct {
+		name          string
+		secret        string
+		method        string
+		url           string
+		body          io.Reader
+		contentLength int64
+		canonical     string
+		contentHash   string
+	}{{
+		name:        "without body",
+		secret:      "backend-secret",
+		method:      "GET",
+		url:         "https://www.example.org/foo%2Fbar?baz=qux",
+		canonical:   "GET\n/foo%2Fbar\nbaz=qux\ncontent-type:text/plain\nhost:backend.example.org\n" + hex.EncodeToString(emptyHash[:]),
+		contentHash: hex.EncodeToString(emptyHash[:]),
+	}, {
+		name:          "with body",
+		secret:        "backend-secret",
+		method:        "POST",
+		url:           "https://www.example.org/foo",
+		body:          strings.NewReader("hello"),
+		contentLength: 5,
+		canonical:     "POST\n/foo\n\ncontent-type:text/plain\nhost:backend.example.org\n" + hex.EncodeToString(bodyHash[:]),
+		contentHash:   hex.EncodeToString(bodyHash[:]),
+	}, {
+		name:          "with body of unknown length",
+		secret:        "backend-secret",
+		method:        "POST",
+		url:           "https://www.example.org/foo",
+		body:          strings.NewReader("hello"),
+		contentLength: -1,
+		canonical:     "POST\n/foo\n\ncontent-type:text/plain\nhost:backend.example.org\n" + UnsignedPayload,
+		contentHash:   UnsignedPayload,
+	}, {
+		name:          "with large body",
+		secret:        "backend-secret",
+		method:        "PUT",
+		url:           "https://www.example.org/foo",
+		body:          bytes.NewReader(make([]byte, signRequestMaxBodySize+1)),
+		contentLength: signRequestMaxBodySize + 1,
+		canonical:     "PUT\n/foo\n\ncontent-type:text/plain\nhost:backend.example.org\n" + UnsignedPayload,
+		contentHash:   UnsignedPayload,
+	}, {
+		name:   "missing secret",
+		secret: "other-secret",
+		method: "GET",
+		url:    "https://www.example.org/foo",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewSignRequest(sr).CreateFilter([]interface{}{tt.secret, "X-Signature", "sha256", "content-type", "Host"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(tt.method, tt.url, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.ContentLength = tt.contentLength
+			req.Header.Set("Content-Type", "text/plain")
+
+			var body []byte
+			if tt.body != nil {
+				seeker := tt.body.(io.Seeker)
+				b, err := io.ReadAll(tt.body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body = b
+				seeker.Seek(0, io.SeekStart)
+			}
+
+			ctx := &filtertest.Context{FRequest: req, FOutgoingHost: "backend.example.org"}
+			f.Request(ctx)
+
+			if tt.canonical == "" {
+				if req.Header.Get("X-Signature") != "" {
+					t.Error("unexpected signature")
+				}
+
+				return
+			}
+
+			mac := hmac.New(sha256.New, []byte("s3cr3t"))
+			mac.Write([]byte(tt.canonical))
+			if s := req.Header.Get("X-Signature"); s != hex.EncodeToString(mac.Sum(nil)) {
+				t.Errorf("invalid signature: %s", s)
+			}
+
+			if h := req.Header.Get(ContentHashHeader); h != tt.contentHash {
+				t.Errorf("expected content hash %s, got %s", tt.contentHash, h)
+			}
+
+			if tt.body != nil {
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !bytes.Equal(b, body) {
+					t.Error("request body changed")
+				}
+			}
+		})
+	}
+}
+
+func TestSignRequestArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{"secret", "X-Signature"},
+		{"secret", "X-Signature", "md5"},
+		{"", "X-Signature", "sha256"},
+		{"secret", "X-Signature", "sha256", 42},
+	} {
+		if _, err := NewSignRequest(&testSecretsReader{}).CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
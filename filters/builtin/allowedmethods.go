@@ -0,0 +1,69 @@
+package builtin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	allowedMethodsSpec struct{}
+
+	allowedMethods struct {
+		methods map[string]bool
+		allow   string
+	}
+)
+
+// NewAllowedMethods creates a filter specification, whose instances
+// respond with 405 Method Not Allowed, when the method of the request
+// is not one of the listed methods, without calling the backend. The
+// Allow header of the response lists the allowed methods, in the order
+// of the arguments.
+//
+// Example:
+//
+//    allowedMethods("GET", "POST")
+func NewAllowedMethods() filters.Spec { return &allowedMethodsSpec{} }
+
+func (*allowedMethodsSpec) Name() string { return filters.AllowedMethodsName }
+
+func (*allowedMethodsSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &allowedMethods{methods: make(map[string]bool)}
+	var allow []string
+	for _, a := range args {
+		m, ok := a.(string)
+		if !ok || m == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		m = strings.ToUpper(m)
+		if f.methods[m] {
+			continue
+		}
+
+		f.methods[m] = true
+		allow = append(allow, m)
+	}
+
+	f.allow = strings.Join(allow, ", ")
+	return f, nil
+}
+
+func (f *allowedMethods) Request(ctx filters.FilterContext) {
+	if f.methods[ctx.Request().Method] {
+		return
+	}
+
+	ctx.Serve(&http.Response{
+		StatusCode: http.StatusMethodNotAllowed,
+		Header:     http.Header{"Allow": []string{f.allow}},
+	})
+}
+
+func (*allowedMethods) Response(filters.FilterContext) {}
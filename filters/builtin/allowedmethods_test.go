@@ -0,0 +1,84 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestAllowedMethodsArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{""}, err: true},
+		{args: []interface{}{"GET", 42.0}, err: true},
+		{args: []interface{}{"GET"}},
+		{args: []interface{}{"GET", "post"}},
+	} {
+		_, err := NewAllowedMethods().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestAllowedMethods(t *testing.T) {
+	for _, tc := range []struct {
+		msg           string
+		args          []interface{}
+		method        string
+		expectServed  bool
+		expectedAllow string
+	}{{
+		msg:    "allowed",
+		args:   []interface{}{"GET", "POST"},
+		method: "POST",
+	}, {
+		msg:    "allowed, case insensitive arguments",
+		args:   []interface{}{"get", "post"},
+		method: "GET",
+	}, {
+		msg:           "not allowed",
+		args:          []interface{}{"GET", "POST"},
+		method:        "DELETE",
+		expectServed:  true,
+		expectedAllow: "GET, POST",
+	}, {
+		msg:           "duplicate methods",
+		args:          []interface{}{"PUT", "GET", "put"},
+		method:        "HEAD",
+		expectServed:  true,
+		expectedAllow: "PUT, GET",
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewAllowedMethods().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FRequest: &http.Request{Method: tc.method}}
+			f.Request(ctx)
+			if ctx.FServed != tc.expectServed {
+				t.Fatalf("unexpected served state, got: %t, expected: %t", ctx.FServed, tc.expectServed)
+			}
+
+			if !tc.expectServed {
+				return
+			}
+
+			rsp := ctx.FResponse
+			if rsp.StatusCode != http.StatusMethodNotAllowed {
+				t.Errorf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, http.StatusMethodNotAllowed)
+			}
+
+			if allow := rsp.Header.Get("Allow"); allow != tc.expectedAllow {
+				t.Errorf("unexpected Allow header, got: %s, expected: %s", allow, tc.expectedAllow)
+			}
+		})
+	}
+}
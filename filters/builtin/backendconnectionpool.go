@@ -0,0 +1,52 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type backendConnectionPool struct {
+	pool filters.ConnectionPool
+}
+
+// NewBackendConnectionPool creates a filter specification, whose instances
+// set the connection pool size of the backend connections. It expects the
+// maximum number of the idle connections kept per backend host, and
+// optionally the maximum number of the connections per backend host,
+// where 0 means no limit:
+//
+//     * -> backendConnectionPool(256) -> "https://www.example.org"
+//     * -> backendConnectionPool(256, 1024) -> "https://www.example.org"
+//
+// The routes with the same settings share the connection pool.
+func NewBackendConnectionPool() filters.Spec { return &backendConnectionPool{} }
+
+func (*backendConnectionPool) Name() string { return filters.BackendConnectionPoolName }
+
+func (*backendConnectionPool) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	maxIdle, ok := args[0].(float64)
+	if !ok || maxIdle < 1 || maxIdle != float64(int(maxIdle)) {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &backendConnectionPool{pool: filters.ConnectionPool{MaxIdleConnsPerHost: int(maxIdle)}}
+	if len(args) == 2 {
+		maxConns, ok := args[1].(float64)
+		if !ok || maxConns < 0 || maxConns != float64(int(maxConns)) {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.pool.MaxConnsPerHost = int(maxConns)
+	}
+
+	return f, nil
+}
+
+func (f *backendConnectionPool) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendConnectionPool] = f.pool
+}
+
+func (*backendConnectionPool) Response(filters.FilterContext) {}
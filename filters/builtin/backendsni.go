@@ -0,0 +1,38 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type backendSNI struct {
+	serverName string
+}
+
+// NewBackendSNI creates a filter specification, whose instances set the
+// TLS server name sent in the SNI extension, and used to verify the
+// certificate of the backend, independently from the Host header of the
+// backend request.
+//
+//     * -> setRequestHeader("Host", "www.example.org") -> backendSNI("name.internal") -> "https://10.0.0.1"
+func NewBackendSNI() filters.Spec { return &backendSNI{} }
+
+func (*backendSNI) Name() string { return filters.BackendSNIName }
+
+func (*backendSNI) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	serverName, ok := args[0].(string)
+	if !ok || serverName == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &backendSNI{serverName: serverName}, nil
+}
+
+func (f *backendSNI) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendSNI] = f.serverName
+}
+
+func (*backendSNI) Response(filters.FilterContext) {}
@@ -0,0 +1,116 @@
+package builtin
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	backendTLSSpec struct{}
+
+	backendTLS struct {
+		config *filters.BackendTLSConfig
+	}
+)
+
+// NewBackendTLS creates a filter specification, whose instances set
+// dedicated TLS settings for the connections to the backend of the route.
+// It expects the path of a client certificate, the path of its key, and
+// the path of the CA certificate bundle used to verify the backend. Either
+// the client certificate and key, or the CA bundle may be empty.
+//
+//     * -> backendTLS("/certs/client.crt", "/certs/client.key", "/certs/ca.crt") -> "https://legacy.example.org"
+//
+// The TLS configuration is identified by the hash of the content of the
+// files, and the proxy shares the connections of the backends between the
+// filter instances with the same content. When the files change, the new
+// settings are used after the next route update.
+func NewBackendTLS() filters.Spec {
+	return &backendTLSSpec{}
+}
+
+func (*backendTLSSpec) Name() string { return filters.BackendTLSName }
+
+func readFiles(paths ...string) ([][]byte, error) {
+	content := make([][]byte, len(paths))
+	for i, p := range paths {
+		if p == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		content[i] = b
+	}
+
+	return content, nil
+}
+
+func (*backendTLSSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var paths []string
+	for _, a := range args {
+		p, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		paths = append(paths, p)
+	}
+
+	certFile, keyFile, caFile := paths[0], paths[1], paths[2]
+	if (certFile == "") != (keyFile == "") || certFile == "" && caFile == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	content, err := readFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	for _, c := range content {
+		h.Write(c)
+		h.Write([]byte{0})
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		cert, err := tls.X509KeyPair(content[0], content[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load X509 keypair from %s and %s: %w", certFile, keyFile, err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(content[2]) {
+			return nil, fmt.Errorf("no valid CA certificate found in %s", caFile)
+		}
+	}
+
+	return &backendTLS{config: &filters.BackendTLSConfig{
+		Key:    hex.EncodeToString(h.Sum(nil)),
+		Config: config,
+	}}, nil
+}
+
+func (f *backendTLS) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendTLS] = f.config
+}
+
+func (*backendTLS) Response(filters.FilterContext) {}
@@ -0,0 +1,69 @@
+package builtin
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestBackendTLSArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		{},
+		{"client.crt", "client.key"},
+		{"", "", ""},
+		{"client.crt", "", "ca.crt"},
+		{"", "", 42.0},
+		{"", "", "/does/not/exist.crt"},
+	} {
+		if _, err := NewBackendTLS().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for arguments: %v", args)
+		}
+	}
+}
+
+func TestBackendTLSConfigKey(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer s.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := func() *filters.BackendTLSConfig {
+		f, err := NewBackendTLS().CreateFilter([]interface{}{"", "", caFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		c, ok := ctx.FStateBag[filters.BackendTLS].(*filters.BackendTLSConfig)
+		if !ok || c.Config == nil || c.Key == "" {
+			t.Fatalf("failed to set the TLS configuration: %v", ctx.FStateBag[filters.BackendTLS])
+		}
+
+		return c
+	}
+
+	c1, c2 := config(), config()
+	if c1.Key != c2.Key {
+		t.Error("failed to identify the same TLS configuration")
+	}
+
+	// the same CA twice is a different content
+	if err := os.WriteFile(caFile, append(ca, ca...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if c3 := config(); c3.Key == c1.Key {
+		t.Error("failed to identify the changed TLS configuration")
+	}
+}
@@ -0,0 +1,97 @@
+package builtin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	bufferRequestBodySpec struct{}
+
+	bufferRequestBody struct {
+		limit int64
+	}
+)
+
+// NewBufferRequestBody creates a filter specification, whose instances
+// read the request body into memory, and forward it to the backend with
+// the Content-Length header set, e.g. for the backends not accepting
+// chunked requests. When the body is larger than the limit, the filter
+// responds with 413 Request Entity Too Large. The buffered body is
+// replayed when the backend request is retried.
+//
+// The limit can be set in bytes as a number, or as a string with one of
+// the k, m or g suffixes, e.g. "10m".
+func NewBufferRequestBody() filters.Spec { return &bufferRequestBodySpec{} }
+
+func (*bufferRequestBodySpec) Name() string { return filters.BufferRequestBodyName }
+
+func (*bufferRequestBodySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var limit int64
+	switch v := args[0].(type) {
+	case float64:
+		limit = int64(v)
+	case string:
+		l, err := parseSize(v)
+		if err != nil {
+			return nil, err
+		}
+
+		limit = l
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if limit <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &bufferRequestBody{limit: limit}, nil
+}
+
+func (f *bufferRequestBody) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if req.ContentLength > f.limit {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, f.limit+1))
+	req.Body.Close()
+	if err != nil {
+		log.Errorf("Failed to buffer the request body: %v", err)
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	if int64(len(body)) > f.limit {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	req.ContentLength = int64(len(body))
+	req.TransferEncoding = nil
+	req.Header.Del("Transfer-Encoding")
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
+func (*bufferRequestBody) Response(filters.FilterContext) {}
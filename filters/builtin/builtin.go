@@ -14,6 +14,9 @@ import (
 	"github.com/zalando/skipper/filters/diag"
 	"github.com/zalando/skipper/filters/fadein"
 	"github.com/zalando/skipper/filters/flowid"
+	"github.com/zalando/skipper/filters/grpc"
+	"github.com/zalando/skipper/filters/hedge"
+	"github.com/zalando/skipper/filters/jsonschema"
 	logfilter "github.com/zalando/skipper/filters/log"
 	"github.com/zalando/skipper/filters/rfc"
 	"github.com/zalando/skipper/filters/scheduler"
@@ -127,12 +130,17 @@ func MakeRegistry() filters.Registry {
 		NewSetResponseHeader(),
 		NewAppendResponseHeader(),
 		NewDropResponseHeader(),
+		NewStripResponseHeaders(),
+		NewSanitizeTrustedHeaders(),
+		NewNormalizeRequestHeaders(),
 		NewSetContextRequestHeader(),
 		NewAppendContextRequestHeader(),
 		NewSetContextResponseHeader(),
 		NewAppendContextResponseHeader(),
 		NewCopyRequestHeader(),
 		NewCopyResponseHeader(),
+		NewCopyRequestHeaderToResponse(),
+		NewSetUpgradeHeader(),
 		NewCopyRequestHeaderDeprecated(),
 		NewCopyResponseHeaderDeprecated(),
 		NewModPath(),
@@ -140,14 +148,22 @@ func MakeRegistry() filters.Registry {
 		NewModRequestHeader(),
 		NewDropQuery(),
 		NewSetQuery(),
+		NewModifyQuery(),
 		NewHealthCheck(),
 		NewStatic(),
+		NewServeFile(),
+		NewServeDirectory(),
 		NewRedirect(),
 		NewRedirectTo(),
 		NewRedirectLower(),
+		NewRewriteLocation(),
+		NewRedirectByAcceptLanguage(),
 		NewStripQuery(),
 		NewInlineContent(),
 		NewInlineContentIfStatus(),
+		NewCacheControlByStatus(),
+		NewResponseCache(),
+		NewIdempotency(),
 		flowid.New(),
 		xforward.New(),
 		xforward.NewFirst(),
@@ -159,6 +175,21 @@ func MakeRegistry() filters.Registry {
 		NewHeaderToQuery(),
 		NewQueryToHeader(),
 		NewBackendTimeout(),
+		NewRequestTimeout(),
+		NewBackendTLS(),
+		NewBackendSNI(),
+		NewBackendConnectionPool(),
+		NewH2CBackend(),
+		grpc.NewStatus(),
+		NewIdempotentRetries(),
+		hedge.NewHedge(),
+		NewFlushInterval(),
+		NewMaxRequestBodySize(),
+		NewBufferRequestBody(),
+		NewRequireRequestHeaders(),
+		NewAllowedMethods(),
+		jsonschema.NewValidateJSONSchema(),
+		NewDecompressRequest(),
 		NewSetDynamicBackendHostFromHeader(),
 		NewSetDynamicBackendSchemeFromHeader(),
 		NewSetDynamicBackendUrlFromHeader(),
@@ -177,27 +208,36 @@ func MakeRegistry() filters.Registry {
 		diag.NewAbsorb(),
 		diag.NewAbsorbSilent(),
 		diag.NewLogHeader(),
+		diag.NewTimingHeaders(),
 		diag.NewUniformRequestLatency(),
 		diag.NewNormalRequestLatency(),
 		diag.NewUniformResponseLatency(),
 		diag.NewNormalResponseLatency(),
+		diag.NewInjectLatency(),
+		diag.NewInjectFault(),
 		tee.NewTee(),
 		tee.NewTeeDeprecated(),
 		tee.NewTeeNoFollow(),
 		tee.NewTeeLoopback(),
+		tee.NewMirror(),
+		tee.NewTeeCompare(),
 		sed.New(),
 		sed.NewDelimited(),
 		sed.NewRequest(),
 		sed.NewDelimitedRequest(),
+		sed.NewRewriteResponseBody(),
+		NewRedactJSON(),
 		auth.NewBasicAuth(),
 		cookie.NewRequestCookie(),
 		cookie.NewResponseCookie(),
 		cookie.NewJSCookie(),
+		cookie.NewSetCookie(),
 		circuit.NewConsecutiveBreaker(),
 		circuit.NewRateBreaker(),
 		circuit.NewDisableBreaker(),
 		script.NewLuaScript(),
 		cors.NewOrigin(),
+		cors.NewCORS(),
 		logfilter.NewUnverifiedAuditLog(),
 		tracing.NewSpanName(),
 		tracing.NewBaggageToTagFilter(),
@@ -206,12 +246,22 @@ func MakeRegistry() filters.Registry {
 		accesslog.NewAccessLogDisabled(),
 		accesslog.NewDisableAccessLog(),
 		accesslog.NewEnableAccessLog(),
+		accesslog.NewLogStateBag(),
+		accesslog.NewAccessLogSampling(),
+		accesslog.NewAccessLogRedact(),
 		auth.NewForwardToken(),
 		auth.NewForwardTokenField(),
+		auth.NewJwtScopes(),
+		auth.NewJwtClaimsToHeaders(),
+		auth.NewRequireClientCertSubject(),
 		scheduler.NewLIFO(),
 		scheduler.NewLIFOGroup(),
+		scheduler.NewLIFOPriority(),
+		scheduler.NewFIFO(),
+		scheduler.NewFIFOGroup(),
 		rfc.NewPath(),
 		rfc.NewHost(),
+		rfc.NewNormalizePath(),
 		fadein.NewFadeIn(),
 		fadein.NewEndpointCreated(),
 		consistenthash.NewConsistentHashKey(),
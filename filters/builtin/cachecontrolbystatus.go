@@ -0,0 +1,90 @@
+package builtin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type cacheControlByStatus struct {
+	classes [6]string
+	codes   map[int]string
+}
+
+// NewCacheControlByStatus creates a filter spec for the cacheControlByStatus()
+// filter, that sets the Cache-Control response header depending on the
+// status code of the response.
+//
+//     * -> cacheControlByStatus("2xx=max-age=3600", "4xx=no-store", "404=max-age=60") -> "https://www.example.org"
+//
+// Each argument maps a status class, like 2xx, or an exact status code to
+// the value of the header. The exact status codes take precedence over the
+// classes. When no argument matches the response status, the header is left
+// untouched.
+func NewCacheControlByStatus() filters.Spec {
+	return &cacheControlByStatus{}
+}
+
+func (*cacheControlByStatus) Name() string { return filters.CacheControlByStatusName }
+
+func (*cacheControlByStatus) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &cacheControlByStatus{codes: make(map[int]string)}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		status, value := strings.ToLower(strings.TrimSpace(kv[0])), kv[1]
+		if len(status) != 3 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if strings.HasSuffix(status, "xx") {
+			class := int(status[0] - '0')
+			if class < 1 || class > 5 || f.classes[class] != "" {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			f.classes[class] = value
+			continue
+		}
+
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 599 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if _, exists := f.codes[code]; exists {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.codes[code] = value
+	}
+
+	return f, nil
+}
+
+func (*cacheControlByStatus) Request(filters.FilterContext) {}
+
+func (f *cacheControlByStatus) Response(ctx filters.FilterContext) {
+	status := ctx.Response().StatusCode
+	value, ok := f.codes[status]
+	if !ok && status >= 100 && status < 600 {
+		value = f.classes[status/100]
+	}
+
+	if value != "" {
+		ctx.Response().Header.Set("Cache-Control", value)
+	}
+}
@@ -0,0 +1,68 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestCacheControlByStatusArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{"2xx"}, err: true},
+		{args: []interface{}{"2xx="}, err: true},
+		{args: []interface{}{"6xx=no-store"}, err: true},
+		{args: []interface{}{"20x=no-store"}, err: true},
+		{args: []interface{}{"600=no-store"}, err: true},
+		{args: []interface{}{"2xx=no-store", "2xx=max-age=60"}, err: true},
+		{args: []interface{}{"404=no-store", "404=max-age=60"}, err: true},
+		{args: []interface{}{"2xx=max-age=3600"}},
+		{args: []interface{}{"2XX=max-age=3600", "4xx=no-store", "404=max-age=60"}},
+	} {
+		_, err := NewCacheControlByStatus().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestCacheControlByStatus(t *testing.T) {
+	f, err := NewCacheControlByStatus().CreateFilter([]interface{}{
+		"2xx=max-age=3600",
+		"4xx=no-store",
+		"404=max-age=60",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		status   int
+		header   string
+		expected string
+	}{
+		{status: 200, expected: "max-age=3600"},
+		{status: 204, header: "private", expected: "max-age=3600"},
+		{status: 404, expected: "max-age=60"},
+		{status: 403, header: "max-age=10", expected: "no-store"},
+		{status: 503, header: "max-age=10", expected: "max-age=10"},
+		{status: 302, expected: ""},
+	} {
+		rsp := &http.Response{StatusCode: tc.status, Header: make(http.Header)}
+		if tc.header != "" {
+			rsp.Header.Set("Cache-Control", tc.header)
+		}
+
+		f.Response(&filtertest.Context{FResponse: rsp})
+		if got := rsp.Header.Get("Cache-Control"); got != tc.expected {
+			t.Errorf("unexpected header for status %d: %q, expected: %q", tc.status, got, tc.expected)
+		}
+	}
+}
@@ -166,3 +166,62 @@ func Test_copyFilter_Response(t *testing.T) {
 		})
 	}
 }
+
+func Test_copyFilter_RequestToResponse(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		requestHeader  http.Header
+		responseHeader http.Header
+		expect         []string
+	}{{
+		name:          "copy request header to response",
+		requestHeader: http.Header{"X-Request-Id": []string{"foo"}},
+		expect:        []string{"foo"},
+	}, {
+		name:          "copy multiple values",
+		requestHeader: http.Header{"X-Request-Id": []string{"foo", "bar"}},
+		expect:        []string{"foo", "bar"},
+	}, {
+		name:           "replace existing response header",
+		requestHeader:  http.Header{"X-Request-Id": []string{"foo"}},
+		responseHeader: http.Header{"X-Request-Id": []string{"baz"}},
+		expect:         []string{"foo"},
+	}, {
+		name:           "missing request header",
+		requestHeader:  http.Header{},
+		responseHeader: http.Header{"X-Request-Id": []string{"baz"}},
+		expect:         []string{"baz"},
+	}, {
+		name:          "missing request header, no response header",
+		requestHeader: http.Header{},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewCopyRequestHeaderToResponse().CreateFilter([]interface{}{"X-Request-Id", "X-Request-Id"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			responseHeader := tt.responseHeader
+			if responseHeader == nil {
+				responseHeader = make(http.Header)
+			}
+
+			ctx := &filtertest.Context{
+				FRequest:  &http.Request{Header: tt.requestHeader},
+				FResponse: &http.Response{Header: responseHeader},
+			}
+
+			f.Response(ctx)
+			got := ctx.Response().Header.Values("X-Request-Id")
+			if len(got) != len(tt.expect) {
+				t.Fatalf("got %v, expected %v", got, tt.expect)
+			}
+
+			for i := range got {
+				if got[i] != tt.expect[i] {
+					t.Errorf("got %v, expected %v", got, tt.expect)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package builtin
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	decompressRequestSpec struct{}
+
+	decompressRequest struct{}
+
+	gzipRequestBody struct {
+		original io.ReadCloser
+		reader   *gzip.Reader
+	}
+)
+
+// NewDecompressRequest creates a filter specification, whose instances
+// decompress the gzip encoded request bodies, while streaming them to the
+// backend. The Content-Encoding header is removed, and the request is
+// sent with unknown content length. When the body is not valid gzip, the
+// filter, or, in case of errors detected while streaming, the proxy
+// responds with 400 Bad Request.
+func NewDecompressRequest() filters.Spec { return &decompressRequestSpec{} }
+
+func (*decompressRequestSpec) Name() string { return filters.DecompressRequestName }
+
+func (*decompressRequestSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &decompressRequest{}, nil
+}
+
+func (*decompressRequest) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if !strings.EqualFold(strings.TrimSpace(req.Header.Get("Content-Encoding")), "gzip") {
+		return
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	r, err := gzip.NewReader(req.Body)
+	if err != nil {
+		ctx.Serve(&http.Response{StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	req.Body = &gzipRequestBody{original: req.Body, reader: r}
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+}
+
+func (*decompressRequest) Response(filters.FilterContext) {}
+
+func (b *gzipRequestBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if err != nil && err != io.EOF {
+		return n, fmt.Errorf("%w: %v", filters.ErrInvalidRequestBody, err)
+	}
+
+	return n, err
+}
+
+func (b *gzipRequestBody) Close() error {
+	b.reader.Close()
+	return b.original.Close()
+}
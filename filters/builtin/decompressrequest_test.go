@@ -0,0 +1,128 @@
+package builtin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func gzipBytes(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompressRequest(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+		w.Write(b)
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewDecompressRequest())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.DecompressRequestName}},
+		Backend: backend.URL,
+	})
+	defer pr.Close()
+
+	content := "Hello, world!"
+	compressed := gzipBytes(t, content)
+	corrupt := append([]byte{}, compressed...)
+	corrupt[len(corrupt)-10] ^= 0xff
+
+	for _, tc := range []struct {
+		msg             string
+		body            []byte
+		encoding        string
+		expectedCode    int
+		expectedContent string
+	}{{
+		msg:             "not encoded",
+		body:            []byte(content),
+		expectedCode:    http.StatusOK,
+		expectedContent: content,
+	}, {
+		msg:             "gzip",
+		body:            compressed,
+		encoding:        "gzip",
+		expectedCode:    http.StatusOK,
+		expectedContent: content,
+	}, {
+		msg:             "other encoding",
+		body:            []byte(content),
+		encoding:        "br",
+		expectedCode:    http.StatusOK,
+		expectedContent: content,
+	}, {
+		msg:          "invalid gzip header",
+		body:         []byte(content),
+		encoding:     "gzip",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		msg:          "corrupt gzip stream",
+		body:         corrupt,
+		encoding:     "gzip",
+		expectedCode: http.StatusBadRequest,
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			req, err := http.NewRequest("POST", pr.URL, bytes.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.encoding != "" {
+				req.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != tc.expectedCode {
+				t.Fatalf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, tc.expectedCode)
+			}
+
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != tc.expectedContent {
+				t.Errorf("unexpected content, got: %s, expected: %s", b, tc.expectedContent)
+			}
+
+			if tc.encoding == "gzip" && rsp.Header.Get("X-Content-Encoding") != "" {
+				t.Error("failed to remove the content encoding")
+			}
+		})
+	}
+}
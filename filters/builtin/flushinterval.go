@@ -0,0 +1,124 @@
+package builtin
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/routing"
+)
+
+type flushInterval struct {
+	interval time.Duration
+}
+
+// FlushIntervalPostProcessor rejects the routes that combine the
+// flushInterval filter with a filter that reads the whole response body
+// before passing it on. The flushing would have no effect on these routes,
+// and the streamed responses, e.g. server-sent events, would never reach
+// the client.
+type FlushIntervalPostProcessor struct{}
+
+// the filters that buffer the response body
+var bufferingResponseFilters = map[string]bool{
+	filters.ResponseCacheName: true,
+	filters.IdempotencyName:   true,
+	filters.RedactJSONName:    true,
+}
+
+// NewFlushInterval creates a filter specification, whose instances set the
+// interval of flushing the response body to the client, for the route.
+// By default, the proxy flushes after every write, which is the same as
+// setting a negative interval. A positive interval batches the writes,
+// flushing at most once per interval.
+//
+// The interval can be set as a duration string, or -1 for immediate
+// flushing.
+func NewFlushInterval() filters.Spec {
+	return &flushInterval{}
+}
+
+func (*flushInterval) Name() string { return filters.FlushIntervalName }
+
+func (*flushInterval) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var f flushInterval
+	switch v := args[0].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+
+		f.interval = d
+	case time.Duration:
+		f.interval = v
+	case float64:
+		if v != -1 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.interval = -1
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if f.interval <= 0 {
+		f.interval = -1
+	}
+
+	return &f, nil
+}
+
+func (f *flushInterval) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.ResponseFlushInterval] = f.interval
+}
+
+func (*flushInterval) Response(filters.FilterContext) {}
+
+// NewFlushIntervalPostProcessor creates the post-processor that validates
+// the routes with the flushInterval filter.
+func NewFlushIntervalPostProcessor() FlushIntervalPostProcessor {
+	return FlushIntervalPostProcessor{}
+}
+
+// Do implements routing.PostProcessor, and drops the routes that use the
+// flushInterval filter together with a buffering response filter.
+func (FlushIntervalPostProcessor) Do(routes []*routing.Route) []*routing.Route {
+	var result []*routing.Route
+	for _, r := range routes {
+		if err := checkFlushInterval(r); err != nil {
+			log.Errorf("failed to post-process route %s: %v", r.Id, err)
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	return result
+}
+
+func checkFlushInterval(r *routing.Route) error {
+	var flush bool
+	var buffering string
+	for _, f := range r.Filters {
+		switch {
+		case f.Name == filters.FlushIntervalName:
+			flush = true
+		case bufferingResponseFilters[f.Name] && buffering == "":
+			buffering = f.Name
+		}
+	}
+
+	if flush && buffering != "" {
+		return fmt.Errorf("%s cannot be used together with %s, because it buffers the response body", filters.FlushIntervalName, buffering)
+	}
+
+	return nil
+}
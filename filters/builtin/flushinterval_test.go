@@ -0,0 +1,75 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/routing"
+)
+
+func TestFlushInterval(t *testing.T) {
+	for _, tc := range []struct {
+		args     []interface{}
+		interval time.Duration
+		err      bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{"100ms", "1s"}, err: true},
+		{args: []interface{}{"fast"}, err: true},
+		{args: []interface{}{100.0}, err: true},
+		{args: []interface{}{"100ms"}, interval: 100 * time.Millisecond},
+		{args: []interface{}{time.Second}, interval: time.Second},
+		{args: []interface{}{-1.0}, interval: -1},
+		{args: []interface{}{"0s"}, interval: -1},
+	} {
+		f, err := NewFlushInterval().CreateFilter(tc.args)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected error for arguments: %v", tc.args)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+			continue
+		}
+
+		ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		if i := ctx.FStateBag[filters.ResponseFlushInterval]; i != tc.interval {
+			t.Errorf("unexpected interval for arguments: %v, got: %v, expected: %v", tc.args, i, tc.interval)
+		}
+	}
+}
+
+func TestFlushIntervalPostProcessor(t *testing.T) {
+	route := func(id string, names ...string) *routing.Route {
+		r := &routing.Route{Route: eskip.Route{Id: id}}
+		for _, n := range names {
+			r.Filters = append(r.Filters, &routing.RouteFilter{Name: n})
+		}
+
+		return r
+	}
+
+	routes := NewFlushIntervalPostProcessor().Do([]*routing.Route{
+		route("flush", filters.FlushIntervalName, filters.SetResponseHeaderName),
+		route("cache", filters.ResponseCacheName),
+		route("flushCache", filters.FlushIntervalName, filters.ResponseCacheName),
+		route("redactFlush", filters.RedactJSONName, filters.FlushIntervalName),
+	})
+
+	var ids []string
+	for _, r := range routes {
+		ids = append(ids, r.Id)
+	}
+
+	if len(ids) != 2 || ids[0] != "flush" || ids[1] != "cache" {
+		t.Errorf("unexpected routes after post-processing: %v", ids)
+	}
+}
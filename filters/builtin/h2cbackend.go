@@ -0,0 +1,31 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type h2cBackend struct{}
+
+// NewH2CBackend creates a filter specification, whose instances make the
+// proxy connect to the backend of the route with HTTP/2 over cleartext
+// connections (h2c), with prior knowledge, e.g. to proxy gRPC services
+// without TLS. It has no effect for backends with https scheme.
+//
+//     * -> h2cBackend() -> "http://grpc.internal:8080"
+func NewH2CBackend() filters.Spec { return h2cBackend{} }
+
+func (h2cBackend) Name() string { return filters.H2CBackendName }
+
+func (h2cBackend) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return h2cBackend{}, nil
+}
+
+func (h2cBackend) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.BackendH2C] = true
+}
+
+func (h2cBackend) Response(filters.FilterContext) {}
@@ -555,3 +555,53 @@ func BenchmarkCopyRequestHeader(b *testing.B) {
 		f.Request(fc)
 	}
 }
+
+func TestSetUpgradeHeader(t *testing.T) {
+	for _, key := range []string{"Connection", "upgrade", "Sec-WebSocket-Key", "sec-websocket-protocol"} {
+		if _, err := NewSetUpgradeHeader().CreateFilter([]interface{}{key, "foo"}); err == nil {
+			t.Errorf("failed to fail for handshake header: %s", key)
+		}
+	}
+
+	f, err := NewSetUpgradeHeader().CreateFilter([]interface{}{"Authorization", "Bearer foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ti := range []struct {
+		msg      string
+		header   http.Header
+		expected string
+	}{{
+		msg:    "not an upgrade request",
+		header: http.Header{},
+	}, {
+		msg: "upgrade only as part of another token",
+		header: http.Header{
+			"Connection": []string{"keep-alive, X-No-Upgrade"},
+			"Upgrade":    []string{"websocket"},
+		},
+	}, {
+		msg: "websocket upgrade",
+		header: http.Header{
+			"Connection":        []string{"keep-alive, Upgrade"},
+			"Upgrade":           []string{"websocket"},
+			"Sec-Websocket-Key": []string{"dGhlIHNhbXBsZSBub25jZQ=="},
+		},
+		expected: "Bearer foo",
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			req := &http.Request{Header: ti.header}
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+
+			if v := req.Header.Get("Authorization"); v != ti.expected {
+				t.Errorf("unexpected header value: %q, expected: %q", v, ti.expected)
+			}
+
+			if ti.expected != "" && req.Header.Get("Sec-Websocket-Key") != "dGhlIHNhbXBsZSBub25jZQ==" {
+				t.Error("handshake header changed")
+			}
+		})
+	}
+}
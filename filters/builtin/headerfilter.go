@@ -2,6 +2,7 @@ package builtin
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/zalando/skipper/eskip"
@@ -23,6 +24,8 @@ const (
 	appendContextResponseHeader
 	copyRequestHeader
 	copyResponseHeader
+	copyRequestHeaderToResponse
+	setUpgradeHeader
 	copyRequestHeaderDeprecated
 	copyResponseHeaderDeprecated
 
@@ -61,6 +64,10 @@ func headerFilterConfig(typ headerType, config []interface{}) (string, string, *
 		return "", "", nil, filters.ErrInvalidFilterParameters
 	}
 
+	if typ == setUpgradeHeader && isUpgradeHandshakeHeader(key) {
+		return "", "", nil, filters.ErrInvalidFilterParameters
+	}
+
 	var value string
 	if len(config) == 2 {
 		value, ok = config[1].(string)
@@ -71,7 +78,8 @@ func headerFilterConfig(typ headerType, config []interface{}) (string, string, *
 
 	switch typ {
 	case setRequestHeader, appendRequestHeader,
-		setResponseHeader, appendResponseHeader:
+		setResponseHeader, appendResponseHeader,
+		setUpgradeHeader:
 		return key, "", eskip.NewTemplate(value), nil
 	default:
 		return key, value, nil, nil
@@ -184,6 +192,26 @@ func NewCopyResponseHeader() filters.Spec {
 	return &headerFilter{typ: copyResponseHeader}
 }
 
+// NewCopyRequestHeaderToResponse creates a filter specification whose
+// instances copy the values of a specified request header to a defined
+// response header. When the request doesn't have the source header, the
+// response is not changed.
+func NewCopyRequestHeaderToResponse() filters.Spec {
+	return &headerFilter{typ: copyRequestHeaderToResponse}
+}
+
+// NewSetUpgradeHeader returns a filter specification that is used to set
+// headers for the protocol upgrade requests, e.g. WebSocket handshakes,
+// before they are sent to the backend. Requests that are not upgrade
+// requests are not changed. Instances expect two parameters: the header
+// name and the header value template, see eskip.Template.ApplyContext.
+// The headers of the handshake itself, Connection, Upgrade and
+// Sec-WebSocket-*, cannot be set.
+// Name: "setUpgradeHeader".
+func NewSetUpgradeHeader() filters.Spec {
+	return &headerFilter{typ: setUpgradeHeader}
+}
+
 func NewCopyRequestHeaderDeprecated() filters.Spec {
 	return &headerFilter{typ: copyRequestHeaderDeprecated}
 }
@@ -222,6 +250,10 @@ func (spec *headerFilter) Name() string {
 		return filters.CopyRequestHeaderName
 	case copyResponseHeader:
 		return filters.CopyResponseHeaderName
+	case copyRequestHeaderToResponse:
+		return filters.CopyRequestHeaderToResponseName
+	case setUpgradeHeader:
+		return filters.SetUpgradeHeaderName
 	case copyRequestHeaderDeprecated:
 		return copyRequestHeaderDeprecatedName
 	case copyResponseHeaderDeprecated:
@@ -237,6 +269,27 @@ func (spec *headerFilter) CreateFilter(config []interface{}) (filters.Filter, er
 	return &headerFilter{typ: spec.typ, key: key, value: value, template: template}, err
 }
 
+// isUpgradeHandshakeHeader returns true for the headers negotiating the
+// protocol upgrade, which are passed on to the backend unchanged.
+func isUpgradeHandshakeHeader(key string) bool {
+	key = strings.ToLower(key)
+	return key == "connection" || key == "upgrade" || strings.HasPrefix(key, "sec-websocket-")
+}
+
+// isUpgradeRequest returns true when any of the comma separated values
+// of the Connection header is the upgrade token, case-insensitive.
+func isUpgradeRequest(r *http.Request) bool {
+	for _, h := range r.Header.Values("Connection") {
+		for _, t := range strings.Split(h, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), "upgrade") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func valueFromContext(
 	ctx filters.FilterContext,
 	headerName,
@@ -286,6 +339,18 @@ func (f *headerFilter) Request(ctx filters.FilterContext) {
 		valueFromContext(ctx, f.key, f.value, true, header.Set)
 	case appendContextRequestHeader:
 		valueFromContext(ctx, f.key, f.value, true, header.Add)
+	case setUpgradeHeader:
+		if !isUpgradeRequest(ctx.Request()) {
+			return
+		}
+
+		value, ok := f.template.ApplyContext(ctx)
+		if ok {
+			header.Set(f.key, value)
+			if strings.ToLower(f.key) == "host" {
+				ctx.SetOutgoingHost(value)
+			}
+		}
 	case copyRequestHeader, copyRequestHeaderDeprecated:
 		headerValue := header.Get(f.key)
 		if headerValue != "" {
@@ -323,5 +388,13 @@ func (f *headerFilter) Response(ctx filters.FilterContext) {
 		if headerValue != "" {
 			header.Set(f.value, headerValue)
 		}
+	case copyRequestHeaderToResponse:
+		values := ctx.Request().Header.Values(f.key)
+		if len(values) > 0 {
+			header.Del(f.value)
+			for _, v := range values {
+				header.Add(f.value, v)
+			}
+		}
 	}
 }
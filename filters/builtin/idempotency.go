@@ -0,0 +1,269 @@
+package builtin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	idempotencyStateKey = "filter." + filters.IdempotencyName
+
+	// IdempotencyKeyHeader is the request header, that identifies the
+	// repeated requests.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on the replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// the requests in progress are locked for this duration, after
+	// which the repeated requests are forwarded to the backend
+	idempotencyLockTimeout = time.Minute
+
+	// larger responses are not stored
+	idempotencyMaxBodySize = 1 << 20
+)
+
+type (
+	// IdempotentResponse is a response stored for an idempotency key.
+	IdempotentResponse struct {
+		StatusCode int
+		Header     http.Header
+		Body       []byte
+	}
+
+	// IdempotencyStore stores the responses of the requests with
+	// idempotency keys. Implementations need to be safe for concurrent
+	// use.
+	IdempotencyStore interface {
+		// Lock returns the stored response of the key, when it exists.
+		// Otherwise it locks the key for the duration of the timeout,
+		// and returns true, when the key was not locked by another
+		// request.
+		Lock(key string, timeout time.Duration) (*IdempotentResponse, bool, error)
+
+		// Store stores the response of the key for the duration of the
+		// TTL, and releases the lock.
+		Store(key string, rsp *IdempotentResponse, ttl time.Duration) error
+
+		// Unlock releases the lock of the key without storing a
+		// response.
+		Unlock(key string) error
+	}
+
+	idempotencySpec struct {
+		store IdempotencyStore
+	}
+
+	idempotency struct {
+		ttl   time.Duration
+		store IdempotencyStore
+	}
+
+	idempotencyEntry struct {
+		response *IdempotentResponse
+		expires  time.Time
+	}
+
+	memoryIdempotencyStore struct {
+		mx         sync.Mutex
+		entries    map[string]*idempotencyEntry
+		purgeAfter int
+	}
+)
+
+// NewIdempotency creates a filter specification, whose instances replay
+// the stored response for the repeated requests with the same
+// Idempotency-Key header, without contacting the backend. It expects the
+// TTL of the stored responses as argument. The responses are stored in
+// memory, shared by the filter instances.
+//
+//     * -> idempotency("24h") -> "https://payments.example.org"
+//
+// The requests are identified by the method, the host, the path and the
+// idempotency key. While the first request is in progress, the repeated
+// requests are rejected with 409 Conflict. Server error responses, and
+// the responses larger than 1MB are not stored, and neither are the
+// failed backend requests. Requests without the
+// Idempotency-Key header are not affected.
+func NewIdempotency() filters.Spec {
+	return NewIdempotencyWithStore(newMemoryIdempotencyStore())
+}
+
+// NewIdempotencyWithStore creates a filter specification like
+// NewIdempotency, but it stores the responses in the provided store,
+// e.g. in an external database shared by multiple instances.
+func NewIdempotencyWithStore(s IdempotencyStore) filters.Spec {
+	return &idempotencySpec{store: s}
+}
+
+func (*idempotencySpec) Name() string { return filters.IdempotencyName }
+
+func (s *idempotencySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	ttl, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &idempotency{ttl: d, store: s.store}, nil
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		entries:    make(map[string]*idempotencyEntry),
+		purgeAfter: 1024,
+	}
+}
+
+// removes the expired entries, when the number of entries doubled since
+// the last purge
+func (s *memoryIdempotencyStore) purgeExpired(now time.Time) {
+	if len(s.entries) < s.purgeAfter {
+		return
+	}
+
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.purgeAfter = 2 * len(s.entries)
+	if s.purgeAfter < 1024 {
+		s.purgeAfter = 1024
+	}
+}
+
+func (s *memoryIdempotencyStore) Lock(key string, timeout time.Duration) (*IdempotentResponse, bool, error) {
+	now := time.Now()
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.purgeExpired(now)
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return e.response, false, nil
+	}
+
+	s.entries[key] = &idempotencyEntry{expires: now.Add(timeout)}
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Store(key string, rsp *IdempotentResponse, ttl time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.entries[key] = &idempotencyEntry{response: rsp, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Unlock(key string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.response == nil {
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+func idempotencyKey(r *http.Request, key string) string {
+	return strings.Join([]string{r.Method, r.Host, r.URL.Path, key}, " ")
+}
+
+func (f *idempotency) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	k := req.Header.Get(IdempotencyKeyHeader)
+	if k == "" {
+		return
+	}
+
+	key := idempotencyKey(req, k)
+	rsp, locked, err := f.store.Lock(key, idempotencyLockTimeout)
+	if err != nil {
+		log.Errorf("Failed to lock idempotency key: %v", err)
+		return
+	}
+
+	if rsp != nil {
+		h := rsp.Header.Clone()
+		h.Set(IdempotentReplayedHeader, "true")
+		ctx.Serve(&http.Response{
+			StatusCode:    rsp.StatusCode,
+			Header:        h,
+			ContentLength: int64(len(rsp.Body)),
+			Body:          io.NopCloser(bytes.NewReader(rsp.Body)),
+		})
+
+		return
+	}
+
+	if !locked {
+		ctx.Serve(&http.Response{StatusCode: http.StatusConflict})
+		return
+	}
+
+	ctx.StateBag()[idempotencyStateKey] = key
+	ctx.StateBag()[filters.IdempotencyUnlock] = func() { f.unlock(key) }
+}
+
+func (f *idempotency) unlock(key string) {
+	if err := f.store.Unlock(key); err != nil {
+		log.Errorf("Failed to unlock idempotency key: %v", err)
+	}
+}
+
+func (f *idempotency) Response(ctx filters.FilterContext) {
+	key, ok := ctx.StateBag()[idempotencyStateKey].(string)
+	if !ok {
+		return
+	}
+
+	delete(ctx.StateBag(), filters.IdempotencyUnlock)
+
+	rsp := ctx.Response()
+	if rsp.StatusCode >= http.StatusInternalServerError || rsp.ContentLength > idempotencyMaxBodySize {
+		f.unlock(key)
+		return
+	}
+
+	var body []byte
+	if rsp.Body != nil {
+		var buf bytes.Buffer
+		n, err := io.Copy(&buf, io.LimitReader(rsp.Body, idempotencyMaxBodySize+1))
+		if err != nil || n > idempotencyMaxBodySize {
+			f.unlock(key)
+			rsp.Body = &bufferedBody{Reader: io.MultiReader(&buf, rsp.Body), closer: rsp.Body}
+			return
+		}
+
+		rsp.Body.Close()
+		body = buf.Bytes()
+		rsp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := f.store.Store(key, &IdempotentResponse{
+		StatusCode: rsp.StatusCode,
+		Header:     rsp.Header.Clone(),
+		Body:       body,
+	}, f.ttl); err != nil {
+		log.Errorf("Failed to store idempotent response: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestIdempotency(t *testing.T) {
+	f, err := NewIdempotency().CreateFilter([]interface{}{"1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(key string) *filtertest.Context {
+		req, err := http.NewRequest("POST", "https://payments.example.org/charges", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		ctx := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		return ctx
+	}
+
+	respond := func(ctx *filtertest.Context, status int, body string) {
+		ctx.FResponse = &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"X-Charge": []string{body}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+
+		f.Response(ctx)
+	}
+
+	first := request("key-1")
+	if first.FServed {
+		t.Fatal("first request served")
+	}
+
+	if concurrent := request("key-1"); !concurrent.FServed || concurrent.FResponse.StatusCode != http.StatusConflict {
+		t.Fatal("concurrent request not rejected")
+	}
+
+	respond(first, http.StatusCreated, "charge-1")
+	if b, err := io.ReadAll(first.FResponse.Body); err != nil || string(b) != "charge-1" {
+		t.Fatalf("invalid body of the first response: %s, %v", b, err)
+	}
+
+	replay := request("key-1")
+	if !replay.FServed || replay.FResponse.StatusCode != http.StatusCreated {
+		t.Fatal("failed to replay response")
+	}
+
+	if replay.FResponse.Header.Get(IdempotentReplayedHeader) != "true" || replay.FResponse.Header.Get("X-Charge") != "charge-1" {
+		t.Errorf("invalid replayed headers: %v", replay.FResponse.Header)
+	}
+
+	if b, err := io.ReadAll(replay.FResponse.Body); err != nil || string(b) != "charge-1" {
+		t.Errorf("invalid replayed body: %s, %v", b, err)
+	}
+
+	if other := request("key-2"); other.FServed {
+		t.Error("request with other key served")
+	}
+
+	if nokey := request(""); nokey.FServed {
+		t.Error("request without key served")
+	}
+
+	failed := request("key-3")
+	respond(failed, http.StatusBadGateway, "")
+	if retry := request("key-3"); retry.FServed {
+		t.Error("request after server error served")
+	}
+}
+
+func TestIdempotencyArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{"foo"},
+		{"-1h"},
+		{3600.0},
+		{"1h", "2h"},
+	} {
+		if _, err := NewIdempotency().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	maxRequestBodySizeSpec struct{}
+
+	maxRequestBodySize struct {
+		limit int64
+	}
+
+	limitedBody struct {
+		body      io.ReadCloser
+		remaining int64
+	}
+)
+
+// NewMaxRequestBodySize creates a filter specification, whose instances
+// limit the size of the request body. When the request declares a larger
+// Content-Length than the limit, the filter responds with 413 Request
+// Entity Too Large, without reading the body. Otherwise, the body is
+// streamed to the backend, and when it exceeds the limit, e.g. in case of
+// chunked requests, the proxy responds with 413.
+//
+// The limit can be set in bytes as a number, or as a string with one of
+// the k, m or g suffixes, e.g. "10m".
+func NewMaxRequestBodySize() filters.Spec { return &maxRequestBodySizeSpec{} }
+
+func (*maxRequestBodySizeSpec) Name() string { return filters.MaxRequestBodySizeName }
+
+func (*maxRequestBodySizeSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var limit int64
+	switch v := args[0].(type) {
+	case float64:
+		limit = int64(v)
+	case string:
+		l, err := parseSize(v)
+		if err != nil {
+			return nil, err
+		}
+
+		limit = l
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if limit < 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &maxRequestBodySize{limit: limit}, nil
+}
+
+// parseSize parses a size in bytes, optionally suffixed with k, m or g.
+func parseSize(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "m"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "g"):
+		multiplier = 1 << 30
+	}
+
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	return n * multiplier, nil
+}
+
+func (f *maxRequestBodySize) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if req.ContentLength > f.limit {
+		ctx.Serve(&http.Response{StatusCode: http.StatusRequestEntityTooLarge})
+		return
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &limitedBody{body: req.Body, remaining: f.limit}
+	}
+}
+
+func (*maxRequestBodySize) Response(filters.FilterContext) {}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, filters.ErrRequestBodyTooLarge
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), filters.ErrRequestBodyTooLarge
+	}
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
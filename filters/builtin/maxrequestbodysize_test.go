@@ -0,0 +1,112 @@
+package builtin
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestMaxRequestBodySizeArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args  []interface{}
+		limit int64
+		err   bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{"10m", "1k"}, err: true},
+		{args: []interface{}{"10x"}, err: true},
+		{args: []interface{}{-1.0}, err: true},
+		{args: []interface{}{1024.0}, limit: 1024},
+		{args: []interface{}{"512"}, limit: 512},
+		{args: []interface{}{"2k"}, limit: 2 << 10},
+		{args: []interface{}{"10M"}, limit: 10 << 20},
+		{args: []interface{}{"1g"}, limit: 1 << 30},
+	} {
+		f, err := NewMaxRequestBodySize().CreateFilter(tc.args)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected error for arguments: %v", tc.args)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+			continue
+		}
+
+		if l := f.(*maxRequestBodySize).limit; l != tc.limit {
+			t.Errorf("unexpected limit for arguments: %v, got: %d, expected: %d", tc.args, l, tc.limit)
+		}
+	}
+}
+
+func TestMaxRequestBodySize(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer backend.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewMaxRequestBodySize())
+	pr := proxytest.New(fr, &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.MaxRequestBodySizeName, Args: []interface{}{"1k"}}},
+		Backend: backend.URL,
+	})
+	defer pr.Close()
+
+	for _, tc := range []struct {
+		msg          string
+		body         io.Reader
+		expectedCode int
+	}{{
+		msg:          "no body",
+		expectedCode: http.StatusOK,
+	}, {
+		msg:          "content length within the limit",
+		body:         bytes.NewBufferString(strings.Repeat("x", 1024)),
+		expectedCode: http.StatusOK,
+	}, {
+		msg:          "content length exceeding the limit",
+		body:         bytes.NewBufferString(strings.Repeat("x", 1025)),
+		expectedCode: http.StatusRequestEntityTooLarge,
+	}, {
+		msg:          "chunked within the limit",
+		body:         io.MultiReader(strings.NewReader(strings.Repeat("x", 1024))),
+		expectedCode: http.StatusOK,
+	}, {
+		msg:          "chunked exceeding the limit",
+		body:         io.MultiReader(strings.NewReader(strings.Repeat("x", 4096))),
+		expectedCode: http.StatusRequestEntityTooLarge,
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			req, err := http.NewRequest("POST", pr.URL, tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Close = true
+
+			rsp, err := (&http.Client{}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+
+			if rsp.StatusCode != tc.expectedCode {
+				t.Errorf("status code doesn't match, got: %d, expected: %d", rsp.StatusCode, tc.expectedCode)
+			}
+		})
+	}
+}
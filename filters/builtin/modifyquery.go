@@ -0,0 +1,148 @@
+package builtin
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	modifyQueryOperation int
+
+	modifyQuerySpec struct{}
+
+	modifyQuery struct {
+		operation modifyQueryOperation
+		name      string
+		arg       string
+	}
+)
+
+const (
+	renameQuery modifyQueryOperation = iota
+	dropQueryParam
+	addQuery
+	setDefaultQuery
+)
+
+var modifyQueryOperations = map[string]modifyQueryOperation{
+	"rename":     renameQuery,
+	"drop":       dropQueryParam,
+	"add":        addQuery,
+	"setDefault": setDefaultQuery,
+}
+
+// NewModifyQuery returns a new filter Spec, whose instances modify the
+// query parameters of the request. Instances expect the operation and
+// its arguments:
+//
+//     modifyQuery("rename", "q", "query")
+//     modifyQuery("drop", "legacy")
+//     modifyQuery("add", "tag", "new")
+//     modifyQuery("setDefault", "page", "1")
+//
+// The rename and drop operations apply to all the values of the
+// parameter. The add operation appends a value, and setDefault sets a
+// value only when the parameter is missing. The order and the encoding of
+// the other parameters are preserved.
+//
+// Name: "modifyQuery".
+func NewModifyQuery() filters.Spec { return modifyQuerySpec{} }
+
+func (modifyQuerySpec) Name() string { return filters.ModifyQueryName }
+
+func (modifyQuerySpec) CreateFilter(config []interface{}) (filters.Filter, error) {
+	if len(config) < 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	args := make([]string, len(config))
+	for i, c := range config {
+		s, ok := c.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		args[i] = s
+	}
+
+	op, ok := modifyQueryOperations[args[0]]
+	if !ok || args[1] == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &modifyQuery{operation: op, name: args[1]}
+	switch op {
+	case dropQueryParam:
+		if len(args) != 2 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	case renameQuery:
+		if len(args) != 3 || args[2] == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.arg = args[2]
+	default:
+		if len(args) != 3 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.arg = args[2]
+	}
+
+	return f, nil
+}
+
+// returns the decoded name of a raw query parameter, and the raw value
+// including the separator
+func splitQueryParam(p string) (string, string) {
+	rawName, rawValue := p, ""
+	if i := strings.Index(p, "="); i >= 0 {
+		rawName, rawValue = p[:i], p[i:]
+	}
+
+	name, err := url.QueryUnescape(rawName)
+	if err != nil {
+		name = rawName
+	}
+
+	return name, rawValue
+}
+
+func (f *modifyQuery) Request(ctx filters.FilterContext) {
+	u := ctx.Request().URL
+
+	var params []string
+	if u.RawQuery != "" {
+		params = strings.Split(u.RawQuery, "&")
+	}
+
+	found := false
+	result := params[:0]
+	for _, p := range params {
+		name, rawValue := splitQueryParam(p)
+		if name != f.name {
+			result = append(result, p)
+			continue
+		}
+
+		found = true
+		switch f.operation {
+		case dropQueryParam:
+		case renameQuery:
+			result = append(result, url.QueryEscape(f.arg)+rawValue)
+		default:
+			result = append(result, p)
+		}
+	}
+
+	if f.operation == addQuery || f.operation == setDefaultQuery && !found {
+		result = append(result, url.QueryEscape(f.name)+"="+url.QueryEscape(f.arg))
+	}
+
+	u.RawQuery = strings.Join(result, "&")
+}
+
+func (*modifyQuery) Response(filters.FilterContext) {}
@@ -0,0 +1,98 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestModifyQuery(t *testing.T) {
+	for _, tt := range []struct {
+		msg      string
+		args     []interface{}
+		query    string
+		expected string
+	}{{
+		msg:      "rename",
+		args:     []interface{}{"rename", "q", "query"},
+		query:    "b=2&q=foo%20bar&a=1&q=baz",
+		expected: "b=2&query=foo%20bar&a=1&query=baz",
+	}, {
+		msg:      "rename encoded name",
+		args:     []interface{}{"rename", "a b", "c&d"},
+		query:    "a+b=1&x=%2F",
+		expected: "c%26d=1&x=%2F",
+	}, {
+		msg:      "rename missing",
+		args:     []interface{}{"rename", "q", "query"},
+		query:    "b=2",
+		expected: "b=2",
+	}, {
+		msg:      "drop",
+		args:     []interface{}{"drop", "legacy"},
+		query:    "legacy=1&a=%2F&legacy&b=2",
+		expected: "a=%2F&b=2",
+	}, {
+		msg:      "drop the only parameter",
+		args:     []interface{}{"drop", "legacy"},
+		query:    "legacy=1",
+		expected: "",
+	}, {
+		msg:      "add",
+		args:     []interface{}{"add", "tag", "a b"},
+		query:    "tag=x",
+		expected: "tag=x&tag=a+b",
+	}, {
+		msg:      "add to empty query",
+		args:     []interface{}{"add", "tag", "x"},
+		query:    "",
+		expected: "tag=x",
+	}, {
+		msg:      "set default when missing",
+		args:     []interface{}{"setDefault", "page", "1"},
+		query:    "z=%7E",
+		expected: "z=%7E&page=1",
+	}, {
+		msg:      "set default when present",
+		args:     []interface{}{"setDefault", "page", "1"},
+		query:    "page=3",
+		expected: "page=3",
+	}} {
+		t.Run(tt.msg, func(t *testing.T) {
+			f, err := NewModifyQuery().CreateFilter(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", "https://www.example.org/path", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.URL.RawQuery = tt.query
+			f.Request(&filtertest.Context{FRequest: req})
+			if req.URL.RawQuery != tt.expected {
+				t.Errorf("expected query %q, got %q", tt.expected, req.URL.RawQuery)
+			}
+		})
+	}
+}
+
+func TestModifyQueryArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{"drop"},
+		{"unknown", "q"},
+		{"drop", "q", "x"},
+		{"rename", "q"},
+		{"rename", "q", ""},
+		{"setDefault", "page"},
+		{"add", "", "x"},
+		{"add", "tag", 1.0},
+	} {
+		if _, err := NewModifyQuery().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package builtin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const normalizeTrimOption = "trim"
+
+type normalizeRequestHeaders struct {
+	trim  bool
+	names map[string]bool
+}
+
+// NewNormalizeRequestHeaders creates a filter spec for the
+// normalizeRequestHeaders() filter, that canonicalizes the names of the
+// request headers, and merges the multiple values of the same header into a
+// single, comma separated value, as allowed by RFC 7230. The Cookie values
+// are merged with "; " separators, as defined by RFC 6265, and Set-Cookie is
+// never merged.
+//
+// The optional "trim" argument enables trimming the leading and trailing
+// whitespace of the values. The further optional arguments limit the
+// normalization to the named headers.
+//
+//     * -> normalizeRequestHeaders() -> "https://www.example.org"
+//     * -> normalizeRequestHeaders("trim", "Accept", "X-Forwarded-For") -> "https://www.example.org"
+//
+func NewNormalizeRequestHeaders() filters.Spec {
+	return &normalizeRequestHeaders{}
+}
+
+func (*normalizeRequestHeaders) Name() string { return filters.NormalizeRequestHeadersName }
+
+func (*normalizeRequestHeaders) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f := &normalizeRequestHeaders{}
+	for i, a := range args {
+		s, ok := a.(string)
+		if !ok || s == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if i == 0 && s == normalizeTrimOption {
+			f.trim = true
+			continue
+		}
+
+		if f.names == nil {
+			f.names = make(map[string]bool)
+		}
+
+		f.names[http.CanonicalHeaderKey(s)] = true
+	}
+
+	return f, nil
+}
+
+func (f *normalizeRequestHeaders) normalizeValues(name string, values []string) []string {
+	if f.trim {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.TrimSpace(v)
+		}
+
+		values = trimmed
+	}
+
+	switch {
+	case len(values) < 2 || name == "Set-Cookie":
+		return values
+	case name == "Cookie":
+		return []string{strings.Join(values, "; ")}
+	default:
+		return []string{strings.Join(values, ", ")}
+	}
+}
+
+func (f *normalizeRequestHeaders) Request(ctx filters.FilterContext) {
+	h := ctx.Request().Header
+
+	// collect the values under the canonical names first, preserving the
+	// order of the values of the already canonical names
+	merged := make(map[string][]string)
+	for name, values := range h {
+		canonical := http.CanonicalHeaderKey(name)
+		if f.names != nil && !f.names[canonical] {
+			continue
+		}
+
+		if canonical == name {
+			merged[canonical] = append(values[:len(values):len(values)], merged[canonical]...)
+		} else {
+			merged[canonical] = append(merged[canonical], values...)
+		}
+
+		delete(h, name)
+	}
+
+	for name, values := range merged {
+		h[name] = f.normalizeValues(name, values)
+	}
+}
+
+func (*normalizeRequestHeaders) Response(filters.FilterContext) {}
@@ -0,0 +1,91 @@
+package builtin
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestNormalizeRequestHeadersArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{""}, err: true},
+		{args: []interface{}{"trim", 42.0}, err: true},
+		{args: nil},
+		{args: []interface{}{"trim"}},
+		{args: []interface{}{"trim", "Accept"}},
+		{args: []interface{}{"Accept", "X-Forwarded-For"}},
+	} {
+		_, err := NewNormalizeRequestHeaders().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestNormalizeRequestHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		msg      string
+		args     []interface{}
+		header   http.Header
+		expected http.Header
+	}{{
+		msg:      "merges duplicate values",
+		header:   http.Header{"Accept": {"text/html", "application/json"}},
+		expected: http.Header{"Accept": {"text/html, application/json"}},
+	}, {
+		msg:      "canonicalizes names and merges them",
+		header:   http.Header{"X-Forwarded-For": {"10.0.0.1"}, "x-forwarded-for": {"10.0.0.2"}},
+		expected: http.Header{"X-Forwarded-For": {"10.0.0.1, 10.0.0.2"}},
+	}, {
+		msg:      "merges cookies with semicolons",
+		header:   http.Header{"Cookie": {"a=1", "b=2"}},
+		expected: http.Header{"Cookie": {"a=1; b=2"}},
+	}, {
+		msg:      "does not merge set-cookie",
+		header:   http.Header{"Set-Cookie": {"a=1", "b=2"}},
+		expected: http.Header{"Set-Cookie": {"a=1", "b=2"}},
+	}, {
+		msg:      "keeps whitespace by default",
+		header:   http.Header{"X-Foo": {" bar "}},
+		expected: http.Header{"X-Foo": {" bar "}},
+	}, {
+		msg:      "trims whitespace",
+		args:     []interface{}{"trim"},
+		header:   http.Header{"X-Foo": {" bar ", "baz\t"}},
+		expected: http.Header{"X-Foo": {"bar, baz"}},
+	}, {
+		msg:  "only the named headers",
+		args: []interface{}{"trim", "accept"},
+		header: http.Header{
+			"Accept": {" text/html", "application/json"},
+			"X-Foo":  {" bar ", "baz"},
+			"x-bar":  {"qux"},
+		},
+		expected: http.Header{
+			"Accept": {"text/html, application/json"},
+			"X-Foo":  {" bar ", "baz"},
+			"x-bar":  {"qux"},
+		},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewNormalizeRequestHeaders().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &http.Request{Header: tc.header}
+			f.Request(&filtertest.Context{FRequest: req})
+			if !reflect.DeepEqual(req.Header, tc.expected) {
+				t.Errorf("unexpected headers: %v, expected: %v", req.Header, tc.expected)
+			}
+		})
+	}
+}
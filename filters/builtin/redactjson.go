@@ -0,0 +1,334 @@
+package builtin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	defaultRedactJSONMaxSize = 2 << 20
+	redactJSONMask           = "***"
+)
+
+type (
+	redactJSONSpec struct{}
+
+	jsonPathStep struct {
+		key      string
+		index    int
+		isIndex  bool
+		wildcard bool
+	}
+
+	redactJSON struct {
+		paths   [][]jsonPathStep
+		mask    bool
+		maxSize int64
+	}
+)
+
+// NewRedactJSON creates a filter specification, whose instances remove or
+// mask the fields of JSON response bodies selected by JSONPath expressions.
+//
+// Usage of the filter:
+//
+//     * -> redactJSON("$.user.ssn", "$.user.email") -> "https://www.example.org"
+//
+// Or, to replace the values with "***" instead of removing them, and to set
+// the maximum body size:
+//
+//     * -> redactJSON("$.user.ssn", "$.items[*].email", "mode=mask", "maxSize=1m") -> "https://www.example.org"
+//
+// The supported JSONPath subset consists of the root ($), the object
+// members (.name or ['name']), the array indexes ([0]) and the wildcard
+// ([*]), which selects all the members of an object or an array.
+//
+// Only the responses with the application/json or application/*+json
+// content type, e.g. application/problem+json, are edited,
+// other responses are passed through untouched. Responses that are larger
+// than the maximum size, 2MB by default, that are encoded, e.g. gzipped,
+// or that contain invalid JSON or more than one JSON value, are replaced with 502 Bad Gateway, to
+// avoid leaking the fields that should have been redacted.
+func NewRedactJSON() filters.Spec { return &redactJSONSpec{} }
+
+func (*redactJSONSpec) Name() string { return filters.RedactJSONName }
+
+func (*redactJSONSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f := &redactJSON{maxSize: defaultRedactJSONMaxSize}
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if strings.HasPrefix(s, "$") {
+			p, err := parseJSONPath(s)
+			if err != nil {
+				return nil, err
+			}
+
+			f.paths = append(f.paths, p)
+			continue
+		}
+
+		if err := f.setOption(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(f.paths) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return f, nil
+}
+
+func (f *redactJSON) setOption(o string) error {
+	kv := strings.SplitN(o, "=", 2)
+	if len(kv) != 2 {
+		return filters.ErrInvalidFilterParameters
+	}
+
+	switch kv[0] {
+	case "mode":
+		switch kv[1] {
+		case "remove":
+			f.mask = false
+		case "mask":
+			f.mask = true
+		default:
+			return filters.ErrInvalidFilterParameters
+		}
+	case "maxSize":
+		s, err := parseSize(kv[1])
+		if err != nil || s <= 0 {
+			return filters.ErrInvalidFilterParameters
+		}
+
+		f.maxSize = s
+	default:
+		return filters.ErrInvalidFilterParameters
+	}
+
+	return nil
+}
+
+// parseJSONPath parses the supported subset of JSONPath, e.g.
+// $.user.email, $.items[*].email or $['user']['email'].
+func parseJSONPath(p string) ([]jsonPathStep, error) {
+	invalid := fmt.Errorf("%w: invalid JSONPath: %s", filters.ErrInvalidFilterParameters, p)
+	if !strings.HasPrefix(p, "$") {
+		return nil, invalid
+	}
+
+	var steps []jsonPathStep
+	for rest := p[1:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			i := strings.IndexAny(rest, ".[")
+			if i < 0 {
+				i = len(rest)
+			}
+
+			if i == 0 {
+				return nil, invalid
+			}
+
+			steps = append(steps, jsonPathStep{key: rest[:i]})
+			rest = rest[i:]
+		case '[':
+			i := strings.IndexByte(rest, ']')
+			if i < 0 {
+				return nil, invalid
+			}
+
+			sel := rest[1:i]
+			rest = rest[i+1:]
+			switch {
+			case sel == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(sel) >= 2 && sel[0] == '\'' && sel[len(sel)-1] == '\'':
+				steps = append(steps, jsonPathStep{key: sel[1 : len(sel)-1]})
+			default:
+				index, err := strconv.Atoi(sel)
+				if err != nil || index < 0 {
+					return nil, invalid
+				}
+
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, invalid
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, invalid
+	}
+
+	return steps, nil
+}
+
+// redact removes or masks the values selected by the path, and returns
+// the edited value.
+func (f *redactJSON) redact(v interface{}, path []jsonPathStep) interface{} {
+	step, last := path[0], len(path) == 1
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		if step.isIndex {
+			return v
+		}
+
+		for key, member := range vv {
+			if !step.wildcard && key != step.key {
+				continue
+			}
+
+			switch {
+			case !last:
+				vv[key] = f.redact(member, path[1:])
+			case f.mask:
+				vv[key] = redactJSONMask
+			default:
+				delete(vv, key)
+			}
+		}
+
+		return vv
+	case []interface{}:
+		if !step.isIndex && !step.wildcard {
+			return v
+		}
+
+		var edited []interface{}
+		for i, item := range vv {
+			if step.isIndex && i != step.index {
+				edited = append(edited, item)
+				continue
+			}
+
+			switch {
+			case !last:
+				edited = append(edited, f.redact(item, path[1:]))
+			case f.mask:
+				edited = append(edited, redactJSONMask)
+			}
+		}
+
+		if edited == nil {
+			edited = []interface{}{}
+		}
+
+		return edited
+	default:
+		return v
+	}
+}
+
+func (f *redactJSON) Request(ctx filters.FilterContext) {
+	// asking for an unencoded response, because the encoded responses
+	// cannot be edited:
+	ctx.Request().Header.Del("Accept-Encoding")
+}
+
+func isJSONResponse(rsp *http.Response) bool {
+	mt, _, err := mime.ParseMediaType(rsp.Header.Get("Content-Type"))
+	return err == nil && (mt == "application/json" ||
+		strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json"))
+}
+
+func replaceWithBadGateway(rsp *http.Response) {
+	rsp.Body.Close()
+	rsp.StatusCode = http.StatusBadGateway
+	rsp.Status = ""
+	rsp.Header.Del("Content-Type")
+	rsp.Header.Del("Content-Encoding")
+	rsp.Header.Set("Content-Length", "0")
+	rsp.ContentLength = 0
+	rsp.Body = http.NoBody
+}
+
+func hasNoBody(ctx filters.FilterContext) bool {
+	rsp := ctx.Response()
+	return ctx.Request().Method == "HEAD" ||
+		rsp.StatusCode == http.StatusNoContent ||
+		rsp.StatusCode == http.StatusNotModified
+}
+
+func (f *redactJSON) Response(ctx filters.FilterContext) {
+	rsp := ctx.Response()
+	if !isJSONResponse(rsp) || hasNoBody(ctx) {
+		return
+	}
+
+	if ce := rsp.Header.Get("Content-Encoding"); ce != "" && ce != "identity" {
+		log.Errorf("%s: cannot redact response with content encoding: %s", filters.RedactJSONName, ce)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	b, err := io.ReadAll(io.LimitReader(rsp.Body, f.maxSize+1))
+	if err != nil {
+		log.Errorf("%s: failed to read response body: %v", filters.RedactJSONName, err)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	if int64(len(b)) > f.maxSize {
+		log.Errorf("%s: response body exceeds the maximum size: %d", filters.RedactJSONName, f.maxSize)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	// nothing to redact:
+	if len(b) == 0 {
+		rsp.Body.Close()
+		rsp.Body = http.NoBody
+		return
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		log.Errorf("%s: failed to parse response body: %v", filters.RedactJSONName, err)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	// the values following the first document would be passed on
+	// without being redacted:
+	if _, err := d.Token(); err != io.EOF {
+		log.Errorf("%s: response body contains trailing data after the JSON document", filters.RedactJSONName)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	for _, p := range f.paths {
+		v = f.redact(v, p)
+	}
+
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		log.Errorf("%s: failed to serialize response body: %v", filters.RedactJSONName, err)
+		replaceWithBadGateway(rsp)
+		return
+	}
+
+	rsp.Body.Close()
+	rsp.Body = io.NopCloser(&buf)
+	rsp.ContentLength = int64(buf.Len())
+	rsp.Header.Set("Content-Length", strconv.Itoa(buf.Len()))
+}
@@ -0,0 +1,202 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRedactJSONArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{"mode=mask"}, err: true},
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{"$"}, err: true},
+		{args: []interface{}{"$.user."}, err: true},
+		{args: []interface{}{"$.items[x]"}, err: true},
+		{args: []interface{}{"$.items[0"}, err: true},
+		{args: []interface{}{"$.user.ssn", "mode=hide"}, err: true},
+		{args: []interface{}{"$.user.ssn", "maxSize=0"}, err: true},
+		{args: []interface{}{"$.user.ssn", "foo=bar"}, err: true},
+		{args: []interface{}{"$.user.ssn"}},
+		{args: []interface{}{"$.user.ssn", "$['user']['email']", "$.items[*].email", "$.items[0]"}},
+		{args: []interface{}{"$.user.ssn", "mode=mask", "maxSize=1m"}},
+	} {
+		_, err := NewRedactJSON().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	const body = `{"user": {"name": "jane", "ssn": "123-45-6789", "email": "jane@example.org"},` +
+		` "items": [{"id": 1, "email": "a@example.org"}, {"id": 2, "email": "b@example.org"}]}`
+
+	for _, tc := range []struct {
+		msg            string
+		args           []interface{}
+		method         string
+		status         int
+		contentType    string
+		encoding       string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{{
+		msg:            "not json",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "text/plain",
+		body:           body,
+		expectedStatus: http.StatusOK,
+		expectedBody:   body,
+	}, {
+		msg:            "remove fields",
+		args:           []interface{}{"$.user.ssn", "$.user.email"},
+		contentType:    "application/json; charset=utf-8",
+		body:           body,
+		expectedStatus: http.StatusOK,
+		expectedBody: `{"items":[{"email":"a@example.org","id":1},{"email":"b@example.org","id":2}],` +
+			`"user":{"name":"jane"}}`,
+	}, {
+		msg:            "structured syntax suffix",
+		args:           []interface{}{"$.user.ssn", "$.user.email"},
+		contentType:    "application/problem+json",
+		body:           body,
+		expectedStatus: http.StatusOK,
+		expectedBody: `{"items":[{"email":"a@example.org","id":1},{"email":"b@example.org","id":2}],` +
+			`"user":{"name":"jane"}}`,
+	}, {
+		msg:            "mask fields",
+		args:           []interface{}{"$['user']['ssn']", "$.items[*].email", "mode=mask"},
+		contentType:    "application/json",
+		body:           body,
+		expectedStatus: http.StatusOK,
+		expectedBody: `{"items":[{"email":"***","id":1},{"email":"***","id":2}],` +
+			`"user":{"email":"jane@example.org","name":"jane","ssn":"***"}}`,
+	}, {
+		msg:            "remove array item",
+		args:           []interface{}{"$.items[0]"},
+		contentType:    "application/json",
+		body:           body,
+		expectedStatus: http.StatusOK,
+		expectedBody: `{"items":[{"email":"b@example.org","id":2}],` +
+			`"user":{"email":"jane@example.org","name":"jane","ssn":"123-45-6789"}}`,
+	}, {
+		msg:            "missing path",
+		args:           []interface{}{"$.account.iban"},
+		contentType:    "application/json",
+		body:           `{"user": "jane"}`,
+		expectedStatus: http.StatusOK,
+		expectedBody:   `{"user":"jane"}`,
+	}, {
+		msg:            "invalid json",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "application/json",
+		body:           `{"user": `,
+		expectedStatus: http.StatusBadGateway,
+	}, {
+		msg:            "trailing json values",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "application/json",
+		body:           `{"user": {"ssn": "123"}} {"user": {"ssn": "456"}}`,
+		expectedStatus: http.StatusBadGateway,
+	}, {
+		msg:            "trailing whitespace",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "application/json",
+		body:           "{\"user\": {\"ssn\": \"123\"}}\n\n",
+		expectedStatus: http.StatusOK,
+		expectedBody:   `{"user":{}}`,
+	}, {
+		msg:            "encoded",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "application/json",
+		encoding:       "gzip",
+		body:           body,
+		expectedStatus: http.StatusBadGateway,
+	}, {
+		msg:            "too large",
+		args:           []interface{}{"$.user.ssn", "maxSize=64"},
+		contentType:    "application/json",
+		body:           body,
+		expectedStatus: http.StatusBadGateway,
+	}, {
+		msg:            "head request",
+		args:           []interface{}{"$.user.ssn"},
+		method:         "HEAD",
+		contentType:    "application/json",
+		expectedStatus: http.StatusOK,
+	}, {
+		msg:            "no content",
+		args:           []interface{}{"$.user.ssn"},
+		status:         http.StatusNoContent,
+		contentType:    "application/json",
+		expectedStatus: http.StatusNoContent,
+	}, {
+		msg:            "not modified",
+		args:           []interface{}{"$.user.ssn"},
+		status:         http.StatusNotModified,
+		contentType:    "application/json",
+		expectedStatus: http.StatusNotModified,
+	}, {
+		msg:            "empty body",
+		args:           []interface{}{"$.user.ssn"},
+		contentType:    "application/json",
+		expectedStatus: http.StatusOK,
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewRedactJSON().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			method := tc.method
+			if method == "" {
+				method = "GET"
+			}
+
+			status := tc.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			req := &http.Request{Method: method, Header: http.Header{}}
+			rsp := &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": []string{tc.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tc.body)),
+			}
+
+			if tc.encoding != "" {
+				rsp.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			f.Response(&filtertest.Context{FRequest: req, FResponse: rsp})
+			if rsp.StatusCode != tc.expectedStatus {
+				t.Fatalf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, tc.expectedStatus)
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.TrimSpace(string(b)); got != tc.expectedBody {
+				t.Errorf("unexpected body, got: %s, expected: %s", got, tc.expectedBody)
+			}
+
+			if rsp.StatusCode == http.StatusOK && tc.body != tc.expectedBody && rsp.ContentLength != int64(len(b)) {
+				t.Errorf("unexpected content length, got: %d, expected: %d", rsp.ContentLength, len(b))
+			}
+		})
+	}
+}
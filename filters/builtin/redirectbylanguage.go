@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	redirectByAcceptLanguage struct {
+		sourcePath string
+		fallback   *url.URL
+		languages  map[string]*url.URL
+	}
+
+	acceptedLanguage struct {
+		tag string
+		q   float64
+	}
+)
+
+// NewRedirectByAcceptLanguage returns a new filter Spec, whose instances
+// redirect the requests of the configured source path with 302 to the
+// location matching the Accept-Language header best. Instances expect the
+// source path, the fallback location, and one or more language to location
+// mappings in the form of "<language>=<location>".
+//
+//     * -> redirectByAcceptLanguage("/", "/en/", "de=/de/", "en=/en/") -> "https://www.example.org"
+//
+// The languages are matched in the order of their quality values, first
+// with the full tag, then with the primary tag only, e.g. "de-CH" matches
+// "de". Requests with other paths are not redirected.
+// Name: "redirectByAcceptLanguage".
+func NewRedirectByAcceptLanguage() filters.Spec { return &redirectByAcceptLanguage{} }
+
+func (*redirectByAcceptLanguage) Name() string {
+	return filters.RedirectByAcceptLanguageName
+}
+
+func (*redirectByAcceptLanguage) CreateFilter(config []interface{}) (filters.Filter, error) {
+	if len(config) < 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	args := make([]string, len(config))
+	for i, c := range config {
+		s, ok := c.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		args[i] = s
+	}
+
+	fallback, err := url.Parse(args[1])
+	if err != nil {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &redirectByAcceptLanguage{
+		sourcePath: args[0],
+		fallback:   fallback,
+		languages:  make(map[string]*url.URL),
+	}
+
+	for _, m := range args[2:] {
+		kv := strings.SplitN(m, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		u, err := url.Parse(kv[1])
+		if err != nil {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.languages[strings.ToLower(strings.TrimSpace(kv[0]))] = u
+	}
+
+	return f, nil
+}
+
+// returns the languages of the Accept-Language header, ordered by their
+// quality values, keeping the order of the header for the equal ones, and
+// skipping the ones with zero quality
+func parseAcceptLanguage(h string) []acceptedLanguage {
+	var langs []acceptedLanguage
+	for _, s := range strings.Split(h, ",") {
+		sp := strings.Split(s, ";")
+		tag := strings.ToLower(strings.TrimSpace(sp[0]))
+		if tag == "" {
+			continue
+		}
+
+		lang := acceptedLanguage{tag: tag, q: 1}
+		for _, spi := range sp[1:] {
+			spi = strings.TrimSpace(spi)
+			if !strings.HasPrefix(spi, "q=") {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimPrefix(spi, "q="), 64)
+			if err != nil {
+				continue
+			}
+
+			lang.q = q
+			break
+		}
+
+		if lang.q > 0 {
+			langs = append(langs, lang)
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	return langs
+}
+
+func (f *redirectByAcceptLanguage) location(h string) *url.URL {
+	for _, lang := range parseAcceptLanguage(h) {
+		if u, ok := f.languages[lang.tag]; ok {
+			return u
+		}
+
+		if i := strings.Index(lang.tag, "-"); i > 0 {
+			if u, ok := f.languages[lang.tag[:i]]; ok {
+				return u
+			}
+		}
+	}
+
+	return f.fallback
+}
+
+func (f *redirectByAcceptLanguage) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+	if r.URL.Path != f.sourcePath {
+		return
+	}
+
+	u := getLocation(ctx, f.location(r.Header.Get("Accept-Language")), redTo)
+	ctx.Serve(&http.Response{
+		StatusCode: http.StatusFound,
+		Header: http.Header{
+			"Location": []string{u},
+			"Vary":     []string{"Accept-Language"},
+		},
+	})
+}
+
+func (*redirectByAcceptLanguage) Response(filters.FilterContext) {}
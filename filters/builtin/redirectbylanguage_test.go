@@ -0,0 +1,95 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRedirectByAcceptLanguage(t *testing.T) {
+	for _, tt := range []struct {
+		msg            string
+		url            string
+		acceptLanguage string
+		location       string
+	}{{
+		msg:            "exact match",
+		url:            "https://www.example.org/",
+		acceptLanguage: "de",
+		location:       "https://www.example.org/de/",
+	}, {
+		msg:            "quality values",
+		url:            "https://www.example.org/",
+		acceptLanguage: "de;q=0.5, en;q=0.8",
+		location:       "https://www.example.org/en/",
+	}, {
+		msg:            "primary tag",
+		url:            "https://www.example.org/",
+		acceptLanguage: "fr;q=0.9, de-CH;q=0.7",
+		location:       "https://www.example.org/de/",
+	}, {
+		msg:            "zero quality",
+		url:            "https://www.example.org/",
+		acceptLanguage: "de;q=0",
+		location:       "https://www.example.org/en/",
+	}, {
+		msg:            "fallback without header",
+		url:            "https://www.example.org/?foo=bar",
+		acceptLanguage: "",
+		location:       "https://www.example.org/en/?foo=bar",
+	}, {
+		msg:            "other path",
+		url:            "https://www.example.org/de/",
+		acceptLanguage: "en",
+	}} {
+		t.Run(tt.msg, func(t *testing.T) {
+			f, err := NewRedirectByAcceptLanguage().CreateFilter([]interface{}{"/", "/en/", "de=/de/", "en=/en/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+
+			if tt.location == "" {
+				if ctx.FServed {
+					t.Error("unexpected redirect")
+				}
+
+				return
+			}
+
+			if !ctx.FServed || ctx.FResponse.StatusCode != http.StatusFound {
+				t.Fatal("failed to redirect")
+			}
+
+			if l := ctx.FResponse.Header.Get("Location"); l != tt.location {
+				t.Errorf("expected location %s, got %s", tt.location, l)
+			}
+		})
+	}
+}
+
+func TestRedirectByAcceptLanguageArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{"/", "/en/"},
+		{"/", "/en/", "de"},
+		{"/", "/en/", "=/de/"},
+		{"/", "/en/", 42},
+	} {
+		if _, err := NewRedirectByAcceptLanguage().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package builtin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const defaultRequireRequestHeadersMessage = "missing required request headers"
+
+type (
+	requireRequestHeadersSpec struct{}
+
+	requireRequestHeaders struct {
+		headers    []string
+		statusCode int
+		message    string
+	}
+
+	requireRequestHeadersResponse struct {
+		Error   string   `json:"error"`
+		Missing []string `json:"missing"`
+	}
+)
+
+// NewRequireRequestHeaders creates a filter specification, whose instances
+// check that the listed request headers are present and not empty. When
+// any of them is missing, the filter responds with 400 Bad Request and a
+// JSON body listing the missing headers, without calling the backend.
+//
+// Optionally, the first argument can be the status code of the response,
+// followed by the error message, and only then the list of the headers.
+//
+// Example:
+//
+//    requireRequestHeaders("X-Tenant-Id", "X-Api-Version")
+//    requireRequestHeaders(422, "tenant required", "X-Tenant-Id")
+func NewRequireRequestHeaders() filters.Spec { return &requireRequestHeadersSpec{} }
+
+func (*requireRequestHeadersSpec) Name() string { return filters.RequireRequestHeadersName }
+
+func (*requireRequestHeadersSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f := &requireRequestHeaders{
+		statusCode: http.StatusBadRequest,
+		message:    defaultRequireRequestHeadersMessage,
+	}
+
+	if len(args) > 0 {
+		if code, ok := args[0].(float64); ok {
+			if len(args) < 2 {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			f.statusCode = int(code)
+			if f.statusCode < 400 || f.statusCode > 599 {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			message, ok := args[1].(string)
+			if !ok {
+				return nil, filters.ErrInvalidFilterParameters
+			}
+
+			f.message = message
+			args = args[2:]
+		}
+	}
+
+	if len(args) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	for _, a := range args {
+		h, ok := a.(string)
+		if !ok || h == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.headers = append(f.headers, h)
+	}
+
+	return f, nil
+}
+
+func (f *requireRequestHeaders) Request(ctx filters.FilterContext) {
+	header := ctx.Request().Header
+	var missing []string
+	for _, h := range f.headers {
+		if strings.TrimSpace(header.Get(h)) == "" {
+			missing = append(missing, h)
+		}
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	// encoding strings only, it cannot fail
+	body, _ := json.Marshal(requireRequestHeadersResponse{Error: f.message, Missing: missing})
+
+	ctx.Serve(&http.Response{
+		StatusCode: f.statusCode,
+		Header: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"Content-Length": []string{strconv.Itoa(len(body))},
+		},
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	})
+}
+
+func (*requireRequestHeaders) Response(filters.FilterContext) {}
@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRequireRequestHeadersArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{""}, err: true},
+		{args: []interface{}{"X-Foo", 42.0}, err: true},
+		{args: []interface{}{400.0, "missing"}, err: true},
+		{args: []interface{}{200.0, "missing", "X-Foo"}, err: true},
+		{args: []interface{}{400.0, 42.0, "X-Foo"}, err: true},
+		{args: []interface{}{"X-Foo"}},
+		{args: []interface{}{"X-Foo", "X-Bar"}},
+		{args: []interface{}{422.0, "missing", "X-Foo"}},
+	} {
+		_, err := NewRequireRequestHeaders().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestRequireRequestHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		msg             string
+		args            []interface{}
+		header          http.Header
+		expectServed    bool
+		expectedStatus  int
+		expectedMessage string
+		expectedMissing []string
+	}{{
+		msg:    "all headers present",
+		args:   []interface{}{"X-Tenant-Id", "X-Api-Version"},
+		header: http.Header{"X-Tenant-Id": []string{"foo"}, "X-Api-Version": []string{"1"}},
+	}, {
+		msg:             "one header missing",
+		args:            []interface{}{"X-Tenant-Id", "X-Api-Version"},
+		header:          http.Header{"X-Tenant-Id": []string{"foo"}},
+		expectServed:    true,
+		expectedStatus:  http.StatusBadRequest,
+		expectedMessage: defaultRequireRequestHeadersMessage,
+		expectedMissing: []string{"X-Api-Version"},
+	}, {
+		msg:             "empty header",
+		args:            []interface{}{"X-Tenant-Id", "X-Api-Version"},
+		header:          http.Header{"X-Tenant-Id": []string{" "}},
+		expectServed:    true,
+		expectedStatus:  http.StatusBadRequest,
+		expectedMessage: defaultRequireRequestHeadersMessage,
+		expectedMissing: []string{"X-Tenant-Id", "X-Api-Version"},
+	}, {
+		msg:             "custom status and message",
+		args:            []interface{}{422.0, "tenant required", "X-Tenant-Id"},
+		header:          http.Header{},
+		expectServed:    true,
+		expectedStatus:  http.StatusUnprocessableEntity,
+		expectedMessage: "tenant required",
+		expectedMissing: []string{"X-Tenant-Id"},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewRequireRequestHeaders().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FRequest: &http.Request{Header: tc.header}}
+			f.Request(ctx)
+			if ctx.FServed != tc.expectServed {
+				t.Fatalf("unexpected served state, got: %t, expected: %t", ctx.FServed, tc.expectServed)
+			}
+
+			if !tc.expectServed {
+				return
+			}
+
+			rsp := ctx.FResponse
+			if rsp.StatusCode != tc.expectedStatus {
+				t.Errorf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, tc.expectedStatus)
+			}
+
+			if ct := rsp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("unexpected content type: %s", ct)
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var body requireRequestHeadersResponse
+			if err := json.Unmarshal(b, &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body.Error != tc.expectedMessage {
+				t.Errorf("unexpected message, got: %s, expected: %s", body.Error, tc.expectedMessage)
+			}
+
+			if !reflect.DeepEqual(body.Missing, tc.expectedMissing) {
+				t.Errorf("unexpected missing headers, got: %v, expected: %v", body.Missing, tc.expectedMissing)
+			}
+		})
+	}
+}
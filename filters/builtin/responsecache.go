@@ -0,0 +1,424 @@
+package builtin
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	responseCacheStateKey = "filter." + filters.ResponseCacheName
+
+	responseCacheHitKey  = "hit"
+	responseCacheMissKey = "miss"
+)
+
+type (
+	responseCacheSpec struct {
+		mx     sync.Mutex
+		caches map[string]*responseCache
+	}
+
+	responseCache struct {
+		ttl     time.Duration
+		maxSize int64
+
+		mx      sync.Mutex
+		size    int64
+		lru     *list.List
+		entries map[string]*list.Element
+	}
+
+	// all the variants of a resource, identified by the method and the URL
+	cacheEntry struct {
+		key      string
+		size     int64
+		variants []*cacheVariant
+	}
+
+	// a cached response, selected by the values of the request headers
+	// listed in the Vary header of the response
+	cacheVariant struct {
+		vary       []string
+		varyValues []string
+		expires    time.Time
+		stored     time.Time
+		statusCode int
+		header     http.Header
+		body       []byte
+	}
+
+	// the request headers are preserved, because the following filters
+	// may change them
+	cacheCandidate struct {
+		key    string
+		header http.Header
+	}
+
+	// bufferedBody is used, when the response is too large to be cached,
+	// to continue streaming the response after the buffered part
+	bufferedBody struct {
+		io.Reader
+		closer io.Closer
+	}
+)
+
+// NewResponseCache creates a filter specification, whose instances cache
+// the responses of the GET requests in memory, and serve them without
+// contacting the backend, until they expire. It expects the TTL of the
+// cached responses and the maximum size of the cache as arguments. The
+// size can be set in bytes as a number, or as a string with one of the k,
+// m or g suffixes. When the size is exceeded, the least recently used
+// responses are evicted.
+//
+//     * -> responseCache("30s", "10m") -> "https://www.example.org"
+//
+// The responses are cached by the method, the URL and the values of the
+// request headers listed in the Vary header of the response. Responses
+// with Cache-Control no-store, no-cache or private, with Set-Cookie, or
+// with Vary: * are not cached. The responses to requests with an
+// Authorization header are cached only when the response allows it
+// explicitly with public, s-maxage or must-revalidate. A shorter max-age or
+// s-maxage of the response overrides the TTL.
+//
+// The caches are kept by the spec, and the filter instances with the same
+// arguments share the same cache, including the instances created for the
+// updated versions of the same route.
+//
+// The filter counts the hits and the misses with the hit and miss custom
+// counters.
+func NewResponseCache() filters.Spec {
+	return &responseCacheSpec{caches: make(map[string]*responseCache)}
+}
+
+func (*responseCacheSpec) Name() string { return filters.ResponseCacheName }
+
+func (s *responseCacheSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	ttl, ok := args[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var maxSize int64
+	switch v := args[1].(type) {
+	case float64:
+		maxSize = int64(v)
+	case string:
+		if maxSize, err = parseSize(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if maxSize <= 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	// the cache survives the route updates
+	key := fmt.Sprintf("%v:%d", d, maxSize)
+	if c, ok := s.caches[key]; ok {
+		return c, nil
+	}
+
+	c := &responseCache{
+		ttl:     d,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	s.caches[key] = c
+	return c, nil
+}
+
+func cacheKey(r *http.Request) string {
+	return r.Method + " " + r.Host + r.URL.RequestURI()
+}
+
+func cacheControlDirectives(h http.Header) map[string]string {
+	d := make(map[string]string)
+	for _, v := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			kv := strings.SplitN(strings.TrimSpace(directive), "=", 2)
+			name := strings.ToLower(kv[0])
+			if len(kv) == 2 {
+				d[name] = strings.Trim(kv[1], `"`)
+			} else {
+				d[name] = ""
+			}
+		}
+	}
+
+	return d
+}
+
+func varyHeaders(h http.Header) []string {
+	var vary []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				vary = append(vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return vary
+}
+
+func headerValues(h http.Header, names []string) []string {
+	values := make([]string, len(names))
+	for i, n := range names {
+		values[i] = strings.Join(h.Values(n), ",")
+	}
+
+	return values
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCacheableStatus(code int) bool {
+	switch code {
+	case http.StatusOK,
+		http.StatusNonAuthoritativeInfo,
+		http.StatusNoContent,
+		http.StatusMultipleChoices,
+		http.StatusMovedPermanently,
+		http.StatusNotFound,
+		http.StatusGone:
+		return true
+	default:
+		return false
+	}
+}
+
+// a shared cache can store the responses to the authorized requests only
+// when the response allows it explicitly, RFC 9111 section 3.5
+func allowsAuthorized(cc map[string]string) bool {
+	for _, d := range []string{"public", "s-maxage", "must-revalidate"} {
+		if _, ok := cc[d]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns how long the response can be cached, or 0 when it is not
+// cacheable
+func (c *responseCache) responseTTL(reqHeader http.Header, rsp *http.Response) time.Duration {
+	if !isCacheableStatus(rsp.StatusCode) || len(rsp.Header.Values("Set-Cookie")) > 0 {
+		return 0
+	}
+
+	for _, v := range varyHeaders(rsp.Header) {
+		if v == "*" {
+			return 0
+		}
+	}
+
+	cc := cacheControlDirectives(rsp.Header)
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[d]; ok {
+			return 0
+		}
+	}
+
+	if reqHeader.Get("Authorization") != "" && !allowsAuthorized(cc) {
+		return 0
+	}
+
+	ttl := c.ttl
+	for _, d := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[d]; ok {
+			if s, err := strconv.Atoi(v); err == nil {
+				if maxAge := time.Duration(s) * time.Second; maxAge < ttl {
+					ttl = maxAge
+				}
+
+				break
+			}
+		}
+	}
+
+	return ttl
+}
+
+func (c *responseCache) removeElement(e *list.Element) {
+	entry := e.Value.(*cacheEntry)
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+func (c *responseCache) lookup(key string, req *http.Request, now time.Time) *cacheVariant {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := e.Value.(*cacheEntry)
+	for i, v := range entry.variants {
+		if !equalValues(v.varyValues, headerValues(req.Header, v.vary)) {
+			continue
+		}
+
+		if now.After(v.expires) {
+			entry.variants = append(entry.variants[:i], entry.variants[i+1:]...)
+			entry.size -= int64(len(v.body))
+			c.size -= int64(len(v.body))
+			if len(entry.variants) == 0 {
+				c.removeElement(e)
+			}
+
+			return nil
+		}
+
+		c.lru.MoveToFront(e)
+		return v
+	}
+
+	return nil
+}
+
+func (c *responseCache) store(key string, v *cacheVariant) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var entry *cacheEntry
+	if e, ok := c.entries[key]; ok {
+		entry = e.Value.(*cacheEntry)
+		c.lru.MoveToFront(e)
+	} else {
+		entry = &cacheEntry{key: key}
+		c.entries[key] = c.lru.PushFront(entry)
+	}
+
+	for i, existing := range entry.variants {
+		if equalValues(existing.vary, v.vary) && equalValues(existing.varyValues, v.varyValues) {
+			entry.size -= int64(len(existing.body))
+			c.size -= int64(len(existing.body))
+			entry.variants = append(entry.variants[:i], entry.variants[i+1:]...)
+			break
+		}
+	}
+
+	entry.variants = append(entry.variants, v)
+	entry.size += int64(len(v.body))
+	c.size += int64(len(v.body))
+
+	for c.size > c.maxSize && c.lru.Len() > 0 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *responseCache) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if req.Method != "GET" {
+		return
+	}
+
+	if _, ok := cacheControlDirectives(req.Header)["no-store"]; ok {
+		return
+	}
+
+	now := time.Now()
+	key := cacheKey(req)
+	if v := c.lookup(key, req, now); v != nil {
+		ctx.Metrics().IncCounter(responseCacheHitKey)
+		ctx.StateBag()[responseCacheStateKey] = nil
+
+		h := v.header.Clone()
+		h.Set("Age", strconv.Itoa(int(now.Sub(v.stored).Seconds())))
+		ctx.Serve(&http.Response{
+			StatusCode:    v.statusCode,
+			Header:        h,
+			ContentLength: int64(len(v.body)),
+			Body:          io.NopCloser(bytes.NewReader(v.body)),
+		})
+
+		return
+	}
+
+	ctx.Metrics().IncCounter(responseCacheMissKey)
+	ctx.StateBag()[responseCacheStateKey] = &cacheCandidate{key: key, header: req.Header.Clone()}
+}
+
+func (c *responseCache) Response(ctx filters.FilterContext) {
+	candidate, ok := ctx.StateBag()[responseCacheStateKey].(*cacheCandidate)
+	if !ok {
+		return
+	}
+
+	rsp := ctx.Response()
+	ttl := c.responseTTL(candidate.header, rsp)
+	if ttl <= 0 || rsp.ContentLength > c.maxSize {
+		return
+	}
+
+	var body []byte
+	if rsp.Body != nil {
+		var buf bytes.Buffer
+		n, err := io.Copy(&buf, io.LimitReader(rsp.Body, c.maxSize+1))
+		if err != nil || n > c.maxSize {
+			// not cacheable, continue streaming the rest of the body
+			rsp.Body = &bufferedBody{Reader: io.MultiReader(&buf, rsp.Body), closer: rsp.Body}
+			return
+		}
+
+		rsp.Body.Close()
+		body = buf.Bytes()
+		rsp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	vary := varyHeaders(rsp.Header)
+	now := time.Now()
+	c.store(candidate.key, &cacheVariant{
+		vary:       vary,
+		varyValues: headerValues(candidate.header, vary),
+		expires:    now.Add(ttl),
+		stored:     now,
+		statusCode: rsp.StatusCode,
+		header:     rsp.Header.Clone(),
+		body:       body,
+	})
+}
+
+func (b *bufferedBody) Close() error {
+	return b.closer.Close()
+}
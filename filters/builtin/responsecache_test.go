@@ -0,0 +1,227 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestResponseCacheArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{"30s"}, err: true},
+		{args: []interface{}{30.0, "10m"}, err: true},
+		{args: []interface{}{"foo", "10m"}, err: true},
+		{args: []interface{}{"-1s", "10m"}, err: true},
+		{args: []interface{}{"30s", "foo"}, err: true},
+		{args: []interface{}{"30s", 0.0}, err: true},
+		{args: []interface{}{"30s", "10m"}},
+		{args: []interface{}{"30s", 4096.0}},
+	} {
+		_, err := NewResponseCache().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	var requests int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/short":
+			w.Header().Set("Cache-Control", "max-age=0")
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Language")
+			w.Write([]byte(r.Header.Get("Accept-Language")))
+			return
+		case "/large":
+			w.Write([]byte(strings.Repeat("x", 2048)))
+			return
+		}
+
+		w.Write([]byte("Hello, world!"))
+	}))
+	defer backend.Close()
+
+	r, err := eskip.Parse(`* -> responseCache("1m", 1024) -> "` + backend.URL + `"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fr := make(filters.Registry)
+	fr.Register(NewResponseCache())
+	p := proxytest.New(fr, r...)
+	defer p.Close()
+
+	get := func(path string, header http.Header) (string, string) {
+		req, err := http.NewRequest("GET", p.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header = header
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer rsp.Body.Close()
+		b, err := io.ReadAll(rsp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b), rsp.Header.Get("Age")
+	}
+
+	for _, tc := range []struct {
+		title            string
+		path             string
+		header           http.Header
+		expectedBody     string
+		expectedRequests int64
+	}{{
+		path:             "/cached",
+		expectedBody:     "Hello, world!",
+		expectedRequests: 1,
+	}, {
+		title:            "authorized",
+		path:             "/authorized",
+		header:           http.Header{"Authorization": []string{"Bearer foo"}},
+		expectedBody:     "Hello, world!",
+		expectedRequests: 2,
+	}, {
+		title:            "authorized public",
+		path:             "/public",
+		header:           http.Header{"Authorization": []string{"Bearer foo"}},
+		expectedBody:     "Hello, world!",
+		expectedRequests: 1,
+	}, {
+		path:             "/no-store",
+		expectedBody:     "Hello, world!",
+		expectedRequests: 2,
+	}, {
+		path:             "/private",
+		expectedBody:     "Hello, world!",
+		expectedRequests: 2,
+	}, {
+		path:             "/short",
+		expectedBody:     "Hello, world!",
+		expectedRequests: 2,
+	}, {
+		path:             "/large",
+		expectedBody:     strings.Repeat("x", 2048),
+		expectedRequests: 2,
+	}} {
+		title := tc.title
+		if title == "" {
+			title = tc.path
+		}
+
+		t.Run(title, func(t *testing.T) {
+			atomic.StoreInt64(&requests, 0)
+			for i := 0; i < 2; i++ {
+				body, age := get(tc.path, tc.header)
+				if body != tc.expectedBody {
+					t.Errorf("unexpected body: %s", body)
+				}
+
+				if i == 1 && tc.expectedRequests == 1 && age == "" {
+					t.Error("missing Age header of the cached response")
+				}
+			}
+
+			if n := atomic.LoadInt64(&requests); n != tc.expectedRequests {
+				t.Errorf("unexpected number of backend requests: %d, expected: %d", n, tc.expectedRequests)
+			}
+		})
+	}
+
+	t.Run("vary", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for _, lang := range []string{"en", "de", "en", "de"} {
+			body, _ := get("/vary", http.Header{"Accept-Language": []string{lang}})
+			if body != lang {
+				t.Errorf("unexpected body: %s, expected: %s", body, lang)
+			}
+		}
+
+		if n := atomic.LoadInt64(&requests); n != 2 {
+			t.Errorf("unexpected number of backend requests: %d", n)
+		}
+	})
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	f, err := NewResponseCache().CreateFilter([]interface{}{"1m", 10.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := f.(*responseCache)
+	now := time.Now()
+	for _, key := range []string{"a", "b", "c"} {
+		c.store(key, &cacheVariant{expires: now.Add(time.Minute), body: []byte("xxxx")})
+	}
+
+	if _, ok := c.entries["a"]; ok {
+		t.Error("failed to evict the oldest entry")
+	}
+
+	if c.size != 8 || c.lru.Len() != 2 {
+		t.Errorf("unexpected cache size: %d, entries: %d", c.size, c.lru.Len())
+	}
+
+	req := &http.Request{Header: make(http.Header)}
+	if c.lookup("b", req, now.Add(2*time.Minute)) != nil {
+		t.Error("unexpected expired entry")
+	}
+
+	if _, ok := c.entries["b"]; ok || c.size != 4 {
+		t.Error("failed to remove the expired entry")
+	}
+}
+
+func TestResponseCacheSharedByArgs(t *testing.T) {
+	spec := NewResponseCache()
+	create := func(args ...interface{}) filters.Filter {
+		f, err := spec.CreateFilter(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return f
+	}
+
+	// e.g. the same route after an update
+	f1 := create("1m", "10m")
+	f2 := create("1m", "10m")
+	if f1 != f2 {
+		t.Error("failed to share the cache between the filters with the same arguments")
+	}
+
+	if f3 := create("2m", "10m"); f3 == f1 {
+		t.Error("unexpected shared cache for different arguments")
+	}
+}
@@ -0,0 +1,48 @@
+package builtin
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type idempotentRetries struct {
+	retries int
+}
+
+// NewIdempotentRetries creates a filter specification, whose instances
+// override the maximum number of retries of the idempotent requests, when
+// the connection to the load balanced backend fails before a response was
+// received. 0 disables the retries of the route.
+func NewIdempotentRetries() filters.Spec {
+	return &idempotentRetries{}
+}
+
+func (*idempotentRetries) Name() string { return filters.IdempotentRetriesName }
+
+func (*idempotentRetries) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var retries int
+	switch v := args[0].(type) {
+	case int:
+		retries = v
+	case float64:
+		retries = int(v)
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if retries < 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &idempotentRetries{retries: retries}, nil
+}
+
+func (r *idempotentRetries) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.IdempotentRetries] = r.retries
+}
+
+func (*idempotentRetries) Response(filters.FilterContext) {}
@@ -0,0 +1,44 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestIdempotentRetries(t *testing.T) {
+	for _, tc := range []struct {
+		args    []interface{}
+		retries int
+		err     bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{1.0, 2.0}, err: true},
+		{args: []interface{}{"3"}, err: true},
+		{args: []interface{}{-1.0}, err: true},
+		{args: []interface{}{0.0}, retries: 0},
+		{args: []interface{}{3.0}, retries: 3},
+		{args: []interface{}{2}, retries: 2},
+	} {
+		f, err := NewIdempotentRetries().CreateFilter(tc.args)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected error for arguments: %v", tc.args)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+			continue
+		}
+
+		ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		if r := ctx.FStateBag[filters.IdempotentRetries]; r != tc.retries {
+			t.Errorf("unexpected retries for arguments: %v, got: %v, expected: %d", tc.args, r, tc.retries)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package builtin
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type rewriteLocation struct {
+	rx          *regexp.Regexp
+	replacement string
+}
+
+// NewRewriteLocation returns a new filter Spec, whose instances execute
+// regexp.ReplaceAllString on the Location header of the redirect (3xx)
+// responses. Instances expect two parameters: the expression to match and
+// the replacement string.
+//
+//     * -> rewriteLocation("^https?://internal\\.host/", "https://public.host/") -> "http://internal.host"
+//
+// Relative Location values are resolved against the backend before
+// matching, and when the expression doesn't match, the original value is
+// kept. Name: "rewriteLocation".
+func NewRewriteLocation() filters.Spec { return &rewriteLocation{} }
+
+func (spec *rewriteLocation) Name() string {
+	return filters.RewriteLocationName
+}
+
+//lint:ignore ST1016 "spec" makes sense here and we reuse the type for the filter
+func (spec *rewriteLocation) CreateFilter(config []interface{}) (filters.Filter, error) {
+	if len(config) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	expr, ok := config[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	replacement, ok := config[1].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	rx, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rewriteLocation{rx: rx, replacement: replacement}, nil
+}
+
+func (*rewriteLocation) Request(filters.FilterContext) {}
+
+// resolves the location against the backend, when it is relative
+func resolveLocation(ctx filters.FilterContext, location string) string {
+	loc, err := url.Parse(location)
+	if err != nil || loc.IsAbs() && loc.Host != "" {
+		return location
+	}
+
+	base := &url.URL{Scheme: "http"}
+	if b, err := url.Parse(ctx.BackendUrl()); err == nil && b.Scheme != "" {
+		base.Scheme = b.Scheme
+		base.Host = b.Host
+	}
+
+	if h := ctx.OutgoingHost(); h != "" {
+		base.Host = h
+	}
+
+	if base.Host == "" {
+		return location
+	}
+
+	base.Path = ctx.Request().URL.Path
+	return base.ResolveReference(loc).String()
+}
+
+func (f *rewriteLocation) Response(ctx filters.FilterContext) {
+	rsp := ctx.Response()
+	if rsp.StatusCode < 300 || rsp.StatusCode >= 400 {
+		return
+	}
+
+	location := rsp.Header.Get("Location")
+	if location == "" {
+		return
+	}
+
+	if f.rx.MatchString(location) {
+		rsp.Header.Set("Location", f.rx.ReplaceAllString(location, f.replacement))
+		return
+	}
+
+	if resolved := resolveLocation(ctx, location); resolved != location && f.rx.MatchString(resolved) {
+		rsp.Header.Set("Location", f.rx.ReplaceAllString(resolved, f.replacement))
+	}
+}
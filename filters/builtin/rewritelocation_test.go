@@ -0,0 +1,93 @@
+package builtin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestRewriteLocation(t *testing.T) {
+	for _, tt := range []struct {
+		msg        string
+		expression string
+		status     int
+		location   string
+		expected   string
+	}{{
+		msg:        "absolute location",
+		expression: `^https?://internal\.host/`,
+		status:     http.StatusFound,
+		location:   "http://internal.host/foo?bar=baz",
+		expected:   "https://public.host/foo?bar=baz",
+	}, {
+		msg:        "relative location matching",
+		expression: `^/old/`,
+		status:     http.StatusMovedPermanently,
+		location:   "/old/foo",
+		expected:   "https://public.host/foo",
+	}, {
+		msg:        "relative location resolved against the backend",
+		expression: `^https?://internal\.host/`,
+		status:     http.StatusSeeOther,
+		location:   "/login",
+		expected:   "https://public.host/login",
+	}, {
+		msg:        "relative location not matching after resolved",
+		expression: `^https?://other\.host/`,
+		status:     http.StatusFound,
+		location:   "login",
+		expected:   "login",
+	}, {
+		msg:        "other host",
+		expression: `^https?://internal\.host/`,
+		status:     http.StatusFound,
+		location:   "https://example.org/foo",
+		expected:   "https://example.org/foo",
+	}, {
+		msg:        "not a redirect",
+		expression: `^https?://internal\.host/`,
+		status:     http.StatusCreated,
+		location:   "http://internal.host/foo",
+		expected:   "http://internal.host/foo",
+	}} {
+		t.Run(tt.msg, func(t *testing.T) {
+			f, err := NewRewriteLocation().CreateFilter([]interface{}{tt.expression, "https://public.host/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", "https://public.host/path", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{
+				FRequest:    req,
+				FBackendUrl: "http://internal.host",
+				FResponse: &http.Response{
+					StatusCode: tt.status,
+					Header:     http.Header{"Location": []string{tt.location}},
+				},
+			}
+
+			f.Response(ctx)
+			if l := ctx.FResponse.Header.Get("Location"); l != tt.expected {
+				t.Errorf("expected location %s, got %s", tt.expected, l)
+			}
+		})
+	}
+}
+
+func TestRewriteLocationArgs(t *testing.T) {
+	for _, args := range [][]interface{}{
+		nil,
+		{"^foo"},
+		{"^foo", 42},
+		{"(foo", "bar"},
+	} {
+		if _, err := NewRewriteLocation().CreateFilter(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package builtin
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/serve"
+)
+
+type (
+	serveDirectorySpec struct{}
+
+	serveDirectory struct {
+		root   http.FileSystem
+		prefix string
+		index  string
+	}
+)
+
+// NewServeDirectory creates a filter specification, whose instances
+// serve the files of a directory, without calling the backend. It expects
+// the path of the directory, the request path prefix mapped to the
+// directory, and optionally the name of the index file served for the
+// requests to directories:
+//
+//     * -> serveDirectory("/var/www", "/static") -> <shunt>
+//     * -> serveDirectory("/var/www", "/", "index.html") -> <shunt>
+//
+// The request paths are cleaned before they are mapped to the files, so
+// they cannot point outside of the directory. The content type is set
+// based on the file extension, or detected from the content. The Range and
+// the conditional requests are supported. The missing files, and the
+// directories without an index file are answered with 404 Not Found.
+func NewServeDirectory() filters.Spec { return &serveDirectorySpec{} }
+
+func (*serveDirectorySpec) Name() string { return filters.ServeDirectoryName }
+
+func (*serveDirectorySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	dir, ok := args[0].(string)
+	if !ok || dir == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	prefix, ok := args[1].(string)
+	if !ok || !strings.HasPrefix(prefix, "/") {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &serveDirectory{root: http.Dir(dir), prefix: strings.TrimSuffix(prefix, "/")}
+	if len(args) == 3 {
+		index, ok := args[2].(string)
+		if !ok || index == "" || strings.Contains(index, "/") {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.index = index
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("serve directory: %s is not a directory", dir)
+	}
+
+	return f, nil
+}
+
+// fileName maps the request path to the cleaned name of the file in the
+// directory.
+func (f *serveDirectory) fileName(p string) (string, bool) {
+	if !strings.HasPrefix(p, f.prefix) {
+		return "", false
+	}
+
+	rest := p[len(f.prefix):]
+	if rest != "" && rest[0] != '/' {
+		return "", false
+	}
+
+	return path.Clean("/" + rest), true
+}
+
+func serveFileError(w http.ResponseWriter, err error) {
+	switch {
+	case os.IsNotExist(err):
+		http.Error(w, "Not Found", http.StatusNotFound)
+	case os.IsPermission(err):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		log.Errorf("Failed to serve file: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func (f *serveDirectory) open(name string) (http.File, os.FileInfo, error) {
+	file, err := f.root.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, info, nil
+}
+
+func (f *serveDirectory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := f.fileName(r.URL.Path)
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	file, info, err := f.open(name)
+	if err != nil {
+		serveFileError(w, err)
+		return
+	}
+
+	if info.IsDir() {
+		file.Close()
+		if f.index == "" {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		// the relative links of the index need the trailing slash
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			u := *r.URL
+			u.Path += "/"
+			http.Redirect(w, r, u.RequestURI(), http.StatusMovedPermanently)
+			return
+		}
+
+		file, info, err = f.open(path.Join(name, f.index))
+		if err != nil {
+			serveFileError(w, err)
+			return
+		}
+
+		if info.IsDir() {
+			file.Close()
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+	}
+
+	defer file.Close()
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func (f *serveDirectory) Request(ctx filters.FilterContext) {
+	serve.ServeHTTP(ctx, f)
+}
+
+func (*serveDirectory) Response(filters.FilterContext) {}
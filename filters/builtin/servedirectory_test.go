@@ -0,0 +1,214 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestServeDirectoryArgs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.js")
+	if err := os.WriteFile(file, []byte("console.log(42)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "no prefix",
+		args: []interface{}{dir},
+		fail: true,
+	}, {
+		name: "too many args",
+		args: []interface{}{dir, "/static", "index.html", "foo"},
+		fail: true,
+	}, {
+		name: "relative prefix",
+		args: []interface{}{dir, "static"},
+		fail: true,
+	}, {
+		name: "missing directory",
+		args: []interface{}{filepath.Join(dir, "missing"), "/static"},
+		fail: true,
+	}, {
+		name: "file instead of directory",
+		args: []interface{}{file, "/static"},
+		fail: true,
+	}, {
+		name: "index with path",
+		args: []interface{}{dir, "/static", "docs/index.html"},
+		fail: true,
+	}, {
+		name: "directory",
+		args: []interface{}{dir, "/static"},
+	}, {
+		name: "directory with index",
+		args: []interface{}{dir, "/", "index.html"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServeDirectory().CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestServeDirectory(t *testing.T) {
+	parent := t.TempDir()
+	if err := os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Join(parent, "www")
+	for name, content := range map[string]string{
+		"app.js":          "console.log(42)",
+		"style.css":       "body {}",
+		"docs/index.html": "<h1>Docs</h1>",
+		"assets/big.bin":  "0123456789",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		args        []interface{}
+		path        string
+		header      http.Header
+		status      int
+		contentType string
+		body        string
+		location    string
+	}{{
+		name:   "file",
+		args:   []interface{}{root, "/static"},
+		path:   "/static/app.js",
+		status: http.StatusOK,
+		body:   "console.log(42)",
+	}, {
+		name:        "prefix with trailing slash",
+		args:        []interface{}{root, "/static/"},
+		path:        "/static/style.css",
+		status:      http.StatusOK,
+		contentType: "text/css; charset=utf-8",
+		body:        "body {}",
+	}, {
+		name:   "missing file",
+		args:   []interface{}{root, "/static"},
+		path:   "/static/missing.js",
+		status: http.StatusNotFound,
+	}, {
+		name:   "path outside of the prefix",
+		args:   []interface{}{root, "/static"},
+		path:   "/staticapp.js",
+		status: http.StatusNotFound,
+	}, {
+		name:   "directory traversal",
+		args:   []interface{}{root, "/static"},
+		path:   "/static/../../secret.txt",
+		status: http.StatusNotFound,
+	}, {
+		name:   "directory without index",
+		args:   []interface{}{root, "/static"},
+		path:   "/static/docs/",
+		status: http.StatusNotFound,
+	}, {
+		name:        "directory with index",
+		args:        []interface{}{root, "/static", "index.html"},
+		path:        "/static/docs/",
+		status:      http.StatusOK,
+		contentType: "text/html; charset=utf-8",
+		body:        "<h1>Docs</h1>",
+	}, {
+		name:     "directory with index, redirect to trailing slash",
+		args:     []interface{}{root, "/static", "index.html"},
+		path:     "/static/docs",
+		status:   http.StatusMovedPermanently,
+		location: "/static/docs/",
+	}, {
+		name:   "directory with missing index",
+		args:   []interface{}{root, "/static", "index.html"},
+		path:   "/static/assets/",
+		status: http.StatusNotFound,
+	}, {
+		name:   "range",
+		args:   []interface{}{root, "/static"},
+		path:   "/static/assets/big.bin",
+		header: http.Header{"Range": []string{"bytes=2-5"}},
+		status: http.StatusPartialContent,
+		body:   "2345",
+	}, {
+		name:   "root prefix",
+		args:   []interface{}{root, "/"},
+		path:   "/app.js",
+		status: http.StatusOK,
+		body:   "console.log(42)",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewServeDirectory().CreateFilter(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", "https://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// set directly to bypass the path cleaning of the URL parser
+			req.URL.Path = tt.path
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+			if !ctx.FServed {
+				t.Fatal("the request was not served")
+			}
+
+			rsp := ctx.FResponse
+			defer rsp.Body.Close()
+
+			if rsp.StatusCode != tt.status {
+				t.Fatalf("unexpected status code, expected: %d, got: %d", tt.status, rsp.StatusCode)
+			}
+
+			if tt.contentType != "" && rsp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("unexpected content type, expected: %s, got: %s", tt.contentType, rsp.Header.Get("Content-Type"))
+			}
+
+			if tt.location != "" && rsp.Header.Get("Location") != tt.location {
+				t.Errorf("unexpected location, expected: %s, got: %s", tt.location, rsp.Header.Get("Location"))
+			}
+
+			body, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("unexpected body, expected: %s, got: %s", tt.body, body)
+			}
+		})
+	}
+}
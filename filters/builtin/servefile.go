@@ -0,0 +1,170 @@
+package builtin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	serveFileSpec struct{}
+
+	servedFile struct {
+		content     []byte
+		contentType string
+		modTime     time.Time
+		size        int64
+	}
+
+	serveFile struct {
+		path       string
+		statusCode int
+
+		mx     sync.Mutex
+		cached *servedFile
+	}
+)
+
+// NewServeFile creates a filter specification, whose instances respond
+// with the content of a file, without calling the backend, e.g. to serve
+// maintenance pages or robots.txt. It expects the path of the file, and
+// optionally the status code of the response, which defaults to 200:
+//
+//     * -> serveFile("/var/www/robots.txt") -> <shunt>
+//     * -> serveFile("/var/www/maintenance.html", 503) -> <shunt>
+//
+// The content of the file is cached, and it is read again when the
+// modification time or the size of the file changes. The content type is
+// set based on the extension of the file, or detected from the content.
+// The responses with status 200 contain the Last-Modified header, and the
+// requests with a matching If-Modified-Since header are answered with 304
+// Not Modified.
+func NewServeFile() filters.Spec { return &serveFileSpec{} }
+
+func (*serveFileSpec) Name() string { return filters.ServeFileName }
+
+func (*serveFileSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	path, ok := args[0].(string)
+	if !ok || path == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &serveFile{path: path, statusCode: http.StatusOK}
+	if len(args) == 2 {
+		code, ok := args[1].(float64)
+		if !ok || code < 200 || code > 599 || code != float64(int(code)) {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.statusCode = int(code)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("serve file: %s is a directory", path)
+	}
+
+	return f, nil
+}
+
+// load returns the cached content of the file, or reads it again when
+// it was changed since the last read.
+func (f *serveFile) load() (*servedFile, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.cached != nil && f.cached.modTime.Equal(info.ModTime()) && f.cached.size == info.Size() {
+		return f.cached, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(f.path))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	f.cached = &servedFile{
+		content:     content,
+		contentType: contentType,
+		modTime:     info.ModTime(),
+		size:        info.Size(),
+	}
+
+	return f.cached, nil
+}
+
+func notModified(r *http.Request, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// the header has a precision of seconds
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+func (f *serveFile) Request(ctx filters.FilterContext) {
+	file, err := f.load()
+	if err != nil {
+		log.Errorf("Failed to serve file %s: %v", f.path, err)
+		status := http.StatusInternalServerError
+		if os.IsNotExist(err) {
+			status = http.StatusNotFound
+		}
+
+		ctx.Serve(&http.Response{StatusCode: status})
+		return
+	}
+
+	header := make(http.Header)
+	if f.statusCode == http.StatusOK {
+		header.Set("Last-Modified", file.modTime.UTC().Format(http.TimeFormat))
+		if notModified(ctx.Request(), file.modTime) {
+			ctx.Serve(&http.Response{StatusCode: http.StatusNotModified, Header: header})
+			return
+		}
+	}
+
+	header.Set("Content-Type", file.contentType)
+	header.Set("Content-Length", strconv.Itoa(len(file.content)))
+	ctx.Serve(&http.Response{
+		StatusCode:    f.statusCode,
+		Header:        header,
+		ContentLength: int64(len(file.content)),
+		Body:          io.NopCloser(bytes.NewReader(file.content)),
+	})
+}
+
+func (*serveFile) Response(filters.FilterContext) {}
@@ -0,0 +1,227 @@
+package builtin
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestServeFileArgs(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "robots.txt")
+	if err := os.WriteFile(file, []byte("User-agent: *\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []interface{}
+		fail bool
+	}{{
+		name: "no args",
+		fail: true,
+	}, {
+		name: "too many args",
+		args: []interface{}{file, 200.0, "foo"},
+		fail: true,
+	}, {
+		name: "invalid path type",
+		args: []interface{}{42.0},
+		fail: true,
+	}, {
+		name: "missing file",
+		args: []interface{}{filepath.Join(dir, "missing.txt")},
+		fail: true,
+	}, {
+		name: "directory",
+		args: []interface{}{dir},
+		fail: true,
+	}, {
+		name: "invalid status type",
+		args: []interface{}{file, "503"},
+		fail: true,
+	}, {
+		name: "invalid status",
+		args: []interface{}{file, 600.0},
+		fail: true,
+	}, {
+		name: "file",
+		args: []interface{}{file},
+	}, {
+		name: "file with status",
+		args: []interface{}{file, 503.0},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServeFile().CreateFilter(tt.args)
+			if tt.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !tt.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "maintenance.html")
+	if err := os.WriteFile(page, []byte("<h1>Down for maintenance</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(page, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(t *testing.T, args []interface{}, header http.Header) (*http.Response, string) {
+		f, err := NewServeFile().CreateFilter(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("GET", "https://www.example.org/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		ctx := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+		f.Request(ctx)
+		if !ctx.FServed {
+			t.Fatal("the request was not served")
+		}
+
+		var body []byte
+		if ctx.FResponse.Body != nil {
+			body, _ = io.ReadAll(ctx.FResponse.Body)
+		}
+
+		return ctx.FResponse, string(body)
+	}
+
+	t.Run("status and content type", func(t *testing.T) {
+		rsp, body := request(t, []interface{}{page, 503.0}, nil)
+		if rsp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("unexpected status code: %d", rsp.StatusCode)
+		}
+
+		if ct := rsp.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("unexpected content type: %s", ct)
+		}
+
+		if body != "<h1>Down for maintenance</h1>" {
+			t.Errorf("unexpected body: %s", body)
+		}
+
+		if rsp.Header.Get("Last-Modified") != "" {
+			t.Error("unexpected Last-Modified header for non-200 response")
+		}
+	})
+
+	t.Run("content type detected", func(t *testing.T) {
+		noExt := filepath.Join(dir, "page")
+		if err := os.WriteFile(noExt, []byte("plain text"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		rsp, _ := request(t, []interface{}{noExt}, nil)
+		if ct := rsp.Header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+			t.Errorf("unexpected content type: %s", ct)
+		}
+	})
+
+	t.Run("last modified", func(t *testing.T) {
+		rsp, _ := request(t, []interface{}{page}, nil)
+		if rsp.StatusCode != http.StatusOK {
+			t.Errorf("unexpected status code: %d", rsp.StatusCode)
+		}
+
+		if lm := rsp.Header.Get("Last-Modified"); lm != modTime.Format(http.TimeFormat) {
+			t.Errorf("unexpected Last-Modified header: %s", lm)
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		rsp, body := request(t, []interface{}{page}, http.Header{
+			"If-Modified-Since": []string{modTime.Format(http.TimeFormat)},
+		})
+
+		if rsp.StatusCode != http.StatusNotModified {
+			t.Errorf("unexpected status code: %d", rsp.StatusCode)
+		}
+
+		if body != "" {
+			t.Errorf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("modified since", func(t *testing.T) {
+		rsp, _ := request(t, []interface{}{page}, http.Header{
+			"If-Modified-Since": []string{modTime.Add(-time.Hour).Format(http.TimeFormat)},
+		})
+
+		if rsp.StatusCode != http.StatusOK {
+			t.Errorf("unexpected status code: %d", rsp.StatusCode)
+		}
+	})
+
+	t.Run("if modified since ignored for non-200", func(t *testing.T) {
+		rsp, _ := request(t, []interface{}{page, 503.0}, http.Header{
+			"If-Modified-Since": []string{modTime.Format(http.TimeFormat)},
+		})
+
+		if rsp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("unexpected status code: %d", rsp.StatusCode)
+		}
+	})
+
+	t.Run("reloads changed file", func(t *testing.T) {
+		file := filepath.Join(dir, "robots.txt")
+		if err := os.WriteFile(file, []byte("v1"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := NewServeFile().CreateFilter([]interface{}{file})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		get := func() string {
+			ctx := &filtertest.Context{FRequest: &http.Request{Method: "GET", Header: make(http.Header)}}
+			f.Request(ctx)
+			b, _ := io.ReadAll(ctx.FResponse.Body)
+			return string(b)
+		}
+
+		if b := get(); b != "v1" {
+			t.Errorf("unexpected body: %s", b)
+		}
+
+		if err := os.WriteFile(file, []byte("v2, changed"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if b := get(); b != "v2, changed" {
+			t.Errorf("unexpected body: %s", b)
+		}
+
+		if err := os.Remove(file); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := &filtertest.Context{FRequest: &http.Request{Method: "GET", Header: make(http.Header)}}
+		f.Request(ctx)
+		if ctx.FResponse.StatusCode != http.StatusNotFound {
+			t.Errorf("unexpected status code for removed file: %d", ctx.FResponse.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package builtin
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// header names and glob patterns
+type headerGlobs struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+type stripResponseHeaders struct {
+	headerGlobs
+}
+
+type sanitizeTrustedHeaders struct {
+	headerGlobs
+}
+
+// NewStripResponseHeaders creates a filter spec for the stripResponseHeaders()
+// filter, that removes the response headers matching any of the arguments.
+// The arguments are header names or glob patterns, where * matches any
+// sequence of characters and ? matches a single character. The matching is
+// case insensitive.
+//
+//     * -> stripResponseHeaders("X-Internal-*", "Server") -> "https://www.example.org"
+//
+func NewStripResponseHeaders() filters.Spec {
+	return &stripResponseHeaders{}
+}
+
+func (*stripResponseHeaders) Name() string { return filters.StripResponseHeadersName }
+
+// compiles a glob pattern to a case insensitive regular expression
+func compileHeaderGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func parseHeaderGlobs(args []interface{}) (headerGlobs, error) {
+	var g headerGlobs
+	if len(args) == 0 {
+		return g, filters.ErrInvalidFilterParameters
+	}
+
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok || s == "" {
+			return g, filters.ErrInvalidFilterParameters
+		}
+
+		if !strings.ContainsAny(s, "*?") {
+			g.names = append(g.names, http.CanonicalHeaderKey(s))
+			continue
+		}
+
+		rx, err := compileHeaderGlob(s)
+		if err != nil {
+			return g, filters.ErrInvalidFilterParameters
+		}
+
+		g.patterns = append(g.patterns, rx)
+	}
+
+	return g, nil
+}
+
+// removes the headers matching any of the names or the patterns
+func (g *headerGlobs) strip(h http.Header) {
+	for _, n := range g.names {
+		h.Del(n)
+	}
+
+	if len(g.patterns) == 0 {
+		return
+	}
+
+	for name := range h {
+		for _, rx := range g.patterns {
+			if rx.MatchString(name) {
+				delete(h, name)
+				break
+			}
+		}
+	}
+}
+
+func (*stripResponseHeaders) CreateFilter(args []interface{}) (filters.Filter, error) {
+	g, err := parseHeaderGlobs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stripResponseHeaders{headerGlobs: g}, nil
+}
+
+func (*stripResponseHeaders) Request(filters.FilterContext) {}
+
+func (f *stripResponseHeaders) Response(ctx filters.FilterContext) {
+	f.strip(ctx.Response().Header)
+}
+
+// NewSanitizeTrustedHeaders creates a filter spec for the
+// sanitizeTrustedHeaders() filter, that removes the request headers matching
+// any of the arguments, so that the clients cannot spoof the headers that the
+// backends trust, e.g. the ones set by other filters of the route. The
+// arguments are header names or glob patterns, like in stripResponseHeaders().
+// The filter should be the first one in the filter chain.
+//
+//     * -> sanitizeTrustedHeaders("X-User-*", "X-Internal-*") -> jwtClaimsToHeaders("sub=X-User-Id") -> "https://www.example.org"
+//
+func NewSanitizeTrustedHeaders() filters.Spec {
+	return &sanitizeTrustedHeaders{}
+}
+
+func (*sanitizeTrustedHeaders) Name() string { return filters.SanitizeTrustedHeadersName }
+
+func (*sanitizeTrustedHeaders) CreateFilter(args []interface{}) (filters.Filter, error) {
+	g, err := parseHeaderGlobs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sanitizeTrustedHeaders{headerGlobs: g}, nil
+}
+
+func (f *sanitizeTrustedHeaders) Request(ctx filters.FilterContext) {
+	f.strip(ctx.Request().Header)
+}
+
+func (*sanitizeTrustedHeaders) Response(filters.FilterContext) {}
@@ -0,0 +1,119 @@
+package builtin
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestStripResponseHeadersArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{""}, err: true},
+		{args: []interface{}{"Server"}},
+		{args: []interface{}{"X-Internal-*", "Server", "X-?-Debug"}},
+	} {
+		_, err := NewStripResponseHeaders().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestStripResponseHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		msg      string
+		args     []interface{}
+		header   http.Header
+		expected http.Header
+	}{{
+		msg:      "exact name",
+		args:     []interface{}{"server"},
+		header:   http.Header{"Server": {"nginx"}, "Content-Type": {"text/plain"}},
+		expected: http.Header{"Content-Type": {"text/plain"}},
+	}, {
+		msg:  "glob pattern",
+		args: []interface{}{"X-Internal-*"},
+		header: http.Header{
+			"X-Internal-Trace": {"abc"},
+			"X-Internal-Host":  {"10.0.0.1"},
+			"X-Internal":       {"keep"},
+			"X-Request-Id":     {"42"},
+		},
+		expected: http.Header{"X-Internal": {"keep"}, "X-Request-Id": {"42"}},
+	}, {
+		msg:      "single character wildcard, case insensitive",
+		args:     []interface{}{"x-?-debug"},
+		header:   http.Header{"X-A-Debug": {"1"}, "X-Ab-Debug": {"2"}},
+		expected: http.Header{"X-Ab-Debug": {"2"}},
+	}, {
+		msg:      "regexp characters are literal",
+		args:     []interface{}{"X.Foo*"},
+		header:   http.Header{"Xafoo": {"1"}, "X.foo-Bar": {"2"}},
+		expected: http.Header{"Xafoo": {"1"}},
+	}, {
+		msg:      "names and patterns",
+		args:     []interface{}{"Server", "X-Internal-*"},
+		header:   http.Header{"Server": {"nginx"}, "X-Internal-Trace": {"abc"}, "Date": {"today"}},
+		expected: http.Header{"Date": {"today"}},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewStripResponseHeaders().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp := &http.Response{Header: tc.header}
+			f.Response(&filtertest.Context{FResponse: rsp})
+			if !reflect.DeepEqual(rsp.Header, tc.expected) {
+				t.Errorf("unexpected headers: %v, expected: %v", rsp.Header, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSanitizeTrustedHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		msg      string
+		args     []interface{}
+		header   http.Header
+		expected http.Header
+	}{{
+		msg:  "names and patterns",
+		args: []interface{}{"x-forwarded-for", "X-User-*", "X-Internal-*"},
+		header: http.Header{
+			"X-Forwarded-For": {"10.0.0.1"},
+			"X-User-Id":       {"admin"},
+			"X-User-Tenant":   {"acme"},
+			"X-Internal-Role": {"root"},
+			"X-Request-Id":    {"42"},
+		},
+		expected: http.Header{"X-Request-Id": {"42"}},
+	}, {
+		msg:      "no matching headers",
+		args:     []interface{}{"X-User-*"},
+		header:   http.Header{"Accept": {"text/plain"}},
+		expected: http.Header{"Accept": {"text/plain"}},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewSanitizeTrustedHeaders().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &http.Request{Header: tc.header}
+			f.Request(&filtertest.Context{FRequest: req})
+			if !reflect.DeepEqual(req.Header, tc.expected) {
+				t.Errorf("unexpected headers: %v, expected: %v", req.Header, tc.expected)
+			}
+		})
+	}
+}
@@ -8,20 +8,42 @@ import (
 
 type timeout struct {
 	timeout time.Duration
+	request bool
 }
 
 func NewBackendTimeout() filters.Spec {
 	return &timeout{}
 }
 
-func (*timeout) Name() string { return filters.BackendTimeoutName }
+// NewRequestTimeout creates a filter specification, whose instances set
+// a deadline for handling the request, counted from the start of serving
+// the request. It includes the time spent in the filters, e.g. waiting in
+// the scheduler queues, and the backend request with the retries. When
+// the deadline is exceeded, the proxy cancels the backend request and
+// responds with 504 Gateway Timeout.
+//
+//     * -> requestTimeout("2s") -> "https://www.example.org"
+//
+// When used multiple times, e.g. in loopback routes, the shortest
+// deadline applies.
+func NewRequestTimeout() filters.Spec {
+	return &timeout{request: true}
+}
+
+func (t *timeout) Name() string {
+	if t.request {
+		return filters.RequestTimeoutName
+	}
 
-func (*timeout) CreateFilter(args []interface{}) (filters.Filter, error) {
+	return filters.BackendTimeoutName
+}
+
+func (t *timeout) CreateFilter(args []interface{}) (filters.Filter, error) {
 	if len(args) != 1 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
-	var tf timeout
+	tf := timeout{request: t.request}
 	switch v := args[0].(type) {
 	case string:
 		d, err := time.ParseDuration(v)
@@ -38,6 +60,11 @@ func (*timeout) CreateFilter(args []interface{}) (filters.Filter, error) {
 }
 
 func (t *timeout) Request(ctx filters.FilterContext) {
+	if t.request {
+		ctx.StateBag()[filters.RequestTimeout] = t.timeout
+		return
+	}
+
 	// allows overwrite
 	ctx.StateBag()[filters.BackendTimeout] = t.timeout
 }
@@ -35,3 +35,26 @@ func TestBackendTimeout(t *testing.T) {
 		t.Error("overwrite expected")
 	}
 }
+
+func TestRequestTimeout(t *testing.T) {
+	rt := NewRequestTimeout()
+	if rt.Name() != filters.RequestTimeoutName {
+		t.Error("wrong name")
+	}
+
+	f, err := rt.CreateFilter([]interface{}{"2s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &filtertest.Context{FRequest: &http.Request{}, FStateBag: make(map[string]interface{})}
+	f.Request(c)
+
+	if c.FStateBag[filters.RequestTimeout] != 2*time.Second {
+		t.Error("wrong timeout")
+	}
+
+	if _, ok := c.FStateBag[filters.BackendTimeout]; ok {
+		t.Error("unexpected backend timeout")
+	}
+}
@@ -43,8 +43,22 @@ func getIntArg(a interface{}) (int, error) {
 	return 0, filters.ErrInvalidFilterParameters
 }
 
+// getOptionalIntArg accepts an empty string, too, meaning the default.
+func getOptionalIntArg(a interface{}) (int, error) {
+	if s, ok := a.(string); ok && s == "" {
+		return 0, nil
+	}
+
+	return getIntArg(a)
+}
+
 func getDurationArg(a interface{}) (time.Duration, error) {
 	if s, ok := a.(string); ok {
+		// an empty string means the default
+		if s == "" {
+			return 0, nil
+		}
+
 		return time.ParseDuration(s)
 	}
 
@@ -52,6 +66,19 @@ func getDurationArg(a interface{}) (time.Duration, error) {
 	return time.Duration(i) * time.Millisecond, err
 }
 
+func getStatusCodesArg(a interface{}) (string, error) {
+	s, ok := a.(string)
+	if !ok {
+		return "", filters.ErrInvalidFilterParameters
+	}
+
+	if s == "" {
+		return "", nil
+	}
+
+	return circuit.ParseStatusCodes(s)
+}
+
 // NewConsecutiveBreaker creates a filter specification to instantiate consecutiveBreaker() filters.
 //
 // These filters set a breaker for the current route that open if the backend failures for the route reach a
@@ -60,7 +87,11 @@ func getDurationArg(a interface{}) (time.Duration, error) {
 // 	consecutiveBreaker(15)
 //
 // The filter accepts the following optional arguments: timeout (milliseconds or duration string),
-// half-open-requests (integer), idle-ttl (milliseconds or duration string).
+// half-open-requests (integer), idle-ttl (milliseconds or duration string), failure status codes (comma
+// separated list of status codes that count as failures instead of the default 5xx). Empty strings can be
+// used to keep the default values of the optional arguments:
+//
+// 	consecutiveBreaker(5, "", "", "", "429,503")
 func NewConsecutiveBreaker() filters.Spec {
 	return &spec{typ: circuit.ConsecutiveFailures}
 }
@@ -73,7 +104,8 @@ func NewConsecutiveBreaker() filters.Spec {
 // 	rateBreaker(30, 300)
 //
 // The filter accepts the following optional arguments: timeout (milliseconds or duration string),
-// half-open-requests (integer), idle-ttl (milliseconds or duration string).
+// half-open-requests (integer), idle-ttl (milliseconds or duration string), failure status codes (comma
+// separated list of status codes that count as failures instead of the default 5xx).
 func NewRateBreaker() filters.Spec {
 	return &spec{typ: circuit.FailureRate}
 }
@@ -95,7 +127,7 @@ func (s *spec) Name() string {
 }
 
 func consecutiveFilter(args []interface{}) (filters.Filter, error) {
-	if len(args) == 0 || len(args) > 4 {
+	if len(args) == 0 || len(args) > 5 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
@@ -114,7 +146,7 @@ func consecutiveFilter(args []interface{}) (filters.Filter, error) {
 
 	var halfOpenRequests int
 	if len(args) > 2 {
-		halfOpenRequests, err = getIntArg(args[2])
+		halfOpenRequests, err = getOptionalIntArg(args[2])
 		if err != nil {
 			return nil, err
 		}
@@ -128,19 +160,28 @@ func consecutiveFilter(args []interface{}) (filters.Filter, error) {
 		}
 	}
 
+	var failureStatusCodes string
+	if len(args) > 4 {
+		failureStatusCodes, err = getStatusCodesArg(args[4])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &filter{
 		settings: circuit.BreakerSettings{
-			Type:             circuit.ConsecutiveFailures,
-			Failures:         failures,
-			Timeout:          timeout,
-			HalfOpenRequests: halfOpenRequests,
-			IdleTTL:          idleTTL,
+			Type:               circuit.ConsecutiveFailures,
+			Failures:           failures,
+			Timeout:            timeout,
+			HalfOpenRequests:   halfOpenRequests,
+			IdleTTL:            idleTTL,
+			FailureStatusCodes: failureStatusCodes,
 		},
 	}, nil
 }
 
 func rateFilter(args []interface{}) (filters.Filter, error) {
-	if len(args) < 2 || len(args) > 5 {
+	if len(args) < 2 || len(args) > 6 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
@@ -164,7 +205,7 @@ func rateFilter(args []interface{}) (filters.Filter, error) {
 
 	var halfOpenRequests int
 	if len(args) > 3 {
-		halfOpenRequests, err = getIntArg(args[3])
+		halfOpenRequests, err = getOptionalIntArg(args[3])
 		if err != nil {
 			return nil, err
 		}
@@ -178,14 +219,23 @@ func rateFilter(args []interface{}) (filters.Filter, error) {
 		}
 	}
 
+	var failureStatusCodes string
+	if len(args) > 5 {
+		failureStatusCodes, err = getStatusCodesArg(args[5])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &filter{
 		settings: circuit.BreakerSettings{
-			Type:             circuit.FailureRate,
-			Failures:         failures,
-			Window:           window,
-			Timeout:          timeout,
-			HalfOpenRequests: halfOpenRequests,
-			IdleTTL:          idleTTL,
+			Type:               circuit.FailureRate,
+			Failures:           failures,
+			Window:             window,
+			Timeout:            timeout,
+			HalfOpenRequests:   halfOpenRequests,
+			IdleTTL:            idleTTL,
+			FailureStatusCodes: failureStatusCodes,
 		},
 	}, nil
 }
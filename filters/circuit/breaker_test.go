@@ -26,7 +26,7 @@ func TestArgs(t *testing.T) {
 	t.Run("consecutive", func(t *testing.T) {
 		s := NewConsecutiveBreaker()
 		t.Run("missing", testErr(s, nil))
-		t.Run("too many", testErr(s, 6, "1m", 12, "30m", 42))
+		t.Run("too many", testErr(s, 6, "1m", 12, "30m", "429", 42))
 		t.Run("wrong failure count", testErr(s, "6", "1m", 12))
 		t.Run("wrong timeout", testErr(s, 6, "foo", 12))
 		t.Run("wrong half-open requests", testErr(s, 6, "1m", "foo"))
@@ -35,13 +35,17 @@ func TestArgs(t *testing.T) {
 		t.Run("full", testOK(s, 6, "1m", 12))
 		t.Run("timeout as milliseconds", testOK(s, 6, 60000, 12))
 		t.Run("with idle ttl", testOK(s, 6, 60000, 12, "30m"))
+		t.Run("with failure status codes", testOK(s, 6, 60000, 12, "30m", "429,503"))
+		t.Run("with defaults and failure status codes", testOK(s, 6, "", "", "", "429,503"))
+		t.Run("wrong failure status codes", testErr(s, 6, "", "", "", "429,foo"))
+		t.Run("failure status codes out of range", testErr(s, 6, "", "", "", "42"))
 	})
 
 	t.Run("rate", func(t *testing.T) {
 		s := NewRateBreaker()
 		t.Run("missing both", testErr(s, nil))
 		t.Run("missing window", testErr(s, 30))
-		t.Run("too many", testErr(s, 30, 300, "1m", 45, "30m", 42))
+		t.Run("too many", testErr(s, 30, 300, "1m", 45, "30m", "429", 42))
 		t.Run("wrong failure count", testErr(s, "30", 300, "1m", 45))
 		t.Run("wrong window", testErr(s, 30, "300", "1m", 45))
 		t.Run("wrong timeout", testErr(s, 30, "300", "foo", 45))
@@ -51,6 +55,8 @@ func TestArgs(t *testing.T) {
 		t.Run("full", testOK(s, 30, 300, "1m", 45))
 		t.Run("timeout as milliseconds", testOK(s, 30, 300, 60000, 45))
 		t.Run("with idle ttl", testOK(s, 30, 300, 60000, 12, "30m"))
+		t.Run("with failure status codes", testOK(s, 30, 300, "", "", "", "429"))
+		t.Run("wrong failure status codes", testErr(s, 30, 300, "", "", "", 429))
 	})
 
 	t.Run("disable", func(t *testing.T) {
@@ -121,6 +127,20 @@ func TestBreaker(t *testing.T) {
 		12,
 	))
 
+	t.Run("consecutive breaker with failure status codes", test(
+		NewConsecutiveBreaker,
+		circuit.BreakerSettings{
+			Type:               circuit.ConsecutiveFailures,
+			Failures:           5,
+			FailureStatusCodes: "429,503",
+		},
+		5,
+		"",
+		"",
+		"",
+		"503, 429",
+	))
+
 	t.Run("disable breaker", test(
 		NewDisableBreaker,
 		circuit.BreakerSettings{
@@ -21,6 +21,9 @@ The JS cookie behaves exactly as the response cookie, but it doesn't
 set the HttpOnly directive, so these cookies will be
 accessible from JS code running in web browsers.
 
+The setCookie filter appends a cookie to responses, with all the
+attributes of the cookie set explicitly by the arguments.
+
 Examples:
 
     requestCookie("test-session", "abc")
@@ -31,6 +34,8 @@ Examples:
 
     // response cookie without HttpOnly:
     jsCookie("test-session-info", "abc-debug", 31536000, "change-only")
+
+    setCookie("test-session", "abc", "path=/", "maxAge=3600", "httpOnly", "secure", "sameSite=Lax")
 */
 package cookie
 
@@ -0,0 +1,190 @@
+package cookie
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	setCookieSpec struct{}
+
+	setCookieFilter struct {
+		header string
+	}
+)
+
+// NewSetCookie creates a filter spec for appending cookies to responses,
+// with the attributes of the cookie set explicitly. It expects the name
+// and the value of the cookie, followed by any number of attributes:
+//
+//    setCookie("session", "abc", "path=/", "maxAge=3600", "httpOnly", "secure", "sameSite=Lax")
+//
+// The supported attributes are domain=<domain>, path=<path>,
+// maxAge=<seconds>, expires=<HTTP date>, httpOnly, secure and
+// sameSite=Strict|Lax|None. The attribute names are case insensitive.
+// Unknown or repeated attributes and invalid values are rejected when
+// the filter is created. maxAge=0 expires the cookie immediately. The
+// Set-Cookie header is appended to the response, the existing Set-Cookie
+// headers are preserved.
+//
+// Name: setCookie
+func NewSetCookie() filters.Spec { return setCookieSpec{} }
+
+func (setCookieSpec) Name() string { return filters.SetCookieName }
+
+func validCookieValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		b := v[i]
+		if b <= ' ' || b >= 0x7f || b == '"' || b == ',' || b == ';' || b == '\\' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validAttributeValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		if b := v[i]; b < ' ' || b >= 0x7f || b == ';' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseCookieAttribute(c *http.Cookie, attribute string) (string, error) {
+	name, value, hasValue := attribute, "", false
+	if i := strings.IndexByte(attribute, '='); i >= 0 {
+		name, value, hasValue = attribute[:i], attribute[i+1:], true
+	}
+
+	key := strings.ToLower(name)
+	switch key {
+	case "httponly":
+		if hasValue {
+			return "", fmt.Errorf("cookie attribute %s does not accept a value", name)
+		}
+
+		c.HttpOnly = true
+		return key, nil
+	case "secure":
+		if hasValue {
+			return "", fmt.Errorf("cookie attribute %s does not accept a value", name)
+		}
+
+		c.Secure = true
+		return key, nil
+	}
+
+	if value == "" || !validAttributeValue(value) {
+		return "", fmt.Errorf("invalid value of cookie attribute %s: %q", name, value)
+	}
+
+	switch key {
+	case "domain":
+		if strings.ContainsAny(value, " /:") {
+			return "", fmt.Errorf("invalid cookie domain: %q", value)
+		}
+
+		c.Domain = value
+	case "path":
+		if !strings.HasPrefix(value, "/") {
+			return "", fmt.Errorf("invalid cookie path: %q", value)
+		}
+
+		c.Path = value
+	case "maxage":
+		maxAge, err := strconv.Atoi(value)
+		if err != nil || maxAge < 0 {
+			return "", fmt.Errorf("invalid cookie max age: %q", value)
+		}
+
+		// in http.Cookie, 0 means unspecified, and a negative
+		// value means Max-Age=0
+		if maxAge == 0 {
+			maxAge = -1
+		}
+
+		c.MaxAge = maxAge
+	case "expires":
+		t, err := http.ParseTime(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid cookie expires: %q", value)
+		}
+
+		c.Expires = t
+	case "samesite":
+		switch strings.ToLower(value) {
+		case "strict":
+			c.SameSite = http.SameSiteStrictMode
+		case "lax":
+			c.SameSite = http.SameSiteLaxMode
+		case "none":
+			c.SameSite = http.SameSiteNoneMode
+		default:
+			return "", fmt.Errorf("invalid cookie same site mode: %q", value)
+		}
+	default:
+		return "", fmt.Errorf("unknown cookie attribute: %q", name)
+	}
+
+	return key, nil
+}
+
+func (setCookieSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var sargs []string
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		sargs = append(sargs, s)
+	}
+
+	c := &http.Cookie{Name: sargs[0], Value: sargs[1]}
+	if !validCookieValue(c.Value) {
+		return nil, fmt.Errorf("invalid cookie value: %q", c.Value)
+	}
+
+	seen := make(map[string]bool)
+	for _, a := range sargs[2:] {
+		key, err := parseCookieAttribute(c, a)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate cookie attribute: %q", a)
+		}
+
+		seen[key] = true
+	}
+
+	if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+		return nil, fmt.Errorf("cookie with sameSite=None requires the secure attribute")
+	}
+
+	// http.Cookie.String returns an empty string for invalid names
+	header := c.String()
+	if header == "" {
+		return nil, fmt.Errorf("invalid cookie name: %q", c.Name)
+	}
+
+	return &setCookieFilter{header: header}, nil
+}
+
+func (*setCookieFilter) Request(filters.FilterContext) {}
+
+func (f *setCookieFilter) Response(ctx filters.FilterContext) {
+	ctx.Response().Header.Add(SetCookieHttpHeader, f.header)
+}
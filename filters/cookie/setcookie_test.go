@@ -0,0 +1,160 @@
+package cookie
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestSetCookieFilterArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+	}{{
+		"no args",
+		nil,
+	}, {
+		"no value",
+		[]interface{}{"session"},
+	}, {
+		"invalid name type",
+		[]interface{}{3.14, "abc"},
+	}, {
+		"empty name",
+		[]interface{}{"", "abc"},
+	}, {
+		"invalid name",
+		[]interface{}{"sess ion", "abc"},
+	}, {
+		"invalid value",
+		[]interface{}{"session", "a;b"},
+	}, {
+		"invalid attribute type",
+		[]interface{}{"session", "abc", 3600.0},
+	}, {
+		"unknown attribute",
+		[]interface{}{"session", "abc", "maxage-seconds=3600"},
+	}, {
+		"unknown flag",
+		[]interface{}{"session", "abc", "partitioned"},
+	}, {
+		"duplicate attribute",
+		[]interface{}{"session", "abc", "path=/", "Path=/foo"},
+	}, {
+		"flag with value",
+		[]interface{}{"session", "abc", "secure=true"},
+	}, {
+		"empty attribute value",
+		[]interface{}{"session", "abc", "path="},
+	}, {
+		"attribute value with semicolon",
+		[]interface{}{"session", "abc", "path=/foo;secure"},
+	}, {
+		"relative path",
+		[]interface{}{"session", "abc", "path=foo"},
+	}, {
+		"invalid domain",
+		[]interface{}{"session", "abc", "domain=example.org:9090"},
+	}, {
+		"invalid max age",
+		[]interface{}{"session", "abc", "maxAge=1h"},
+	}, {
+		"negative max age",
+		[]interface{}{"session", "abc", "maxAge=-1"},
+	}, {
+		"invalid expires",
+		[]interface{}{"session", "abc", "expires=tomorrow"},
+	}, {
+		"invalid same site",
+		[]interface{}{"session", "abc", "sameSite=Loose"},
+	}, {
+		"same site none without secure",
+		[]interface{}{"session", "abc", "sameSite=None"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			if _, err := NewSetCookie().CreateFilter(ti.args); err == nil {
+				t.Error("failed to fail")
+			}
+		})
+	}
+}
+
+func TestSetCookieFilter(t *testing.T) {
+	for _, ti := range []struct {
+		msg      string
+		args     []interface{}
+		existing []string
+		expected []string
+	}{{
+		"name and value only",
+		[]interface{}{"session", "abc"},
+		nil,
+		[]string{"session=abc"},
+	}, {
+		"empty value",
+		[]interface{}{"session", ""},
+		nil,
+		[]string{"session="},
+	}, {
+		"all attributes",
+		[]interface{}{
+			"session",
+			"abc",
+			"domain=example.org",
+			"path=/",
+			"maxAge=3600",
+			"expires=Sun, 06 Nov 1994 08:49:37 GMT",
+			"httpOnly",
+			"secure",
+			"sameSite=Lax",
+		},
+		nil,
+		[]string{"session=abc; Path=/; Domain=example.org; Expires=Sun, 06 Nov 1994 08:49:37 GMT; Max-Age=3600; HttpOnly; Secure; SameSite=Lax"},
+	}, {
+		"case insensitive attribute names",
+		[]interface{}{"session", "abc", "MAXAGE=60", "HttpOnly", "samesite=strict"},
+		nil,
+		[]string{"session=abc; Max-Age=60; HttpOnly; SameSite=Strict"},
+	}, {
+		"zero max age expires the cookie",
+		[]interface{}{"session", "", "path=/", "maxAge=0"},
+		nil,
+		[]string{"session=; Path=/; Max-Age=0"},
+	}, {
+		"same site none",
+		[]interface{}{"session", "abc", "secure", "sameSite=None"},
+		nil,
+		[]string{"session=abc; Secure; SameSite=None"},
+	}, {
+		"appends to existing cookies",
+		[]interface{}{"session", "abc", "path=/"},
+		[]string{"tracking=xyz"},
+		[]string{"tracking=xyz", "session=abc; Path=/"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewSetCookie().CreateFilter(ti.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rsp := &http.Response{Header: http.Header{}}
+			for _, c := range ti.existing {
+				rsp.Header.Add("Set-Cookie", c)
+			}
+
+			ctx := &filtertest.Context{
+				FRequest:  &http.Request{},
+				FResponse: rsp,
+			}
+
+			f.Request(ctx)
+			f.Response(ctx)
+
+			if got := rsp.Header["Set-Cookie"]; !reflect.DeepEqual(got, ti.expected) {
+				t.Errorf("unexpected Set-Cookie headers, expected: %v, got: %v", ti.expected, got)
+			}
+		})
+	}
+}
@@ -1,5 +1,6 @@
 /*
-Package cors implements the origin header for CORS.
+Package cors implements the origin header for CORS, and the complete CORS
+handling including the preflight requests.
 
 How It Works
 
@@ -12,5 +13,32 @@ Usage
 	corsOrigin()
 	corsOrigin("https://www.example.org")
 	corsOrigin("https://www.example.org", "http://localhost:9001")
+
+CORS
+
+The cors filter answers the preflight requests, the OPTIONS requests with the
+Access-Control-Request-Method header, without calling the backend, and sets
+the CORS headers of the actual responses. It accepts options in the key=value
+format:
+
+	origin         allowed origin, can be repeated. It can be an exact origin,
+	               * to allow any origin, an origin with wildcards like
+	               https://*.example.org, or a regular expression between
+	               slashes
+	methods        comma separated list of the allowed methods, defaults to
+	               GET, HEAD, POST
+	headers        comma separated list of the allowed request headers, or *
+	               to allow the headers requested by the preflight request
+	exposeHeaders  comma separated list of the exposed response headers
+	credentials    true to allow credentials, it cannot be combined with
+	               origin=*
+	maxAge         number of seconds the preflight response can be cached
+
+The allowed origin is echoed in the response, instead of *, when credentials
+are allowed. The preflight requests from the not allowed origins are rejected
+with 403 Forbidden.
+
+	cors("origin=https://www.example.org", "methods=GET,PUT", "headers=Content-Type", "maxAge=600")
+	cors("origin=https://*.example.org", "origin=/^http://localhost:[0-9]+$/", "credentials=true")
 */
 package cors
@@ -0,0 +1,255 @@
+package cors
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	allowMethodsHeader     = "Access-Control-Allow-Methods"
+	allowHeadersHeader     = "Access-Control-Allow-Headers"
+	allowCredentialsHeader = "Access-Control-Allow-Credentials"
+	exposeHeadersHeader    = "Access-Control-Expose-Headers"
+	maxAgeHeader           = "Access-Control-Max-Age"
+	requestMethodHeader    = "Access-Control-Request-Method"
+	requestHeadersHeader   = "Access-Control-Request-Headers"
+
+	defaultAllowMethods = "GET, HEAD, POST"
+)
+
+type (
+	corsSpec struct{}
+
+	corsFilter struct {
+		anyOrigin      bool
+		origins        map[string]bool
+		originRxs      []*regexp.Regexp
+		methods        string
+		headers        string
+		reflectHeaders bool
+		exposeHeaders  string
+		credentials    bool
+		maxAge         string
+	}
+)
+
+// NewCORS creates a filter specification, whose instances answer the
+// CORS preflight requests without calling the backend, and set the CORS
+// headers of the actual responses. The filter expects options in the
+// key=value format:
+//
+//    cors("origin=https://www.example.org", "origin=https://*.example.org", "methods=GET,POST", "credentials=true")
+//
+// See the package documentation for the available options.
+func NewCORS() filters.Spec { return &corsSpec{} }
+
+func (*corsSpec) Name() string { return filters.CorsName }
+
+// converts the wildcards of an origin to a regular expression, e.g.
+// https://*.example.org to ^https://[^/]+\.example\.org$
+func wildcardOriginRx(o string) (*regexp.Regexp, error) {
+	parts := strings.Split(o, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+
+	return regexp.Compile("^" + strings.Join(parts, "[^/]+") + "$")
+}
+
+// joins a comma separated list in the canonical format of the headers
+func joinList(s string, canonical bool) string {
+	var l []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if canonical {
+			v = http.CanonicalHeaderKey(v)
+		}
+
+		l = append(l, v)
+	}
+
+	return strings.Join(l, ", ")
+}
+
+func (f *corsFilter) setOption(o string) error {
+	kv := strings.SplitN(o, "=", 2)
+	if len(kv) != 2 || kv[1] == "" {
+		return filters.ErrInvalidFilterParameters
+	}
+
+	switch kv[0] {
+	case "origin":
+		switch v := kv[1]; {
+		case v == "*":
+			f.anyOrigin = true
+		case len(v) > 2 && strings.HasPrefix(v, "/") && strings.HasSuffix(v, "/"):
+			rx, err := regexp.Compile(v[1 : len(v)-1])
+			if err != nil {
+				return filters.ErrInvalidFilterParameters
+			}
+
+			f.originRxs = append(f.originRxs, rx)
+		case strings.Contains(v, "*"):
+			rx, err := wildcardOriginRx(v)
+			if err != nil {
+				return filters.ErrInvalidFilterParameters
+			}
+
+			f.originRxs = append(f.originRxs, rx)
+		default:
+			f.origins[v] = true
+		}
+	case "methods":
+		f.methods = strings.ToUpper(joinList(kv[1], false))
+	case "headers":
+		if kv[1] == "*" {
+			f.reflectHeaders = true
+		} else {
+			f.headers = joinList(kv[1], true)
+		}
+	case "exposeHeaders":
+		f.exposeHeaders = joinList(kv[1], true)
+	case "credentials":
+		c, err := strconv.ParseBool(kv[1])
+		if err != nil {
+			return filters.ErrInvalidFilterParameters
+		}
+
+		f.credentials = c
+	case "maxAge":
+		s, err := strconv.Atoi(kv[1])
+		if err != nil || s < 0 {
+			return filters.ErrInvalidFilterParameters
+		}
+
+		f.maxAge = kv[1]
+	default:
+		return filters.ErrInvalidFilterParameters
+	}
+
+	return nil
+}
+
+func (*corsSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f := &corsFilter{
+		origins: make(map[string]bool),
+		methods: defaultAllowMethods,
+	}
+
+	for _, a := range args {
+		s, ok := a.(string)
+		if !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		if err := f.setOption(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if !f.anyOrigin && len(f.origins) == 0 && len(f.originRxs) == 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	// allowing credentials for any origin would let any site make
+	// credentialed cross-origin requests
+	if f.anyOrigin && f.credentials {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return f, nil
+}
+
+func (f *corsFilter) allowOrigin(origin string) bool {
+	if f.anyOrigin || f.origins[origin] {
+		return true
+	}
+
+	for _, rx := range f.originRxs {
+		if rx.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sets the allowed origin, echoing the origin of the request, unless
+// any origin is allowed
+func (f *corsFilter) setOrigin(h http.Header, origin string) {
+	if f.anyOrigin {
+		h.Set(allowOriginHeader, "*")
+	} else {
+		h.Set(allowOriginHeader, origin)
+		h.Add("Vary", "Origin")
+	}
+
+	if f.credentials {
+		h.Set(allowCredentialsHeader, "true")
+	}
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get(requestMethodHeader) != ""
+}
+
+// Request answers the preflight requests. The preflight requests of
+// the not allowed origins are rejected with 403 Forbidden.
+func (f *corsFilter) Request(ctx filters.FilterContext) {
+	r := ctx.Request()
+	origin := r.Header.Get("Origin")
+	if origin == "" || !isPreflight(r) {
+		return
+	}
+
+	if !f.allowOrigin(origin) {
+		ctx.Serve(&http.Response{StatusCode: http.StatusForbidden})
+		return
+	}
+
+	h := make(http.Header)
+	f.setOrigin(h, origin)
+	h.Add("Vary", requestMethodHeader)
+	h.Add("Vary", requestHeadersHeader)
+	h.Set(allowMethodsHeader, f.methods)
+
+	headers := f.headers
+	if f.reflectHeaders {
+		headers = joinList(r.Header.Get(requestHeadersHeader), true)
+	}
+
+	if headers != "" {
+		h.Set(allowHeadersHeader, headers)
+	}
+
+	if f.maxAge != "" {
+		h.Set(maxAgeHeader, f.maxAge)
+	}
+
+	ctx.Serve(&http.Response{StatusCode: http.StatusNoContent, Header: h})
+}
+
+// Response sets the CORS headers of the actual responses, when the
+// origin of the request is allowed. The preflight responses are
+// already complete.
+func (f *corsFilter) Response(ctx filters.FilterContext) {
+	r := ctx.Request()
+	origin := r.Header.Get("Origin")
+	if origin == "" || isPreflight(r) || !f.allowOrigin(origin) {
+		return
+	}
+
+	h := ctx.Response().Header
+	f.setOrigin(h, origin)
+	if f.exposeHeaders != "" {
+		h.Set(exposeHeadersHeader, f.exposeHeaders)
+	}
+}
@@ -0,0 +1,242 @@
+package cors
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestCORSArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{"methods=GET"}, err: true},
+		{args: []interface{}{"origin="}, err: true},
+		{args: []interface{}{"origin=/[/"}, err: true},
+		{args: []interface{}{"origin=*", "credentials=maybe"}, err: true},
+		{args: []interface{}{"origin=*", "credentials=true"}, err: true},
+		{args: []interface{}{"credentials=true", "origin=*"}, err: true},
+		{args: []interface{}{"origin=*", "maxAge=-1"}, err: true},
+		{args: []interface{}{"origin=*", "foo=bar"}, err: true},
+		{args: []interface{}{"origin=*", 42.0}, err: true},
+		{args: []interface{}{"origin=*"}},
+		{args: []interface{}{"origin=https://*.example.org", "origin=/^http://localhost:[0-9]+$/"}},
+		{args: []interface{}{"origin=https://www.example.org", "methods=GET,PUT", "headers=*", "exposeHeaders=X-Foo", "credentials=true", "maxAge=600"}},
+	} {
+		_, err := NewCORS().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	for _, tc := range []struct {
+		msg            string
+		args           []interface{}
+		method         string
+		header         http.Header
+		expectServed   bool
+		expectedStatus int
+		expectedHeader http.Header
+	}{{
+		msg:    "not a preflight request",
+		args:   []interface{}{"origin=https://www.example.org"},
+		method: "OPTIONS",
+		header: http.Header{"Origin": []string{"https://www.example.org"}},
+	}, {
+		msg:    "no origin",
+		args:   []interface{}{"origin=https://www.example.org"},
+		method: "OPTIONS",
+		header: http.Header{"Access-Control-Request-Method": []string{"PUT"}},
+	}, {
+		msg:    "origin not allowed",
+		args:   []interface{}{"origin=https://www.example.org"},
+		method: "OPTIONS",
+		header: http.Header{
+			"Origin":                        []string{"https://www.example.com"},
+			"Access-Control-Request-Method": []string{"PUT"},
+		},
+		expectServed:   true,
+		expectedStatus: http.StatusForbidden,
+	}, {
+		msg:    "defaults",
+		args:   []interface{}{"origin=*"},
+		method: "OPTIONS",
+		header: http.Header{
+			"Origin":                        []string{"https://www.example.org"},
+			"Access-Control-Request-Method": []string{"POST"},
+		},
+		expectServed:   true,
+		expectedStatus: http.StatusNoContent,
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin":  []string{"*"},
+			"Access-Control-Allow-Methods": []string{"GET, HEAD, POST"},
+			"Vary":                         []string{"Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+	}, {
+		msg: "configured",
+		args: []interface{}{
+			"origin=https://*.example.org",
+			"methods=get, put",
+			"headers=content-type,x-foo",
+			"credentials=true",
+			"maxAge=600",
+		},
+		method: "OPTIONS",
+		header: http.Header{
+			"Origin":                         []string{"https://www.example.org"},
+			"Access-Control-Request-Method":  []string{"PUT"},
+			"Access-Control-Request-Headers": []string{"content-type"},
+		},
+		expectServed:   true,
+		expectedStatus: http.StatusNoContent,
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin":      []string{"https://www.example.org"},
+			"Access-Control-Allow-Credentials": []string{"true"},
+			"Access-Control-Allow-Methods":     []string{"GET, PUT"},
+			"Access-Control-Allow-Headers":     []string{"Content-Type, X-Foo"},
+			"Access-Control-Max-Age":           []string{"600"},
+			"Vary":                             []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+	}, {
+		msg:    "reflected headers",
+		args:   []interface{}{"origin=/^https://[a-z]+[.]example[.]org$/", "headers=*"},
+		method: "OPTIONS",
+		header: http.Header{
+			"Origin":                         []string{"https://api.example.org"},
+			"Access-Control-Request-Method":  []string{"GET"},
+			"Access-Control-Request-Headers": []string{"x-foo, x-bar"},
+		},
+		expectServed:   true,
+		expectedStatus: http.StatusNoContent,
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin":  []string{"https://api.example.org"},
+			"Access-Control-Allow-Methods": []string{"GET, HEAD, POST"},
+			"Access-Control-Allow-Headers": []string{"X-Foo, X-Bar"},
+			"Vary":                         []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewCORS().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{FRequest: &http.Request{Method: tc.method, Header: tc.header}}
+			f.Request(ctx)
+			if ctx.FServed != tc.expectServed {
+				t.Fatalf("unexpected served state, got: %t, expected: %t", ctx.FServed, tc.expectServed)
+			}
+
+			if !tc.expectServed {
+				return
+			}
+
+			rsp := ctx.FResponse
+			if rsp.StatusCode != tc.expectedStatus {
+				t.Errorf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, tc.expectedStatus)
+			}
+
+			for k, v := range tc.expectedHeader {
+				if got := rsp.Header.Values(k); len(got) != len(v) {
+					t.Errorf("unexpected header %s, got: %v, expected: %v", k, got, v)
+				} else {
+					for i := range v {
+						if got[i] != v[i] {
+							t.Errorf("unexpected header %s, got: %v, expected: %v", k, got, v)
+						}
+					}
+				}
+			}
+
+			f.Response(ctx)
+			if len(rsp.Header) != len(tc.expectedHeader) {
+				t.Errorf("unexpected headers after the response: %v", rsp.Header)
+			}
+		})
+	}
+}
+
+func TestCORSResponse(t *testing.T) {
+	for _, tc := range []struct {
+		msg            string
+		args           []interface{}
+		origin         string
+		expectedHeader http.Header
+	}{{
+		msg:            "no origin",
+		args:           []interface{}{"origin=*"},
+		expectedHeader: http.Header{},
+	}, {
+		msg:            "origin not allowed",
+		args:           []interface{}{"origin=https://www.example.org"},
+		origin:         "https://evil.example.org",
+		expectedHeader: http.Header{},
+	}, {
+		msg:    "any origin",
+		args:   []interface{}{"origin=*", "exposeHeaders=x-request-id"},
+		origin: "https://www.example.org",
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin":   []string{"*"},
+			"Access-Control-Expose-Headers": []string{"X-Request-Id"},
+		},
+	}, {
+		msg:    "exact origin with credentials",
+		args:   []interface{}{"origin=https://www.example.org", "credentials=true"},
+		origin: "https://www.example.org",
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin":      []string{"https://www.example.org"},
+			"Access-Control-Allow-Credentials": []string{"true"},
+			"Vary":                             []string{"Origin"},
+		},
+	}, {
+		msg:    "exact origin",
+		args:   []interface{}{"origin=https://www.example.org"},
+		origin: "https://www.example.org",
+		expectedHeader: http.Header{
+			"Access-Control-Allow-Origin": []string{"https://www.example.org"},
+			"Vary":                        []string{"Origin"},
+		},
+	}, {
+		msg:            "wildcard does not match the path separator",
+		args:           []interface{}{"origin=https://*.example.org"},
+		origin:         "https://evil.org/.example.org",
+		expectedHeader: http.Header{},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewCORS().CreateFilter(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &http.Request{Method: "GET", Header: http.Header{}}
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+
+			ctx := &filtertest.Context{FRequest: req, FResponse: &http.Response{Header: http.Header{}}}
+			f.Request(ctx)
+			if ctx.FServed {
+				t.Fatal("unexpected served request")
+			}
+
+			f.Response(ctx)
+			h := ctx.FResponse.Header
+			if len(h) != len(tc.expectedHeader) {
+				t.Errorf("unexpected headers, got: %v, expected: %v", h, tc.expectedHeader)
+			}
+
+			for k, v := range tc.expectedHeader {
+				if got := h.Get(k); got != v[0] {
+					t.Errorf("unexpected header %s, got: %s, expected: %s", k, got, v[0])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package diag
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	injectedLatencyKey = "diag.inject.latency"
+	injectedFaultKey   = "diag.inject.fault"
+)
+
+type sampler struct {
+	mx          sync.Mutex
+	rand        *rand.Rand
+	probability float64
+}
+
+type injectLatency struct {
+	*sampler
+	delay time.Duration
+}
+
+type injectFault struct {
+	*sampler
+	status int
+	body   string
+	header string
+}
+
+// NewInjectLatency creates a filter specification whose filter instances can
+// be used to delay a fraction of the requests before they are forwarded to the
+// backend, for testing purpose. It expects the delay and the probability in the
+// range of [0, 1] as arguments. When the request is canceled, e.g. the client
+// disconnects, the delay is aborted. Each injected delay increments the
+// diag.inject.latency counter.
+// Eskip example:
+//
+// 	* -> injectLatency("200ms", 0.1) -> "https://www.example.org";
+//
+func NewInjectLatency() filters.Spec { return &injectLatency{} }
+
+func (*injectLatency) Name() string { return filters.InjectLatencyName }
+
+// NewInjectFault creates a filter specification whose filter instances can be
+// used to respond to a fraction of the requests with a synthetic error,
+// without forwarding them to the backend, for testing purpose. It expects the
+// probability in the range of [0, 1] and the status code as arguments, and
+// optionally the response body and the name of a response header, that is
+// set to "true" to mark the response as injected. Each injected fault
+// increments the diag.inject.fault counter.
+// Eskip example:
+//
+// 	* -> injectFault(0.05, 503, "injected fault", "X-Injected-Fault") -> "https://www.example.org";
+//
+func NewInjectFault() filters.Spec { return &injectFault{} }
+
+func newSampler(v interface{}) (*sampler, error) {
+	p, ok := v.(float64)
+	if !ok || p < 0 || p > 1 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &sampler{
+		/* #nosec */
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		probability: p,
+	}, nil
+}
+
+func (s *sampler) sample() bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.rand.Float64() < s.probability
+}
+
+func (*injectLatency) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	d, err := parseDuration(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newSampler(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &injectLatency{sampler: s, delay: d}, nil
+}
+
+func (l *injectLatency) Request(ctx filters.FilterContext) {
+	if l.delay == 0 || !l.sample() {
+		return
+	}
+
+	ctx.Metrics().IncCounter(injectedLatencyKey)
+
+	t := time.NewTimer(l.delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Request().Context().Done():
+	}
+}
+
+func (*injectLatency) Response(filters.FilterContext) {}
+
+func (*injectFault) Name() string { return filters.InjectFaultName }
+
+func (*injectFault) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	s, err := newSampler(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	status, ok := args[1].(float64)
+	if !ok || status < 100 || status > 599 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &injectFault{sampler: s, status: int(status)}
+
+	if len(args) > 2 {
+		if f.body, ok = args[2].(string); !ok {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	}
+
+	if len(args) > 3 {
+		if f.header, ok = args[3].(string); !ok || f.header == "" {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	}
+
+	return f, nil
+}
+
+func (f *injectFault) Request(ctx filters.FilterContext) {
+	if !f.sample() {
+		return
+	}
+
+	ctx.Metrics().IncCounter(injectedFaultKey)
+
+	rsp := &http.Response{
+		StatusCode: f.status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewBufferString(f.body)),
+	}
+
+	if f.body != "" {
+		rsp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		rsp.Header.Set("Content-Length", strconv.Itoa(len(f.body)))
+		rsp.ContentLength = int64(len(f.body))
+	}
+
+	if f.header != "" {
+		rsp.Header.Set(f.header, "true")
+	}
+
+	ctx.Serve(rsp)
+}
+
+func (*injectFault) Response(filters.FilterContext) {}
@@ -0,0 +1,243 @@
+package diag
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/metrics/metricstest"
+)
+
+func TestInjectLatencyArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{{
+		msg:  "no args",
+		args: nil,
+		err:  true,
+	}, {
+		msg:  "missing probability",
+		args: []interface{}{"200ms"},
+		err:  true,
+	}, {
+		msg:  "invalid duration",
+		args: []interface{}{"foo", 0.1},
+		err:  true,
+	}, {
+		msg:  "invalid probability",
+		args: []interface{}{"200ms", 1.5},
+		err:  true,
+	}, {
+		msg:  "invalid probability type",
+		args: []interface{}{"200ms", "0.1"},
+		err:  true,
+	}, {
+		msg:  "duration string",
+		args: []interface{}{"200ms", 0.1},
+	}, {
+		msg:  "duration milliseconds",
+		args: []interface{}{200.0, 1.0},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewInjectLatency().CreateFilter(ti.args)
+			if ti.err && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func testInjectLatency(t *testing.T, f filters.Filter, ctx context.Context) (time.Duration, int64) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.example.org", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &metricstest.MockMetrics{}
+	fc := &filtertest.Context{FRequest: req, FMetrics: m}
+
+	start := time.Now()
+	f.Request(fc)
+	d := time.Since(start)
+
+	var count int64
+	m.WithCounters(func(c map[string]int64) {
+		count = c[injectedLatencyKey]
+	})
+
+	return d, count
+}
+
+func TestInjectLatency(t *testing.T) {
+	t.Run("always", func(t *testing.T) {
+		f, err := NewInjectLatency().CreateFilter([]interface{}{"50ms", 1.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d, count := testInjectLatency(t, f, context.Background())
+		if d < 50*time.Millisecond {
+			t.Errorf("failed to delay the request: %v", d)
+		}
+
+		if count != 1 {
+			t.Errorf("invalid counter: %d", count)
+		}
+	})
+
+	t.Run("never", func(t *testing.T) {
+		f, err := NewInjectLatency().CreateFilter([]interface{}{"1s", 0.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d, count := testInjectLatency(t, f, context.Background())
+		if d >= time.Second {
+			t.Errorf("unexpected delay: %v", d)
+		}
+
+		if count != 0 {
+			t.Errorf("invalid counter: %d", count)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		f, err := NewInjectLatency().CreateFilter([]interface{}{"10s", 1.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		d, _ := testInjectLatency(t, f, ctx)
+		if d >= time.Second {
+			t.Errorf("failed to abort the delay: %v", d)
+		}
+	})
+}
+
+func TestInjectFaultArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{{
+		msg:  "no args",
+		args: nil,
+		err:  true,
+	}, {
+		msg:  "missing status",
+		args: []interface{}{0.1},
+		err:  true,
+	}, {
+		msg:  "invalid probability",
+		args: []interface{}{-0.1, 503.0},
+		err:  true,
+	}, {
+		msg:  "invalid status",
+		args: []interface{}{0.1, 42.0},
+		err:  true,
+	}, {
+		msg:  "invalid body",
+		args: []interface{}{0.1, 503.0, 42.0},
+		err:  true,
+	}, {
+		msg:  "empty header",
+		args: []interface{}{0.1, 503.0, "", ""},
+		err:  true,
+	}, {
+		msg:  "too many args",
+		args: []interface{}{0.1, 503.0, "", "X-Injected-Fault", "foo"},
+		err:  true,
+	}, {
+		msg:  "status",
+		args: []interface{}{0.1, 503.0},
+	}, {
+		msg:  "status, body and header",
+		args: []interface{}{0.1, 503.0, "injected", "X-Injected-Fault"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewInjectFault().CreateFilter(ti.args)
+			if ti.err && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	for _, ti := range []struct {
+		msg    string
+		args   []interface{}
+		served bool
+	}{{
+		msg:    "always",
+		args:   []interface{}{1.0, 503.0, "injected fault", "X-Injected-Fault"},
+		served: true,
+	}, {
+		msg:  "never",
+		args: []interface{}{0.0, 503.0, "injected fault", "X-Injected-Fault"},
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			f, err := NewInjectFault().CreateFilter(ti.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("GET", "https://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := &metricstest.MockMetrics{}
+			fc := &filtertest.Context{FRequest: req, FMetrics: m}
+			f.Request(fc)
+
+			var count int64
+			m.WithCounters(func(c map[string]int64) {
+				count = c[injectedFaultKey]
+			})
+
+			if !ti.served {
+				if fc.FServed || count != 0 {
+					t.Error("unexpected fault injected")
+				}
+
+				return
+			}
+
+			if !fc.FServed || count != 1 {
+				t.Fatal("failed to inject fault")
+			}
+
+			rsp := fc.FResponse
+			if rsp.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("invalid status code: %d", rsp.StatusCode)
+			}
+
+			if rsp.Header.Get("X-Injected-Fault") != "true" {
+				t.Error("failed to mark the response")
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != "injected fault" {
+				t.Errorf("invalid body: %s", b)
+			}
+		})
+	}
+}
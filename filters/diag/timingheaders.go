@@ -0,0 +1,29 @@
+package diag
+
+import "github.com/zalando/skipper/filters"
+
+type timingHeaders struct{}
+
+// NewTimingHeaders creates a filter specification for the 'timingHeaders()'
+// filter. It instructs the proxy to set the X-Skipper-Backend-Time and the
+// X-Skipper-Total-Time response headers to the duration of the backend
+// round-trip, and to the total time of handling the request, in
+// milliseconds. It should be used only for debugging, since it reveals the
+// timing of the backends to the clients.
+func NewTimingHeaders() filters.Spec { return timingHeaders{} }
+
+func (timingHeaders) Name() string { return filters.TimingHeadersName }
+
+func (timingHeaders) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return timingHeaders{}, nil
+}
+
+func (timingHeaders) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[filters.TimingHeaders] = true
+}
+
+func (timingHeaders) Response(filters.FilterContext) {}
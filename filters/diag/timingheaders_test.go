@@ -0,0 +1,26 @@
+package diag
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestTimingHeaders(t *testing.T) {
+	spec := NewTimingHeaders()
+	if _, err := spec.CreateFilter([]interface{}{"foo"}); err == nil {
+		t.Error("failed to fail")
+	}
+
+	f, err := spec.CreateFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+	f.Request(ctx)
+	if enabled, _ := ctx.FStateBag[filters.TimingHeaders].(bool); !enabled {
+		t.Error("failed to enable the timing headers")
+	}
+}
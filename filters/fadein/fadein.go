@@ -237,7 +237,20 @@ func (p *postProcessor) Do(r []*routing.Route) []*routing.Route {
 
 			key := endpointKey(s, h)
 			detected := p.detected[key].when
-			if detected.IsZero() || endpointsCreated[key].After(detected) {
+			rampStart := ep.RampStart
+			if rampStart.After(now) {
+				// same clock skew mitigation as for the endpoint created time
+				log.Errorf(
+					"Endpoint ramp start in the future, fading in without ramp start: %v. Potential clock skew.",
+					rampStart,
+				)
+
+				rampStart = time.Time{}
+			}
+
+			if !rampStart.IsZero() {
+				detected = rampStart
+			} else if detected.IsZero() || endpointsCreated[key].After(detected) {
 				detected = now
 			}
 
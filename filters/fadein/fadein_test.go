@@ -416,4 +416,48 @@ func TestPostProcessor(t *testing.T) {
 			t.Fatal("Endpoint not found.")
 		}
 	})
+
+	t.Run("ramp start is used as detection time", func(t *testing.T) {
+		rampStart := time.Now().Add(-30 * time.Second).Truncate(time.Second)
+		routes := fmt.Sprintf(`
+			* -> fadeIn("1m") -> <"http://10.0.0.1:8080?rampStart=%s", "http://10.0.0.2:8080">
+		`, rampStart.Format(time.RFC3339))
+
+		rt, _ := createRouting(t, routes)
+		r := route(rt, "/")
+		if r == nil || len(r.LBEndpoints) != 2 {
+			t.Fatal("Failed to process route.")
+		}
+
+		for _, ep := range r.LBEndpoints {
+			switch ep.Host {
+			case "10.0.0.1:8080":
+				if !ep.Detected.Equal(rampStart) {
+					t.Fatalf("Failed to use ramp start, got: %v, expected: %v.", ep.Detected, rampStart)
+				}
+			case "10.0.0.2:8080":
+				if !ep.Detected.After(rampStart) {
+					t.Fatal("Failed to use detection time.")
+				}
+			}
+		}
+	})
+
+	t.Run("ramp start in the future is ignored", func(t *testing.T) {
+		rampStart := time.Now().Add(time.Hour).Unix()
+		routes := fmt.Sprintf(`
+			* -> fadeIn("1m") -> <"http://10.0.0.1:8080?rampStart=%d">
+		`, rampStart)
+
+		before := time.Now()
+		rt, _ := createRouting(t, routes)
+		r := route(rt, "/")
+		if r == nil || len(r.LBEndpoints) != 1 {
+			t.Fatal("Failed to process route.")
+		}
+
+		if d := r.LBEndpoints[0].Detected; d.Before(before) || d.After(time.Now()) {
+			t.Fatalf("Failed to ignore ramp start in the future, got: %v.", d)
+		}
+	})
 }
@@ -1,6 +1,7 @@
 package filters
 
 import (
+	"crypto/tls"
 	"errors"
 	log "github.com/sirupsen/logrus"
 	"net/http"
@@ -25,8 +26,41 @@ const (
 	// BackendTimeout is the key used in the state bag to configure backend timeout in proxy
 	BackendTimeout = "backend:timeout"
 
+	// RequestTimeout is the key used in the state bag to configure the deadline of handling the request in proxy
+	RequestTimeout = "request:timeout"
+
+	// IdempotentRetries is the key used in the state bag to configure the max retries of idempotent requests in proxy
+	IdempotentRetries = "backend:idempotent-retries"
+
+	// BackendHedge is the key used in the state bag to configure hedged backend requests in proxy
+	BackendHedge = "backend:hedge"
+
+	// ResponseFlushInterval is the key used in the state bag to configure the flush interval of the response in proxy
+	ResponseFlushInterval = "response:flush-interval"
+
 	// BackendRatelimit is the key used in the state bag to configure backend ratelimit in proxy
 	BackendRatelimit = "backend:ratelimit"
+
+	// BackendTLS is the key used in the state bag to configure the TLS settings of the backend connections in proxy
+	BackendTLS = "backend:tls"
+
+	// BackendSNI is the key used in the state bag to configure the TLS server name of the backend connections in proxy
+	BackendSNI = "backend:sni"
+
+	// BackendH2C is the key used in the state bag to configure HTTP/2 with prior knowledge over cleartext connections to the backend in proxy
+	BackendH2C = "backend:h2c"
+
+	// BackendConnectionPool is the key used in the state bag to configure the connection pool of the backend in proxy
+	BackendConnectionPool = "backend:connection-pool"
+
+	// GRPCErrors is the key used in the state bag to configure gRPC compliant error responses in proxy
+	GRPCErrors = "response:grpc-errors"
+
+	// TimingHeaders is the key used in the state bag to configure the timing response headers in proxy
+	TimingHeaders = "response:timing-headers"
+
+	// IdempotencyUnlock is the key used in the state bag to release the idempotency lock in proxy, when the response filters were not executed
+	IdempotencyUnlock = "request:idempotency-unlock"
 )
 
 // Context object providing state and information that is unique to a request.
@@ -162,12 +196,39 @@ type Spec interface {
 	CreateFilter(config []interface{}) (Filter, error)
 }
 
+// ConnectionPool contains the connection pool settings of the backend
+// transport, set in the state bag with the BackendConnectionPool key. The
+// zero values mean the defaults of the proxy.
+type ConnectionPool struct {
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+}
+
+// BackendTLSConfig contains the TLS settings of the backend connections,
+// set in the state bag with the BackendTLS key. The Key identifies the
+// content of the settings, and the proxy shares the connections of the
+// backends between the configurations with the same key.
+type BackendTLSConfig struct {
+	Key    string
+	Config *tls.Config
+}
+
 // Registry used to lookup Spec objects while initializing routes.
 type Registry map[string]Spec
 
 // ErrInvalidFilterParameters is used in case of invalid filter parameters.
 var ErrInvalidFilterParameters = errors.New("invalid filter parameters")
 
+// ErrRequestBodyTooLarge is returned when reading a request body that exceeds
+// the limit set by the maxRequestBodySize filter. The proxy responds with 413
+// Request Entity Too Large, when the backend roundtrip fails with this error.
+var ErrRequestBodyTooLarge = errors.New("request body too large")
+
+// ErrInvalidRequestBody is returned when reading a request body that cannot
+// be processed, e.g. by the decompressRequest filter. The proxy responds with
+// 400 Bad Request, when the backend roundtrip fails with this error.
+var ErrInvalidRequestBody = errors.New("invalid request body")
+
 // Registers a filter specification.
 func (r Registry) Register(s Spec) {
 	name := s.Name()
@@ -187,32 +248,54 @@ const (
 	SetResponseHeaderName                      = "setResponseHeader"
 	AppendResponseHeaderName                   = "appendResponseHeader"
 	DropResponseHeaderName                     = "dropResponseHeader"
+	StripResponseHeadersName                   = "stripResponseHeaders"
+	SanitizeTrustedHeadersName                 = "sanitizeTrustedHeaders"
+	NormalizeRequestHeadersName                = "normalizeRequestHeaders"
 	SetContextRequestHeaderName                = "setContextRequestHeader"
 	AppendContextRequestHeaderName             = "appendContextRequestHeader"
 	SetContextResponseHeaderName               = "setContextResponseHeader"
 	AppendContextResponseHeaderName            = "appendContextResponseHeader"
 	CopyRequestHeaderName                      = "copyRequestHeader"
 	CopyResponseHeaderName                     = "copyResponseHeader"
+	CopyRequestHeaderToResponseName            = "copyRequestHeaderToResponse"
+	SetUpgradeHeaderName                       = "setUpgradeHeader"
 	ModPathName                                = "modPath"
 	SetPathName                                = "setPath"
 	RedirectToName                             = "redirectTo"
 	RedirectToLowerName                        = "redirectToLower"
+	RewriteLocationName                        = "rewriteLocation"
+	RedirectByAcceptLanguageName               = "redirectByAcceptLanguage"
 	StaticName                                 = "static"
+	ServeFileName                              = "serveFile"
+	ServeDirectoryName                         = "serveDirectory"
 	StripQueryName                             = "stripQuery"
 	PreserveHostName                           = "preserveHost"
 	StatusName                                 = "status"
 	CompressName                               = "compress"
 	DecompressName                             = "decompress"
 	SetQueryName                               = "setQuery"
+	ModifyQueryName                            = "modifyQuery"
 	DropQueryName                              = "dropQuery"
 	InlineContentName                          = "inlineContent"
 	InlineContentIfStatusName                  = "inlineContentIfStatus"
+	CacheControlByStatusName                   = "cacheControlByStatus"
+	ResponseCacheName                          = "responseCache"
+	IdempotencyName                            = "idempotency"
 	FlowIdName                                 = "flowId"
 	XforwardName                               = "xforward"
 	XforwardFirstName                          = "xforwardFirst"
 	RandomContentName                          = "randomContent"
 	RepeatContentName                          = "repeatContent"
 	BackendTimeoutName                         = "backendTimeout"
+	RequestTimeoutName                         = "requestTimeout"
+	BackendTLSName                             = "backendTLS"
+	BackendSNIName                             = "backendSNI"
+	BackendConnectionPoolName                  = "backendConnectionPool"
+	H2CBackendName                             = "h2cBackend"
+	GRPCStatusName                             = "grpcStatus"
+	IdempotentRetriesName                      = "idempotentRetries"
+	HedgeName                                  = "hedge"
+	FlushIntervalName                          = "flushInterval"
 	LatencyName                                = "latency"
 	BandwidthName                              = "bandwidth"
 	ChunksName                                 = "chunks"
@@ -225,14 +308,21 @@ const (
 	NormalRequestLatencyName                   = "normalRequestLatency"
 	UniformResponseLatencyName                 = "uniformResponseLatency"
 	NormalResponseLatencyName                  = "normalResponseLatency"
+	InjectLatencyName                          = "injectLatency"
+	InjectFaultName                            = "injectFault"
 	LogHeaderName                              = "logHeader"
+	TimingHeadersName                          = "timingHeaders"
 	TeeName                                    = "tee"
 	TeenfName                                  = "teenf"
 	TeeLoopbackName                            = "teeLoopback"
+	MirrorName                                 = "mirror"
+	TeeCompareName                             = "teeCompare"
 	SedName                                    = "sed"
 	SedDelimName                               = "sedDelim"
 	SedRequestName                             = "sedRequest"
 	SedRequestDelimName                        = "sedRequestDelim"
+	RewriteResponseBodyName                    = "rewriteResponseBody"
+	RedactJSONName                             = "redactJSON"
 	BasicAuthName                              = "basicAuth"
 	WebhookName                                = "webhook"
 	OAuthTokeninfoAnyScopeName                 = "oauthTokeninfoAnyScope"
@@ -247,6 +337,7 @@ const (
 	SecureOAuthTokenintrospectionAllClaimsName = "secureOauthTokenintrospectionAllClaims"
 	SecureOAuthTokenintrospectionAnyKVName     = "secureOauthTokenintrospectionAnyKV"
 	SecureOAuthTokenintrospectionAllKVName     = "secureOauthTokenintrospectionAllKV"
+	OAuthIntrospectionName                     = "oauthIntrospection"
 	ForwardTokenName                           = "forwardToken"
 	ForwardTokenFieldName                      = "forwardTokenField"
 	OAuthGrantName                             = "oauthGrant"
@@ -254,6 +345,9 @@ const (
 	GrantLogoutName                            = "grantLogout"
 	GrantClaimsQueryName                       = "grantClaimsQuery"
 	JwtValidationName                          = "jwtValidation"
+	JwtScopesName                              = "jwtScopes"
+	JwtClaimsToHeadersName                     = "jwtClaimsToHeaders"
+	RequireClientCertSubjectName               = "requireClientCertSubject"
 	OAuthOidcUserInfoName                      = "oauthOidcUserInfo"
 	OAuthOidcAnyClaimsName                     = "oauthOidcAnyClaims"
 	OAuthOidcAllClaimsName                     = "oauthOidcAllClaims"
@@ -261,20 +355,28 @@ const (
 	OidcClaimsQueryName                        = "oidcClaimsQuery"
 	ResponseCookieName                         = "responseCookie"
 	JsCookieName                               = "jsCookie"
+	SetCookieName                              = "setCookie"
 	ConsecutiveBreakerName                     = "consecutiveBreaker"
 	RateBreakerName                            = "rateBreaker"
 	DisableBreakerName                         = "disableBreaker"
 	ClientRatelimitName                        = "clientRatelimit"
+	LocalRatelimitName                         = "localRatelimit" // deprecated, use ClientRatelimitName
 	RatelimitName                              = "ratelimit"
 	ClusterClientRatelimitName                 = "clusterClientRatelimit"
 	ClusterRatelimitName                       = "clusterRatelimit"
 	BackendRateLimitName                       = "backendRatelimit"
+	LeakyBucketRatelimitName                   = "leakyBucketRatelimit"
+	ClusterLeakyBucketRatelimitName            = "clusterLeakyBucketRatelimit"
 	LuaName                                    = "lua"
 	CorsOriginName                             = "corsOrigin"
+	CorsName                                   = "cors"
 	HeaderToQueryName                          = "headerToQuery"
 	QueryToHeaderName                          = "queryToHeader"
 	DisableAccessLogName                       = "disableAccessLog"
 	EnableAccessLogName                        = "enableAccessLog"
+	LogStateBagName                            = "logStateBag"
+	AccessLogSamplingName                      = "accessLogSampling"
+	AccessLogRedactName                        = "accessLogRedact"
 	AuditLogName                               = "auditLog"
 	UnverifiedAuditLogName                     = "unverifiedAuditLog"
 	SetDynamicBackendHostFromHeader            = "setDynamicBackendHostFromHeader"
@@ -286,9 +388,20 @@ const (
 	ApiUsageMonitoringName                     = "apiUsageMonitoring"
 	LifoName                                   = "lifo"
 	LifoGroupName                              = "lifoGroup"
+	LifoPriorityName                           = "lifoPriority"
+	FifoName                                   = "fifo"
+	FifoGroupName                              = "fifoGroup"
+	MaxRequestBodySizeName                     = "maxRequestBodySize"
+	BufferRequestBodyName                      = "bufferRequestBody"
+	RequireRequestHeadersName                  = "requireRequestHeaders"
+	AllowedMethodsName                         = "allowedMethods"
+	ValidateJSONSchemaName                     = "validateJSONSchema"
+	DecompressRequestName                      = "decompressRequest"
 	RfcPathName                                = "rfcPath"
 	RfcHostName                                = "rfcHost"
+	NormalizePathName                          = "normalizePath"
 	BearerInjectorName                         = "bearerinjector"
+	SignRequestName                            = "signRequest"
 	TracingBaggageToTagName                    = "tracingBaggageToTag"
 	StateBagToTagName                          = "stateBagToTag"
 	TracingTagName                             = "tracingTag"
@@ -0,0 +1,131 @@
+/*
+Package grpc provides a filter to make the error responses of the gRPC
+requests compliant with the gRPC protocol.
+
+gRPC clients expect the status of a call in the grpc-status and
+grpc-message trailers of a response with the HTTP status 200, and they
+misinterpret the plain HTTP error responses, e.g. 502 or 503. For the
+gRPC requests, detected by the application/grpc content type, the filter
+converts the error responses of the proxy, and the HTTP error responses
+of the backends without the gRPC content type, to gRPC responses, with
+the gRPC status mapped from the HTTP status. Other requests are not
+changed.
+
+Example:
+
+	* -> grpcStatus() -> "http://grpc.internal:8080"
+*/
+package grpc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// gRPC status codes, as defined by the gRPC protocol.
+const (
+	StatusOK               = 0
+	StatusCanceled         = 1
+	StatusUnknown          = 2
+	StatusPermissionDenied = 7
+	StatusUnimplemented    = 12
+	StatusInternal         = 13
+	StatusUnavailable      = 14
+	StatusUnauthenticated  = 16
+)
+
+const contentType = "application/grpc"
+
+type status struct{}
+
+// NewStatus creates a filter specification, whose instances convert the
+// error responses of the gRPC requests to gRPC responses.
+func NewStatus() filters.Spec { return status{} }
+
+// IsRequest tells whether a request is a gRPC request, based on its
+// content type.
+func IsRequest(r *http.Request) bool {
+	return isGRPCContentType(r.Header.Get("Content-Type"))
+}
+
+func isGRPCContentType(ct string) bool {
+	return ct == contentType ||
+		strings.HasPrefix(ct, contentType+"+") ||
+		strings.HasPrefix(ct, contentType+";")
+}
+
+// StatusFromHTTP maps an HTTP status code to a gRPC status code, as
+// described in the gRPC documentation about the HTTP to gRPC status code
+// mapping. The 499 status, used by the proxy for the requests canceled by
+// the clients, is mapped to the canceled gRPC status.
+func StatusFromHTTP(code int) int {
+	switch code {
+	case http.StatusOK:
+		return StatusOK
+	case http.StatusBadRequest:
+		return StatusInternal
+	case http.StatusUnauthorized:
+		return StatusUnauthenticated
+	case http.StatusForbidden:
+		return StatusPermissionDenied
+	case http.StatusNotFound:
+		return StatusUnimplemented
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return StatusUnavailable
+	case 499:
+		return StatusCanceled
+	default:
+		return StatusUnknown
+	}
+}
+
+// Trailer returns the gRPC trailers of an HTTP error status code.
+func Trailer(code int) http.Header {
+	return http.Header{
+		"Grpc-Status":  []string{strconv.Itoa(StatusFromHTTP(code))},
+		"Grpc-Message": []string{http.StatusText(code)},
+	}
+}
+
+func (status) Name() string { return filters.GRPCStatusName }
+
+func (status) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return status{}, nil
+}
+
+func (status) Request(ctx filters.FilterContext) {
+	if IsRequest(ctx.Request()) {
+		ctx.StateBag()[filters.GRPCErrors] = true
+	}
+}
+
+func (status) Response(ctx filters.FilterContext) {
+	if !IsRequest(ctx.Request()) {
+		return
+	}
+
+	rsp := ctx.Response()
+	if rsp.StatusCode == http.StatusOK || isGRPCContentType(rsp.Header.Get("Content-Type")) {
+		return
+	}
+
+	if rsp.Body != nil {
+		rsp.Body.Close()
+	}
+
+	rsp.Trailer = Trailer(rsp.StatusCode)
+	rsp.StatusCode = http.StatusOK
+	rsp.Header = http.Header{"Content-Type": []string{contentType}}
+	rsp.ContentLength = -1
+	rsp.Body = http.NoBody
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestIsRequest(t *testing.T) {
+	for ct, expected := range map[string]bool{
+		"application/grpc":       true,
+		"application/grpc+proto": true,
+		"application/grpc-web":   false,
+		"application/json":       false,
+		"":                       false,
+	} {
+		r := &http.Request{Header: http.Header{"Content-Type": []string{ct}}}
+		if IsRequest(r) != expected {
+			t.Errorf("unexpected result for %q", ct)
+		}
+	}
+}
+
+func TestStatusFromHTTP(t *testing.T) {
+	for code, expected := range map[int]int{
+		http.StatusOK:                  StatusOK,
+		http.StatusBadRequest:          StatusInternal,
+		http.StatusUnauthorized:        StatusUnauthenticated,
+		http.StatusForbidden:           StatusPermissionDenied,
+		http.StatusNotFound:            StatusUnimplemented,
+		http.StatusTooManyRequests:     StatusUnavailable,
+		http.StatusBadGateway:          StatusUnavailable,
+		http.StatusServiceUnavailable:  StatusUnavailable,
+		http.StatusGatewayTimeout:      StatusUnavailable,
+		499:                            StatusCanceled,
+		http.StatusInternalServerError: StatusUnknown,
+	} {
+		if s := StatusFromHTTP(code); s != expected {
+			t.Errorf("expected %d for %d, got %d", expected, code, s)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		requestType    string
+		responseType   string
+		status         int
+		expectedStatus int
+		expectedGRPC   string
+	}{{
+		name:           "not gRPC",
+		requestType:    "application/json",
+		status:         http.StatusServiceUnavailable,
+		expectedStatus: http.StatusServiceUnavailable,
+	}, {
+		name:           "gRPC response",
+		requestType:    "application/grpc",
+		responseType:   "application/grpc",
+		status:         http.StatusOK,
+		expectedStatus: http.StatusOK,
+	}, {
+		name:           "HTTP error",
+		requestType:    "application/grpc",
+		responseType:   "text/html",
+		status:         http.StatusServiceUnavailable,
+		expectedStatus: http.StatusOK,
+		expectedGRPC:   "14",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewStatus().CreateFilter(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{
+				FRequest: &http.Request{Header: http.Header{"Content-Type": []string{tt.requestType}}},
+				FResponse: &http.Response{
+					StatusCode: tt.status,
+					Header:     http.Header{"Content-Type": []string{tt.responseType}},
+					Body:       io.NopCloser(strings.NewReader("Service Unavailable")),
+				},
+				FStateBag: make(map[string]interface{}),
+			}
+
+			f.Request(ctx)
+			if _, ok := ctx.FStateBag[filters.GRPCErrors]; ok != (tt.requestType == "application/grpc") {
+				t.Error("unexpected gRPC errors flag")
+			}
+
+			f.Response(ctx)
+			if ctx.FResponse.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, ctx.FResponse.StatusCode)
+			}
+
+			if s := ctx.FResponse.Trailer.Get("Grpc-Status"); s != tt.expectedGRPC {
+				t.Errorf("expected gRPC status %q, got %q", tt.expectedGRPC, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Package hedge provides a filter to send hedged requests to load balanced
+backends, to reduce the tail latency.
+
+When the backend doesn't respond within the configured delay, the proxy
+sends the same request to another endpoint of the load balanced backend,
+and uses the response that arrives first. The other requests are
+cancelled. Only the idempotent requests without a body are hedged.
+
+Example:
+
+	* -> hedge("50ms", 2) -> <roundRobin, "http://10.2.0.1", "http://10.2.0.2">
+*/
+package hedge
+
+import (
+	"time"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// Hedge instructs the proxy to send hedged requests to the load balanced
+// backend of the route.
+type Hedge struct {
+	// Delay is the time to wait for a response before sending the next
+	// request.
+	Delay time.Duration
+
+	// MaxAttempts is the maximum number of the requests sent, including
+	// the first one.
+	MaxAttempts int
+}
+
+// NewHedge creates a filter Spec, whose instances instruct the proxy to
+// send a new request to another endpoint of the load balanced backend,
+// when the previous requests didn't respond within the delay, up to the
+// max attempts. The first response is used, and the other requests are
+// cancelled.
+func NewHedge() filters.Spec { return &Hedge{} }
+
+func (*Hedge) Name() string { return filters.HedgeName }
+
+func (*Hedge) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var delay time.Duration
+	switch v := args[0].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		delay = d
+	case time.Duration:
+		delay = v
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	var maxAttempts int
+	switch v := args[1].(type) {
+	case int:
+		maxAttempts = v
+	case float64:
+		maxAttempts = int(v)
+	default:
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	if delay <= 0 || maxAttempts < 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &Hedge{Delay: delay, MaxAttempts: maxAttempts}, nil
+}
+
+func (h *Hedge) Request(ctx filters.FilterContext) {
+	// allows overwrite
+	ctx.StateBag()[filters.BackendHedge] = h
+}
+
+func (*Hedge) Response(filters.FilterContext) {}
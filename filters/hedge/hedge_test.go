@@ -0,0 +1,67 @@
+package hedge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+func TestHedgeArgs(t *testing.T) {
+	for _, tc := range []struct {
+		msg      string
+		args     []interface{}
+		expected *Hedge
+	}{{
+		msg: "no args",
+	}, {
+		msg:  "missing attempts",
+		args: []interface{}{"50ms"},
+	}, {
+		msg:  "invalid delay",
+		args: []interface{}{"fifty", 2.0},
+	}, {
+		msg:  "zero delay",
+		args: []interface{}{"0s", 2.0},
+	}, {
+		msg:  "invalid attempts",
+		args: []interface{}{"50ms", "2"},
+	}, {
+		msg:  "too few attempts",
+		args: []interface{}{"50ms", 1.0},
+	}, {
+		msg:      "valid",
+		args:     []interface{}{"50ms", 2.0},
+		expected: &Hedge{Delay: 50 * time.Millisecond, MaxAttempts: 2},
+	}, {
+		msg:      "duration and int",
+		args:     []interface{}{time.Second, 3},
+		expected: &Hedge{Delay: time.Second, MaxAttempts: 3},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewHedge().CreateFilter(tc.args)
+			if tc.expected == nil {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if h := f.(*Hedge); *h != *tc.expected {
+				t.Errorf("unexpected settings, got: %v, expected: %v", h, tc.expected)
+			}
+
+			ctx := &filtertest.Context{FStateBag: make(map[string]interface{})}
+			f.Request(ctx)
+			if ctx.FStateBag[filters.BackendHedge] != f {
+				t.Error("failed to set the state bag")
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Package jsonschema implements the validation of the JSON request bodies
+// against JSON Schemas.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	// DefaultMaxBodySize is the default limit of the validated request
+	// bodies.
+	DefaultMaxBodySize = 1 << 20
+
+	// the number of validation errors reported in the response
+	maxReportedErrors = 32
+)
+
+type (
+	spec struct{}
+
+	filter struct {
+		schema      *document
+		maxBodySize int64
+	}
+
+	errorResponse struct {
+		Error            string   `json:"error"`
+		ValidationErrors []string `json:"validationErrors,omitempty"`
+	}
+)
+
+// NewValidateJSONSchema creates a filter specification, whose instances
+// validate the JSON request bodies against a JSON Schema. It expects the
+// path of the schema file, and optionally the maximum size of the
+// validated bodies in bytes, which defaults to 1MB:
+//
+//    validateJSONSchema("/etc/schemas/order.json")
+//    validateJSONSchema("/etc/schemas/order.json", 65536)
+//
+// The schema is loaded and compiled when the filter is created, and the
+// creation fails when the schema uses keywords that the filter doesn't
+// support, e.g. format or if-then-else. Only the
+// requests with the application/json content type, or with a content type
+// ending with +json, are validated. When the body is not valid, the
+// filter responds with 400 Bad Request and a JSON body listing the
+// validation errors, without calling the backend. The larger bodies are
+// rejected with 413 Request Entity Too Large. The valid bodies are
+// forwarded to the backend unchanged.
+func NewValidateJSONSchema() filters.Spec { return &spec{} }
+
+func (*spec) Name() string { return filters.ValidateJSONSchemaName }
+
+func (*spec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	path, ok := args[0].(string)
+	if !ok || path == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	f := &filter{maxBodySize: DefaultMaxBodySize}
+	if len(args) == 2 {
+		size, ok := args[1].(float64)
+		if !ok || size <= 0 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		f.maxBodySize = int64(size)
+	}
+
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON schema: %w", err)
+	}
+
+	if f.schema, err = compileSchema(doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+func isJSON(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json"))
+}
+
+func serveJSON(ctx filters.FilterContext, status int, rsp errorResponse) {
+	// encoding strings only, it cannot fail
+	body, _ := json.Marshal(rsp)
+
+	ctx.Serve(&http.Response{
+		StatusCode: status,
+		Header: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"Content-Length": []string{strconv.Itoa(len(body))},
+		},
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	})
+}
+
+func (f *filter) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	if !isJSON(req) {
+		return
+	}
+
+	if req.ContentLength > f.maxBodySize {
+		serveJSON(ctx, http.StatusRequestEntityTooLarge, errorResponse{Error: "request body too large"})
+		return
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, f.maxBodySize+1))
+		req.Body.Close()
+		if err != nil {
+			log.Errorf("Failed to read the request body: %v", err)
+			serveJSON(ctx, http.StatusBadRequest, errorResponse{Error: "failed to read request body"})
+			return
+		}
+
+		if int64(len(body)) > f.maxBodySize {
+			serveJSON(ctx, http.StatusRequestEntityTooLarge, errorResponse{Error: "request body too large"})
+			return
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		serveJSON(ctx, http.StatusBadRequest, errorResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+
+	errs := f.schema.validate(v)
+	if len(errs) == 0 {
+		return
+	}
+
+	rsp := errorResponse{Error: "request body does not match the schema"}
+	for i, e := range errs {
+		if i == maxReportedErrors {
+			break
+		}
+
+		rsp.ValidationErrors = append(rsp.ValidationErrors, e.String())
+	}
+
+	serveJSON(ctx, http.StatusBadRequest, rsp)
+}
+
+func (*filter) Response(filters.FilterContext) {}
@@ -0,0 +1,165 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"type": "string"},
+		"amount": {"type": "number", "minimum": 0}
+	}
+}`
+
+func writeSchema(t *testing.T, schema string) string {
+	p := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(p, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestValidateJSONSchemaArgs(t *testing.T) {
+	valid := writeSchema(t, testSchema)
+	invalid := writeSchema(t, `{"type": 42}`)
+	for _, tc := range []struct {
+		args []interface{}
+		err  bool
+	}{
+		{args: nil, err: true},
+		{args: []interface{}{42.0}, err: true},
+		{args: []interface{}{valid, "1m"}, err: true},
+		{args: []interface{}{valid, 0.0}, err: true},
+		{args: []interface{}{valid, 1024.0, "foo"}, err: true},
+		{args: []interface{}{"/no/such/schema.json"}, err: true},
+		{args: []interface{}{invalid}, err: true},
+		{args: []interface{}{valid}},
+		{args: []interface{}{valid, 1024.0}},
+	} {
+		_, err := NewValidateJSONSchema().CreateFilter(tc.args)
+		if tc.err && err == nil {
+			t.Errorf("expected error for arguments: %v", tc.args)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for arguments: %v, %v", tc.args, err)
+		}
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := writeSchema(t, testSchema)
+	for _, tc := range []struct {
+		msg            string
+		contentType    string
+		body           string
+		contentLength  int64
+		expectServed   bool
+		expectedStatus int
+		expectedErrors []string
+	}{{
+		msg:         "not JSON",
+		contentType: "text/plain",
+		body:        "foo",
+	}, {
+		msg:         "valid",
+		contentType: "application/json; charset=utf-8",
+		body:        `{"id": "foo", "amount": 42}`,
+	}, {
+		msg:         "valid, JSON suffix",
+		contentType: "application/problem+json",
+		body:        `{"id": "foo"}`,
+	}, {
+		msg:            "invalid JSON",
+		contentType:    "application/json",
+		body:           `{"id": `,
+		expectServed:   true,
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		msg:            "empty body",
+		contentType:    "application/json",
+		expectServed:   true,
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		msg:            "does not match",
+		contentType:    "application/json",
+		body:           `{"amount": -1}`,
+		expectServed:   true,
+		expectedStatus: http.StatusBadRequest,
+		expectedErrors: []string{`/: missing required property "id"`, "/amount: must be greater than or equal to 0"},
+	}, {
+		msg:            "too large",
+		contentType:    "application/json",
+		body:           `{"id": "` + strings.Repeat("x", 64) + `"}`,
+		expectServed:   true,
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}, {
+		msg:            "too large, unknown length",
+		contentType:    "application/json",
+		body:           `{"id": "` + strings.Repeat("x", 64) + `"}`,
+		contentLength:  -1,
+		expectServed:   true,
+		expectedStatus: http.StatusRequestEntityTooLarge,
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			f, err := NewValidateJSONSchema().CreateFilter([]interface{}{schema, 64.0})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &http.Request{
+				Method:        "POST",
+				Header:        http.Header{"Content-Type": []string{tc.contentType}},
+				Body:          io.NopCloser(strings.NewReader(tc.body)),
+				ContentLength: int64(len(tc.body)),
+			}
+
+			if tc.contentLength != 0 {
+				req.ContentLength = tc.contentLength
+			}
+
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+			if ctx.FServed != tc.expectServed {
+				t.Fatalf("unexpected served state, got: %t, expected: %t", ctx.FServed, tc.expectServed)
+			}
+
+			if !tc.expectServed {
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(b) != tc.body {
+					t.Errorf("unexpected body forwarded, got: %s, expected: %s", b, tc.body)
+				}
+
+				return
+			}
+
+			rsp := ctx.FResponse
+			if rsp.StatusCode != tc.expectedStatus {
+				t.Errorf("unexpected status code, got: %d, expected: %d", rsp.StatusCode, tc.expectedStatus)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rsp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(body.ValidationErrors, tc.expectedErrors) {
+				t.Errorf("unexpected validation errors, got: %v, expected: %v", body.ValidationErrors, tc.expectedErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,759 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// schema is a compiled JSON Schema. It supports the validation keywords of
+// the draft-07 specification, except the ones listed in
+// unsupportedKeywords and the remote references. The compilation fails
+// with the unsupported keywords, while the annotation keywords are
+// ignored.
+type schema struct {
+	boolean *bool
+
+	types []string
+	enum  []interface{}
+	cnst  []interface{}
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	items           *schema
+	tupleItems      []*schema
+	additionalItems *schema
+	minItems        *int
+	maxItems        *int
+	uniqueItems     bool
+	contains        *schema
+
+	properties           map[string]*schema
+	patternProperties    map[*regexp.Regexp]*schema
+	additionalProperties *schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+	propertyNames        *schema
+
+	allOf, anyOf, oneOf []*schema
+	not                 *schema
+
+	ref string
+}
+
+// these keywords would restrict the valid documents, so they cannot be
+// ignored silently
+var unsupportedKeywords = []string{
+	"if",
+	"then",
+	"else",
+	"dependencies",
+	"format",
+	"contentEncoding",
+	"contentMediaType",
+}
+
+// document is a compiled JSON Schema document with the resolved local
+// references.
+type document struct {
+	root     *schema
+	compiled map[string]*schema
+}
+
+type compiler struct {
+	root     interface{}
+	compiled map[string]*schema
+}
+
+// validationError is a validation error at a JSON pointer of the
+// validated document.
+type validationError struct {
+	path    string
+	message string
+}
+
+func (e validationError) String() string {
+	p := e.path
+	if p == "" {
+		p = "/"
+	}
+
+	return p + ": " + e.message
+}
+
+// compileSchema compiles a JSON Schema document. The local references
+// are resolved during the compilation.
+func compileSchema(doc []byte) (*document, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	c := &compiler{root: root, compiled: make(map[string]*schema)}
+	s, err := c.compile(root, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.resolveRefs(); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkRefLoops(); err != nil {
+		return nil, err
+	}
+
+	return &document{root: s, compiled: c.compiled}, nil
+}
+
+// checks that the references are not referencing themselves without
+// any other keywords
+func (c *compiler) checkRefLoops() error {
+	for _, s := range c.compiled {
+		for i := 0; s.ref != ""; i++ {
+			if i > len(c.compiled) {
+				return fmt.Errorf("invalid JSON schema: reference loop: %s", s.ref)
+			}
+
+			s = c.compiled[s.ref]
+		}
+	}
+
+	return nil
+}
+
+func (c *compiler) resolveRefs() error {
+	for {
+		var pending []string
+		for _, s := range c.compiled {
+			if s.ref != "" {
+				if _, ok := c.compiled[s.ref]; !ok {
+					pending = append(pending, s.ref)
+				}
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		for _, ref := range pending {
+			v, err := resolvePointer(c.root, ref)
+			if err != nil {
+				return err
+			}
+
+			if _, err := c.compile(v, ref); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// resolves a local reference, like #/definitions/item
+func resolvePointer(root interface{}, ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("invalid JSON schema: only local references are supported: %s", ref)
+	}
+
+	p, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: invalid reference: %s", ref)
+	}
+
+	if p == "" {
+		return root, nil
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid JSON schema: invalid reference: %s", ref)
+	}
+
+	v := root
+	for _, token := range strings.Split(p[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = vv[token]; !ok {
+				return nil, fmt.Errorf("invalid JSON schema: reference not found: %s", ref)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(vv) {
+				return nil, fmt.Errorf("invalid JSON schema: reference not found: %s", ref)
+			}
+
+			v = vv[i]
+		default:
+			return nil, fmt.Errorf("invalid JSON schema: reference not found: %s", ref)
+		}
+	}
+
+	return v, nil
+}
+
+func invalidKeyword(ptr, keyword string) error {
+	return fmt.Errorf("invalid JSON schema: invalid %s at %s", keyword, ptr)
+}
+
+func getNumber(m map[string]interface{}, ptr, keyword string) (*float64, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	f, ok := v.(float64)
+	if !ok {
+		return nil, invalidKeyword(ptr, keyword)
+	}
+
+	return &f, nil
+}
+
+func getCount(m map[string]interface{}, ptr, keyword string) (*int, error) {
+	f, err := getNumber(m, ptr, keyword)
+	if f == nil || err != nil {
+		return nil, err
+	}
+
+	if *f < 0 || *f != math.Trunc(*f) {
+		return nil, invalidKeyword(ptr, keyword)
+	}
+
+	i := int(*f)
+	return &i, nil
+}
+
+func (c *compiler) compileChild(m map[string]interface{}, ptr, keyword string) (*schema, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	return c.compile(v, ptr+"/"+keyword)
+}
+
+func (c *compiler) compileList(m map[string]interface{}, ptr, keyword string) ([]*schema, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, invalidKeyword(ptr, keyword)
+	}
+
+	var result []*schema
+	for i, li := range l {
+		s, err := c.compile(li, fmt.Sprintf("%s/%s/%d", ptr, keyword, i))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func (c *compiler) compileMap(m map[string]interface{}, ptr, keyword string) (map[string]*schema, error) {
+	v, ok := m[keyword]
+	if !ok {
+		return nil, nil
+	}
+
+	mm, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, invalidKeyword(ptr, keyword)
+	}
+
+	result := make(map[string]*schema)
+	for k, mv := range mm {
+		s, err := c.compile(mv, ptr+"/"+keyword+"/"+escapeToken(k))
+		if err != nil {
+			return nil, err
+		}
+
+		result[k] = s
+	}
+
+	return result, nil
+}
+
+func (c *compiler) compile(v interface{}, ptr string) (*schema, error) {
+	if s, ok := c.compiled[ptr]; ok {
+		return s, nil
+	}
+
+	s := &schema{}
+	c.compiled[ptr] = s
+
+	if b, ok := v.(bool); ok {
+		s.boolean = &b
+		return s, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid JSON schema: invalid schema at %s", ptr)
+	}
+
+	if ref, ok := m["$ref"]; ok {
+		// other keywords next to $ref are ignored in draft-07
+		if s.ref, ok = ref.(string); !ok {
+			return nil, invalidKeyword(ptr, "$ref")
+		}
+
+		return s, nil
+	}
+
+	for _, k := range unsupportedKeywords {
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("invalid JSON schema: unsupported keyword %s at %s", k, ptr)
+		}
+	}
+
+	var err error
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, ti := range t {
+			ts, ok := ti.(string)
+			if !ok {
+				return nil, invalidKeyword(ptr, "type")
+			}
+
+			s.types = append(s.types, ts)
+		}
+	default:
+		return nil, invalidKeyword(ptr, "type")
+	}
+
+	if e, ok := m["enum"]; ok {
+		if s.enum, ok = e.([]interface{}); !ok {
+			return nil, invalidKeyword(ptr, "enum")
+		}
+	}
+
+	if cv, ok := m["const"]; ok {
+		s.cnst = []interface{}{cv}
+	}
+
+	for keyword, dst := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		if *dst, err = getNumber(m, ptr, keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, invalidKeyword(ptr, "multipleOf")
+	}
+
+	for keyword, dst := range map[string]**int{
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+	} {
+		if *dst, err = getCount(m, ptr, keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	if p, ok := m["pattern"]; ok {
+		ps, ok := p.(string)
+		if !ok {
+			return nil, invalidKeyword(ptr, "pattern")
+		}
+
+		if s.pattern, err = regexp.Compile(ps); err != nil {
+			return nil, invalidKeyword(ptr, "pattern")
+		}
+	}
+
+	if items, ok := m["items"]; ok {
+		if _, isList := items.([]interface{}); isList {
+			if s.tupleItems, err = c.compileList(m, ptr, "items"); err != nil {
+				return nil, err
+			}
+		} else if s.items, err = c.compileChild(m, ptr, "items"); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.additionalItems, err = c.compileChild(m, ptr, "additionalItems"); err != nil {
+		return nil, err
+	}
+
+	if u, ok := m["uniqueItems"]; ok {
+		if s.uniqueItems, ok = u.(bool); !ok {
+			return nil, invalidKeyword(ptr, "uniqueItems")
+		}
+	}
+
+	if s.contains, err = c.compileChild(m, ptr, "contains"); err != nil {
+		return nil, err
+	}
+
+	if s.properties, err = c.compileMap(m, ptr, "properties"); err != nil {
+		return nil, err
+	}
+
+	pp, err := c.compileMap(m, ptr, "patternProperties")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pp) > 0 {
+		s.patternProperties = make(map[*regexp.Regexp]*schema)
+		for p, ps := range pp {
+			rx, err := regexp.Compile(p)
+			if err != nil {
+				return nil, invalidKeyword(ptr, "patternProperties")
+			}
+
+			s.patternProperties[rx] = ps
+		}
+	}
+
+	if s.additionalProperties, err = c.compileChild(m, ptr, "additionalProperties"); err != nil {
+		return nil, err
+	}
+
+	if s.propertyNames, err = c.compileChild(m, ptr, "propertyNames"); err != nil {
+		return nil, err
+	}
+
+	if r, ok := m["required"]; ok {
+		rl, ok := r.([]interface{})
+		if !ok {
+			return nil, invalidKeyword(ptr, "required")
+		}
+
+		for _, ri := range rl {
+			rs, ok := ri.(string)
+			if !ok {
+				return nil, invalidKeyword(ptr, "required")
+			}
+
+			s.required = append(s.required, rs)
+		}
+	}
+
+	if s.allOf, err = c.compileList(m, ptr, "allOf"); err != nil {
+		return nil, err
+	}
+
+	if s.anyOf, err = c.compileList(m, ptr, "anyOf"); err != nil {
+		return nil, err
+	}
+
+	if s.oneOf, err = c.compileList(m, ptr, "oneOf"); err != nil {
+		return nil, err
+	}
+
+	if s.not, err = c.compileChild(m, ptr, "not"); err != nil {
+		return nil, err
+	}
+
+	// the definitions are compiled only when referenced
+	return s, nil
+}
+
+func escapeToken(t string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1")
+}
+
+func typeOf(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if vv == math.Trunc(vv) {
+			return "integer"
+		}
+
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func matchType(types []string, v interface{}) bool {
+	t := typeOf(v)
+	for _, ti := range types {
+		if ti == t || ti == "number" && t == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(l []interface{}, v interface{}) bool {
+	for _, li := range l {
+		if reflect.DeepEqual(li, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type validator struct {
+	compiled map[string]*schema
+	errors   []validationError
+}
+
+func (vr *validator) fail(path, format string, args ...interface{}) {
+	vr.errors = append(vr.errors, validationError{path: path, message: fmt.Sprintf(format, args...)})
+}
+
+// tells whether the value is valid, without collecting the errors
+func (vr *validator) valid(s *schema, path string, v interface{}) bool {
+	sub := &validator{compiled: vr.compiled}
+	sub.validate(s, path, v)
+	return len(sub.errors) == 0
+}
+
+func (vr *validator) validate(s *schema, path string, v interface{}) {
+	for s.ref != "" {
+		s = vr.compiled[s.ref]
+	}
+
+	if s.boolean != nil {
+		if !*s.boolean {
+			vr.fail(path, "not allowed")
+		}
+
+		return
+	}
+
+	if len(s.types) > 0 && !matchType(s.types, v) {
+		vr.fail(path, "expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return
+	}
+
+	if s.enum != nil && !contains(s.enum, v) {
+		vr.fail(path, "value not allowed")
+	}
+
+	if s.cnst != nil && !contains(s.cnst, v) {
+		vr.fail(path, "value not allowed")
+	}
+
+	switch vv := v.(type) {
+	case float64:
+		vr.validateNumber(s, path, vv)
+	case string:
+		vr.validateString(s, path, vv)
+	case []interface{}:
+		vr.validateArray(s, path, vv)
+	case map[string]interface{}:
+		vr.validateObject(s, path, vv)
+	}
+
+	for _, si := range s.allOf {
+		vr.validate(si, path, v)
+	}
+
+	if len(s.anyOf) > 0 {
+		var matched bool
+		for _, si := range s.anyOf {
+			if vr.valid(si, path, v) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			vr.fail(path, "does not match any of the schemas")
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		var matched int
+		for _, si := range s.oneOf {
+			if vr.valid(si, path, v) {
+				matched++
+			}
+		}
+
+		if matched != 1 {
+			vr.fail(path, "matches %d schemas instead of one", matched)
+		}
+	}
+
+	if s.not != nil && vr.valid(s.not, path, v) {
+		vr.fail(path, "matches a not allowed schema")
+	}
+}
+
+func (vr *validator) validateNumber(s *schema, path string, v float64) {
+	if s.minimum != nil && v < *s.minimum {
+		vr.fail(path, "must be greater than or equal to %v", *s.minimum)
+	}
+
+	if s.maximum != nil && v > *s.maximum {
+		vr.fail(path, "must be less than or equal to %v", *s.maximum)
+	}
+
+	if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+		vr.fail(path, "must be greater than %v", *s.exclusiveMinimum)
+	}
+
+	if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+		vr.fail(path, "must be less than %v", *s.exclusiveMaximum)
+	}
+
+	if s.multipleOf != nil {
+		if q := v / *s.multipleOf; q != math.Trunc(q) {
+			vr.fail(path, "must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func (vr *validator) validateString(s *schema, path string, v string) {
+	l := utf8.RuneCountInString(v)
+	if s.minLength != nil && l < *s.minLength {
+		vr.fail(path, "must be at least %d characters long", *s.minLength)
+	}
+
+	if s.maxLength != nil && l > *s.maxLength {
+		vr.fail(path, "must be at most %d characters long", *s.maxLength)
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		vr.fail(path, "does not match the pattern %s", s.pattern)
+	}
+}
+
+func (vr *validator) validateArray(s *schema, path string, v []interface{}) {
+	if s.minItems != nil && len(v) < *s.minItems {
+		vr.fail(path, "must have at least %d items", *s.minItems)
+	}
+
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		vr.fail(path, "must have at most %d items", *s.maxItems)
+	}
+
+	if s.uniqueItems {
+		for i := 1; i < len(v); i++ {
+			if contains(v[:i], v[i]) {
+				vr.fail(path, "items must be unique")
+				break
+			}
+		}
+	}
+
+	for i, vi := range v {
+		ip := path + "/" + strconv.Itoa(i)
+		switch {
+		case s.items != nil:
+			vr.validate(s.items, ip, vi)
+		case i < len(s.tupleItems):
+			vr.validate(s.tupleItems[i], ip, vi)
+		case s.tupleItems != nil && s.additionalItems != nil:
+			vr.validate(s.additionalItems, ip, vi)
+		}
+	}
+
+	if s.contains != nil {
+		var found bool
+		for i, vi := range v {
+			if vr.valid(s.contains, path+"/"+strconv.Itoa(i), vi) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			vr.fail(path, "must contain a matching item")
+		}
+	}
+}
+
+func (vr *validator) validateObject(s *schema, path string, v map[string]interface{}) {
+	if s.minProperties != nil && len(v) < *s.minProperties {
+		vr.fail(path, "must have at least %d properties", *s.minProperties)
+	}
+
+	if s.maxProperties != nil && len(v) > *s.maxProperties {
+		vr.fail(path, "must have at most %d properties", *s.maxProperties)
+	}
+
+	for _, r := range s.required {
+		if _, ok := v[r]; !ok {
+			vr.fail(path, "missing required property %q", r)
+		}
+	}
+
+	// validating in a stable order, to report the errors in a stable order
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		kp := path + "/" + escapeToken(k)
+		if s.propertyNames != nil && !vr.valid(s.propertyNames, kp, k) {
+			vr.fail(kp, "property name not allowed")
+		}
+
+		ps, matched := s.properties[k]
+		if matched {
+			vr.validate(ps, kp, v[k])
+		}
+
+		for rx, pps := range s.patternProperties {
+			if rx.MatchString(k) {
+				matched = true
+				vr.validate(pps, kp, v[k])
+			}
+		}
+
+		if !matched && s.additionalProperties != nil {
+			vr.validate(s.additionalProperties, kp, v[k])
+		}
+	}
+}
+
+// validate validates a decoded JSON document, and returns the validation
+// errors.
+func (d *document) validate(v interface{}) []validationError {
+	vr := &validator{compiled: d.compiled}
+	vr.validate(d.root, "", v)
+	return vr.errors
+}
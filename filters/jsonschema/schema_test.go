@@ -0,0 +1,204 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompileSchema(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		err    bool
+	}{
+		{schema: `{`, err: true},
+		{schema: `42`, err: true},
+		{schema: `{"type": 42}`, err: true},
+		{schema: `{"minLength": -1}`, err: true},
+		{schema: `{"maxItems": 1.5}`, err: true},
+		{schema: `{"pattern": "["}`, err: true},
+		{schema: `{"multipleOf": 0}`, err: true},
+		{schema: `{"allOf": []}`, err: true},
+		{schema: `{"$ref": "#/definitions/missing"}`, err: true},
+		{schema: `{"$ref": "https://example.org/schema.json"}`, err: true},
+		{schema: `{"definitions": {"a": {"$ref": "#/definitions/a"}}, "$ref": "#/definitions/a"}`, err: true},
+		{schema: `{"type": "string", "format": "email"}`, err: true},
+		{schema: `{"if": {"type": "string"}, "then": {"minLength": 1}, "else": {"type": "integer"}}`, err: true},
+		{schema: `{"dependencies": {"a": ["b"]}}`, err: true},
+		{schema: `{"properties": {"a": {"contentEncoding": "base64"}}}`, err: true},
+		{schema: `true`},
+		{schema: `{}`},
+		{schema: `{"type": ["string", "null"], "title": "ignored", "description": "ignored"}`},
+		{schema: `{"definitions": {"node": {"type": "object", "properties": {"next": {"$ref": "#/definitions/node"}}}}, "$ref": "#/definitions/node"}`},
+	} {
+		_, err := compileSchema([]byte(tc.schema))
+		if tc.err && err == nil {
+			t.Errorf("expected error for schema: %s", tc.schema)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for schema: %s, %v", tc.schema, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		title  string
+		schema string
+		doc    string
+		errors []string
+	}{{
+		title:  "boolean schema, true",
+		schema: `true`,
+		doc:    `{"foo": 42}`,
+	}, {
+		title:  "boolean schema, false",
+		schema: `false`,
+		doc:    `{"foo": 42}`,
+		errors: []string{"/: not allowed"},
+	}, {
+		title:  "type",
+		schema: `{"type": "object"}`,
+		doc:    `[]`,
+		errors: []string{"/: expected object, got array"},
+	}, {
+		title:  "integer is a number",
+		schema: `{"type": "number"}`,
+		doc:    `42`,
+	}, {
+		title:  "number is not an integer",
+		schema: `{"type": "integer"}`,
+		doc:    `4.2`,
+		errors: []string{"/: expected integer, got number"},
+	}, {
+		title:  "multiple types",
+		schema: `{"type": ["string", "null"]}`,
+		doc:    `null`,
+	}, {
+		title: "object",
+		schema: `{
+			"type": "object",
+			"required": ["id", "items"],
+			"properties": {
+				"id": {"type": "string", "pattern": "^[a-z0-9-]+$"},
+				"amount": {"type": "number", "minimum": 0, "exclusiveMaximum": 1000}
+			},
+			"additionalProperties": false
+		}`,
+		doc: `{"id": "Order-1", "amount": 1000, "note": "foo"}`,
+		errors: []string{
+			`/: missing required property "items"`,
+			"/amount: must be less than 1000",
+			"/id: does not match the pattern ^[a-z0-9-]+$",
+			"/note: not allowed",
+		},
+	}, {
+		title: "pattern properties",
+		schema: `{
+			"patternProperties": {"^x-": {"type": "string"}},
+			"additionalProperties": {"type": "integer"}
+		}`,
+		doc:    `{"x-foo": 42, "bar": "baz", "qux": 1}`,
+		errors: []string{"/bar: expected integer, got string", "/x-foo: expected string, got integer"},
+	}, {
+		title: "array",
+		schema: `{
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 3,
+			"uniqueItems": true,
+			"items": {"type": "string", "minLength": 2, "maxLength": 3}
+		}`,
+		doc: `["foo", "x", "foo", "quux"]`,
+		errors: []string{
+			"/: must have at most 3 items",
+			"/: items must be unique",
+			"/1: must be at least 2 characters long",
+			"/3: must be at most 3 characters long",
+		},
+	}, {
+		title:  "tuple",
+		schema: `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`,
+		doc:    `["foo", 42, true]`,
+		errors: []string{"/2: not allowed"},
+	}, {
+		title:  "contains",
+		schema: `{"contains": {"const": "admin"}}`,
+		doc:    `["user", "guest"]`,
+		errors: []string{"/: must contain a matching item"},
+	}, {
+		title:  "enum",
+		schema: `{"enum": ["new", "paid", {"custom": true}]}`,
+		doc:    `{"custom": true}`,
+	}, {
+		title:  "enum, not allowed",
+		schema: `{"enum": ["new", "paid"]}`,
+		doc:    `"shipped"`,
+		errors: []string{"/: value not allowed"},
+	}, {
+		title:  "multipleOf",
+		schema: `{"multipleOf": 0.5}`,
+		doc:    `1.25`,
+		errors: []string{"/: must be a multiple of 0.5"},
+	}, {
+		title:  "anyOf",
+		schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`,
+		doc:    `true`,
+		errors: []string{"/: does not match any of the schemas"},
+	}, {
+		title:  "oneOf",
+		schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+		doc:    `42`,
+		errors: []string{"/: matches 2 schemas instead of one"},
+	}, {
+		title:  "not",
+		schema: `{"not": {"type": "null"}}`,
+		doc:    `null`,
+		errors: []string{"/: matches a not allowed schema"},
+	}, {
+		title:  "property names",
+		schema: `{"propertyNames": {"maxLength": 3}}`,
+		doc:    `{"foo": 1, "quux": 2}`,
+		errors: []string{"/quux: property name not allowed"},
+	}, {
+		title: "recursive reference",
+		schema: `{
+			"definitions": {
+				"node": {
+					"type": "object",
+					"required": ["value"],
+					"properties": {"value": {"type": "integer"}, "next": {"$ref": "#/definitions/node"}}
+				}
+			},
+			"$ref": "#/definitions/node"
+		}`,
+		doc:    `{"value": 1, "next": {"value": 2, "next": {"value": "3"}}}`,
+		errors: []string{"/next/next/value: expected integer, got string"},
+	}, {
+		title:  "escaped property names",
+		schema: `{"properties": {"a/b": {"type": "string"}}}`,
+		doc:    `{"a/b": 42}`,
+		errors: []string{"/a~1b: expected string, got integer"},
+	}} {
+		t.Run(tc.title, func(t *testing.T) {
+			s, err := compileSchema([]byte(tc.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var v interface{}
+			if err := json.Unmarshal([]byte(tc.doc), &v); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := s.validate(v)
+			if len(errs) != len(tc.errors) {
+				t.Fatalf("unexpected errors, got: %v, expected: %v", errs, tc.errors)
+			}
+
+			for i := range errs {
+				if errs[i].String() != tc.errors[i] {
+					t.Errorf("unexpected error, got: %s, expected: %s", errs[i], tc.errors[i])
+				}
+			}
+		})
+	}
+}
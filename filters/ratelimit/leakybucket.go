@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/ratelimit"
+)
+
+// NewLeakyBucketRatelimit creates an instance based leaky bucket rate
+// limiting per client. Every client has a bucket with the capacity
+// given by the first argument, which drains with the rate of the second
+// argument, in requests per second. The requests are allowed only when
+// the bucket of the client has room for them. This smooths out the
+// bursts of the clients, while still allowing bursts up to the capacity.
+// The optional third argument chooses the HTTP header used to identify
+// the clients, the same way as in the clientRatelimit filter.
+//
+// Example:
+//
+//    api: Path("/api")
+//    -> leakyBucketRatelimit(10, "2.5")
+//    -> "https://api.backend.net";
+//
+// Example leaky bucket per Authorization header:
+//
+//    api: Path("/api")
+//    -> leakyBucketRatelimit(10, "2.5", "Authorization")
+//    -> "https://api.backend.net";
+func NewLeakyBucketRatelimit(provider RatelimitProvider) filters.Spec {
+	return &spec{typ: ratelimit.LeakyBucketRatelimit, provider: provider, filterName: filters.LeakyBucketRatelimitName}
+}
+
+// NewClusterLeakyBucketRatelimit creates a leaky bucket rate limiting
+// per client, where the buckets are shared by all the skipper instances
+// in the cluster, using redis. The ratelimit group argument can be used
+// to share the buckets across one or more routes. The other arguments
+// are the same as of the leakyBucketRatelimit filter.
+//
+// Example:
+//
+//    api: Path("/api")
+//    -> clusterLeakyBucketRatelimit("api", 10, "2.5", "Authorization")
+//    -> "https://api.backend.net";
+func NewClusterLeakyBucketRatelimit(provider RatelimitProvider) filters.Spec {
+	return &spec{typ: ratelimit.ClusterLeakyBucketRatelimit, provider: provider, filterName: filters.ClusterLeakyBucketRatelimitName}
+}
+
+// getLeakRateArg returns the leak rate in requests per second, accepting
+// numbers and strings.
+func getLeakRateArg(a interface{}) (float64, error) {
+	var (
+		rate float64
+		err  error
+	)
+
+	switch v := a.(type) {
+	case int:
+		rate = float64(v)
+	case float64:
+		rate = v
+	case string:
+		rate, err = strconv.ParseFloat(v, 64)
+	default:
+		err = filters.ErrInvalidFilterParameters
+	}
+
+	if err != nil || !(rate > 0) {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	return rate, nil
+}
+
+// leakyBucketSettings converts the capacity and the leak rate to the
+// ratelimit settings, where the time window is the time it takes a
+// full bucket to drain.
+func leakyBucketSettings(typ ratelimit.RatelimitType, args []interface{}) (ratelimit.Settings, error) {
+	capacity, err := getIntArg(args[0])
+	if err != nil {
+		return ratelimit.Settings{}, err
+	}
+
+	if capacity <= 0 {
+		return ratelimit.Settings{}, filters.ErrInvalidFilterParameters
+	}
+
+	rate, err := getLeakRateArg(args[1])
+	if err != nil {
+		return ratelimit.Settings{}, err
+	}
+
+	timeWindow := time.Duration(float64(capacity) / rate * float64(time.Second))
+	if timeWindow < time.Duration(capacity) {
+		return ratelimit.Settings{}, filters.ErrInvalidFilterParameters
+	}
+
+	s := ratelimit.Settings{
+		Type:          typ,
+		MaxHits:       capacity,
+		TimeWindow:    timeWindow,
+		CleanInterval: 10 * timeWindow,
+		Lookuper:      ratelimit.NewXForwardedForLookuper(),
+	}
+
+	if len(args) > 2 {
+		s.Lookuper, err = parseLookuper(args[2:], getLookuper)
+		if err != nil {
+			return ratelimit.Settings{}, err
+		}
+	}
+
+	return s, nil
+}
+
+func leakyBucketRatelimitFilter(args []interface{}) (*filter, error) {
+	if !(len(args) == 2 || len(args) == 3) {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	s, err := leakyBucketSettings(ratelimit.LeakyBucketRatelimit, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filter{settings: s, statusCode: defaultStatusCode}, nil
+}
+
+func clusterLeakyBucketRatelimitFilter(args []interface{}) (*filter, error) {
+	if !(len(args) == 3 || len(args) == 4) {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	group, err := getStringArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := leakyBucketSettings(ratelimit.ClusterLeakyBucketRatelimit, args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	s.Group = group
+	return &filter{settings: s, statusCode: defaultStatusCode}, nil
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/ratelimit"
+)
+
+func TestLeakyBucketSettings(t *testing.T) {
+	f, err := NewClusterLeakyBucketRatelimit(nil).CreateFilter([]interface{}{"group", 10, "2.5", "Authorization"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ratelimit.Settings{
+		Type:          ratelimit.ClusterLeakyBucketRatelimit,
+		Group:         "group",
+		MaxHits:       10,
+		TimeWindow:    4 * time.Second,
+		CleanInterval: 40 * time.Second,
+		Lookuper:      ratelimit.NewHeaderLookuper("Authorization"),
+	}
+
+	if s := f.(*filter).settings; s != expected {
+		t.Errorf("unexpected settings, got: %v, expected: %v", s, expected)
+	}
+}
+
+func TestLeakyBucketRatelimit(t *testing.T) {
+	registry := ratelimit.NewRegistry()
+	defer registry.Close()
+
+	f, err := NewLeakyBucketRatelimit(NewRatelimitProvider(registry)).CreateFilter([]interface{}{2, 10, "X-Client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(client string) *http.Response {
+		ctx := &filtertest.Context{FRequest: &http.Request{Header: http.Header{"X-Client": []string{client}}}}
+		f.Request(ctx)
+		return ctx.FResponse
+	}
+
+	for i := 0; i < 2; i++ {
+		if rsp := request("foo"); rsp != nil {
+			t.Fatalf("request %d should be allowed, got: %d", i, rsp.StatusCode)
+		}
+	}
+
+	rsp := request("foo")
+	if rsp == nil || rsp.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("request should be limited")
+	}
+
+	if h := rsp.Header.Get(ratelimit.RetryAfterHeader); h != "1" {
+		t.Errorf("unexpected Retry-After header: %s", h)
+	}
+
+	if rsp := request("bar"); rsp != nil {
+		t.Error("request of another client should be allowed")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if rsp := request("foo"); rsp != nil {
+		t.Error("request should be allowed after leaking")
+	}
+}
@@ -7,16 +7,30 @@ package ratelimit
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/auth/claims"
 	"github.com/zalando/skipper/ratelimit"
 )
 
-const defaultStatusCode = http.StatusTooManyRequests
+const (
+	defaultStatusCode    = http.StatusTooManyRequests
+	claimLookuperPrefix  = "claim:"
+	headerLookuperPrefix = "header:"
+	queryLookuperPrefix  = "query:"
+	pathLookuperSource   = "path:"
+	methodLookuperSource = "method:"
+	hostLookuperSource   = "host:"
+)
+
+// getClaims is a variable to allow replacing it in the tests.
+var getClaims = claims.Get
 
 type spec struct {
 	typ        ratelimit.RatelimitType
@@ -37,6 +51,15 @@ type RatelimitProvider interface {
 	get(s ratelimit.Settings) limit
 }
 
+// claimLookuper selects the bucket by the value of a JWT claim, parsed by
+// the jwtValidation or the OpenID Connect filters earlier in the filter
+// chain. When the claim is not found, it uses the default key, if set.
+type claimLookuper struct {
+	claim      string
+	defaultKey string
+	hasDefault bool
+}
+
 type limit interface {
 	// AllowContext is used to decide if call is allowed to pass
 	AllowContext(context.Context, string) bool
@@ -53,14 +76,47 @@ type limit interface {
 // and enables easier test stubbing
 type registryAdapter struct {
 	registry *ratelimit.Registry
+	options  ProviderOptions
+}
+
+// ProviderOptions can be used to change the behavior of the ratelimit
+// filters created with the same provider.
+type ProviderOptions struct {
+	// EnableResetMillisHeader enables the X-Rate-Limit-Reset-Ms header,
+	// which tells the clients in milliseconds how long they should wait
+	// before making a new request. The Retry-After header is sent
+	// in either case.
+	EnableResetMillisHeader bool
+}
+
+// resetDeltaLimit is implemented by the limits that can tell with
+// sub-second precision when the next request is allowed.
+type resetDeltaLimit interface {
+	ResetDelta(string) time.Duration
+}
+
+// resetMillisProvider is implemented by the providers that can enable the
+// X-Rate-Limit-Reset-Ms header.
+type resetMillisProvider interface {
+	resetMillisHeader() bool
 }
 
 func (a *registryAdapter) get(s ratelimit.Settings) limit {
 	return a.registry.Get(s)
 }
 
+func (a *registryAdapter) resetMillisHeader() bool {
+	return a.options.EnableResetMillisHeader
+}
+
 func NewRatelimitProvider(registry *ratelimit.Registry) RatelimitProvider {
-	return &registryAdapter{registry}
+	return &registryAdapter{registry: registry}
+}
+
+// NewRatelimitProviderWithOptions creates a provider like
+// NewRatelimitProvider, applying the provided options.
+func NewRatelimitProviderWithOptions(registry *ratelimit.Registry, o ProviderOptions) RatelimitProvider {
+	return &registryAdapter{registry: registry, options: o}
 }
 
 // NewLocalRatelimit is *DEPRECATED*, use NewClientRatelimit, instead
@@ -86,6 +142,9 @@ func NewLocalRatelimit(provider RatelimitProvider) filters.Spec {
 //    login: Path("/login")
 //    -> clientRatelimit(3, "1m", "Authorization")
 //    -> "https://login.backend.net";
+//
+// More arguments can be used to combine more sources of the request
+// in the key of the client, see NewClusterClientRateLimit.
 func NewClientRatelimit(provider RatelimitProvider) filters.Spec {
 	return &spec{typ: ratelimit.ClientRatelimit, provider: provider, filterName: filters.ClientRatelimitName}
 }
@@ -170,6 +229,19 @@ func NewShardedClusterRateLimit(provider RatelimitProvider, maxGroupShards int)
 //    -> clusterClientRatelimit("groupC", 20, "1h", "Authorization")
 //    -> "https://foo.backend.net";
 //
+// The key of the client can be composed from more sources, given as
+// more arguments, or as a comma separated list. A source can be a
+// header name, "header:<name>", "query:<name>", "path", "method" or
+// "host". The latter three can be written with a trailing colon, too,
+// e.g. "path:". To use a header called Path, Method or Host, use the
+// header: prefix.
+//
+// Example:
+//
+//    api: PathSubtree("/api")
+//    -> clusterClientRatelimit("groupD", 100, "1m", "header:X-Tenant", "path:")
+//    -> "https://foo.backend.net";
+//
 func NewClusterClientRateLimit(provider RatelimitProvider) filters.Spec {
 	return &spec{typ: ratelimit.ClusterClientRatelimit, provider: provider, filterName: filters.ClusterClientRatelimitName}
 }
@@ -282,7 +354,7 @@ func getKeyShards(maxHits, maxShards int) int {
 }
 
 func clusterClientRatelimitFilter(args []interface{}) (*filter, error) {
-	if !(len(args) == 3 || len(args) == 4) {
+	if len(args) < 3 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
@@ -310,19 +382,10 @@ func clusterClientRatelimitFilter(args []interface{}) (*filter, error) {
 	}
 
 	if len(args) > 3 {
-		lookuperString, err := getStringArg(args[3])
+		s.Lookuper, err = parseLookuper(args[3:], getLookuper)
 		if err != nil {
 			return nil, err
 		}
-		if strings.Contains(lookuperString, ",") {
-			var lookupers []ratelimit.Lookuper
-			for _, ls := range strings.Split(lookuperString, ",") {
-				lookupers = append(lookupers, getLookuper(ls))
-			}
-			s.Lookuper = ratelimit.NewTupleLookuper(lookupers...)
-		} else {
-			s.Lookuper = getLookuper(lookuperString)
-		}
 	} else {
 		s.Lookuper = ratelimit.NewXForwardedForLookuper()
 	}
@@ -330,6 +393,131 @@ func clusterClientRatelimitFilter(args []interface{}) (*filter, error) {
 	return &filter{settings: s, statusCode: defaultStatusCode}, nil
 }
 
+// newClaimLookuper parses the claim lookuper definitions in the format of
+// "claim:<name>" or "claim:<name>=<default key>".
+func newClaimLookuper(s string) (claimLookuper, error) {
+	kv := strings.SplitN(strings.TrimPrefix(s, claimLookuperPrefix), "=", 2)
+	if kv[0] == "" {
+		return claimLookuper{}, fmt.Errorf("%w: missing claim name: %s", filters.ErrInvalidFilterParameters, s)
+	}
+
+	l := claimLookuper{claim: kv[0]}
+	if len(kv) == 2 {
+		l.defaultKey = kv[1]
+		l.hasDefault = true
+	}
+
+	return l, nil
+}
+
+// Lookup implements ratelimit.Lookuper. It always returns an empty string,
+// because the claims are not available from the request. The filter uses
+// lookup, instead.
+func (claimLookuper) Lookup(*http.Request) string { return "" }
+
+func (claimLookuper) String() string { return "ClaimLookuper" }
+
+func (l claimLookuper) lookup(stateBag map[string]interface{}) (string, bool) {
+	if claims, ok := getClaims(stateBag); ok {
+		if v, ok := claims[l.claim]; ok && v != nil {
+			return fmt.Sprint(v), true
+		}
+	}
+
+	return l.defaultKey, l.hasDefault
+}
+
+// parseLookuper returns the lookuper for the lookuper arguments of a client
+// ratelimit filter. Every argument can contain a comma separated list of
+// sources, and the key is the combination of all sources. The claim
+// lookuper cannot be combined with other lookupers. Only headers are
+// combined the same way as before the request sources were supported, to
+// keep the existing keys of the ratelimit counters unchanged.
+func parseLookuper(args []interface{}, single func(string) ratelimit.Lookuper) (ratelimit.Lookuper, error) {
+	var sources []string
+	for _, a := range args {
+		s, err := getStringArg(a)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, strings.Split(s, ",")...)
+	}
+
+	if len(sources) == 1 {
+		if strings.HasPrefix(sources[0], claimLookuperPrefix) {
+			return newClaimLookuper(sources[0])
+		}
+
+		return sourceLookuper(sources[0], single)
+	}
+
+	var (
+		lookupers   []ratelimit.Lookuper
+		headersOnly = true
+	)
+
+	for _, ls := range sources {
+		if strings.HasPrefix(ls, claimLookuperPrefix) {
+			return nil, fmt.Errorf("%w: claim lookuper cannot be combined: %s", filters.ErrInvalidFilterParameters, strings.Join(sources, ","))
+		}
+
+		l, err := sourceLookuper(ls, getLookuper)
+		if err != nil {
+			return nil, err
+		}
+
+		switch l.(type) {
+		case ratelimit.HeaderLookuper, ratelimit.XForwardedForLookuper:
+		default:
+			headersOnly = false
+		}
+
+		lookupers = append(lookupers, l)
+	}
+
+	if headersOnly {
+		return ratelimit.NewTupleLookuper(lookupers...), nil
+	}
+
+	return ratelimit.NewCompositeLookuper(lookupers...), nil
+}
+
+// isSource returns true when s is the source name, with or without the
+// trailing colon, case-insensitive.
+func isSource(s, source string) bool {
+	return strings.EqualFold(strings.TrimSuffix(s, ":"), strings.TrimSuffix(source, ":"))
+}
+
+// sourceLookuper returns the lookuper for a single source: "path",
+// "method", "host", "query:<name>", "header:<name>" or a header name.
+func sourceLookuper(s string, header func(string) ratelimit.Lookuper) (ratelimit.Lookuper, error) {
+	switch {
+	case isSource(s, pathLookuperSource):
+		return ratelimit.NewPathLookuper(), nil
+	case isSource(s, methodLookuperSource):
+		return ratelimit.NewMethodLookuper(), nil
+	case isSource(s, hostLookuperSource):
+		return ratelimit.NewHostLookuper(), nil
+	case strings.HasPrefix(s, queryLookuperPrefix):
+		name := strings.TrimPrefix(s, queryLookuperPrefix)
+		if name == "" {
+			return nil, fmt.Errorf("%w: missing query parameter name: %s", filters.ErrInvalidFilterParameters, s)
+		}
+
+		return ratelimit.NewQueryLookuper(name), nil
+	case strings.HasPrefix(s, headerLookuperPrefix):
+		s = strings.TrimPrefix(s, headerLookuperPrefix)
+		fallthrough
+	default:
+		if s == "" {
+			return nil, fmt.Errorf("%w: missing header name", filters.ErrInvalidFilterParameters)
+		}
+
+		return header(s), nil
+	}
+}
+
 func getLookuper(s string) ratelimit.Lookuper {
 	headerName := http.CanonicalHeaderKey(s)
 	if headerName == "X-Forwarded-For" {
@@ -340,7 +528,7 @@ func getLookuper(s string) ratelimit.Lookuper {
 }
 
 func clientRatelimitFilter(args []interface{}) (*filter, error) {
-	if !(len(args) == 2 || len(args) == 3) {
+	if len(args) < 2 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
@@ -356,19 +544,12 @@ func clientRatelimitFilter(args []interface{}) (*filter, error) {
 
 	var lookuper ratelimit.Lookuper
 	if len(args) > 2 {
-		lookuperString, err := getStringArg(args[2])
+		lookuper, err = parseLookuper(args[2:], func(s string) ratelimit.Lookuper {
+			return ratelimit.NewHeaderLookuper(s)
+		})
 		if err != nil {
 			return nil, err
 		}
-		if strings.Contains(lookuperString, ",") {
-			var lookupers []ratelimit.Lookuper
-			for _, ls := range strings.Split(lookuperString, ",") {
-				lookupers = append(lookupers, getLookuper(ls))
-			}
-			lookuper = ratelimit.NewTupleLookuper(lookupers...)
-		} else {
-			lookuper = ratelimit.NewHeaderLookuper(lookuperString)
-		}
 	} else {
 		lookuper = ratelimit.NewXForwardedForLookuper()
 	}
@@ -415,6 +596,10 @@ func (s *spec) createFilter(args []interface{}) (*filter, error) {
 		return clusterRatelimitFilter(s.maxShards, args)
 	case ratelimit.ClusterClientRatelimit:
 		return clusterClientRatelimitFilter(args)
+	case ratelimit.LeakyBucketRatelimit:
+		return leakyBucketRatelimitFilter(args)
+	case ratelimit.ClusterLeakyBucketRatelimit:
+		return clusterLeakyBucketRatelimitFilter(args)
 	default:
 		return disableFilter(args)
 	}
@@ -471,7 +656,18 @@ func (f *filter) Request(ctx filters.FilterContext) {
 		return
 	}
 
-	s := f.settings.Lookuper.Lookup(ctx.Request())
+	var s string
+	if cl, ok := f.settings.Lookuper.(claimLookuper); ok {
+		var found bool
+		if s, found = cl.lookup(ctx.StateBag()); !found {
+			log.Debugf("Claim %s not found for settings: %s", cl.claim, f.settings)
+			ctx.Serve(&http.Response{StatusCode: http.StatusUnauthorized})
+			return
+		}
+	} else {
+		s = f.settings.Lookuper.Lookup(ctx.Request())
+	}
+
 	if s == "" {
 		log.Debugf("Lookuper found no data in request for settings: %s and request: %v", f.settings, ctx.Request())
 		return
@@ -482,11 +678,28 @@ func (f *filter) Request(ctx filters.FilterContext) {
 		if f.maxHits != 0 {
 			maxHits = f.maxHits
 		}
+		header := ratelimit.Headers(maxHits, f.settings.TimeWindow, rateLimiter.RetryAfter(s))
+		if p, ok := f.provider.(resetMillisProvider); ok && p.resetMillisHeader() {
+			if dl, ok := rateLimiter.(resetDeltaLimit); ok {
+				header.Set(ratelimit.ResetMillisHeader, strconv.FormatInt(resetMillis(dl.ResetDelta(s)), 10))
+			}
+		}
+
 		ctx.Serve(&http.Response{
 			StatusCode: f.statusCode,
-			Header:     ratelimit.Headers(maxHits, f.settings.TimeWindow, rateLimiter.RetryAfter(s)),
+			Header:     header,
 		})
 	}
 }
 
+// resetMillis rounds up the time until the next allowed request to
+// milliseconds, never returning a negative value.
+func resetMillis(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
+
 func (*filter) Response(filters.FilterContext) {}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/auth/claims"
 	"github.com/zalando/skipper/filters/filtertest"
 	"github.com/zalando/skipper/ratelimit"
 )
@@ -42,6 +44,8 @@ func TestArgs(t *testing.T) {
 	t.Run("client", func(t *testing.T) {
 		rl := NewClientRatelimit(provider)
 		t.Run("missing", testErr(rl, nil))
+		t.Run("composite", testOK(rl, 10, "1m", "header:X-Tenant", "path:"))
+		t.Run("not a string", testErr(rl, 10, "1m", "path:", 429))
 	})
 
 	t.Run("cluster", func(t *testing.T) {
@@ -52,6 +56,37 @@ func TestArgs(t *testing.T) {
 	t.Run("clusterClient", func(t *testing.T) {
 		rl := NewClusterClientRateLimit(provider)
 		t.Run("missing", testErr(rl, nil))
+		t.Run("claim", testOK(rl, "group", 10, "1m", "claim:tenant"))
+		t.Run("claim with default", testOK(rl, "group", 10, "1m", "claim:tenant=anonymous"))
+		t.Run("claim without name", testErr(rl, "group", 10, "1m", "claim:"))
+		t.Run("claim combined", testErr(rl, "group", 10, "1m", "claim:tenant,X-Forwarded-For"))
+		t.Run("claim combined in arguments", testErr(rl, "group", 10, "1m", "claim:tenant", "path:"))
+		t.Run("composite", testOK(rl, "group", 10, "1m", "header:X-Tenant", "path:"))
+		t.Run("composite with query and method", testOK(rl, "group", 10, "1m", "query:tenant", "method:", "host:"))
+		t.Run("composite without colons", testOK(rl, "group", 10, "1m", "header:X-Tenant", "path", "method,host"))
+		t.Run("missing header name", testErr(rl, "group", 10, "1m", "header:", "path:"))
+		t.Run("missing query parameter name", testErr(rl, "group", 10, "1m", "query:"))
+	})
+
+	t.Run("leakyBucket", func(t *testing.T) {
+		rl := NewLeakyBucketRatelimit(provider)
+		t.Run("missing", testErr(rl, nil))
+		t.Run("missing rate", testErr(rl, 10))
+		t.Run("ok", testOK(rl, 10, "2.5"))
+		t.Run("numeric rate", testOK(rl, 10, 2))
+		t.Run("with lookuper", testOK(rl, 10, "2.5", "Authorization"))
+		t.Run("zero capacity", testErr(rl, 0, "2.5"))
+		t.Run("zero rate", testErr(rl, 10, "0"))
+		t.Run("invalid rate", testErr(rl, 10, "fast"))
+		t.Run("too many args", testErr(rl, 10, "2.5", "Authorization", 429))
+	})
+
+	t.Run("clusterLeakyBucket", func(t *testing.T) {
+		rl := NewClusterLeakyBucketRatelimit(provider)
+		t.Run("missing", testErr(rl, nil))
+		t.Run("missing group", testErr(rl, 10, "2.5"))
+		t.Run("ok", testOK(rl, "group", 10, "2.5"))
+		t.Run("with lookuper", testOK(rl, "group", 10, "2.5", "claim:sub"))
 	})
 
 	t.Run("disable", func(t *testing.T) {
@@ -60,6 +95,34 @@ func TestArgs(t *testing.T) {
 	})
 }
 
+func TestSourceLookuper(t *testing.T) {
+	for _, tc := range []struct {
+		source   string
+		expected ratelimit.Lookuper
+	}{
+		{"path", ratelimit.NewPathLookuper()},
+		{"path:", ratelimit.NewPathLookuper()},
+		{"Method", ratelimit.NewMethodLookuper()},
+		{"method:", ratelimit.NewMethodLookuper()},
+		{"host", ratelimit.NewHostLookuper()},
+		{"host:", ratelimit.NewHostLookuper()},
+		{"query:tenant", ratelimit.NewQueryLookuper("tenant")},
+		{"header:Host", ratelimit.NewHeaderLookuper("Host")},
+		{"X-Tenant", ratelimit.NewHeaderLookuper("X-Tenant")},
+	} {
+		t.Run(tc.source, func(t *testing.T) {
+			l, err := sourceLookuper(tc.source, func(s string) ratelimit.Lookuper { return ratelimit.NewHeaderLookuper(s) })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(l, tc.expected) {
+				t.Errorf("unexpected lookuper, got: %v, expected: %v", l, tc.expected)
+			}
+		})
+	}
+}
+
 type testLimit struct {
 	t        *testing.T
 	expected ratelimit.Settings
@@ -237,6 +300,72 @@ func TestRateLimit(t *testing.T) {
 		"1s",
 		"Authorization,X-Forwarded-For",
 	))
+	t.Run("ratelimit client composite", test(
+		NewClientRatelimit,
+		ratelimit.Settings{
+			Type:          ratelimit.ClientRatelimit,
+			MaxHits:       3,
+			TimeWindow:    1 * time.Second,
+			CleanInterval: 10 * time.Second,
+			Lookuper: ratelimit.NewCompositeLookuper(
+				ratelimit.NewHeaderLookuper("Authorization"),
+				ratelimit.NewMethodLookuper(),
+				ratelimit.NewHostLookuper()),
+		},
+		&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header: http.Header{
+				"X-Rate-Limit": []string{"10800"},
+				"Retry-After":  []string{"31415"},
+			},
+		},
+		3,
+		"1s",
+		"header:Authorization,method:",
+		"host:",
+	))
+	t.Run("ratelimit client source names without colon", test(
+		NewClientRatelimit,
+		ratelimit.Settings{
+			Type:          ratelimit.ClientRatelimit,
+			MaxHits:       3,
+			TimeWindow:    1 * time.Second,
+			CleanInterval: 10 * time.Second,
+			Lookuper: ratelimit.NewCompositeLookuper(
+				ratelimit.NewHeaderLookuper("Authorization"),
+				ratelimit.NewMethodLookuper(),
+				ratelimit.NewHostLookuper()),
+		},
+		&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header: http.Header{
+				"X-Rate-Limit": []string{"10800"},
+				"Retry-After":  []string{"31415"},
+			},
+		},
+		3,
+		"1s",
+		"header:Authorization,method",
+		"Host",
+	))
+	t.Run("ratelimit client path header", test(
+		NewClientRatelimit,
+		ratelimit.Settings{
+			Type:          ratelimit.ClientRatelimit,
+			MaxHits:       3,
+			TimeWindow:    1 * time.Second,
+			CleanInterval: 10 * time.Second,
+			Lookuper: ratelimit.NewCompositeLookuper(
+				ratelimit.NewHeaderLookuper("Path"),
+				ratelimit.NewMethodLookuper()),
+		},
+		// the request has no such header:
+		nil,
+		3,
+		"1s",
+		"header:Path",
+		"method",
+	))
 	t.Run("ratelimit client header", test(
 		NewClientRatelimit,
 		ratelimit.Settings{
@@ -464,6 +593,173 @@ func TestAllowsContext(t *testing.T) {
 	}
 }
 
+type claimLimit struct {
+	key string
+}
+
+func (l *claimLimit) get(ratelimit.Settings) limit { return l }
+
+func (l *claimLimit) AllowContext(_ context.Context, key string) bool {
+	l.key = key
+	return true
+}
+
+func (l *claimLimit) RetryAfter(string) int { return 0 }
+
+func TestClaimLookuper(t *testing.T) {
+	defer func() { getClaims = claims.Get }()
+	getClaims = func(stateBag map[string]interface{}) (map[string]interface{}, bool) {
+		claims, ok := stateBag["claims"].(map[string]interface{})
+		return claims, ok
+	}
+
+	for _, tc := range []struct {
+		title          string
+		lookuper       string
+		claims         map[string]interface{}
+		expectedKey    string
+		expectedStatus int
+	}{{
+		title:       "claim found",
+		lookuper:    "claim:tenant",
+		claims:      map[string]interface{}{"tenant": "foo"},
+		expectedKey: "foo",
+	}, {
+		title:       "non-string claim",
+		lookuper:    "claim:tenant",
+		claims:      map[string]interface{}{"tenant": 42.0},
+		expectedKey: "42",
+	}, {
+		title:          "claim not found",
+		lookuper:       "claim:tenant",
+		claims:         map[string]interface{}{"sub": "foo"},
+		expectedStatus: http.StatusUnauthorized,
+	}, {
+		title:          "no token",
+		lookuper:       "claim:tenant",
+		expectedStatus: http.StatusUnauthorized,
+	}, {
+		title:       "claim not found, default key",
+		lookuper:    "claim:tenant=anonymous",
+		claims:      map[string]interface{}{"sub": "foo"},
+		expectedKey: "anonymous",
+	}, {
+		title:       "claim found, default key",
+		lookuper:    "claim:tenant=anonymous",
+		claims:      map[string]interface{}{"tenant": "foo"},
+		expectedKey: "foo",
+	}} {
+		t.Run(tc.title, func(t *testing.T) {
+			l := &claimLimit{}
+			f, err := NewClusterClientRateLimit(l).CreateFilter([]interface{}{"group", 10, "1m", tc.lookuper})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &filtertest.Context{
+				FRequest:  &http.Request{},
+				FStateBag: make(map[string]interface{}),
+			}
+
+			if tc.claims != nil {
+				ctx.FStateBag["claims"] = tc.claims
+			}
+
+			f.Request(ctx)
+			if tc.expectedStatus != 0 {
+				if ctx.FResponse == nil || ctx.FResponse.StatusCode != tc.expectedStatus {
+					t.Fatalf("failed to reject the request with status: %d", tc.expectedStatus)
+				}
+
+				return
+			}
+
+			if ctx.FResponse != nil {
+				t.Fatalf("unexpected response: %v", ctx.FResponse)
+			}
+
+			if l.key != tc.expectedKey {
+				t.Errorf("unexpected key, got: %s, expected: %s", l.key, tc.expectedKey)
+			}
+		})
+	}
+}
+
+func TestResetMillisHeader(t *testing.T) {
+	for _, tc := range []struct {
+		msg     string
+		enabled bool
+	}{{
+		msg: "disabled",
+	}, {
+		msg:     "enabled",
+		enabled: true,
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			registry := ratelimit.NewRegistry()
+			defer registry.Close()
+
+			provider := NewRatelimitProviderWithOptions(registry, ProviderOptions{EnableResetMillisHeader: tc.enabled})
+			f, err := NewClientRatelimit(provider).CreateFilter([]interface{}{1, "1500ms", "X-Client"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := &http.Request{Header: http.Header{"X-Client": []string{"foo"}}}
+			ctx := &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+			if ctx.FResponse != nil {
+				t.Fatalf("unexpected response: %v", ctx.FResponse)
+			}
+
+			ctx = &filtertest.Context{FRequest: req}
+			f.Request(ctx)
+			if ctx.FResponse == nil {
+				t.Fatal("expected the request to be limited")
+			}
+
+			if ctx.FResponse.Header.Get(ratelimit.RetryAfterHeader) == "" {
+				t.Error("missing Retry-After header")
+			}
+
+			h := ctx.FResponse.Header.Get(ratelimit.ResetMillisHeader)
+			if !tc.enabled {
+				if h != "" {
+					t.Errorf("unexpected reset header: %s", h)
+				}
+
+				return
+			}
+
+			ms, err := strconv.Atoi(h)
+			if err != nil {
+				t.Fatalf("invalid reset header: %q", h)
+			}
+
+			if ms <= 1000 || ms > 1500 {
+				t.Errorf("unexpected reset value: %d", ms)
+			}
+		})
+	}
+}
+
+func TestResetMillis(t *testing.T) {
+	for _, tc := range []struct {
+		delta    time.Duration
+		expected int64
+	}{
+		{delta: -time.Second, expected: 0},
+		{delta: 0, expected: 0},
+		{delta: time.Microsecond, expected: 1},
+		{delta: 1500 * time.Millisecond, expected: 1500},
+		{delta: 1500*time.Millisecond + time.Nanosecond, expected: 1501},
+	} {
+		if got := resetMillis(tc.delta); got != tc.expected {
+			t.Errorf("unexpected value for %v, got: %d, expected: %d", tc.delta, got, tc.expected)
+		}
+	}
+}
+
 func TestGetKeyShards(t *testing.T) {
 	for _, tc := range []struct {
 		maxHits      int
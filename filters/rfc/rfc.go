@@ -33,6 +33,25 @@ func (p path) Request(ctx filters.FilterContext) {
 	req.URL.Path = rfc.PatchPath(req.URL.Path, req.URL.RawPath)
 }
 
+type normalizePath struct{}
+
+// NewNormalizePath creates a filter specification for the normalizePath()
+// filter, that normalizes the percent-encoding of the request path,
+// collapses the duplicate slashes and removes the dot segments, before
+// the request is proxied to the backend.
+//
+// See also the NormalizePath documentation in the rfc package.
+//
+func NewNormalizePath() filters.Spec { return normalizePath{} }
+
+func (normalizePath) Name() string                                       { return filters.NormalizePathName }
+func (normalizePath) CreateFilter([]interface{}) (filters.Filter, error) { return normalizePath{}, nil }
+func (normalizePath) Response(filters.FilterContext)                     {}
+
+func (normalizePath) Request(ctx filters.FilterContext) {
+	rfc.NormalizeURLPath(ctx.Request().URL)
+}
+
 type host struct{}
 
 // NewHost creates a filter specification for the rfcHost() filter, that
@@ -45,3 +45,24 @@ func TestPatchHost(t *testing.T) {
 		t.Error("failed to patch the host", req.Host)
 	}
 }
+
+func TestNormalizePath(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://www.example.org//foo/./bar/../baz%2fqux", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &filtertest.Context{
+		FRequest: req,
+	}
+
+	f, err := NewNormalizePath().CreateFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Request(ctx)
+	if req.URL.Path != "/foo/baz/qux" || req.URL.EscapedPath() != "/foo/baz%2Fqux" {
+		t.Error("failed to normalize the path", req.URL.Path, req.URL.EscapedPath())
+	}
+}
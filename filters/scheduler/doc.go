@@ -18,6 +18,11 @@
 // scheduler group and lifo will get a per route unique scheduler
 // group.
 //
+// The fifo and fifoGroup filters work similar to the lifo filters, but
+// they use a first in first out queue, that rejects the new requests
+// when it is full. This bounds the time that a request may spend
+// waiting in the queue.
+//
 // Bounded schedulers were tested in Kubernetes with 3 proxy instances
 // with 500m CPU and 500Mi memory resources. The load test was done
 // with 500 requests per second to backends with 25 seconds latency
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"github.com/zalando/skipper/filters"
+)
+
+type (
+	fifoSpec      struct{}
+	fifoGroupSpec struct{}
+
+	fifoFilter struct {
+		lifoFilter
+	}
+
+	fifoGroupFilter struct {
+		lifoGroupFilter
+	}
+)
+
+func NewFIFO() filters.Spec {
+	return &fifoSpec{}
+}
+
+func NewFIFOGroup() filters.Spec {
+	return &fifoGroupSpec{}
+}
+
+func (*fifoSpec) Name() string { return filters.FifoName }
+
+// CreateFilter creates a fifoFilter, that will use a bounded first in
+// first out queue for handling requests. The parameters are the same
+// as of the lifo filter: MaxConcurrency, MaxQueueSize and Timeout.
+//
+// All parameters are optional and defaults to
+// MaxConcurrency 100, MaxQueueSize 100, Timeout 10s.
+//
+// Unlike the lifo filter, when the queue is full, the fifo filter
+// rejects the newly arriving requests, and the queued requests are
+// handled in the order of their arrival. This bounds the time that a
+// request can wait in the queue.
+func (*fifoSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f, err := (&lifoSpec{}).CreateFilter(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fifoFilter{lifoFilter: *f.(*lifoFilter)}, nil
+}
+
+func (*fifoGroupSpec) Name() string { return filters.FifoGroupName }
+
+// CreateFilter creates a fifoGroupFilter, that shares a first in first
+// out queue between the routes using the same group name. The
+// parameters are the same as of the lifoGroup filter: Name,
+// MaxConcurrency, MaxQueueSize and Timeout.
+//
+// The fifoGroup and the lifoGroup filters don't share the queues, even
+// when they use the same group name.
+func (*fifoGroupSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	f, err := (&lifoGroupSpec{}).CreateFilter(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fifoGroupFilter{lifoGroupFilter: *f.(*lifoGroupFilter)}, nil
+}
+
+// FIFO implements scheduler.FIFOFilter.
+func (*fifoFilter) FIFO() bool { return true }
+
+// FIFO implements scheduler.FIFOFilter.
+func (*fifoGroupFilter) FIFO() bool { return true }
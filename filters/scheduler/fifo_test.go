@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/metrics/metricstest"
+	"github.com/zalando/skipper/proxy"
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+	"github.com/zalando/skipper/scheduler"
+)
+
+func TestNewFIFO(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		args       []interface{}
+		schedFunc  func() filters.Spec
+		wantName   string
+		wantErr    bool
+		wantConfig scheduler.Config
+	}{
+		{
+			name:      "fifo with valid configuration",
+			args:      []interface{}{10, 15, "5s"},
+			schedFunc: NewFIFO,
+			wantName:  filters.FifoName,
+			wantConfig: scheduler.Config{
+				MaxConcurrency: 10,
+				MaxQueueSize:   15,
+				Timeout:        5 * time.Second,
+			},
+		},
+		{
+			name:      "fifo with defaults",
+			schedFunc: NewFIFO,
+			wantName:  filters.FifoName,
+			wantConfig: scheduler.Config{
+				MaxConcurrency: defaultMaxConcurreny,
+				MaxQueueSize:   defaultMaxQueueSize,
+				Timeout:        defaultTimeout,
+			},
+		},
+		{
+			name:      "fifo with invalid configuration",
+			args:      []interface{}{10, 15, "4a"},
+			schedFunc: NewFIFO,
+			wantName:  filters.FifoName,
+			wantErr:   true,
+		},
+		{
+			name:      "fifoGroup with valid configuration",
+			args:      []interface{}{"mygroup", 10, 15, "5s"},
+			schedFunc: NewFIFOGroup,
+			wantName:  filters.FifoGroupName,
+			wantConfig: scheduler.Config{
+				MaxConcurrency: 10,
+				MaxQueueSize:   15,
+				Timeout:        5 * time.Second,
+			},
+		},
+		{
+			name:      "fifoGroup without group name",
+			schedFunc: NewFIFOGroup,
+			wantName:  filters.FifoGroupName,
+			wantErr:   true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schedFunc()
+			assert.Equal(t, tt.wantName, s.Name())
+
+			f, err := s.CreateFilter(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			ff, ok := f.(scheduler.FIFOFilter)
+			require.True(t, ok, "filter does not implement scheduler.FIFOFilter")
+			assert.True(t, ff.FIFO())
+			assert.Equal(t, tt.wantConfig, ff.Config())
+		})
+	}
+}
+
+func TestFifoErrors(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer backend.Close()
+
+	doc := fmt.Sprintf(`aroute: * -> fifo(5, 7, "100ms") -> "%s"`, backend.URL)
+
+	dc, err := testdataclient.NewDoc(doc)
+	require.NoError(t, err)
+
+	metrics := &metricstest.MockMetrics{}
+	reg := scheduler.RegistryWith(scheduler.Options{
+		Metrics:                metrics,
+		EnableRouteLIFOMetrics: true,
+	})
+	defer reg.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewFIFO())
+
+	ro := routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  fr,
+		DataClients:     []routing.DataClient{dc},
+		PostProcessors:  []routing.PostProcessor{reg},
+	}
+
+	rt := routing.New(ro)
+	defer rt.Close()
+
+	<-rt.FirstLoad()
+
+	tracer := mocktracer.New()
+	pr := proxy.WithParams(proxy.Params{
+		Routing:     rt,
+		OpenTracing: &proxy.OpenTracingParams{Tracer: tracer},
+	})
+	defer pr.Close()
+
+	ts := httptest.NewServer(pr)
+	defer ts.Close()
+
+	requestSpike(t, 20, ts.URL)
+
+	codes := make(map[uint16]int)
+	for _, span := range tracer.FinishedSpans() {
+		if span.OperationName == "ingress" {
+			codes[span.Tag("http.status_code").(uint16)]++
+		}
+	}
+
+	assert.Equal(t, map[uint16]int{
+		// 20 request in total, of which:
+		200: 5, // went straight to the backend
+		502: 7, // were queued and timed out as backend latency is greater than scheduling timeout
+		503: 8, // were refused due to full queue
+	}, codes)
+
+	metrics.WithCounters(func(counters map[string]int64) {
+		assert.Equal(t, int64(7), counters["fifo.aroute.error.timeout"])
+		assert.Equal(t, int64(8), counters["fifo.aroute.error.full"])
+	})
+}
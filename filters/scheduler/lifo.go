@@ -1,10 +1,12 @@
 package scheduler
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/aryszka/jobqueue"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	log "github.com/sirupsen/logrus"
@@ -38,6 +40,8 @@ const (
 	defaultMaxConcurreny = 100
 	defaultMaxQueueSize  = 100
 	defaultTimeout       = 10 * time.Second
+
+	priorityConcurrencyOption = "priorityConcurrency"
 )
 
 func NewLIFO() filters.Spec {
@@ -68,6 +72,26 @@ func durationArg(a interface{}) (time.Duration, error) {
 	}
 }
 
+// priorityArg parses an option in the form of priorityConcurrency=<n>.
+func priorityArg(a interface{}) (int, error) {
+	s, ok := a.(string)
+	if !ok {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] != priorityConcurrencyOption {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	n, err := strconv.Atoi(kv[1])
+	if err != nil || n < 1 {
+		return 0, filters.ErrInvalidFilterParameters
+	}
+
+	return n, nil
+}
+
 func (s *lifoSpec) Name() string { return filters.LifoName }
 
 // CreateFilter creates a lifoFilter, that will use a queue based
@@ -138,7 +162,8 @@ func (*lifoGroupSpec) Name() string { return filters.LifoGroupName }
 // CreateFilter creates a lifoGroupFilter, that will use a queue based
 // queue for handling requests instead of the fifo queue. The first
 // parameter is the Name, the second MaxConcurrency, the third
-// MaxQueueSize and the fourth Timeout.
+// MaxQueueSize, the fourth Timeout and the optional fifth is the
+// priority option in the form of "priorityConcurrency=<n>".
 //
 // The Name parameter is used to group the queue by one or
 // multiple routes. All other parameters are optional and defaults to
@@ -164,8 +189,15 @@ func (*lifoGroupSpec) Name() string { return filters.LifoGroupName }
 // is accidentally a difference between the settings in the same group, a
 // warning will be logged.
 //
+// When the priority concurrency is set, the requests marked by the
+// lifoPriority filter can use this number of reserved slots, in addition to
+// MaxConcurrency, e.g. to avoid liveness probes timing out behind the
+// regular traffic. The reserved slots are not a separate queue: when all of
+// them are taken, the priority requests wait in the queue of the group
+// without any precedence over the regular requests.
+//
 func (*lifoGroupSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
-	if len(args) < 1 || len(args) > 4 {
+	if len(args) < 1 || len(args) > 5 {
 		return nil, filters.ErrInvalidFilterParameters
 	}
 
@@ -217,6 +249,15 @@ func (*lifoGroupSpec) CreateFilter(args []interface{}) (filters.Filter, error) {
 		}
 	}
 
+	if len(args) > 4 {
+		n, err := priorityArg(args[4])
+		if err != nil {
+			return nil, err
+		}
+
+		l.config.PriorityConcurrency = n
+	}
+
 	return l, nil
 }
 
@@ -239,8 +280,9 @@ func (l *lifoFilter) GetQueue() *scheduler.Queue {
 // increase the number of inflight requests and respond to the caller,
 // if the bounded queue returns an error. Status code by Error:
 //
-// - 503 if jobqueue.ErrQueueFull
-// - 502 if jobqueue.ErrTimeout
+// - 503 if scheduler.ErrQueueFull
+// - 502 if scheduler.ErrQueueTimeout
+// - 504 if the request timeout was exceeded while waiting
 func (l *lifoFilter) Request(ctx filters.FilterContext) {
 	request(l.GetQueue(), scheduler.LIFOKey, ctx)
 }
@@ -278,8 +320,9 @@ func (l *lifoGroupFilter) GetQueue() *scheduler.Queue {
 // increase the number of inflight requests and respond to the caller,
 // if the bounded queue returns an error. Status code by Error:
 //
-// - 503 if jobqueue.ErrStackFull
-// - 502 if jobqueue.ErrTimeout
+// - 503 if scheduler.ErrQueueFull
+// - 502 if scheduler.ErrQueueTimeout
+// - 504 if the request timeout was exceeded while waiting
 func (l *lifoGroupFilter) Request(ctx filters.FilterContext) {
 	request(l.GetQueue(), scheduler.LIFOKey, ctx)
 }
@@ -296,24 +339,40 @@ func request(q *scheduler.Queue, key string, ctx filters.FilterContext) {
 		return
 	}
 
-	done, err := q.Wait()
+	var (
+		done func()
+		err  error
+	)
+
+	if priority, _ := ctx.StateBag()[scheduler.LIFOPriorityKey].(bool); priority {
+		done, err = q.WaitPriority(ctx.Request().Context())
+	} else {
+		done, err = q.WaitContext(ctx.Request().Context())
+	}
+
 	if err != nil {
 		if span := opentracing.SpanFromContext(ctx.Request().Context()); span != nil {
 			ext.Error.Set(span, true)
 		}
 		switch err {
-		case jobqueue.ErrStackFull:
+		case scheduler.ErrQueueFull:
 			log.Debugf("Failed to get an entry on to the queue to process QueueFull: %v for host %s", err, ctx.Request().Host)
 			ctx.Serve(&http.Response{
 				StatusCode: http.StatusServiceUnavailable,
 				Status:     "Queue Full - https://opensource.zalando.com/skipper/operation/operation/#scheduler",
 			})
-		case jobqueue.ErrTimeout:
+		case scheduler.ErrQueueTimeout:
 			log.Debugf("Failed to get an entry on to the queue to process Timeout: %v for host %s", err, ctx.Request().Host)
 			ctx.Serve(&http.Response{
 				StatusCode: http.StatusBadGateway,
 				Status:     "Queue timeout - https://opensource.zalando.com/skipper/operation/operation/#scheduler",
 			})
+		case context.DeadlineExceeded:
+			log.Debugf("Request timeout while waiting in the queue for host %s", ctx.Request().Host)
+			ctx.Serve(&http.Response{StatusCode: http.StatusGatewayTimeout})
+		case context.Canceled:
+			log.Debugf("Client canceled the request while waiting in the queue for host %s", ctx.Request().Host)
+			ctx.Serve(&http.Response{StatusCode: 499})
 		default:
 			log.Errorf("Unknown error for route based LIFO: %v for host %s", err, ctx.Request().Host)
 			ctx.Serve(&http.Response{StatusCode: http.StatusInternalServerError})
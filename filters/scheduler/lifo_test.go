@@ -16,6 +16,7 @@ import (
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
 	"github.com/zalando/skipper/metrics/metricstest"
 	"github.com/zalando/skipper/proxy"
 	"github.com/zalando/skipper/proxy/proxytest"
@@ -72,6 +73,42 @@ func TestNewLIFO(t *testing.T) {
 			},
 			wantCode: http.StatusOK,
 		},
+		{
+			name: "lifogroup with valid priority concurrency configuration",
+			args: []interface{}{
+				"mygroup",
+				10,
+				15,
+				"5s",
+				"priorityConcurrency=2",
+			},
+			schedFunc: NewLIFOGroup,
+			wantName:  filters.LifoGroupName,
+			wantKey:   "mygroup",
+			wantErr:   false,
+			wantConfig: scheduler.Config{
+				MaxConcurrency:      10,
+				MaxQueueSize:        15,
+				Timeout:             5 * time.Second,
+				PriorityConcurrency: 2,
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "lifogroup with invalid priority option, does not create filter",
+			args: []interface{}{
+				"mygroup",
+				10,
+				15,
+				"5s",
+				"priorityConcurrency=0",
+			},
+			schedFunc: NewLIFOGroup,
+			wantName:  filters.LifoGroupName,
+			wantKey:   "mygroup",
+			wantErr:   true,
+			wantCode:  http.StatusOK,
+		},
 		{
 			name: "lifogroup with valid float64 configuration",
 			args: []interface{}{
@@ -372,6 +409,85 @@ func TestLifoErrors(t *testing.T) {
 	})
 }
 
+func TestLifoGroupPriority(t *testing.T) {
+	dc, err := testdataclient.NewDoc(`r: * -> lifoGroup("g", 1, 10, "10s", "priorityConcurrency=1") -> <shunt>`)
+	require.NoError(t, err)
+
+	reg := scheduler.NewRegistry()
+	defer reg.Close()
+
+	fr := make(filters.Registry)
+	fr.Register(NewLIFOGroup())
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  fr,
+		DataClients:     []routing.DataClient{dc},
+		PostProcessors:  []routing.PostProcessor{reg},
+	})
+	defer rt.Close()
+
+	<-rt.FirstLoad()
+
+	req := &http.Request{URL: &url.URL{}, Header: http.Header{}}
+	r, _ := rt.Route(req)
+	require.NotNil(t, r)
+
+	f := r.Filters[0].Filter
+	q := f.(scheduler.LIFOFilter).GetQueue()
+
+	priority, err := NewLIFOPriority().CreateFilter(nil)
+	require.NoError(t, err)
+
+	newContext := func(header http.Header, isPriority bool) *filtertest.Context {
+		ctx := &filtertest.Context{
+			FRequest:  &http.Request{URL: &url.URL{}, Header: header},
+			FStateBag: make(map[string]interface{}),
+		}
+
+		if isPriority {
+			priority.Request(ctx)
+		}
+
+		return ctx
+	}
+
+	// take the only regular slot:
+	done, err := q.Wait()
+	require.NoError(t, err)
+	defer done()
+
+	// the priority request takes the reserved slot:
+	first := newContext(http.Header{}, true)
+	f.Request(first)
+	assert.False(t, first.FServed, "priority request was not expected to be served by the filter")
+	assert.Equal(t, scheduler.QueueStatus{ActiveRequests: 1}, q.Status())
+
+	// the reserved concurrency is bounded, the next priority request waits in the queue:
+	go f.Request(newContext(http.Header{}, true))
+	require.Eventually(t, func() bool {
+		return q.Status().QueuedRequests == 1
+	}, 120*time.Millisecond, time.Millisecond)
+
+	// releasing the reserved slot allows the next priority request:
+	f.Response(first)
+	second := newContext(http.Header{}, true)
+	f.Request(second)
+	assert.False(t, second.FServed, "priority request was not expected to be served by the filter")
+	f.Response(second)
+
+	// the client cannot make a request priority by a header:
+	go f.Request(newContext(http.Header{"X-Probe": []string{"1"}}, false))
+	require.Eventually(t, func() bool {
+		return q.Status().QueuedRequests == 2
+	}, 120*time.Millisecond, time.Millisecond)
+}
+
+func TestLifoPriorityArgs(t *testing.T) {
+	_, err := NewLIFOPriority().CreateFilter([]interface{}{"foo"})
+	assert.Equal(t, filters.ErrInvalidFilterParameters, err)
+}
+
 func requestSpike(t *testing.T, n int, url string) {
 	var wg sync.WaitGroup
 	wg.Add(n)
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/scheduler"
+)
+
+type lifoPrioritySpec struct{}
+
+type lifoPriorityFilter struct{}
+
+// NewLIFOPriority creates a filter spec, that marks the requests of the
+// route as priority requests for the lifoGroup filters later in the filter
+// chain. The priority requests can use the reserved priority concurrency of
+// the group, configured by the "priorityConcurrency=<n>" option. There is no
+// separate priority queue: when all the reserved slots are taken, the
+// priority requests wait in the LIFO queue of the group like any other
+// request.
+//
+// Example:
+//
+//    probe: Path("/healthz") -> lifoPriority() -> lifoGroup("g", 10, 12, "10s", "priorityConcurrency=2") -> "http://backend";
+func NewLIFOPriority() filters.Spec {
+	return &lifoPrioritySpec{}
+}
+
+func (*lifoPrioritySpec) Name() string { return filters.LifoPriorityName }
+
+func (*lifoPrioritySpec) CreateFilter(args []interface{}) (filters.Filter, error) {
+	if len(args) != 0 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	return &lifoPriorityFilter{}, nil
+}
+
+func (*lifoPriorityFilter) Request(ctx filters.FilterContext) {
+	ctx.StateBag()[scheduler.LIFOPriorityKey] = true
+}
+
+func (*lifoPriorityFilter) Response(filters.FilterContext) {}
@@ -75,5 +75,14 @@ Filter sedRequestDelim
 Like sedDelim(), but for the request content.
 
 	editorRoute: * -> sedRequestDelim("foo", "bar", "\n") -> "https://www.example.org";
+
+Filter rewriteResponseBody
+
+Like sed(), but the replacement is used as a template, where the submatches of the
+pattern can be referenced, e.g. $1 or ${name}, as in the regexp.Expand function of
+the Go standard library. Responses with a Content-Encoding other than identity,
+e.g. gzip, are not edited.
+
+	editorRoute: * -> rewriteResponseBody("/old/([a-z]+)", "/new/$1") -> "https://www.example.org";
 */
 package sed
@@ -51,11 +51,15 @@ const (
 // buffered data. If the input implements io.Closer, closing the editor closes the
 // input, too.
 //
+// When expand is set, the replacement is used as a template, where the submatches
+// of the pattern can be referenced as $1, ${name}, etc., as in regexp.Expand.
+//
 type editor struct {
 	// init:
 	input             io.Reader
 	pattern           *regexp.Regexp
 	replacement       []byte
+	expand            bool
 	delimiter         []byte
 	maxBufferSize     int
 	maxBufferHandling maxBufferHandling
@@ -125,7 +129,45 @@ func (e *editor) readNTimes(times int) (bool, error) {
 	return consumedInput, nil
 }
 
-func (e *editor) edit(b []byte, keepLastChunk bool) (int, bool) {
+func (e *editor) find(b []byte) []int {
+	if e.expand {
+		return e.pattern.FindSubmatchIndex(b)
+	}
+
+	return e.pattern.FindIndex(b)
+}
+
+func (e *editor) writeReplacement(src []byte, match []int) {
+	if !e.expand {
+		e.ready.Write(e.replacement)
+		return
+	}
+
+	e.ready.Write(e.pattern.Expand(nil, e.replacement, src, match))
+}
+
+// relativeMatch returns the submatch indexes shifted to the start of the
+// match, keeping the unmatched submatches as -1.
+func relativeMatch(match []int) []int {
+	rel := make([]int, len(match))
+	for i, m := range match {
+		if m < 0 {
+			rel[i] = -1
+			continue
+		}
+
+		rel[i] = m - match[0]
+	}
+
+	return rel
+}
+
+// edit replaces the matches in b, and returns the number of the consumed
+// bytes. When keepLastChunk is set, and the last match reaches the end of
+// b, it returns the submatch indexes of this match, relative to the first
+// not consumed byte, because the match may continue with the data not read
+// yet.
+func (e *editor) edit(b []byte, keepLastChunk bool) (int, []int) {
 	var consumed int
 	for len(b) > 0 {
 		if len(e.prefix) > 0 && len(b) >= len(e.prefix) {
@@ -137,15 +179,15 @@ func (e *editor) edit(b []byte, keepLastChunk bool) (int, bool) {
 			}
 		}
 
-		match := e.pattern.FindIndex(b)
+		match := e.find(b)
 		if len(match) == 0 {
 			if keepLastChunk {
-				return consumed, false
+				return consumed, nil
 			}
 
 			e.ready.Write(b)
 			consumed += len(b)
-			return consumed, false
+			return consumed, nil
 		}
 
 		e.ready.Write(b[:match[0]])
@@ -153,30 +195,30 @@ func (e *editor) edit(b []byte, keepLastChunk bool) (int, bool) {
 
 		if match[1] == match[0] {
 			if keepLastChunk {
-				return consumed, false
+				return consumed, nil
 			}
 
 			e.ready.Write(b[match[0]:])
 			consumed += len(b) - match[0]
-			return consumed, false
+			return consumed, nil
 		}
 
 		if keepLastChunk && match[1] == len(b) {
-			return consumed, true
+			return consumed, relativeMatch(match)
 		}
 
-		e.ready.Write(e.replacement)
+		e.writeReplacement(b, match)
 		consumed += match[1] - match[0]
 		b = b[match[1]:]
 	}
 
-	return consumed, false
+	return consumed, nil
 }
 
-func (e *editor) editUnbound() bool {
-	consumed, pendingMatches := e.edit(e.pending.Bytes(), true)
+func (e *editor) editUnbound() []int {
+	consumed, pendingMatch := e.edit(e.pending.Bytes(), true)
 	e.pending.Next(consumed)
-	return pendingMatches
+	return pendingMatch
 }
 
 func (e *editor) editDelimited() {
@@ -191,9 +233,13 @@ func (e *editor) editDelimited() {
 	}
 }
 
-func (e *editor) finalizeEdit(pendingMatches bool) {
-	if pendingMatches {
-		e.ready.Write(e.replacement)
+// finalizeEdit edits the remaining buffered data. When the last match
+// reached the end of the buffered data, it is replaced using its original
+// submatches, because matching it again without the preceding data could
+// yield a different result, e.g. with \b.
+func (e *editor) finalizeEdit(pendingMatch []int) {
+	if pendingMatch != nil {
+		e.writeReplacement(e.pending.Bytes(), pendingMatch)
 		return
 	}
 
@@ -206,26 +252,26 @@ func (e *editor) finalizeEdit(pendingMatches bool) {
 }
 
 func (e *editor) fill(requested int) error {
-	var pendingMatches bool
+	var pendingMatch []int
 	readSize := 1
 	for e.ready.Len() < requested {
 		consumedInput, err := e.readNTimes(readSize)
 		if !consumedInput {
 			if err != nil {
-				e.finalizeEdit(pendingMatches)
+				e.finalizeEdit(pendingMatch)
 			}
 
 			return err
 		}
 
 		if len(e.delimiter) == 0 {
-			pendingMatches = e.editUnbound()
+			pendingMatch = e.editUnbound()
 		} else {
 			e.editDelimited()
 		}
 
 		if err != nil {
-			e.finalizeEdit(pendingMatches)
+			e.finalizeEdit(pendingMatch)
 			return err
 		}
 
@@ -236,6 +282,7 @@ func (e *editor) fill(requested int) error {
 			default:
 				e.edit(e.pending.Bytes(), false)
 				e.pending.Reset()
+				pendingMatch = nil
 				readSize = 1
 			}
 		}
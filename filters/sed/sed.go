@@ -3,6 +3,7 @@ package sed
 import (
 	"regexp"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/zalando/skipper/filters"
 )
 
@@ -24,6 +25,7 @@ const (
 	delimited
 	simpleRequest
 	delimitedRequest
+	rewriteResponse
 )
 
 type spec struct {
@@ -63,6 +65,12 @@ func NewDelimitedRequest() filters.Spec {
 	return ofType(delimitedRequest)
 }
 
+// NewRewriteResponseBody creates a filter specification for the rewriteResponseBody()
+// filter.
+func NewRewriteResponseBody() filters.Spec {
+	return ofType(rewriteResponse)
+}
+
 func (s spec) Name() string {
 	switch s.typ {
 	case delimited:
@@ -71,6 +79,8 @@ func (s spec) Name() string {
 		return filters.SedRequestName
 	case delimitedRequest:
 		return filters.SedRequestDelimName
+	case rewriteResponse:
+		return filters.RewriteResponseBodyName
 	default:
 		return filters.SedName
 	}
@@ -178,7 +188,7 @@ func (s spec) CreateFilter(args []interface{}) (filters.Filter, error) {
 
 func (f filter) Request(ctx filters.FilterContext) {
 	switch f.typ {
-	case simple, delimited:
+	case simple, delimited, rewriteResponse:
 		return
 	}
 
@@ -202,9 +212,16 @@ func (f filter) Response(ctx filters.FilterContext) {
 	}
 
 	rsp := ctx.Response()
+	if f.typ == rewriteResponse {
+		if ce := rsp.Header.Get("Content-Encoding"); ce != "" && ce != "identity" {
+			log.Debugf("%s: not rewriting response body with content encoding %s", filters.RewriteResponseBodyName, ce)
+			return
+		}
+	}
+
 	rsp.Header.Del("Content-Length")
 	rsp.ContentLength = -1
-	rsp.Body = newEditor(
+	e := newEditor(
 		rsp.Body,
 		f.pattern,
 		f.replacement,
@@ -212,4 +229,7 @@ func (f filter) Response(ctx filters.FilterContext) {
 		f.maxEditorBuffer,
 		f.maxBufferHandling,
 	)
+
+	e.expand = f.typ == rewriteResponse
+	rsp.Body = e
 }
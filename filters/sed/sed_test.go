@@ -177,6 +177,75 @@ func TestSed(t *testing.T) {
 	}
 }
 
+func TestRewriteResponseBody(t *testing.T) {
+	args := func(a ...interface{}) []interface{} { return a }
+	for _, test := range []testItem{{
+		title:  "no match",
+		args:   args("/old/([a-z]+)", "/new/$1"),
+		body:   "<a href=\"/other/foo\">",
+		expect: "<a href=\"/other/foo\">",
+	}, {
+		title:  "capture groups are expanded",
+		args:   args("/old/([a-z]+)", "/new/$1"),
+		body:   "<a href=\"/old/foo\"><a href=\"/old/bar\">",
+		expect: "<a href=\"/new/foo\"><a href=\"/new/bar\">",
+	}, {
+		title:  "named capture groups are expanded",
+		args:   args("(?P<first>[a-z]+)-(?P<second>[a-z]+)", "${second}-${first}"),
+		body:   "foo-bar baz-qux",
+		expect: "bar-foo qux-baz",
+	}, {
+		title:  "match at the end of the body",
+		args:   args("foo([0-9]+)", "bar$1"),
+		body:   "foo1 foo123",
+		expect: "bar1 bar123",
+	}, {
+		title:  "context dependent match at the end of the body",
+		args:   args(`\B(b+)`, "[$1]"),
+		body:   "abb",
+		expect: "a[bb]",
+	}} {
+		t.Run(test.title, testResponse(filters.RewriteResponseBodyName, test))
+	}
+}
+
+func TestRewriteResponseBodyEncoded(t *testing.T) {
+	const body = "encoded foo"
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte(body))
+	}))
+	defer b.Close()
+
+	p := proxytest.New(builtin.MakeRegistry(), &eskip.Route{
+		Filters: []*eskip.Filter{{Name: filters.RewriteResponseBodyName, Args: []interface{}{"foo", "bar"}}},
+		Backend: b.URL,
+	})
+	defer p.Close()
+
+	req, err := http.NewRequest("GET", p.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// prevent the client from decoding the response:
+	req.Header.Set("Accept-Encoding", "gzip")
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rsp.Body.Close()
+	d, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(d) != body {
+		t.Errorf("Expected the encoded body unchanged, got: %s", string(d))
+	}
+}
+
 func TestSedLongStream(t *testing.T) {
 	const (
 		inputString  = "f"
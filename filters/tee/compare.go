@@ -0,0 +1,230 @@
+package tee
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+const (
+	defaultCompareMaxBodySize = 1 << 20
+
+	compareMatchKey    = "tee.compare.match"
+	compareMismatchKey = "tee.compare.mismatch"
+	compareErrorKey    = "tee.compare.error"
+)
+
+type teeCompareSpec struct {
+	options Options
+}
+
+type teeCompare struct {
+	tee         *tee
+	maxBodySize int64
+	compareDone func() // test hook
+}
+
+type shadowResult struct {
+	statusCode int
+	hash       []byte
+	err        error
+}
+
+// hashBody calculates the hash of the first bytes of the response body up
+// to a maximum size, while the body is streamed to the client. The hash is
+// passed on, when the body was read to the end.
+type hashBody struct {
+	body      io.ReadCloser
+	hash      hash.Hash
+	remaining int64
+	once      sync.Once
+	done      func([]byte)
+}
+
+// NewTeeCompare returns a new teeCompare filter Spec, whose instances
+// execute the exact same Request against a shadow backend, like tee, and
+// compare the status code and the hash of the shadow response body with
+// the ones of the main response. The result of the comparison is counted
+// in the tee.compare.match, tee.compare.mismatch and tee.compare.error
+// metrics, and the mismatches are logged. The comparison happens in the
+// background, and it doesn't delay the response.
+//
+// parameters: shadow backend url, optional - the max number of body bytes
+// used for the hash, defaults to 1MB.
+//
+// Name: "teeCompare".
+func NewTeeCompare() filters.Spec {
+	return NewTeeCompareWithOptions(Options{Timeout: defaultTeeTimeout})
+}
+
+// NewTeeCompareWithOptions returns a new teeCompare filter Spec with the
+// given options, see NewTeeCompare.
+func NewTeeCompareWithOptions(o Options) filters.Spec {
+	return &teeCompareSpec{options: o}
+}
+
+func (*teeCompareSpec) Name() string { return filters.TeeCompareName }
+
+func (spec *teeCompareSpec) CreateFilter(config []interface{}) (filters.Filter, error) {
+	if len(config) == 0 || len(config) > 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	backend, ok := config[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	u, err := url.Parse(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodySize := int64(defaultCompareMaxBodySize)
+	if len(config) == 2 {
+		size, ok := config[1].(float64)
+		if !ok || size < 0 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+
+		maxBodySize = int64(size)
+	}
+
+	client := &http.Client{Timeout: spec.options.Timeout}
+	if spec.options.NoFollow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return &teeCompare{
+		tee: &tee{
+			client: client,
+			typ:    asBackend,
+			host:   u.Host,
+			scheme: u.Scheme,
+		},
+		maxBodySize: maxBodySize,
+	}, nil
+}
+
+func (f *teeCompare) stateBagKey() string {
+	return fmt.Sprintf("%s:%p", filters.TeeCompareName, f)
+}
+
+// Request sends the copy of the request to the shadow backend, and stores
+// the channel of the shadow result in the state bag.
+func (f *teeCompare) Request(ctx filters.FilterContext) {
+	req := ctx.Request()
+	copyOfRequest, tr, err := cloneRequest(f.tee, req)
+	if err != nil {
+		log.Warn("teeCompare: error while cloning the tee request", err)
+		return
+	}
+
+	req.Body = tr
+
+	result := make(chan shadowResult, 1)
+	ctx.StateBag()[f.stateBagKey()] = result
+
+	go func() {
+		rsp, err := f.tee.client.Do(copyOfRequest)
+		if err != nil {
+			result <- shadowResult{err: err}
+			return
+		}
+
+		defer rsp.Body.Close()
+		h := sha256.New()
+		_, err = io.Copy(h, io.LimitReader(rsp.Body, f.maxBodySize))
+		result <- shadowResult{statusCode: rsp.StatusCode, hash: h.Sum(nil), err: err}
+	}()
+}
+
+// Response wraps the response body to calculate its hash, and compares
+// it with the shadow result once the body was streamed to the client.
+func (f *teeCompare) Response(ctx filters.FilterContext) {
+	result, ok := ctx.StateBag()[f.stateBagKey()].(chan shadowResult)
+	if !ok {
+		return
+	}
+
+	rsp := ctx.Response()
+	metrics := ctx.Metrics()
+	statusCode := rsp.StatusCode
+	compare := func(sum []byte) {
+		go f.compare(metrics, statusCode, sum, result)
+	}
+
+	if rsp.Body == nil {
+		compare(sha256.New().Sum(nil))
+		return
+	}
+
+	rsp.Body = &hashBody{
+		body:      rsp.Body,
+		hash:      sha256.New(),
+		remaining: f.maxBodySize,
+		done:      compare,
+	}
+}
+
+func (f *teeCompare) compare(metrics filters.Metrics, statusCode int, sum []byte, result <-chan shadowResult) {
+	if f.compareDone != nil {
+		defer f.compareDone()
+	}
+
+	// the shadow request is bound by the client timeout
+	r := <-result
+	switch {
+	case r.err != nil:
+		log.Warnf("teeCompare: error while tee request to %s: %v", f.tee.host, r.err)
+		metrics.IncCounter(compareErrorKey)
+	case r.statusCode != statusCode || !bytes.Equal(r.hash, sum):
+		log.Infof(
+			"teeCompare: shadow response of %s differs, status: %d, shadow status: %d, body equal: %t",
+			f.tee.host,
+			statusCode,
+			r.statusCode,
+			bytes.Equal(r.hash, sum),
+		)
+
+		metrics.IncCounter(compareMismatchKey)
+	default:
+		metrics.IncCounter(compareMatchKey)
+	}
+}
+
+func (b *hashBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.remaining > 0 && n > 0 {
+		h := p[:n]
+		if int64(len(h)) > b.remaining {
+			h = h[:b.remaining]
+		}
+
+		b.hash.Write(h)
+		b.remaining -= int64(len(h))
+	}
+
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.hash.Sum(nil)) })
+	}
+
+	return n, err
+}
+
+// Close closes the original body. When the body was not read to the end,
+// the comparison is skipped.
+func (b *hashBody) Close() error {
+	b.once.Do(func() {})
+	return b.body.Close()
+}
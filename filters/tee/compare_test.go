@@ -0,0 +1,128 @@
+package tee
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/metrics/metricstest"
+)
+
+func TestTeeCompareArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{
+		{"no args", nil, true},
+		{"not a string", []interface{}{42.0}, true},
+		{"size not a number", []interface{}{"https://shadow.example.org", "42"}, true},
+		{"negative size", []interface{}{"https://shadow.example.org", -1.0}, true},
+		{"too many args", []interface{}{"https://shadow.example.org", 42.0, 42.0}, true},
+		{"backend", []interface{}{"https://shadow.example.org"}, false},
+		{"backend and size", []interface{}{"https://shadow.example.org", 4096.0}, false},
+	} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewTeeCompare().CreateFilter(ti.args)
+			if ti.err && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTeeCompare(t *testing.T) {
+	for _, ti := range []struct {
+		msg          string
+		shadowStatus int
+		shadowBody   string
+		maxBodySize  int
+		expectedKey  string
+	}{{
+		msg:          "match",
+		shadowStatus: http.StatusOK,
+		shadowBody:   "Hello, world!",
+		expectedKey:  compareMatchKey,
+	}, {
+		msg:          "status mismatch",
+		shadowStatus: http.StatusInternalServerError,
+		shadowBody:   "Hello, world!",
+		expectedKey:  compareMismatchKey,
+	}, {
+		msg:          "body mismatch",
+		shadowStatus: http.StatusOK,
+		shadowBody:   "Hello, shadow!",
+		expectedKey:  compareMismatchKey,
+	}, {
+		msg:          "body difference beyond the max size",
+		shadowStatus: http.StatusOK,
+		shadowBody:   "Hello, shadow!",
+		maxBodySize:  5,
+		expectedKey:  compareMatchKey,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(ti.shadowStatus)
+				w.Write([]byte(ti.shadowBody))
+			}))
+			defer shadow.Close()
+
+			args := []interface{}{shadow.URL}
+			if ti.maxBodySize > 0 {
+				args = append(args, float64(ti.maxBodySize))
+			}
+
+			f, err := NewTeeCompare().CreateFilter(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done := make(chan struct{})
+			f.(*teeCompare).compareDone = func() { close(done) }
+
+			metrics := &metricstest.MockMetrics{}
+			req, _ := http.NewRequest("GET", "https://www.example.org/hello", nil)
+			ctx := &filtertest.Context{
+				FRequest:  req,
+				FMetrics:  metrics,
+				FStateBag: make(map[string]interface{}),
+			}
+
+			f.Request(ctx)
+			ctx.FResponse = &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("Hello, world!")),
+			}
+
+			f.Response(ctx)
+			io.Copy(io.Discard, ctx.FResponse.Body)
+			ctx.FResponse.Body.Close()
+			<-done
+
+			metrics.WithCounters(func(counters map[string]int64) {
+				if counters[ti.expectedKey] != 1 {
+					t.Errorf("unexpected counters: %v", counters)
+				}
+			})
+		})
+	}
+}
+
+func TestHashBodyClosedEarly(t *testing.T) {
+	var called bool
+	b := &hashBody{
+		body:      io.NopCloser(&io.LimitedReader{}),
+		remaining: 10,
+		done:      func([]byte) { called = true },
+	}
+
+	b.Close()
+	if called {
+		t.Error("unexpected comparison")
+	}
+}
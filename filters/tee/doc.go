@@ -21,5 +21,17 @@ It is possible to change the path of the tee request, in a similar way to the mo
 	Path("/api/v1") -> tee("https://api.example.org", "^/v1", "/v2" ) -> "http://api.example.org"
 
 In the above example, one can test how a new version of an API would behave on incoming requests.
+
+The mirror filter sends a copy of the request to a shadow backend, too, but it buffers the request body,
+so that the shadow backend cannot affect the main request in any way. Optionally, only a fraction of the
+requests can be mirrored:
+
+	* -> mirror("https://shadow.example.org", 0.1) -> "https://foo.example.org"
+
+The teeCompare filter works like tee, but in addition, it compares the status code and the hash of the body of
+the shadow response with the ones of the main response, in the background, and counts the results in the
+tee.compare.match, tee.compare.mismatch and tee.compare.error metrics:
+
+	* -> teeCompare("https://new-api.example.org") -> "https://api.example.org"
 */
 package tee
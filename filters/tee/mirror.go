@@ -0,0 +1,196 @@
+package tee
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/filters"
+)
+
+// maxMirrorBodySize is the maximum size of the request body buffered for
+// the mirror requests. Requests with larger bodies are not mirrored.
+const maxMirrorBodySize = 1 << 20
+
+type mirrorSpec struct {
+	options Options
+}
+
+type mirror struct {
+	client            *http.Client
+	host              string
+	scheme            string
+	rate              float64
+	shadowRequestDone func() // test hook
+}
+
+// NewMirror returns a new mirror filter Spec, whose instances send a copy of
+// the request to a shadow backend, asynchronously, and discard its
+// response. Unlike tee, the request body is buffered, so the shadow
+// backend can never slow down or fail the main request. Requests with a
+// body larger than 1MB are not mirrored.
+//
+// parameters: shadow backend url, optional - the rate of the mirrored
+// requests, between 0 and 1.
+//
+// Name: "mirror".
+func NewMirror() filters.Spec {
+	return NewMirrorWithOptions(Options{Timeout: defaultTeeTimeout})
+}
+
+// NewMirrorWithOptions returns a new mirror filter Spec with the given
+// options, see NewMirror.
+func NewMirrorWithOptions(o Options) filters.Spec {
+	return &mirrorSpec{options: o}
+}
+
+func (*mirrorSpec) Name() string { return filters.MirrorName }
+
+func (spec *mirrorSpec) CreateFilter(config []interface{}) (filters.Filter, error) {
+	if len(config) == 0 || len(config) > 2 {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	backend, ok := config[0].(string)
+	if !ok {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	u, err := url.Parse(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, filters.ErrInvalidFilterParameters
+	}
+
+	rate := 1.0
+	if len(config) == 2 {
+		rate, ok = config[1].(float64)
+		if !ok || rate < 0 || rate > 1 {
+			return nil, filters.ErrInvalidFilterParameters
+		}
+	}
+
+	client := &http.Client{Timeout: spec.options.Timeout}
+	if spec.options.NoFollow {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return &mirror{
+		client: client,
+		host:   u.Host,
+		scheme: u.Scheme,
+		rate:   rate,
+	}, nil
+}
+
+// bufferBody reads the request body up to the max mirror body size. When
+// the body is larger, it returns false, and the request body is restored
+// to return the full content.
+func bufferBody(req *http.Request) ([]byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true, nil
+	}
+
+	if req.ContentLength > maxMirrorBodySize {
+		return nil, false, nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(req.Body, maxMirrorBodySize+1))
+	if len(b) > maxMirrorBodySize || err != nil {
+		req.Body = &multiReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(b), req.Body),
+			closer: req.Body,
+		}
+
+		return nil, false, err
+	}
+
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, true, nil
+}
+
+type multiReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (m *multiReadCloser) Close() error { return m.closer.Close() }
+
+func (m *mirror) cloneRequest(req *http.Request, body []byte) (*http.Request, error) {
+	u := new(url.URL)
+	*u = *req.URL
+	u.Host = m.host
+	u.Scheme = m.scheme
+
+	var b io.Reader
+	if body != nil {
+		b = bytes.NewReader(body)
+	}
+
+	clone, err := http.NewRequest(req.Method, u.String(), b)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.Header = req.Header.Clone()
+	for _, k := range hopHeaders {
+		clone.Header.Del(k)
+	}
+
+	clone.Host = m.host
+	return clone, nil
+}
+
+// Request sends the buffered copy of the request to the shadow backend.
+func (m *mirror) Request(ctx filters.FilterContext) {
+	if m.rate < 1 && rand.Float64() >= m.rate {
+		return
+	}
+
+	req := ctx.Request()
+	body, ok, err := bufferBody(req)
+	if err != nil {
+		log.Warnf("mirror: error while buffering the request body: %v", err)
+		return
+	}
+
+	if !ok {
+		log.Debug("mirror: request body too large, not mirroring")
+		return
+	}
+
+	clone, err := m.cloneRequest(req, body)
+	if err != nil {
+		log.Warnf("mirror: error while cloning the request: %v", err)
+		return
+	}
+
+	go func() {
+		defer func() {
+			if m.shadowRequestDone != nil {
+				m.shadowRequestDone()
+			}
+		}()
+
+		rsp, err := m.client.Do(clone)
+		if err != nil {
+			log.Warnf("mirror: error while sending the mirror request: %v", err)
+			return
+		}
+
+		io.Copy(io.Discard, rsp.Body)
+		rsp.Body.Close()
+	}()
+}
+
+// Response is not modified.
+func (*mirror) Response(filters.FilterContext) {}
@@ -0,0 +1,150 @@
+package tee
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/proxy/proxytest"
+)
+
+func TestMirrorArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{
+		{"no args", nil, true},
+		{"not a string", []interface{}{42.0}, true},
+		{"not a url", []interface{}{"foo"}, true},
+		{"rate not a number", []interface{}{"https://shadow.example.org", "0.1"}, true},
+		{"rate out of range", []interface{}{"https://shadow.example.org", 1.5}, true},
+		{"too many args", []interface{}{"https://shadow.example.org", 0.1, 0.2}, true},
+		{"backend", []interface{}{"https://shadow.example.org"}, false},
+		{"backend and rate", []interface{}{"https://shadow.example.org", 0.1}, false},
+	} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewMirror().CreateFilter(ti.args)
+			if ti.err && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMirrorEndToEnd(t *testing.T) {
+	shadowHandler := newTestHandler(t, "shadow")
+	shadowServer := httptest.NewServer(shadowHandler)
+	defer shadowServer.Close()
+
+	originalHandler := newTestHandler(t, "original")
+	originalServer := httptest.NewServer(originalHandler)
+	defer originalServer.Close()
+
+	routeStr := fmt.Sprintf(`route1: * -> mirror("%v") -> "%v";`, shadowServer.URL, originalServer.URL)
+	route, _ := eskip.Parse(routeStr)
+	registry := make(filters.Registry)
+	registry.Register(NewMirror())
+	p := proxytest.New(registry, route...)
+	defer p.Close()
+
+	testingStr := "TESTEST"
+	req, err := http.NewRequest("POST", p.URL, strings.NewReader(testingStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("X-Test", "foo")
+	req.Close = true
+	rsp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	<-shadowHandler.served
+	if shadowHandler.body != testingStr || originalHandler.body != testingStr {
+		t.Error("bodies are not equal")
+	}
+
+	if shadowHandler.header.Get("X-Test") != "foo" {
+		t.Error("failed to copy the headers")
+	}
+}
+
+func TestMirrorShadowFailure(t *testing.T) {
+	shadowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadowServer.Close()
+
+	originalHandler := newTestHandler(t, "original")
+	originalServer := httptest.NewServer(originalHandler)
+	defer originalServer.Close()
+
+	routeStr := fmt.Sprintf(`route1: * -> mirror("%v") -> "%v";`, shadowServer.URL, originalServer.URL)
+	route, _ := eskip.Parse(routeStr)
+	registry := make(filters.Registry)
+	registry.Register(NewMirrorWithOptions(Options{Timeout: 10 * time.Millisecond}))
+	p := proxytest.New(registry, route...)
+	defer p.Close()
+
+	rsp, err := http.Post(p.URL, "text/plain", strings.NewReader("TESTEST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status code: %d", rsp.StatusCode)
+	}
+}
+
+func TestMirrorRate(t *testing.T) {
+	f, err := NewMirror().CreateFilter([]interface{}{"https://shadow.example.org", 0.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mirrored := false
+	f.(*mirror).shadowRequestDone = func() { mirrored = true }
+
+	r, _ := http.NewRequest("GET", "http://example.org/api/v3", nil)
+	f.Request(&filtertest.Context{FRequest: r})
+	if mirrored {
+		t.Error("unexpected mirror request")
+	}
+}
+
+func TestMirrorLargeBody(t *testing.T) {
+	body := strings.Repeat("x", maxMirrorBodySize+1)
+	r, _ := http.NewRequest("POST", "http://example.org/api/v3", io.NopCloser(strings.NewReader(body)))
+
+	b, ok, err := bufferBody(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok || b != nil {
+		t.Error("unexpected buffering of a large body")
+	}
+
+	restored, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(restored) != body {
+		t.Error("failed to restore the request body")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"net/url"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -35,6 +36,17 @@ const (
 
 	// PowerOfRandomNChoices selects N random endpoints and picks the one with least outstanding requests from them.
 	PowerOfRandomNChoices
+
+	// WeightedRandom indicates random choice between the backend endpoints, proportional to their weights.
+	WeightedRandom
+
+	// LeastOutstanding selects the endpoint with the least outstanding requests.
+	LeastOutstanding
+
+	// ZoneAware indicates round-robin load balancing between the backend endpoints in the
+	// same topology zone as the proxy, falling back to all the zones when there is no healthy
+	// endpoint in the same zone.
+	ZoneAware
 )
 
 const powerOfRandomNChoicesDefaultN = 2
@@ -43,12 +55,30 @@ const (
 	ConsistentHashBalanceFactor = "consistentHashBalanceFactor"
 )
 
+// WeightParam is the name of the query parameter of the LB endpoint
+// addresses that sets the weight of the endpoint for the weightedRandom
+// algorithm, e.g. http://10.0.0.1:8080?weight=5.
+const WeightParam = "weight"
+
+// RampStartParam is the name of the query parameter of the LB endpoint
+// addresses that sets the start of the fade-in of the endpoint, instead of
+// its detection time, either in RFC3339 format or as the number of seconds
+// since the unix epoch, e.g. http://10.0.0.1:8080?rampStart=2021-06-01T12:00:00Z.
+const RampStartParam = "rampStart"
+
+// ZoneParam is the name of the query parameter of the LB endpoint addresses
+// that sets the topology zone of the endpoint for the zoneAware algorithm,
+// e.g. http://10.0.0.1:8080?zone=eu-central-1a.
+const ZoneParam = "zone"
+
 var (
 	algorithms = map[Algorithm]initializeAlgorithm{
 		RoundRobin:            newRoundRobin,
 		Random:                newRandom,
 		ConsistentHash:        newConsistentHash,
 		PowerOfRandomNChoices: newPowerOfRandomNChoices,
+		WeightedRandom:        newWeightedRandom,
+		LeastOutstanding:      newLeastOutstanding,
 	}
 	defaultAlgorithm = newRoundRobin
 )
@@ -93,7 +123,12 @@ func shiftWeighted(rnd *rand.Rand, ctx *routing.LBContext, w []float64, now time
 func shiftToRemaining(rnd *rand.Rand, ctx *routing.LBContext, wi []int, wf []float64, now time.Time) routing.LBEndpoint {
 	notFadingIndexes := wi
 	ep := ctx.Route.LBEndpoints
+	excluded := exclusions(ctx)
 	for i := 0; i < len(ep); i++ {
+		if excluded[ep[i].Host] {
+			continue
+		}
+
 		if _, fadingIn := fadeInState(now, ctx.Route.LBFadeInDuration, ep[i].Detected); !fadingIn {
 			notFadingIndexes = append(notFadingIndexes, i)
 		}
@@ -127,6 +162,74 @@ func withFadeIn(rnd *rand.Rand, ctx *routing.LBContext, wi []int, wf []float64,
 	return shiftToRemaining(rnd, ctx, wi, wf, now)
 }
 
+// exclusions returns the endpoints excluded for the request, e.g. the ones
+// that failed already. When all the endpoints are excluded, it returns
+// nil, and the algorithms fall back to all the endpoints.
+func exclusions(ctx *routing.LBContext) map[string]bool {
+	excluded := ctx.ExcludedEndpoints
+	if len(excluded) == 0 {
+		return nil
+	}
+
+	for _, e := range ctx.Route.LBEndpoints {
+		if !excluded[e.Host] {
+			return excluded
+		}
+	}
+
+	return nil
+}
+
+// countIncluded returns the number of the endpoints that are not excluded.
+func countIncluded(ep []routing.LBEndpoint, excluded map[string]bool) int {
+	if len(excluded) == 0 {
+		return len(ep)
+	}
+
+	var n int
+	for _, e := range ep {
+		if !excluded[e.Host] {
+			n++
+		}
+	}
+
+	return n
+}
+
+// nthIncluded returns the index of the nth endpoint that is not excluded.
+func nthIncluded(ep []routing.LBEndpoint, excluded map[string]bool, n int) int {
+	if len(excluded) == 0 {
+		return n
+	}
+
+	for i, e := range ep {
+		if excluded[e.Host] {
+			continue
+		}
+
+		if n == 0 {
+			return i
+		}
+
+		n--
+	}
+
+	return len(ep) - 1
+}
+
+// nextIncluded returns the index of the first endpoint, starting from i,
+// that is not excluded.
+func nextIncluded(ep []routing.LBEndpoint, excluded map[string]bool, i int) int {
+	for j := 0; j < len(ep); j++ {
+		k := (i + j) % len(ep)
+		if !excluded[ep[k].Host] {
+			return k
+		}
+	}
+
+	return i
+}
+
 type roundRobin struct {
 	mx               sync.Mutex
 	index            int
@@ -147,7 +250,8 @@ func newRoundRobin(endpoints []string) routing.LBAlgorithm {
 	}
 }
 
-// Apply implements routing.LBAlgorithm with a roundrobin algorithm.
+// Apply implements routing.LBAlgorithm with a roundrobin algorithm. The
+// endpoints excluded for the request are skipped.
 func (r *roundRobin) Apply(ctx *routing.LBContext) routing.LBEndpoint {
 	if len(ctx.Route.LBEndpoints) == 1 {
 		return ctx.Route.LBEndpoints[0]
@@ -156,12 +260,13 @@ func (r *roundRobin) Apply(ctx *routing.LBContext) routing.LBEndpoint {
 	r.mx.Lock()
 	defer r.mx.Unlock()
 	r.index = (r.index + 1) % len(ctx.Route.LBEndpoints)
+	choice := nextIncluded(ctx.Route.LBEndpoints, exclusions(ctx), r.index)
 
 	if ctx.Route.LBFadeInDuration <= 0 {
-		return ctx.Route.LBEndpoints[r.index]
+		return ctx.Route.LBEndpoints[choice]
 	}
 
-	return withFadeIn(r.rnd, ctx, r.notFadingIndexes, r.fadingWeights, r.index)
+	return withFadeIn(r.rnd, ctx, r.notFadingIndexes, r.fadingWeights, choice)
 }
 
 type random struct {
@@ -183,12 +288,15 @@ func newRandom(endpoints []string) routing.LBAlgorithm {
 }
 
 // Apply implements routing.LBAlgorithm with a stateless random algorithm.
+// The endpoints excluded for the request are not chosen.
 func (r *random) Apply(ctx *routing.LBContext) routing.LBEndpoint {
-	if len(ctx.Route.LBEndpoints) == 1 {
-		return ctx.Route.LBEndpoints[0]
+	ep := ctx.Route.LBEndpoints
+	if len(ep) == 1 {
+		return ep[0]
 	}
 
-	i := r.rand.Intn(len(ctx.Route.LBEndpoints))
+	excluded := exclusions(ctx)
+	i := nthIncluded(ep, excluded, r.rand.Intn(countIncluded(ep, excluded)))
 	if ctx.Route.LBFadeInDuration <= 0 {
 		return ctx.Route.LBEndpoints[i]
 	}
@@ -244,6 +352,20 @@ func (ch consistentHash) search(key string) int {
 	return ch[ringIndex].index
 }
 
+// Returns index of endpoint with closest hash to key's hash, which is not excluded
+func (ch consistentHash) searchIncluded(key string, excluded map[string]bool, ctx *routing.LBContext) int {
+	ringIndex := ch.searchRing(key)
+	for i := 0; i < ch.Len(); i++ {
+		if !excluded[ctx.Route.LBEndpoints[ch[ringIndex].index].Host] {
+			break
+		}
+
+		ringIndex = (ringIndex + 1) % ch.Len()
+	}
+
+	return ch[ringIndex].index
+}
+
 func computeLoadAverage(ctx *routing.LBContext) float64 {
 	sum := 1.0 // add 1 to include the request that just arrived
 	endpoints := ctx.Route.LBEndpoints
@@ -253,27 +375,30 @@ func computeLoadAverage(ctx *routing.LBContext) float64 {
 	return sum / float64(len(endpoints))
 }
 
-// Returns index of endpoint with closest hash to key's hash, which is also below the target load
-func (ch consistentHash) boundedLoadSearch(key string, balanceFactor float64, ctx *routing.LBContext) int {
+// Returns index of endpoint with closest hash to key's hash, which is also below the target load, and not excluded
+func (ch consistentHash) boundedLoadSearch(key string, balanceFactor float64, excluded map[string]bool, ctx *routing.LBContext) int {
 	ringIndex := ch.searchRing(key)
 	averageLoad := computeLoadAverage(ctx)
 	targetLoad := averageLoad * balanceFactor
 	// Loop round ring, starting at endpoint with closest hash. Stop when we find one whose load is less than targetLoad.
 	for i := 0; i < ch.Len(); i++ {
-		endpointIndex := ch[ringIndex].index
-		load := ctx.Route.LBEndpoints[endpointIndex].Metrics.GetInflightRequests()
+		endpoint := ctx.Route.LBEndpoints[ch[ringIndex].index]
+		load := endpoint.Metrics.GetInflightRequests()
 		// We know there must be an endpoint whose load <= average load.
 		// Since targetLoad >= average load (balancerFactor >= 1), there must also be an endpoint with load <= targetLoad.
-		if load <= int(targetLoad) {
-			break
+		if load <= int(targetLoad) && !excluded[endpoint.Host] {
+			return ch[ringIndex].index
 		}
 		ringIndex = (ringIndex + 1) % ch.Len()
 	}
 
-	return ch[ringIndex].index
+	// the endpoints below the target load were all excluded
+	return ch.searchIncluded(key, excluded, ctx)
 }
 
 // Apply implements routing.LBAlgorithm with a consistent hash algorithm.
+// When the endpoint of the key is excluded for the request, the next one
+// on the hash ring is chosen.
 func (ch consistentHash) Apply(ctx *routing.LBContext) routing.LBEndpoint {
 	if len(ctx.Route.LBEndpoints) == 1 {
 		return ctx.Route.LBEndpoints[0]
@@ -283,12 +408,13 @@ func (ch consistentHash) Apply(ctx *routing.LBContext) routing.LBEndpoint {
 	if !ok {
 		key = net.RemoteHost(ctx.Request).String()
 	}
+	excluded := exclusions(ctx)
 	balanceFactor, ok := ctx.Params[ConsistentHashBalanceFactor].(float64)
 	var choice int
 	if !ok {
-		choice = ch.search(key)
+		choice = ch.searchIncluded(key, excluded, ctx)
 	} else {
-		choice = ch.boundedLoadSearch(key, balanceFactor, ctx)
+		choice = ch.boundedLoadSearch(key, balanceFactor, excluded, ctx)
 	}
 
 	return ctx.Route.LBEndpoints[choice]
@@ -310,16 +436,19 @@ func newPowerOfRandomNChoices(endpoints []string) routing.LBAlgorithm {
 }
 
 // Apply implements routing.LBAlgorithm with power of random N choices algorithm.
+// The endpoints excluded for the request are not chosen.
 func (p *powerOfRandomNChoices) Apply(ctx *routing.LBContext) routing.LBEndpoint {
-	ne := len(ctx.Route.LBEndpoints)
+	ep := ctx.Route.LBEndpoints
+	excluded := exclusions(ctx)
+	ne := countIncluded(ep, excluded)
 
 	p.mx.Lock()
 	defer p.mx.Unlock()
 
-	best := ctx.Route.LBEndpoints[p.rand.Intn(ne)]
+	best := ep[nthIncluded(ep, excluded, p.rand.Intn(ne))]
 
 	for i := 1; i < p.numberOfChoices; i++ {
-		ce := ctx.Route.LBEndpoints[p.rand.Intn(ne)]
+		ce := ep[nthIncluded(ep, excluded, p.rand.Intn(ne))]
 
 		if p.getScore(ce) > p.getScore(best) {
 			best = ce
@@ -334,17 +463,240 @@ func (p *powerOfRandomNChoices) getScore(e routing.LBEndpoint) int {
 	return -e.Metrics.GetInflightRequests()
 }
 
+type weightedRandom struct {
+	mx   sync.Mutex
+	rand *rand.Rand
+}
+
+func newWeightedRandom(endpoints []string) routing.LBAlgorithm {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec
+	return &weightedRandom{rand: rnd}
+}
+
+// Apply implements routing.LBAlgorithm with a random algorithm, where the
+// probability of choosing an endpoint is proportional to its weight. When
+// fade-in is configured, the weights of the endpoints are multiplied by
+// their fade-in factor. When the sum of the weights is zero, the endpoints
+// are chosen with equal probability. The endpoints excluded for the request
+// are not chosen.
+func (w *weightedRandom) Apply(ctx *routing.LBContext) routing.LBEndpoint {
+	ep := ctx.Route.LBEndpoints
+	if len(ep) == 1 {
+		return ep[0]
+	}
+
+	now := time.Now()
+	rt := ctx.Route
+	excluded := exclusions(ctx)
+	var sum float64
+	for _, e := range ep {
+		if !excluded[e.Host] {
+			sum += e.Weight * fadeIn(now, rt.LBFadeInDuration, rt.LBFadeInExponent, e.Detected)
+		}
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if sum <= 0 {
+		return ep[nthIncluded(ep, excluded, w.rand.Intn(countIncluded(ep, excluded)))]
+	}
+
+	r := w.rand.Float64() * sum
+	last := len(ep) - 1
+	for i, e := range ep {
+		if excluded[e.Host] {
+			continue
+		}
+
+		last = i
+		r -= e.Weight * fadeIn(now, rt.LBFadeInDuration, rt.LBFadeInExponent, e.Detected)
+		if r < 0 {
+			return e
+		}
+	}
+
+	return ep[last]
+}
+
+type leastOutstanding struct {
+	mx               sync.Mutex
+	rand             *rand.Rand
+	notFadingIndexes []int
+	fadingWeights    []float64
+}
+
+func newLeastOutstanding(endpoints []string) routing.LBAlgorithm {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec
+	return &leastOutstanding{
+		rand: rnd,
+
+		// preallocating frequently used slice
+		notFadingIndexes: make([]int, 0, len(endpoints)),
+		fadingWeights:    make([]float64, 0, len(endpoints)),
+	}
+}
+
+// Apply implements routing.LBAlgorithm with an algorithm selecting the endpoint
+// with the least outstanding requests, breaking the ties randomly. The
+// outstanding requests are counted by the proxy in the endpoint metrics. The
+// endpoints excluded for the request are not chosen.
+func (l *leastOutstanding) Apply(ctx *routing.LBContext) routing.LBEndpoint {
+	ep := ctx.Route.LBEndpoints
+	if len(ep) == 1 {
+		return ep[0]
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	excluded := exclusions(ctx)
+	first := nextIncluded(ep, excluded, 0)
+	choice, least, ties := first, ep[first].Metrics.GetInflightRequests(), 1
+	for i := first + 1; i < len(ep); i++ {
+		if excluded[ep[i].Host] {
+			continue
+		}
+
+		switch n := ep[i].Metrics.GetInflightRequests(); {
+		case n < least:
+			choice, least, ties = i, n, 1
+		case n == least:
+			// reservoir sampling, to choose from the ties with equal probability
+			ties++
+			if l.rand.Intn(ties) == 0 {
+				choice = i
+			}
+		}
+	}
+
+	if ctx.Route.LBFadeInDuration <= 0 {
+		return ep[choice]
+	}
+
+	return withFadeIn(l.rand, ctx, l.notFadingIndexes, l.fadingWeights, choice)
+}
+
+type zoneAware struct {
+	mx               sync.Mutex
+	index            int
+	zone             string
+	rnd              *rand.Rand
+	candidates       []routing.LBEndpoint
+	fadeInRoute      routing.Route
+	notFadingIndexes []int
+	fadingWeights    []float64
+}
+
+// newZoneAware initializes the zoneAware algorithm for the given zone of
+// the proxy.
+func newZoneAware(endpoints []string, zone string) routing.LBAlgorithm {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano())) // #nosec
+	return &zoneAware{
+		index: rnd.Intn(len(endpoints)),
+		zone:  zone,
+		rnd:   rnd,
+
+		// preallocating frequently used slices
+		candidates:       make([]routing.LBEndpoint, 0, len(endpoints)),
+		notFadingIndexes: make([]int, 0, len(endpoints)),
+		fadingWeights:    make([]float64, 0, len(endpoints)),
+	}
+}
+
+// selects the endpoints in the same zone as the proxy, that were not
+// excluded for the request. When there are none, it selects the not
+// excluded endpoints of all the zones, and when all of them were excluded,
+// it falls back to all the endpoints.
+func (z *zoneAware) selectEndpoints(ctx *routing.LBContext) []routing.LBEndpoint {
+	ep := ctx.Route.LBEndpoints
+	excluded := ctx.ExcludedEndpoints
+	c := z.candidates[:0]
+	if z.zone != "" {
+		for _, e := range ep {
+			if e.Zone == z.zone && !excluded[e.Host] {
+				c = append(c, e)
+			}
+		}
+	}
+
+	if len(c) == 0 {
+		for _, e := range ep {
+			if !excluded[e.Host] {
+				c = append(c, e)
+			}
+		}
+	}
+
+	z.candidates = c
+	if len(c) == 0 {
+		return ep
+	}
+
+	return c
+}
+
+// Apply implements routing.LBAlgorithm with a roundrobin algorithm over the
+// endpoints in the same zone as the proxy. The endpoints excluded for the
+// request, e.g. the ones that failed already, are not considered, and when
+// the zone of the proxy has no other endpoints, it uses the endpoints of
+// all the zones. The fade-in of the new endpoints is applied to the
+// selected endpoints.
+func (z *zoneAware) Apply(ctx *routing.LBContext) routing.LBEndpoint {
+	ep := ctx.Route.LBEndpoints
+	if len(ep) == 1 {
+		return ep[0]
+	}
+
+	z.mx.Lock()
+	defer z.mx.Unlock()
+	candidates := z.selectEndpoints(ctx)
+	z.index++
+	choice := z.index % len(candidates)
+	if ctx.Route.LBFadeInDuration <= 0 {
+		return candidates[choice]
+	}
+
+	// the fade-in is applied only between the selected endpoints
+	z.fadeInRoute.LBEndpoints = candidates
+	z.fadeInRoute.LBFadeInDuration = ctx.Route.LBFadeInDuration
+	z.fadeInRoute.LBFadeInExponent = ctx.Route.LBFadeInExponent
+	return withFadeIn(
+		z.rnd,
+		&routing.LBContext{Request: ctx.Request, Route: &z.fadeInRoute, Params: ctx.Params},
+		z.notFadingIndexes,
+		z.fadingWeights,
+		choice,
+	)
+}
+
 type (
-	algorithmProvider   struct{}
+	algorithmProvider struct {
+		zone string
+	}
+
 	initializeAlgorithm func(endpoints []string) routing.LBAlgorithm
 )
 
+// AlgorithmProviderOptions are used to initialize the algorithm provider.
+type AlgorithmProviderOptions struct {
+	// Zone is the topology zone of the proxy instance, used by the
+	// zoneAware algorithm.
+	Zone string
+}
+
 // NewAlgorithmProvider creates a routing.PostProcessor used to initialize
 // the algorithm of load balancing routes.
 func NewAlgorithmProvider() routing.PostProcessor {
 	return &algorithmProvider{}
 }
 
+// NewAlgorithmProviderWithOptions creates a routing.PostProcessor used to
+// initialize the algorithm of load balancing routes, with the given options.
+func NewAlgorithmProviderWithOptions(o AlgorithmProviderOptions) routing.PostProcessor {
+	return &algorithmProvider{zone: o.Zone}
+}
+
 // AlgorithmFromString parses the string representation of the algorithm definition.
 func AlgorithmFromString(a string) (Algorithm, error) {
 	switch a {
@@ -360,6 +712,12 @@ func AlgorithmFromString(a string) (Algorithm, error) {
 		return ConsistentHash, nil
 	case "powerOfRandomNChoices":
 		return PowerOfRandomNChoices, nil
+	case "weightedRandom":
+		return WeightedRandom, nil
+	case "leastOutstanding":
+		return LeastOutstanding, nil
+	case "zoneAware":
+		return ZoneAware, nil
 	default:
 		return None, errors.New("unsupported algorithm")
 	}
@@ -376,6 +734,12 @@ func (a Algorithm) String() string {
 		return "consistentHash"
 	case PowerOfRandomNChoices:
 		return "powerOfRandomNChoices"
+	case WeightedRandom:
+		return "weightedRandom"
+	case LeastOutstanding:
+		return "leastOutstanding"
+	case ZoneAware:
+		return "zoneAware"
 	default:
 		return ""
 	}
@@ -389,22 +753,75 @@ func parseEndpoints(r *routing.Route) error {
 			return err
 		}
 
+		w, err := parseWeight(eu)
+		if err != nil {
+			return err
+		}
+
+		rs, err := parseRampStart(eu)
+		if err != nil {
+			return err
+		}
+
 		r.LBEndpoints[i] = routing.LBEndpoint{
-			Scheme:  eu.Scheme,
-			Host:    eu.Host,
-			Metrics: &routing.LBMetrics{},
+			Scheme:    eu.Scheme,
+			Host:      eu.Host,
+			Metrics:   &routing.LBMetrics{},
+			Weight:    w,
+			RampStart: rs,
+			Zone:      eu.Query().Get(ZoneParam),
 		}
 	}
 
 	return nil
 }
 
-func setAlgorithm(r *routing.Route) error {
+// parseWeight returns the weight of an LB endpoint set by the weight query
+// parameter, or 1 when it is not set.
+func parseWeight(u *url.URL) (float64, error) {
+	v := u.Query().Get(WeightParam)
+	if v == "" {
+		return 1, nil
+	}
+
+	w, err := strconv.ParseFloat(v, 64)
+	if err != nil || w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+		return 0, fmt.Errorf("invalid weight of LB endpoint: %s", u)
+	}
+
+	return w, nil
+}
+
+// parseRampStart returns the fade-in start of an LB endpoint set by the
+// rampStart query parameter, or the zero time when it is not set.
+func parseRampStart(u *url.URL) (time.Time, error) {
+	v := u.Query().Get(RampStartParam)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	if s, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(s, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid ramp start of LB endpoint: %s", u)
+}
+
+func setAlgorithm(r *routing.Route, zone string) error {
 	t, err := AlgorithmFromString(r.Route.LBAlgorithm)
 	if err != nil {
 		return err
 	}
 
+	if t == ZoneAware {
+		r.LBAlgorithm = newZoneAware(r.Route.LBEndpoints, zone)
+		return nil
+	}
+
 	initialize := defaultAlgorithm
 	if t != None {
 		initialize = algorithms[t]
@@ -433,7 +850,7 @@ func (p *algorithmProvider) Do(r []*routing.Route) []*routing.Route {
 			continue
 		}
 
-		if err := setAlgorithm(ri); err != nil {
+		if err := setAlgorithm(ri, p.zone); err != nil {
 			log.Errorf("failed to set LB algorithm implementation for route %s: %v", ri.Id, err)
 			continue
 		}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/net"
@@ -192,6 +193,47 @@ func TestSelectAlgorithm(t *testing.T) {
 		}
 	})
 
+	t.Run("LB route with ramp start", func(t *testing.T) {
+		p := NewAlgorithmProvider()
+		r := &routing.Route{
+			Route: eskip.Route{
+				BackendType: eskip.LBBackend,
+				LBEndpoints: []string{
+					"http://10.0.0.1:8080?rampStart=2021-06-01T12:00:00Z",
+					"http://10.0.0.2:8080?rampStart=1622548800",
+					"http://10.0.0.3:8080",
+				},
+			},
+		}
+
+		rr := p.Do([]*routing.Route{r})
+		if len(rr) != 1 {
+			t.Fatal("failed to process LB route")
+		}
+
+		expected := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		if !rr[0].LBEndpoints[0].RampStart.Equal(expected) ||
+			!rr[0].LBEndpoints[1].RampStart.Equal(expected) ||
+			!rr[0].LBEndpoints[2].RampStart.IsZero() {
+			t.Fatal("failed to set the ramp start of the endpoints")
+		}
+	})
+
+	t.Run("LB route with invalid ramp start", func(t *testing.T) {
+		p := NewAlgorithmProvider()
+		r := &routing.Route{
+			Route: eskip.Route{
+				BackendType: eskip.LBBackend,
+				LBEndpoints: []string{"http://10.0.0.1:8080?rampStart=yesterday"},
+			},
+		}
+
+		rr := p.Do([]*routing.Route{r})
+		if len(rr) != 0 {
+			t.Fatal("failed to drop invalid LB route")
+		}
+	})
+
 	t.Run("LB route with invalid LB endpoints", func(t *testing.T) {
 		p := NewAlgorithmProvider()
 		r := &routing.Route{
@@ -244,6 +286,16 @@ func TestApply(t *testing.T) {
 			expected:      N,
 			algorithm:     newPowerOfRandomNChoices(eps),
 			algorithmName: "powerOfRandomNChoices",
+		}, {
+			name:          "weightedRandom algorithm",
+			expected:      N,
+			algorithm:     newWeightedRandom(eps),
+			algorithmName: "weightedRandom",
+		}, {
+			name:          "leastOutstanding algorithm",
+			expected:      N,
+			algorithm:     newLeastOutstanding(eps),
+			algorithmName: "leastOutstanding",
 		}} {
 		t.Run(tt.name, func(t *testing.T) {
 			req, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
@@ -275,6 +327,347 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApplyExcluded(t *testing.T) {
+	const R = 1000
+	const N = 10
+	eps := make([]string, 0, N)
+	for i := 0; i < N; i++ {
+		eps = append(eps, fmt.Sprintf("http://127.0.0.1:123%d/foo", i))
+	}
+
+	for _, tt := range []struct {
+		algorithmName string
+		expected      int
+	}{
+		{"roundRobin", N - 3},
+		{"random", N - 3},
+		{"consistentHash", 1},
+		{"powerOfRandomNChoices", N - 3},
+		{"weightedRandom", N - 3},
+		{"leastOutstanding", N - 3},
+	} {
+		t.Run(tt.algorithmName, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
+			rt := NewAlgorithmProvider().Do([]*routing.Route{{
+				Route: eskip.Route{
+					BackendType: eskip.LBBackend,
+					LBAlgorithm: tt.algorithmName,
+					LBEndpoints: eps,
+				},
+			}})[0]
+
+			apply := func(excluded map[string]bool) map[string]int {
+				lbctx := &routing.LBContext{Request: req, Route: rt, ExcludedEndpoints: excluded}
+				h := make(map[string]int)
+				for i := 0; i < R; i++ {
+					h[rt.LBAlgorithm.Apply(lbctx).Host]++
+				}
+
+				return h
+			}
+
+			excluded := map[string]bool{
+				"127.0.0.1:1230": true,
+				"127.0.0.1:1233": true,
+				"127.0.0.1:1239": true,
+			}
+
+			h := apply(excluded)
+			for host := range h {
+				if excluded[host] {
+					t.Fatalf("Excluded endpoint selected: %s", host)
+				}
+			}
+
+			if len(h) != tt.expected {
+				t.Fatalf("Failed to get expected result %d != %d", tt.expected, len(h))
+			}
+
+			all := make(map[string]bool)
+			for _, e := range rt.LBEndpoints {
+				all[e.Host] = true
+			}
+
+			if h := apply(all); len(h) == 0 {
+				t.Fatal("Failed to fall back to the excluded endpoints")
+			}
+		})
+	}
+}
+
+func TestWeightedRandom(t *testing.T) {
+	t.Run("invalid weight", func(t *testing.T) {
+		for _, ep := range []string{
+			"http://127.0.0.1:1234?weight=foo",
+			"http://127.0.0.1:1234?weight=-1",
+		} {
+			p := NewAlgorithmProvider()
+			r := &routing.Route{
+				Route: eskip.Route{
+					BackendType: eskip.LBBackend,
+					LBAlgorithm: "weightedRandom",
+					LBEndpoints: []string{ep, "http://127.0.0.1:1235"},
+				},
+			}
+
+			if rr := p.Do([]*routing.Route{r}); len(rr) != 0 {
+				t.Errorf("failed to drop LB route with invalid weight: %s", ep)
+			}
+		}
+	})
+
+	for _, tt := range []struct {
+		name     string
+		eps      []string
+		expected map[string]float64
+	}{{
+		name: "equal weights when not specified",
+		eps:  []string{"http://127.0.0.1:1230", "http://127.0.0.1:1231"},
+		expected: map[string]float64{
+			"127.0.0.1:1230": .5,
+			"127.0.0.1:1231": .5,
+		},
+	}, {
+		name: "proportional to weights",
+		eps:  []string{"http://127.0.0.1:1230?weight=95", "http://127.0.0.1:1231?weight=5"},
+		expected: map[string]float64{
+			"127.0.0.1:1230": .95,
+			"127.0.0.1:1231": .05,
+		},
+	}, {
+		name: "default weight is one",
+		eps:  []string{"http://127.0.0.1:1230?weight=3", "http://127.0.0.1:1231"},
+		expected: map[string]float64{
+			"127.0.0.1:1230": .75,
+			"127.0.0.1:1231": .25,
+		},
+	}, {
+		name: "zero weight",
+		eps:  []string{"http://127.0.0.1:1230?weight=0", "http://127.0.0.1:1231"},
+		expected: map[string]float64{
+			"127.0.0.1:1231": 1,
+		},
+	}, {
+		name: "equal weights when all are zero",
+		eps:  []string{"http://127.0.0.1:1230?weight=0", "http://127.0.0.1:1231?weight=0"},
+		expected: map[string]float64{
+			"127.0.0.1:1230": .5,
+			"127.0.0.1:1231": .5,
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			const R = 10000
+			p := NewAlgorithmProvider()
+			r := &routing.Route{
+				Route: eskip.Route{
+					BackendType: eskip.LBBackend,
+					LBAlgorithm: "weightedRandom",
+					LBEndpoints: tt.eps,
+				},
+			}
+
+			rr := p.Do([]*routing.Route{r})
+			if len(rr) != 1 {
+				t.Fatal("failed to process LB route")
+			}
+
+			if _, ok := rr[0].LBAlgorithm.(*weightedRandom); !ok {
+				t.Fatal("failed to set the right algorithm")
+			}
+
+			req, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
+			lbctx := &routing.LBContext{Request: req, Route: rr[0]}
+			h := make(map[string]int)
+			for i := 0; i < R; i++ {
+				h[rr[0].LBAlgorithm.Apply(lbctx).Host]++
+			}
+
+			if len(h) != len(tt.expected) {
+				t.Fatalf("unexpected endpoints chosen: %v", h)
+			}
+
+			for host, expected := range tt.expected {
+				if got := float64(h[host]) / R; math.Abs(got-expected) > .02 {
+					t.Errorf("unexpected ratio for %s, got: %f, expected: %f", host, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLeastOutstanding(t *testing.T) {
+	p := NewAlgorithmProvider()
+	r := &routing.Route{
+		Route: eskip.Route{
+			BackendType: eskip.LBBackend,
+			LBAlgorithm: "leastOutstanding",
+			LBEndpoints: []string{"http://127.0.0.1:1230", "http://127.0.0.1:1231", "http://127.0.0.1:1232"},
+		},
+	}
+
+	rr := p.Do([]*routing.Route{r})
+	if len(rr) != 1 {
+		t.Fatal("failed to process LB route")
+	}
+
+	if _, ok := rr[0].LBAlgorithm.(*leastOutstanding); !ok {
+		t.Fatal("failed to set the right algorithm")
+	}
+
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
+	lbctx := &routing.LBContext{Request: req, Route: rr[0]}
+	eps := rr[0].LBEndpoints
+
+	t.Run("least outstanding", func(t *testing.T) {
+		addInflightRequests(eps[0], 3)
+		addInflightRequests(eps[1], 1)
+		addInflightRequests(eps[2], 2)
+		for i := 0; i < 100; i++ {
+			if e := rr[0].LBAlgorithm.Apply(lbctx); e.Host != eps[1].Host {
+				t.Fatalf("unexpected endpoint, got: %s, expected: %s", e.Host, eps[1].Host)
+			}
+		}
+	})
+
+	t.Run("ties broken randomly", func(t *testing.T) {
+		addInflightRequests(eps[1], 1)
+		h := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			h[rr[0].LBAlgorithm.Apply(lbctx).Host]++
+		}
+
+		if len(h) != 2 || h[eps[1].Host] == 0 || h[eps[2].Host] == 0 {
+			t.Fatalf("failed to break the ties between the endpoints: %v", h)
+		}
+	})
+}
+
+func TestZoneAware(t *testing.T) {
+	process := func(t *testing.T, zone string, endpoints ...string) *routing.Route {
+		p := NewAlgorithmProviderWithOptions(AlgorithmProviderOptions{Zone: zone})
+		r := &routing.Route{
+			Route: eskip.Route{
+				BackendType: eskip.LBBackend,
+				LBAlgorithm: "zoneAware",
+				LBEndpoints: endpoints,
+			},
+		}
+
+		rr := p.Do([]*routing.Route{r})
+		if len(rr) != 1 {
+			t.Fatal("failed to process LB route")
+		}
+
+		if _, ok := rr[0].LBAlgorithm.(*zoneAware); !ok {
+			t.Fatal("failed to set the right algorithm")
+		}
+
+		return rr[0]
+	}
+
+	applyExcluded := func(r *routing.Route, excluded map[string]bool) map[string]int {
+		req, _ := http.NewRequest("GET", "http://127.0.0.1:1234/foo", nil)
+		lbctx := &routing.LBContext{Request: req, Route: r, ExcludedEndpoints: excluded}
+		h := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			h[r.LBAlgorithm.Apply(lbctx).Host]++
+		}
+
+		return h
+	}
+
+	apply := func(r *routing.Route) map[string]int {
+		return applyExcluded(r, nil)
+	}
+
+	t.Run("same zone preferred", func(t *testing.T) {
+		r := process(
+			t,
+			"zone-a",
+			"http://127.0.0.1:1230?zone=zone-a",
+			"http://127.0.0.1:1231?zone=zone-b",
+			"http://127.0.0.1:1232?zone=zone-a",
+		)
+
+		if r.LBEndpoints[0].Zone != "zone-a" || r.LBEndpoints[1].Zone != "zone-b" {
+			t.Fatalf("failed to parse the endpoint zones: %v", r.LBEndpoints)
+		}
+
+		h := apply(r)
+		if len(h) != 2 || h["127.0.0.1:1230"] != 50 || h["127.0.0.1:1232"] != 50 {
+			t.Fatalf("failed to prefer the same zone endpoints: %v", h)
+		}
+	})
+
+	t.Run("fallback to all zones", func(t *testing.T) {
+		r := process(
+			t,
+			"zone-c",
+			"http://127.0.0.1:1230?zone=zone-a",
+			"http://127.0.0.1:1231?zone=zone-b",
+		)
+
+		h := apply(r)
+		if len(h) != 2 || h["127.0.0.1:1230"] != 50 || h["127.0.0.1:1231"] != 50 {
+			t.Fatalf("failed to fall back to all the endpoints: %v", h)
+		}
+	})
+
+	t.Run("excluded same zone endpoints", func(t *testing.T) {
+		r := process(
+			t,
+			"zone-a",
+			"http://127.0.0.1:1230?zone=zone-a",
+			"http://127.0.0.1:1231?zone=zone-b",
+			"http://127.0.0.1:1232?zone=zone-a",
+			"http://127.0.0.1:1233?zone=zone-b",
+		)
+
+		h := applyExcluded(r, map[string]bool{"127.0.0.1:1230": true})
+		if len(h) != 1 || h["127.0.0.1:1232"] != 100 {
+			t.Fatalf("failed to prefer the remaining same zone endpoint: %v", h)
+		}
+
+		h = applyExcluded(r, map[string]bool{"127.0.0.1:1230": true, "127.0.0.1:1232": true})
+		if len(h) != 2 || h["127.0.0.1:1231"] != 50 || h["127.0.0.1:1233"] != 50 {
+			t.Fatalf("failed to fall back to the other zones: %v", h)
+		}
+
+		all := map[string]bool{"127.0.0.1:1230": true, "127.0.0.1:1231": true, "127.0.0.1:1232": true, "127.0.0.1:1233": true}
+		if h = applyExcluded(r, all); len(h) != 4 {
+			t.Fatalf("failed to fall back to all the endpoints: %v", h)
+		}
+	})
+
+	t.Run("fade-in", func(t *testing.T) {
+		r := process(
+			t,
+			"zone-a",
+			"http://127.0.0.1:1230?zone=zone-a",
+			"http://127.0.0.1:1231?zone=zone-b",
+			"http://127.0.0.1:1232?zone=zone-a",
+		)
+
+		r.LBFadeInDuration = time.Hour
+		r.LBFadeInExponent = 1
+		r.LBEndpoints[0].Detected = time.Now().Add(-2 * time.Hour)
+		r.LBEndpoints[1].Detected = time.Now().Add(-2 * time.Hour)
+		r.LBEndpoints[2].Detected = time.Now().Add(-time.Second)
+
+		h := apply(r)
+		if h["127.0.0.1:1231"] != 0 || h["127.0.0.1:1232"] > 10 {
+			t.Fatalf("failed to fade in the new same zone endpoint: %v", h)
+		}
+	})
+
+	t.Run("no proxy zone", func(t *testing.T) {
+		r := process(t, "", "http://127.0.0.1:1230?zone=zone-a", "http://127.0.0.1:1231")
+		if h := apply(r); len(h) != 2 {
+			t.Fatalf("failed to fall back to all the endpoints: %v", h)
+		}
+	})
+}
+
 func TestConsistentHashSearch(t *testing.T) {
 	apply := func(key string, endpoints []string) string {
 		ch := newConsistentHash(endpoints).(consistentHash)
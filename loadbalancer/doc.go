@@ -25,7 +25,21 @@ powerOfRandomNChoices Algorithm
 	and picks the one with least outstanding requests from them.
 	Currently, N is 2.
 
-The roundRobin and the random algorithms also provide fade-in behavior for LB endpoints of routes where the
+weightedRandom Algorithm
+
+	The weightedRandom algorithm does proxy requests to random backend
+	endpoints, with a probability proportional to their weights. The
+	weight of an endpoint can be set with the weight query parameter
+	of its address, e.g. http://127.0.0.1:9998?weight=5, and it
+	defaults to 1.
+
+leastOutstanding Algorithm
+
+	The leastOutstanding algorithm selects the endpoint with the least
+	outstanding requests, counted by the proxy, and breaks the ties
+	randomly.
+
+The roundRobin, the random, the weightedRandom and the leastOutstanding algorithms also provide fade-in behavior for LB endpoints of routes where the
 fade-in duration was configured. This feature can be used to gradually add traffic to new instances of
 applications that require a certain amount of warm-up time.
 
@@ -36,6 +50,8 @@ Eskip example:
         r2: * -> <consistentHash, "http://127.0.0.1:9998", "http://127.0.0.1:9997">;
         r3: * -> <random, "http://127.0.0.1:9998", "http://127.0.0.1:9997">;
         r4: * -> <powerOfRandomNChoices, "http://127.0.0.1:9998", "http://127.0.0.1:9997">;
+        r5: * -> <weightedRandom, "http://127.0.0.1:9998?weight=95", "http://127.0.0.1:9997?weight=5">;
+        r6: * -> <leastOutstanding, "http://127.0.0.1:9998", "http://127.0.0.1:9997">;
 
 
 Package loadbalancer also implements health checking of pool members for
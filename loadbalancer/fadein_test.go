@@ -69,6 +69,7 @@ func testFadeIn(
 		for i := range ep {
 			ctx.Route.LBEndpoints = append(ctx.Route.LBEndpoints, routing.LBEndpoint{
 				Host:     ep[i],
+				Metrics:  &routing.LBMetrics{},
 				Detected: detectionTimes[i],
 			})
 		}
@@ -165,4 +166,11 @@ func TestFadeIn(t *testing.T) {
 	testFadeIn(t, "random, 7", newRandom, old, 0, 0, 0, 0, 0, 0)
 	testFadeIn(t, "random, 8", newRandom, 0, 0, 0, 0, 0, 0)
 	testFadeIn(t, "random, 9", newRandom, fadeInDuration/2, fadeInDuration/3, fadeInDuration/4)
+
+	testFadeIn(t, "least-outstanding, 0", newLeastOutstanding, old, old)
+	testFadeIn(t, "least-outstanding, 1", newLeastOutstanding, 0, old)
+	testFadeIn(t, "least-outstanding, 2", newLeastOutstanding, 0, 0)
+	testFadeIn(t, "least-outstanding, 3", newLeastOutstanding, old, 0)
+	testFadeIn(t, "least-outstanding, 4", newLeastOutstanding, old, old, old, 0)
+	testFadeIn(t, "least-outstanding, 5", newLeastOutstanding, old, 0, 0, 0)
 }
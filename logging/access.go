@@ -1,6 +1,8 @@
 package logging
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,6 +29,10 @@ type accessLogFormatter struct {
 	format string
 }
 
+type jsonFieldsFormatter struct {
+	fields []string
+}
+
 // Access log entry.
 type AccessEntry struct {
 
@@ -44,14 +50,44 @@ type AccessEntry struct {
 
 	// The time that the request was received.
 	RequestTime time.Time
+
+	// The id of the matched route.
+	RouteID string
+
+	// The host of the backend that the request was forwarded to.
+	BackendHost string
+
+	// The client IP resolved from the trusted proxies. When set, it is
+	// logged instead of the X-Forwarded-For header.
+	ClientIP net.IP
+}
+
+// the fields of the access log entries, accepted by AccessLogJSONFields
+var accessLogJSONFieldNames = map[string]bool{
+	"timestamp":      true,
+	"host":           true,
+	"method":         true,
+	"uri":            true,
+	"proto":          true,
+	"referer":        true,
+	"user-agent":     true,
+	"status":         true,
+	"response-size":  true,
+	"requested-host": true,
+	"duration":       true,
+	"flow-id":        true,
+	"audit":          true,
+	"route-id":       true,
+	"backend-host":   true,
 }
 
 // TODO: create individual instances from the access log and
 // delegate the ownership from the package level to the user
 // code.
 var (
-	accessLog  *logrus.Logger
-	stripQuery bool
+	accessLog       *logrus.Logger
+	stripQuery      bool
+	accessLogFields []string
 )
 
 // strip port from addresses with hostname, ipv4 or ipv6
@@ -98,6 +134,60 @@ func (f *accessLogFormatter) Format(e *logrus.Entry) ([]byte, error) {
 	return []byte(fmt.Sprintf(f.format, values...)), nil
 }
 
+// Format creates a JSON object from the configured fields of the entry, in
+// the configured order. The missing fields are omitted.
+func (f *jsonFieldsFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	var written bool
+	for _, key := range f.fields {
+		v, ok := e.Data[key]
+		if !ok {
+			continue
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		jv, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if written {
+			b.WriteByte(',')
+		}
+
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(jv)
+		written = true
+	}
+
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
+
+// ValidateAccessLogJSONFields checks that the JSON access log fields are
+// the fields of the access log entries, or the keys of the additional
+// data set by the filters, that are explicitly allowed.
+func ValidateAccessLogJSONFields(fields, additional []string) error {
+	allowed := make(map[string]bool, len(additional))
+	for _, a := range additional {
+		allowed[a] = true
+	}
+
+	for _, f := range fields {
+		if !accessLogJSONFieldNames[f] && !allowed[f] {
+			return fmt.Errorf("unknown access log JSON field: %s", f)
+		}
+	}
+
+	return nil
+}
+
 func stripQueryString(u string) string {
 	if i := strings.IndexRune(u, '?'); i < 0 {
 		return u
@@ -131,6 +221,9 @@ func LogAccess(entry *AccessEntry, additional map[string]interface{}) {
 
 	if entry.Request != nil {
 		host = remoteHost(entry.Request)
+		if entry.ClientIP != nil {
+			host = entry.ClientIP.String()
+		}
 		method = entry.Request.Method
 		proto = entry.Request.Proto
 		referer = entry.Request.Referer()
@@ -162,6 +255,11 @@ func LogAccess(entry *AccessEntry, additional map[string]interface{}) {
 		"audit":          auditHeader,
 	}
 
+	if len(accessLogFields) > 0 {
+		logData["route-id"] = entry.RouteID
+		logData["backend-host"] = entry.BackendHost
+	}
+
 	for k, v := range additional {
 		logData[k] = v
 	}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"bytes"
+	"net"
 	"net/http"
 	"testing"
 	"time"
@@ -73,6 +74,62 @@ func TestAccessLogFormatJSONWithAdditionalData(t *testing.T) {
 	testAccessLogExtended(t, testAccessEntry(), map[string]interface{}{"extra": "extra"}, logExtendedJSONOutput, Options{AccessLogJSONEnabled: true})
 }
 
+func TestAccessLogFormatJSONFields(t *testing.T) {
+	entry := testAccessEntry()
+	entry.RouteID = "api"
+	entry.BackendHost = "10.0.0.1:8080"
+	testAccessLogExtended(
+		t,
+		entry,
+		map[string]interface{}{"extra": "extra"},
+		`{"method":"GET","uri":"/apache_pb.gif","status":418,"duration":42,"route-id":"api","backend-host":"10.0.0.1:8080","extra":"extra"}`,
+		Options{
+			AccessLogJSONEnabled: true,
+			AccessLogJSONFields:  []string{"method", "uri", "status", "duration", "route-id", "backend-host", "extra", "missing"},
+		},
+	)
+}
+
+func TestValidateAccessLogJSONFields(t *testing.T) {
+	for _, ti := range []struct {
+		name       string
+		fields     []string
+		additional []string
+		fail       bool
+	}{{
+		name: "no fields",
+	}, {
+		name:   "known fields",
+		fields: []string{"method", "uri", "status", "duration", "route-id", "backend-host"},
+	}, {
+		name:       "allowed additional field",
+		fields:     []string{"method", "extra"},
+		additional: []string{"extra"},
+	}, {
+		name:   "unknown field",
+		fields: []string{"method", "extra"},
+		fail:   true,
+	}, {
+		name:       "misspelled field",
+		fields:     []string{"route_id"},
+		additional: []string{"extra"},
+		fail:       true,
+	}} {
+		t.Run(ti.name, func(t *testing.T) {
+			err := ValidateAccessLogJSONFields(ti.fields, ti.additional)
+			if ti.fail && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.fail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAccessLogFormatJSONFieldsIgnoredWithoutJSON(t *testing.T) {
+	testAccessLog(t, testAccessEntry(), logOutput, Options{AccessLogJSONFields: []string{"method"}})
+}
+
 func TestAccessLogIgnoresEmptyEntry(t *testing.T) {
 	testAccessLogDefault(t, nil, "")
 }
@@ -128,6 +185,17 @@ func TestUseXForwardedJSON(t *testing.T) {
 	)
 }
 
+func TestUseClientIP(t *testing.T) {
+	entry := testAccessEntry()
+	entry.Request.Header.Set("X-Forwarded-For", "192.168.3.3, 192.168.4.4")
+	entry.ClientIP = net.ParseIP("192.168.4.4")
+	testAccessLogDefault(
+		t,
+		entry,
+		`192.168.4.4 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.1" 418 2326 "-" "-" 42 example.com - -`,
+	)
+}
+
 func TestPortFwd4(t *testing.T) {
 	entry := testAccessEntry()
 	entry.Request.Header.Set("X-Forwarded-For", "192.168.3.3:6969")
@@ -45,6 +45,12 @@ type Options struct {
 	// AccessLogJsonFormatter, when set and JSON logging is enabled, is passed along to to the underlying
 	// Logrus logger for access logs. To enable structured logging, use AccessLogJSONEnabled.
 	AccessLogJsonFormatter *logrus.JSONFormatter
+
+	// AccessLogJSONFields, when set and JSON logging is enabled, defines the fields of the access log
+	// entries, and their order. Besides the fields of the default format, it accepts route-id,
+	// backend-host and the keys of the additional data set by the filters. It takes precedence over
+	// AccessLogJsonFormatter. See ValidateAccessLogJSONFields.
+	AccessLogJSONFields []string
 }
 
 func (f *prefixFormatter) Format(e *logrus.Entry) ([]byte, error) {
@@ -74,8 +80,12 @@ func initApplicationLog(o Options) {
 
 func initAccessLog(o Options) {
 	l := logrus.New()
+	accessLogFields = nil
 	if o.AccessLogJSONEnabled {
-		if o.AccessLogJsonFormatter != nil {
+		if len(o.AccessLogJSONFields) > 0 {
+			l.Formatter = &jsonFieldsFormatter{fields: o.AccessLogJSONFields}
+			accessLogFields = o.AccessLogJSONFields
+		} else if o.AccessLogJsonFormatter != nil {
 			l.Formatter = o.AccessLogJsonFormatter
 		} else {
 			l.Formatter = &logrus.JSONFormatter{TimestampFormat: dateFormat, DisableTimestamp: true}
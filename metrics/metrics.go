@@ -170,6 +170,18 @@ type Options struct {
 	// histogram metrics.
 	HistogramBuckets []float64
 
+	// RoutingHistogramBuckets overrides the HistogramBuckets for the route
+	// lookup histograms.
+	RoutingHistogramBuckets []float64
+
+	// BackendHistogramBuckets overrides the HistogramBuckets for the
+	// backend histograms.
+	BackendHistogramBuckets []float64
+
+	// FilterHistogramBuckets overrides the HistogramBuckets for the filter
+	// histograms.
+	FilterHistogramBuckets []float64
+
 	// The following options, for backwards compatibility, are true
 	// by default: EnableAllFiltersMetrics, EnableRouteResponseMetrics,
 	// EnableRouteBackendErrorsCounters, EnableRouteStreamingErrorsCounters,
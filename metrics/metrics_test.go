@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -207,3 +208,35 @@ func TestHandlerCodaHaleUnknownMetricRequest(t *testing.T) {
 		t.Error("Request for unknown metrics should return a Not Found status")
 	}
 }
+
+func TestParseHistogramBuckets(t *testing.T) {
+	for _, tt := range []struct {
+		spec    string
+		want    []float64
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "0.1", want: []float64{0.1}},
+		{spec: "1, 0.0005,0.01", want: []float64{0.0005, 0.01, 1}},
+		{spec: "0.1,foo", wantErr: true},
+	} {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := metrics.ParseHistogramBuckets(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected buckets: %v, expected: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -61,12 +61,16 @@ func NewPrometheus(opts Options) *Prometheus {
 		namespace = strings.TrimSuffix(opts.Prefix, ".")
 	}
 
+	routingBuckets := histogramBuckets(opts.RoutingHistogramBuckets, opts.HistogramBuckets)
+	backendBuckets := histogramBuckets(opts.BackendHistogramBuckets, opts.HistogramBuckets)
+	filterBuckets := histogramBuckets(opts.FilterHistogramBuckets, opts.HistogramBuckets)
+
 	routeLookup := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: namespace,
 		Subsystem: promRouteSubsystem,
 		Name:      "lookup_duration_seconds",
 		Help:      "Duration in seconds of a route lookup.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   routingBuckets,
 	}, []string{})
 
 	routeErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -89,7 +93,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "request_duration_seconds",
 		Help:      "Duration in seconds of a filter request.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{"filter"})
 
 	filterAllRequest := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -97,7 +101,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "all_request_duration_seconds",
 		Help:      "Duration in seconds of a filter request by all filters.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{"route"})
 
 	filterAllCombinedRequest := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -105,7 +109,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "all_combined_request_duration_seconds",
 		Help:      "Duration in seconds of a filter request combined by all filters.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{})
 
 	proxyBackend := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -113,7 +117,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promProxySubsystem,
 		Name:      "duration_seconds",
 		Help:      "Duration in seconds of a proxy backend.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   backendBuckets,
 	}, []string{"route", "host"})
 
 	proxyBackendCombined := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -121,7 +125,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promProxySubsystem,
 		Name:      "combined_duration_seconds",
 		Help:      "Duration in seconds of a proxy backend combined.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   backendBuckets,
 	}, []string{})
 
 	filterResponse := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -129,7 +133,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "response_duration_seconds",
 		Help:      "Duration in seconds of a filter request.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{"filter"})
 
 	filterAllResponse := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -137,7 +141,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "all_response_duration_seconds",
 		Help:      "Duration in seconds of a filter response by all filters.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{"route"})
 
 	filterAllCombinedResponse := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -145,7 +149,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promFilterSubsystem,
 		Name:      "all_combined_response_duration_seconds",
 		Help:      "Duration in seconds of a filter response combined by all filters.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   filterBuckets,
 	}, []string{})
 
 	metrics := []string{}
@@ -188,7 +192,7 @@ func NewPrometheus(opts Options) *Prometheus {
 		Subsystem: promProxySubsystem,
 		Name:      "5xx_duration_seconds",
 		Help:      "Duration in seconds of backend 5xx.",
-		Buckets:   opts.HistogramBuckets,
+		Buckets:   backendBuckets,
 	}, []string{})
 	proxyBackendErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
@@ -69,6 +69,26 @@ func TestPrometheusMetrics(t *testing.T) {
 			},
 			expCode: http.StatusOK,
 		},
+		{
+			name: "Measuring the routes lookup with custom routing buckets should use the routing buckets.",
+			opts: metrics.Options{
+				HistogramBuckets:        []float64{0.005, 0.01},
+				RoutingHistogramBuckets: []float64{0.001, 0.004},
+			},
+			addMetrics: func(pm *metrics.Prometheus) {
+				pm.MeasureRouteLookup(time.Now().Add(-3 * time.Millisecond))
+				pm.MeasureFilterRequest("filter1", time.Now().Add(-3*time.Millisecond))
+			},
+			expMetrics: []string{
+				`skipper_route_lookup_duration_seconds_bucket{le="0.001"} 0`,
+				`skipper_route_lookup_duration_seconds_bucket{le="0.004"} 1`,
+				`skipper_route_lookup_duration_seconds_bucket{le="+Inf"} 1`,
+				`skipper_filter_request_duration_seconds_bucket{filter="filter1",le="0.005"} 1`,
+				`skipper_filter_request_duration_seconds_bucket{filter="filter1",le="0.01"} 1`,
+				`skipper_filter_request_duration_seconds_bucket{filter="filter1",le="+Inf"} 1`,
+			},
+			expCode: http.StatusOK,
+		},
 		{
 			name: "Measuring the filter requests should get the duration of the filter requests.",
 			addMetrics: func(pm *metrics.Prometheus) {
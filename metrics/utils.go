@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	metrics "github.com/rcrowley/go-metrics"
@@ -18,6 +21,38 @@ func createTimer(sample metrics.Sample) metrics.Timer {
 	return metrics.NewCustomTimer(metrics.NewHistogram(sample), metrics.NewMeter())
 }
 
+// ParseHistogramBuckets parses a comma separated list of numbers, to be
+// used as the buckets of the histogram metrics. The returned buckets are
+// sorted. An empty string results in nil buckets.
+func ParseHistogramBuckets(s string) ([]float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var buckets []float64
+	for _, v := range strings.Split(s, ",") {
+		b, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid histogram bucket %q: %w", v, err)
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	sort.Float64s(buckets)
+	return buckets, nil
+}
+
+// histogramBuckets returns the buckets specific to a metric family, when
+// set, otherwise the general buckets.
+func histogramBuckets(specific, general []float64) []float64 {
+	if len(specific) > 0 {
+		return specific
+	}
+
+	return general
+}
+
 func hostForKey(h string) string {
 	h = strings.Replace(h, ".", "_", -1)
 	h = strings.Replace(h, ":", "__", -1)
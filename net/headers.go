@@ -1,8 +1,10 @@
 package net
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"strings"
 )
 
 // Sets non-standard X-Forwarded-* Headers
@@ -64,3 +66,61 @@ func (h *ForwardedHeadersHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 	}
 	h.Handler.ServeHTTP(w, r)
 }
+
+type clientIPKey struct{}
+
+// ClientIP returns the client IP resolved by the TrustedProxiesHandler,
+// or nil, when the handler is not used.
+func ClientIP(r *http.Request) net.IP {
+	ip, _ := r.Context().Value(clientIPKey{}).(net.IP)
+	return ip
+}
+
+// TrustedProxiesHandler resolves the client IP, walking the X-Forwarded-For
+// addresses from right to left, and skipping the trusted proxies. The
+// X-Forwarded-For header of requests from untrusted remote hosts is
+// ignored, because it can be set by the client. The headers are forwarded
+// to the backends unchanged, and the client IP is passed in the request
+// context, see ClientIP. This way the client IP based features, e.g. the
+// source predicates and the ratelimits, can rely on it.
+type TrustedProxiesHandler struct {
+	Trusted IPNets
+	Handler http.Handler
+}
+
+func (h *TrustedProxiesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if client := h.clientIP(r); client != nil {
+		r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, client))
+	}
+
+	h.Handler.ServeHTTP(w, r)
+}
+
+func (h *TrustedProxiesHandler) clientIP(r *http.Request) net.IP {
+	remote := parse(r.RemoteAddr)
+	if remote == nil || !h.Trusted.Contain(remote) {
+		return remote
+	}
+
+	client := remote
+	ffs := r.Header.Values("X-Forwarded-For")
+	if len(ffs) == 0 {
+		return client
+	}
+
+	ffa := strings.Split(strings.Join(ffs, ","), ",")
+	for i := len(ffa) - 1; i >= 0; i-- {
+		// the addresses left from an invalid one cannot be trusted
+		ip := parse(strings.TrimSpace(ffa[i]))
+		if ip == nil {
+			break
+		}
+
+		client = ip
+		if !h.Trusted.Contain(ip) {
+			break
+		}
+	}
+
+	return client
+}
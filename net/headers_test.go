@@ -1,6 +1,7 @@
 package net
 
 import (
+	"net"
 	"net/http"
 	"reflect"
 	"testing"
@@ -174,3 +175,111 @@ func TestForwardedHeadersHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestTrustedProxiesHandler(t *testing.T) {
+	for _, ti := range []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		expected   string
+	}{
+		{
+			name:       "untrusted remote without xff",
+			remoteAddr: "1.2.3.4:56",
+			header:     http.Header{},
+			expected:   "1.2.3.4",
+		},
+		{
+			name:       "untrusted remote xff ignored",
+			remoteAddr: "1.2.3.4:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"5.6.7.8"},
+			},
+			expected: "1.2.3.4",
+		},
+		{
+			name:       "trusted remote without xff",
+			remoteAddr: "10.0.0.1:56",
+			header:     http.Header{},
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "trusted remote",
+			remoteAddr: "10.0.0.1:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"1.2.3.4"},
+			},
+			expected: "1.2.3.4",
+		},
+		{
+			name:       "injected leftmost address",
+			remoteAddr: "10.0.0.1:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"6.6.6.6, 1.2.3.4, 10.0.0.2"},
+			},
+			expected: "1.2.3.4",
+		},
+		{
+			name:       "multiple headers",
+			remoteAddr: "10.0.0.1:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"6.6.6.6, 1.2.3.4", "10.0.0.2"},
+			},
+			expected: "1.2.3.4",
+		},
+		{
+			name:       "all trusted",
+			remoteAddr: "10.0.0.1:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"10.0.0.3, 10.0.0.2"},
+			},
+			expected: "10.0.0.3",
+		},
+		{
+			name:       "invalid address",
+			remoteAddr: "10.0.0.1:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"1.2.3.4, invalid, 10.0.0.2"},
+			},
+			expected: "10.0.0.2",
+		},
+		{
+			name:       "ipv6",
+			remoteAddr: "[2001:db8::1]:56",
+			header: http.Header{
+				"X-Forwarded-For": []string{"2001:db8:1::1, 2001:db8::2"},
+			},
+			expected: "2001:db8:1::1",
+		},
+	} {
+		t.Run(ti.name, func(t *testing.T) {
+			nets, err := ParseCIDRs([]string{"10.0.0.0/8", "2001:db8::/64"})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			header := ti.header.Clone()
+			delegated := false
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !reflect.DeepEqual(header, r.Header) {
+					t.Errorf("header mismatch, expected: %v, got: %v", header, r.Header)
+				}
+
+				for _, get := range []func(*http.Request) net.IP{ClientIP, RemoteHost, RemoteHostFromLast} {
+					if ip := get(r); ip.String() != ti.expected {
+						t.Errorf("client IP mismatch, expected: %s, got: %v", ti.expected, ip)
+					}
+				}
+				delegated = true
+			})
+
+			th := TrustedProxiesHandler{nets, h}
+
+			th.ServeHTTP(nil, &http.Request{Host: "example.com", RemoteAddr: ti.remoteAddr, Header: ti.header})
+
+			if !delegated {
+				t.Fatalf("delegate handler was not called")
+			}
+		})
+	}
+}
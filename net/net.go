@@ -24,14 +24,19 @@ func parse(addr string) net.IP {
 }
 
 // RemoteHost returns the remote address of the client. When the
-// 'X-Forwarded-For' header is set, then it is used instead. This is
-// how most often proxies behave. Wikipedia shows the format
+// client IP was resolved from the trusted proxies, see ClientIP, it
+// is returned. Otherwise, when the 'X-Forwarded-For' header is set,
+// then it is used instead. This is how most often proxies behave. Wikipedia shows the format
 // https://en.wikipedia.org/wiki/X-Forwarded-For#Format
 //
 // Example:
 //
 //     X-Forwarded-For: client, proxy1, proxy2
 func RemoteHost(r *http.Request) net.IP {
+	if ip := ClientIP(r); ip != nil {
+		return ip
+	}
+
 	ffs := r.Header.Get("X-Forwarded-For")
 	ff := strings.Split(ffs, ",")[0]
 	if ffh := parse(ff); ffh != nil {
@@ -42,7 +47,9 @@ func RemoteHost(r *http.Request) net.IP {
 }
 
 // RemoteHostFromLast returns the remote address of the client. When
-// the 'X-Forwarded-For' header is set, then it is used instead. This
+// the client IP was resolved from the trusted proxies, see ClientIP,
+// it is returned. Otherwise, when the 'X-Forwarded-For' header is
+// set, then it is used instead. This
 // is known to be true for AWS Application LoadBalancer. AWS docs
 // https://docs.aws.amazon.com/elasticloadbalancing/latest/classic/x-forwarded-headers.html
 //
@@ -50,6 +57,10 @@ func RemoteHost(r *http.Request) net.IP {
 //
 //     X-Forwarded-For: ip-address-1, ip-address-2, client-ip-address
 func RemoteHostFromLast(r *http.Request) net.IP {
+	if ip := ClientIP(r); ip != nil {
+		return ip
+	}
+
 	ffs := r.Header.Get("X-Forwarded-For")
 	ffa := strings.Split(ffs, ",")
 	ff := ffa[len(ffa)-1]
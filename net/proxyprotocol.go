@@ -0,0 +1,254 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// the longest v1 header, including the CRLF
+	proxyProtocolV1MaxLength = 107
+
+	proxyProtocolV2HeaderLength = 16
+)
+
+var (
+	proxyProtocolV1Signature = []byte("PROXY ")
+	proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errInvalidProxyProtocolHeader = errors.New("invalid PROXY protocol header")
+)
+
+// ProxyProtocolListener accepts connections with the PROXY protocol
+// header, version 1 or 2, as sent by L4 load balancers, e.g. AWS NLB. The
+// header is accepted only from the trusted networks, and the remote
+// address of the connections is set to the client address from the
+// header. The header is optional, the connections without the header keep
+// their original remote address. The connections from the untrusted
+// networks are not inspected.
+//
+// The header is parsed on the first call to the Read or the RemoteAddr
+// method of the connection, and not in Accept, so that slow clients
+// cannot block accepting the connections.
+//
+// See: https://www.haproxy.org/download/2.4/doc/proxy-protocol.txt
+type ProxyProtocolListener struct {
+	net.Listener
+
+	// Trusted contains the networks of the load balancers, that are
+	// allowed to send the PROXY protocol header.
+	Trusted IPNets
+
+	// HeaderTimeout, when set, limits the time of reading the PROXY
+	// protocol header.
+	HeaderTimeout time.Duration
+}
+
+type proxyProtocolConn struct {
+	net.Conn
+	timeout time.Duration
+	reader  *bufio.Reader
+	once    sync.Once
+	remote  net.Addr
+	err     error
+}
+
+// Accept waits for the next connection, and wraps it to read the PROXY
+// protocol header, when it was accepted from a trusted network.
+func (l *ProxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.Trusted.Contain(addrIP(c.RemoteAddr())) {
+		return c, nil
+	}
+
+	return &proxyProtocolConn{
+		Conn:    c,
+		timeout: l.HeaderTimeout,
+		reader:  bufio.NewReader(c),
+	}, nil
+}
+
+func addrIP(a net.Addr) net.IP {
+	switch at := a.(type) {
+	case *net.TCPAddr:
+		return at.IP
+	case nil:
+		return nil
+	default:
+		return parse(a.String())
+	}
+}
+
+func (c *proxyProtocolConn) init() {
+	c.once.Do(func() {
+		if c.timeout > 0 {
+			c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+
+		c.remote, c.err = readProxyProtocolHeader(c.reader)
+		if c.err == io.EOF {
+			c.err = nil
+		}
+	})
+}
+
+// Read reads from the connection, after the PROXY protocol header.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY protocol header, or
+// the original remote address when the header was not sent.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.init()
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// reads the PROXY protocol header, when there is one, and returns the
+// source address. It returns nil when there is no header, or when the
+// header doesn't contain a source address.
+func readProxyProtocolHeader(r *bufio.Reader) (net.Addr, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch first[0] {
+	case proxyProtocolV1Signature[0]:
+		sig, err := r.Peek(len(proxyProtocolV1Signature))
+		if err != nil || !bytes.Equal(sig, proxyProtocolV1Signature) {
+			return nil, nil
+		}
+
+		return readProxyProtocolV1(r)
+	case proxyProtocolV2Signature[0]:
+		sig, err := r.Peek(len(proxyProtocolV2Signature))
+		if err != nil || !bytes.Equal(sig, proxyProtocolV2Signature) {
+			return nil, nil
+		}
+
+		return readProxyProtocolV2(r)
+	default:
+		return nil, nil
+	}
+}
+
+// e.g. PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n
+func readProxyProtocolV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+
+		if len(line) >= proxyProtocolV1MaxLength {
+			return nil, errInvalidProxyProtocolHeader
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyProtocolV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, proxyProtocolV2HeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if header[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version: %d", header[12]>>4)
+	}
+
+	command := header[12] & 0xf
+	family := header[13]
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case 0:
+		// LOCAL, e.g. health checks of the load balancer
+		return nil, nil
+	case 1:
+		// PROXY
+	default:
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	var ipLength int
+	switch family >> 4 {
+	case 1:
+		ipLength = net.IPv4len
+	case 2:
+		ipLength = net.IPv6len
+	default:
+		// UNSPEC or UNIX
+		return nil, nil
+	}
+
+	if len(payload) < 2*ipLength+4 {
+		return nil, errInvalidProxyProtocolHeader
+	}
+
+	ip := make(net.IP, ipLength)
+	copy(ip, payload[:ipLength])
+	port := binary.BigEndian.Uint16(payload[2*ipLength:])
+	if family&0xf == 2 {
+		return &net.UDPAddr{IP: ip, Port: int(port)}, nil
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
@@ -0,0 +1,218 @@
+package net
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func proxyProtocolV2(command, family byte, addresses []byte) string {
+	h := append([]byte{}, proxyProtocolV2Signature...)
+	h = append(h, 0x20|command, family, byte(len(addresses)>>8), byte(len(addresses)))
+	return string(append(h, addresses...))
+}
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	ipv6 := append(append(append([]byte{}, net.ParseIP("2001:db8::1")...), net.ParseIP("2001:db8::2")...), 0xdc, 0x04, 0x01, 0xbb)
+	for _, tt := range []struct {
+		name   string
+		input  string
+		remote string
+		rest   string
+		fail   bool
+	}{{
+		name:  "no header",
+		input: "GET / HTTP/1.1\r\n",
+		rest:  "GET / HTTP/1.1\r\n",
+	}, {
+		name:  "no header, starting like v1",
+		input: "POST / HTTP/1.1\r\n",
+		rest:  "POST / HTTP/1.1\r\n",
+	}, {
+		name:  "no header, starting like v2",
+		input: "\r\nGET / HTTP/1.1\r\n",
+		rest:  "\r\nGET / HTTP/1.1\r\n",
+	}, {
+		name:  "empty",
+		input: "",
+	}, {
+		name:   "v1 tcp4",
+		input:  "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nGET / HTTP/1.1\r\n",
+		remote: "192.0.2.1:56324",
+		rest:   "GET / HTTP/1.1\r\n",
+	}, {
+		name:   "v1 tcp6",
+		input:  "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\nGET / HTTP/1.1\r\n",
+		remote: "[2001:db8::1]:56324",
+		rest:   "GET / HTTP/1.1\r\n",
+	}, {
+		name:  "v1 unknown",
+		input: "PROXY UNKNOWN\r\nGET / HTTP/1.1\r\n",
+		rest:  "GET / HTTP/1.1\r\n",
+	}, {
+		name:  "v1 missing fields",
+		input: "PROXY TCP4 192.0.2.1\r\nGET / HTTP/1.1\r\n",
+		fail:  true,
+	}, {
+		name:  "v1 invalid address",
+		input: "PROXY TCP4 2001:db8::1 198.51.100.1 56324 443\r\n",
+		fail:  true,
+	}, {
+		name:  "v1 invalid port",
+		input: "PROXY TCP4 192.0.2.1 198.51.100.1 99999 443\r\n",
+		fail:  true,
+	}, {
+		name:  "v1 missing CR",
+		input: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\n",
+		fail:  true,
+	}, {
+		name:  "v1 too long",
+		input: "PROXY TCP4 " + strings.Repeat("1", 200) + "\r\n",
+		fail:  true,
+	}, {
+		name:   "v2 tcp4",
+		input:  proxyProtocolV2(1, 0x11, ipv4) + "GET / HTTP/1.1\r\n",
+		remote: "192.0.2.1:56324",
+		rest:   "GET / HTTP/1.1\r\n",
+	}, {
+		name:   "v2 tcp6",
+		input:  proxyProtocolV2(1, 0x21, ipv6) + "GET / HTTP/1.1\r\n",
+		remote: "[2001:db8::1]:56324",
+		rest:   "GET / HTTP/1.1\r\n",
+	}, {
+		name:   "v2 with TLVs",
+		input:  proxyProtocolV2(1, 0x11, append(append([]byte{}, ipv4...), 0x04, 0x00, 0x01, 0x2a)) + "GET / HTTP/1.1\r\n",
+		remote: "192.0.2.1:56324",
+		rest:   "GET / HTTP/1.1\r\n",
+	}, {
+		name:  "v2 local",
+		input: proxyProtocolV2(0, 0x00, nil) + "GET / HTTP/1.1\r\n",
+		rest:  "GET / HTTP/1.1\r\n",
+	}, {
+		name:  "v2 short addresses",
+		input: proxyProtocolV2(1, 0x11, ipv4[:8]),
+		fail:  true,
+	}, {
+		name:  "v2 invalid command",
+		input: proxyProtocolV2(2, 0x11, ipv4),
+		fail:  true,
+	}, {
+		name:  "v2 truncated",
+		input: proxyProtocolV2(1, 0x11, ipv4)[:20],
+		fail:  true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			remote, err := readProxyProtocolHeader(r)
+			if tt.fail {
+				if err == nil || err == io.EOF {
+					t.Fatal("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+
+			var got string
+			if remote != nil {
+				got = remote.String()
+			}
+
+			if got != tt.remote {
+				t.Errorf("unexpected remote address, expected: %s, got: %s", tt.remote, got)
+			}
+
+			rest, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(rest) != tt.rest {
+				t.Errorf("unexpected data after the header, expected: %q, got: %q", tt.rest, rest)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		trusted []string
+		header  string
+		remote  string
+		data    string
+	}{{
+		name:    "trusted",
+		trusted: []string{"127.0.0.0/8"},
+		header:  "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		remote:  "192.0.2.1:56324",
+		data:    "hello",
+	}, {
+		name:    "trusted without header",
+		trusted: []string{"127.0.0.0/8"},
+		data:    "hello",
+	}, {
+		name:    "untrusted",
+		trusted: []string{"10.0.0.0/8"},
+		header:  "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		data:    "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			trusted, err := ParseCIDRs(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pl := &ProxyProtocolListener{Listener: l, Trusted: trusted, HeaderTimeout: time.Second}
+			defer pl.Close()
+
+			go func() {
+				c, err := net.Dial("tcp", l.Addr().String())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				defer c.Close()
+				c.Write([]byte(tt.header + "hello"))
+			}()
+
+			c, err := pl.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer c.Close()
+
+			remote := c.RemoteAddr().String()
+			if tt.remote != "" && remote != tt.remote {
+				t.Errorf("unexpected remote address, expected: %s, got: %s", tt.remote, remote)
+			}
+
+			if tt.remote == "" && !strings.HasPrefix(remote, "127.0.0.1:") {
+				t.Errorf("unexpected remote address: %s", remote)
+			}
+
+			data, err := io.ReadAll(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != tt.data {
+				t.Errorf("unexpected data, expected: %q, got: %q", tt.data, data)
+			}
+		})
+	}
+}
@@ -227,6 +227,12 @@ func NewRedisRingClient(ro *RedisOptions) *RedisRingClient {
 	return r
 }
 
+// RingConfigured returns true if the client was created with
+// RedisOptions, and so it has a redis ring to talk to.
+func (r *RedisRingClient) RingConfigured() bool {
+	return r != nil && r.ring != nil
+}
+
 func (r *RedisRingClient) RingAvailable() bool {
 	var err error
 	err = backoff.Retry(func() error {
@@ -307,6 +313,24 @@ func (r *RedisRingClient) ZCard(ctx context.Context, key string) (int64, error)
 	return res.Val(), res.Err()
 }
 
+// RedisScript is a Lua script, that can be executed with RunScript.
+type RedisScript struct {
+	script *redis.Script
+}
+
+// NewScript creates a Lua script. The script is loaded to the redis
+// servers on its first execution.
+func (r *RedisRingClient) NewScript(source string) *RedisScript {
+	return &RedisScript{script: redis.NewScript(source)}
+}
+
+// RunScript executes the script with EVALSHA, falling back to EVAL if the
+// script was not loaded, yet. All the keys of the script need to be on
+// the same shard of the ring.
+func (r *RedisRingClient) RunScript(ctx context.Context, s *RedisScript, keys []string, args ...interface{}) (interface{}, error) {
+	return s.script.Run(ctx, r.ring, keys, args...).Result()
+}
+
 func (r *RedisRingClient) ZRangeByScoreWithScoresFirst(ctx context.Context, key string, min, max float64, offset, count int64) (interface{}, error) {
 	opt := &redis.ZRangeBy{
 		Min:    fmt.Sprint(min),
@@ -0,0 +1,124 @@
+/*
+Package contentlength implements a custom predicate to match routes
+based on the declared body size of the request.
+
+The predicate compares the Content-Length of the request to a threshold,
+before the body is read. Requests with unknown length, e.g. chunked
+requests, don't match by default.
+
+Examples:
+
+    // matches requests declaring a body larger than 10MB
+    uploads: ContentLength(">10485760") -> "https://uploads.example.org";
+
+    // matches also the requests with unknown body size
+    uploads: ContentLength(">10485760", "match") -> "https://uploads.example.org";
+*/
+package contentlength
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+const (
+	matchUnknown   = "match"
+	noMatchUnknown = "nomatch"
+)
+
+type (
+	spec struct{}
+
+	predicate struct {
+		compare      func(int64) bool
+		matchUnknown bool
+	}
+)
+
+// New creates a new ContentLength predicate specification.
+//
+// The first argument is the threshold, optionally prefixed with one of
+// the comparison operators: =, >, >=, < or <=. Without an operator, the
+// content length needs to be equal. The optional second argument defines
+// whether the requests with unknown content length match ("match") or
+// not ("nomatch"), defaults to "nomatch".
+func New() routing.PredicateSpec { return &spec{} }
+
+func (*spec) Name() string { return predicates.ContentLengthName }
+
+func (*spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &predicate{}
+	switch v := args[0].(type) {
+	case float64:
+		n := int64(v)
+		p.compare = func(l int64) bool { return l == n }
+	case string:
+		c, err := parseComparison(v)
+		if err != nil {
+			return nil, err
+		}
+
+		p.compare = c
+	default:
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	if len(args) == 2 {
+		switch args[1] {
+		case matchUnknown:
+			p.matchUnknown = true
+		case noMatchUnknown:
+		default:
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+	}
+
+	return p, nil
+}
+
+func parseComparison(s string) (func(int64) bool, error) {
+	s = strings.TrimSpace(s)
+
+	var op string
+	for _, o := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, o) {
+			op = o
+			s = s[len(o):]
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	switch op {
+	case ">=":
+		return func(l int64) bool { return l >= n }, nil
+	case "<=":
+		return func(l int64) bool { return l <= n }, nil
+	case ">":
+		return func(l int64) bool { return l > n }, nil
+	case "<":
+		return func(l int64) bool { return l < n }, nil
+	default:
+		return func(l int64) bool { return l == n }, nil
+	}
+}
+
+func (p *predicate) Match(r *http.Request) bool {
+	if r.ContentLength < 0 {
+		return p.matchUnknown
+	}
+
+	return p.compare(r.ContentLength)
+}
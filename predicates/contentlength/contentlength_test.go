@@ -0,0 +1,57 @@
+package contentlength
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	s := New()
+	for _, args := range [][]interface{}{
+		{},
+		{"foo"},
+		{">-1"},
+		{"=>10"},
+		{">10", "maybe"},
+		{">10", "match", "nomatch"},
+		{true},
+	} {
+		if _, err := s.Create(args); err == nil {
+			t.Errorf("expected error for arguments: %v", args)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	s := New()
+	for _, tc := range []struct {
+		args          []interface{}
+		contentLength int64
+		match         bool
+	}{
+		{args: []interface{}{">10"}, contentLength: 11, match: true},
+		{args: []interface{}{">10"}, contentLength: 10, match: false},
+		{args: []interface{}{">=10"}, contentLength: 10, match: true},
+		{args: []interface{}{"<10"}, contentLength: 0, match: true},
+		{args: []interface{}{"<=10"}, contentLength: 11, match: false},
+		{args: []interface{}{"=10"}, contentLength: 10, match: true},
+		{args: []interface{}{"10"}, contentLength: 9, match: false},
+		{args: []interface{}{10.0}, contentLength: 10, match: true},
+		{args: []interface{}{">10"}, contentLength: -1, match: false},
+		{args: []interface{}{"<10"}, contentLength: -1, match: false},
+		{args: []interface{}{">10", "nomatch"}, contentLength: -1, match: false},
+		{args: []interface{}{">10", "match"}, contentLength: -1, match: true},
+	} {
+		t.Run(fmt.Sprintf("%v/%d", tc.args, tc.contentLength), func(t *testing.T) {
+			p, err := s.Create(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if p.Match(&http.Request{ContentLength: tc.contentLength}) != tc.match {
+				t.Errorf("expected match: %v", tc.match)
+			}
+		})
+	}
+}
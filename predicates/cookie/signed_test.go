@@ -0,0 +1,153 @@
+package cookie
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testSecrets map[string][]byte
+
+func (s testSecrets) GetSecret(name string) ([]byte, bool) {
+	v, ok := s[name]
+	return v, ok
+}
+
+func (testSecrets) Close() {}
+
+func signedValue(key, name, payload string, expiry time.Time) string {
+	signed := fmt.Sprintf("%s.%d", payload, expiry.Unix())
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(key), name, signed))
+}
+
+func TestValidSignedCookieArgs(t *testing.T) {
+	for _, ti := range []struct {
+		msg  string
+		args []interface{}
+		err  bool
+	}{{
+		"no args",
+		nil,
+		true,
+	}, {
+		"no secret",
+		[]interface{}{"session"},
+		true,
+	}, {
+		"invalid name",
+		[]interface{}{float64(1), "key"},
+		true,
+	}, {
+		"empty secret name",
+		[]interface{}{"session", ""},
+		true,
+	}, {
+		"ok",
+		[]interface{}{"session", "key"},
+		false,
+	}, {
+		"multiple secrets",
+		[]interface{}{"session", "key-new", "key-old"},
+		false,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			_, err := NewValidSigned(testSecrets{}).Create(ti.args)
+			if ti.err && err == nil {
+				t.Error("failed to fail")
+			} else if !ti.err && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestValidSignedCookie(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	sr := testSecrets{"key": []byte("secret"), "key-old": []byte("old-secret")}
+
+	for _, ti := range []struct {
+		msg     string
+		secrets []interface{}
+		cookie  *http.Cookie
+		match   bool
+	}{{
+		"no cookie",
+		[]interface{}{"key"},
+		nil,
+		false,
+	}, {
+		"valid",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: signedValue("secret", "session", "user.42", now.Add(time.Hour))},
+		true,
+	}, {
+		"expired",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: signedValue("secret", "session", "user.42", now.Add(-time.Second))},
+		false,
+	}, {
+		"expires now",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: signedValue("secret", "session", "user.42", now)},
+		false,
+	}, {
+		"wrong secret",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: signedValue("other", "session", "user.42", now.Add(time.Hour))},
+		false,
+	}, {
+		"signed for another cookie",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: signedValue("secret", "other", "user.42", now.Add(time.Hour))},
+		false,
+	}, {
+		"tampered payload",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: "admin" + signedValue("secret", "session", "user.42", now.Add(time.Hour))[4:]},
+		false,
+	}, {
+		"malformed",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: "user42"},
+		false,
+	}, {
+		"invalid signature encoding",
+		[]interface{}{"key"},
+		&http.Cookie{Name: "session", Value: fmt.Sprintf("user.%d.!!!", now.Add(time.Hour).Unix())},
+		false,
+	}, {
+		"missing secret",
+		[]interface{}{"unknown"},
+		&http.Cookie{Name: "session", Value: signedValue("secret", "session", "user.42", now.Add(time.Hour))},
+		false,
+	}, {
+		"rotated secret",
+		[]interface{}{"key", "key-old"},
+		&http.Cookie{Name: "session", Value: signedValue("old-secret", "session", "user.42", now.Add(time.Hour))},
+		true,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			p, err := NewValidSigned(sr).Create(append([]interface{}{"session"}, ti.secrets...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p.(*signedPredicate).now = func() time.Time { return now }
+
+			r, err := http.NewRequest("GET", "https://www.example.org", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ti.cookie != nil {
+				r.AddCookie(ti.cookie)
+			}
+
+			if m := p.Match(r); m != ti.match {
+				t.Errorf("unexpected match result, expected: %v, got: %v", ti.match, m)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Package geoip implements a predicate to match routes based on the
+country of the client, looked up by the client IP in a MaxMind GeoIP2
+or GeoLite2 country or city database.
+
+The client IP is determined the same way as for the Source predicate, from
+the first entry of the X-Forwarded-For header, or from the last one, when
+the reverse source predicate option is set. When the header is not set,
+the remote address of the request is used.
+
+The database is loaded once, when skipper starts, and it is reloaded when
+skipper receives a SIGHUP signal. When the reload fails, the previously
+loaded database is used.
+
+The predicate accepts one or more ISO 3166-1 alpha-2 country codes, and it
+doesn't match when the country of the client cannot be determined.
+
+Examples:
+
+    // only match requests from Germany and Austria
+    example1: ClientCountry("DE", "AT") -> "http://example.org";
+*/
+package geoip
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/oschwald/maxminddb-golang"
+	log "github.com/sirupsen/logrus"
+
+	snet "github.com/zalando/skipper/net"
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type spec struct {
+	path     string
+	fromLast bool
+
+	mx sync.RWMutex
+	db *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+type predicate struct {
+	spec      *spec
+	countries map[string]bool
+}
+
+// the database is read into memory instead of mapping the file, so that
+// a reload doesn't need to wait for the lookups using the previous one
+func openDatabase(path string) (*maxminddb.Reader, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return maxminddb.FromBytes(b)
+}
+
+// NewClientCountry creates a predicate specification, whose instances
+// match the requests by the country of the client. It expects the path
+// of the database, and whether the client IP should be taken from the
+// last entry of the X-Forwarded-For header.
+func NewClientCountry(path string, fromLast bool) (routing.PredicateSpec, error) {
+	db, err := openDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spec{path: path, fromLast: fromLast, db: db}
+	go s.reloadOnSignal()
+	return s, nil
+}
+
+func (s *spec) reload() error {
+	db, err := openDatabase(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.db = db
+	return nil
+}
+
+func (s *spec) reloadOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	for range sigs {
+		if err := s.reload(); err != nil {
+			log.Errorf("Failed to reload GeoIP database %s: %v", s.path, err)
+			continue
+		}
+
+		log.Infof("GeoIP database %s reloaded", s.path)
+	}
+}
+
+func (s *spec) country(ip net.IP) string {
+	s.mx.RLock()
+	db := s.db
+	s.mx.RUnlock()
+
+	var record countryRecord
+	if err := db.Lookup(ip, &record); err != nil {
+		log.Debugf("Failed to look up the country of %v: %v", ip, err)
+		return ""
+	}
+
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+
+	return record.RegisteredCountry.ISOCode
+}
+
+func (*spec) Name() string { return predicates.ClientCountryName }
+
+func (s *spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &predicate{spec: s, countries: make(map[string]bool)}
+	for _, a := range args {
+		c, ok := a.(string)
+		if !ok || len(c) != 2 {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		p.countries[strings.ToUpper(c)] = true
+	}
+
+	return p, nil
+}
+
+func (p *predicate) Match(r *http.Request) bool {
+	var ip net.IP
+	if p.spec.fromLast {
+		ip = snet.RemoteHostFromLast(r)
+	} else {
+		ip = snet.RemoteHost(r)
+	}
+
+	if ip == nil {
+		return false
+	}
+
+	return p.countries[p.spec.country(ip)]
+}
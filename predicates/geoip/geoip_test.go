@@ -0,0 +1,258 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// the subset of the MaxMind DB format used by the test databases, see
+// https://maxmind.github.io/MaxMind-DB/
+const (
+	typeString = 2
+	typeUint16 = 5
+	typeUint32 = 6
+	typeMap    = 7
+
+	dataSectionSeparatorSize = 16
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+func encodeString(b *bytes.Buffer, s string) {
+	b.WriteByte(typeString<<5 | byte(len(s)))
+	b.WriteString(s)
+}
+
+func encodeUint16(b *bytes.Buffer, key string, v uint16) {
+	encodeString(b, key)
+	b.WriteByte(typeUint16<<5 | 2)
+	binary.Write(b, binary.BigEndian, v)
+}
+
+func encodeUint32(b *bytes.Buffer, key string, v uint32) {
+	encodeString(b, key)
+	b.WriteByte(typeUint32<<5 | 4)
+	binary.Write(b, binary.BigEndian, v)
+}
+
+// writes an IPv4 database with 24 bit records, mapping the networks to
+// country codes
+func writeDatabase(t *testing.T, networks map[string]string) string {
+	const empty = -1
+
+	var (
+		nodes [][2]int
+		data  bytes.Buffer
+		leafs = make(map[[2]int]int)
+	)
+
+	nodes = append(nodes, [2]int{empty, empty})
+	for cidr, country := range networks {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		offset := data.Len()
+		data.WriteByte(typeMap<<5 | 1)
+		encodeString(&data, "country")
+		data.WriteByte(typeMap<<5 | 1)
+		encodeString(&data, "iso_code")
+		encodeString(&data, country)
+
+		ones, _ := n.Mask.Size()
+		ip := n.IP.To4()
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := int(ip[i/8]>>(7-uint(i%8))) & 1
+			if i == ones-1 {
+				leafs[[2]int{node, bit}] = offset
+				break
+			}
+
+			if nodes[node][bit] == empty {
+				nodes = append(nodes, [2]int{empty, empty})
+				nodes[node][bit] = len(nodes) - 1
+			}
+
+			node = nodes[node][bit]
+		}
+	}
+
+	var db bytes.Buffer
+	for i, n := range nodes {
+		for bit, r := range n {
+			if offset, ok := leafs[[2]int{i, bit}]; ok {
+				r = len(nodes) + dataSectionSeparatorSize + offset
+			} else if r == empty {
+				r = len(nodes)
+			}
+
+			db.Write([]byte{byte(r >> 16), byte(r >> 8), byte(r)})
+		}
+	}
+
+	db.Write(make([]byte, dataSectionSeparatorSize))
+	db.Write(data.Bytes())
+	db.Write(metadataMarker)
+	db.WriteByte(typeMap<<5 | 3)
+	encodeUint32(&db, "node_count", uint32(len(nodes)))
+	encodeUint16(&db, "record_size", 24)
+	encodeUint16(&db, "ip_version", 4)
+
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	if err := os.WriteFile(path, db.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestClientCountry(t *testing.T) {
+	path := writeDatabase(t, map[string]string{
+		"1.2.3.0/24":  "DE",
+		"5.6.0.0/16":  "AT",
+		"10.0.0.0/8":  "US",
+		"128.0.0.0/1": "FR",
+	})
+
+	for _, tt := range []struct {
+		msg           string
+		fromLast      bool
+		remoteAddr    string
+		forwardedFor  string
+		expectedMatch bool
+	}{{
+		msg:           "remote address",
+		remoteAddr:    "1.2.3.4:8080",
+		expectedMatch: true,
+	}, {
+		msg:           "other country",
+		remoteAddr:    "10.1.2.3:8080",
+		expectedMatch: false,
+	}, {
+		msg:           "second network",
+		remoteAddr:    "5.6.7.8:8080",
+		expectedMatch: true,
+	}, {
+		msg:           "unknown address",
+		remoteAddr:    "2.2.2.2:8080",
+		expectedMatch: false,
+	}, {
+		msg:           "IPv6 address",
+		remoteAddr:    "[2001:db8::1]:8080",
+		expectedMatch: false,
+	}, {
+		msg:           "first forwarded address",
+		remoteAddr:    "10.1.2.3:8080",
+		forwardedFor:  "1.2.3.4, 10.1.2.3",
+		expectedMatch: true,
+	}, {
+		msg:           "last forwarded address",
+		fromLast:      true,
+		remoteAddr:    "10.1.2.3:8080",
+		forwardedFor:  "1.2.3.4, 200.1.2.3",
+		expectedMatch: false,
+	}} {
+		t.Run(tt.msg, func(t *testing.T) {
+			s, err := NewClientCountry(path, tt.fromLast)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := s.Create([]interface{}{"de", "AT"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: http.Header{}}
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+
+			if m := p.Match(r); m != tt.expectedMatch {
+				t.Errorf("expected match: %v, got: %v", tt.expectedMatch, m)
+			}
+		})
+	}
+}
+
+func TestClientCountryReload(t *testing.T) {
+	path := writeDatabase(t, map[string]string{"1.2.3.0/24": "DE"})
+	s, err := NewClientCountry(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := s.Create([]interface{}{"AT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &http.Request{RemoteAddr: "1.2.3.4:8080", Header: http.Header{}}
+	if p.Match(r) {
+		t.Fatal("unexpected match")
+	}
+
+	b, err := os.ReadFile(writeDatabase(t, map[string]string{"1.2.3.0/24": "AT"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.(*spec).reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !p.Match(r) {
+		t.Error("failed to match after reload")
+	}
+
+	if err := os.WriteFile(path, []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.(*spec).reload(); err == nil {
+		t.Error("failed to fail")
+	}
+
+	if !p.Match(r) {
+		t.Error("failed to match with the previous database")
+	}
+}
+
+func TestClientCountryArgs(t *testing.T) {
+	s, err := NewClientCountry(writeDatabase(t, nil), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]interface{}{
+		nil,
+		{"DEU"},
+		{"DE", 42},
+	} {
+		if _, err := s.Create(args); err == nil {
+			t.Errorf("failed to fail for %v", args)
+		}
+	}
+}
+
+func TestInvalidDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	if err := os.WriteFile(path, []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClientCountry(path, false); err == nil {
+		t.Error("failed to fail")
+	}
+}
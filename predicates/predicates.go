@@ -21,6 +21,7 @@ const (
 	ForwardedHostName         = "ForwardedHost"
 	ForwardedProtocolName     = "ForwardedProtocol"
 	WeightName                = "Weight"
+	TrailingSlashName         = "TrailingSlash"
 	TrueName                  = "True"
 	FalseName                 = "False"
 	ShutdownName              = "Shutdown"
@@ -29,6 +30,7 @@ const (
 	HeaderName                = "Header"
 	HeaderRegexpName          = "HeaderRegexp"
 	CookieName                = "Cookie"
+	ValidSignedCookieName     = "ValidSignedCookie"
 	JWTPayloadAnyKVName       = "JWTPayloadAnyKV"
 	JWTPayloadAllKVName       = "JWTPayloadAllKV"
 	JWTPayloadAnyKVRegexpName = "JWTPayloadAnyKVRegexp"
@@ -37,10 +39,19 @@ const (
 	BeforeName                = "Before"
 	BetweenName               = "Between"
 	CronName                  = "Cron"
+	WeekdayName               = "Weekday"
+	TimeOfDayName             = "TimeOfDay"
 	QueryParamName            = "QueryParam"
+	QueryParamRegexpName      = "QueryParamRegexp"
 	SourceName                = "Source"
 	SourceFromLastName        = "SourceFromLast"
 	ClientIPName              = "ClientIP"
+	ClientCountryName         = "ClientCountry"
 	TeeName                   = "Tee"
 	TrafficName               = "Traffic"
+	TLSCipherName             = "TLSCipher"
+	TLSVersionName            = "TLSVersion"
+	ClientCertSANName         = "ClientCertSAN"
+	ContentLengthName         = "ContentLength"
+	WebSocketName             = "WebSocket"
 )
@@ -23,6 +23,15 @@ Examples:
     // matches http://example.org?bb=a&query=testing&query=example
     example1: QueryParam("query", "^example$") -> "http://example.org";
 
+The QueryParamRegexp predicate is the same as QueryParam with two
+arguments, but it always requires the regular expression, similar to the
+HeaderRegexp predicate. When the query param is absent, it doesn't match.
+
+Examples:
+
+    // matches http://example.org?version=v2.1
+    example1: QueryParamRegexp("version", "^v2[.]") -> "http://example.org";
+
 */
 package query
 
@@ -46,17 +55,26 @@ type predicate struct {
 	paramName string
 	valueExp  *regexp.Regexp
 }
-type spec struct{}
+type spec struct {
+	regexpOnly bool
+}
 
 // New creates a new QueryParam predicate specification.
 func New() routing.PredicateSpec { return &spec{} }
 
+// NewRegexp creates a new QueryParamRegexp predicate specification.
+func NewRegexp() routing.PredicateSpec { return &spec{regexpOnly: true} }
+
 func (s *spec) Name() string {
+	if s.regexpOnly {
+		return predicates.QueryParamRegexpName
+	}
+
 	return predicates.QueryParamName
 }
 
 func (s *spec) Create(args []interface{}) (routing.Predicate, error) {
-	if len(args) == 0 || len(args) > 2 {
+	if len(args) == 0 || len(args) > 2 || s.regexpOnly && len(args) != 2 {
 		return nil, predicates.ErrInvalidPredicateParameters
 	}
 
@@ -140,3 +140,42 @@ func TestMatchArgs(t *testing.T) {
 		}()
 	}
 }
+
+func TestQueryParamRegexp(t *testing.T) {
+	spec := NewRegexp()
+	if spec.Name() != "QueryParamRegexp" {
+		t.Errorf("unexpected name: %s", spec.Name())
+	}
+
+	for _, args := range [][]interface{}{
+		{"version"},
+		{"version", `\`},
+		{"version", 2.0},
+	} {
+		if _, err := spec.Create(args); err == nil {
+			t.Errorf("failed to fail for args: %v", args)
+		}
+	}
+
+	p, err := spec.Create([]interface{}{"version", `^v2\.`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ti := range []struct {
+		query string
+		match bool
+	}{
+		{query: "version=v2.1", match: true},
+		{query: "version=v1.9&version=v2.0", match: true},
+		{query: "version=v21", match: false},
+		{query: "version=", match: false},
+		{query: "other=v2.1", match: false},
+		{query: "", match: false},
+	} {
+		req, _ := http.NewRequest("GET", "http://example.com/?"+ti.query, nil)
+		if m := p.Match(req); m != ti.match {
+			t.Errorf("unexpected match result for %q, got: %t, expected: %t", ti.query, m, ti.match)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Package schedule implements custom predicates to match routes only on
+some days of the week, or during some time of the day, repeatedly.
+
+Package includes two predicates: Weekday and TimeOfDay.
+
+Weekday predicate matches only if the current day of the week is one of
+the specified days. The days are given as a comma separated list of
+three letter day names or ranges of them, e.g. "Mon-Fri" or "Mon,Wed,Fri".
+Ranges can wrap around the end of the week, e.g. "Fri-Mon" matches
+Friday, Saturday, Sunday and Monday.
+
+TimeOfDay predicate matches only if the current time of the day is inside
+the specified range. The range is given in the format of "HH:MM-HH:MM" or
+"HH:MM:SS-HH:MM:SS". The range includes the start, but excludes the end.
+When the end is before the start, the range wraps around midnight, e.g.
+"22:00-02:00" matches from 10pm until 2am.
+
+Both predicates accept an optional second argument, the name of the time
+zone location (see https://golang.org/pkg/time/#LoadLocation), in which the
+current time is evaluated. It defaults to UTC.
+
+The predicates can be combined with each other and with other predicates.
+When combined, they are evaluated independently, e.g. Weekday("Fri") and
+TimeOfDay("22:00-02:00") together match on Fridays between midnight and
+2am, and between 10pm and midnight.
+
+Examples:
+
+	businessHours: Weekday("Mon-Fri", "Europe/Berlin") && TimeOfDay("09:00-17:00", "Europe/Berlin") -> "https://www.example.org";
+	maintenance: TimeOfDay("22:00-02:00", "Europe/Berlin") -> status(503) -> <shunt>;
+	weekend: Weekday("Sat,Sun") -> "https://weekend.example.org";
+*/
+package schedule
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type spec int
+
+const (
+	weekday spec = iota
+	timeOfDay
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type predicate struct {
+	typ      spec
+	location *time.Location
+	days     [7]bool
+	start    time.Duration
+	end      time.Duration
+	getTime  func() time.Time
+}
+
+// NewWeekday creates the Weekday predicate.
+func NewWeekday() routing.PredicateSpec { return weekday }
+
+// NewTimeOfDay creates the TimeOfDay predicate.
+func NewTimeOfDay() routing.PredicateSpec { return timeOfDay }
+
+func (s spec) Name() string {
+	switch s {
+	case weekday:
+		return predicates.WeekdayName
+	case timeOfDay:
+		return predicates.TimeOfDayName
+	default:
+		panic("invalid schedule predicate type")
+	}
+}
+
+func (s spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &predicate{typ: s, location: time.UTC, getTime: time.Now}
+	if len(args) == 2 {
+		name, ok := args[1].(string)
+		if !ok {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		p.location = loc
+	}
+
+	value, ok := args[0].(string)
+	if !ok {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	var valid bool
+	switch s {
+	case weekday:
+		valid = parseWeekdays(value, &p.days)
+	case timeOfDay:
+		valid = parseTimeRange(value, &p.start, &p.end)
+	}
+
+	if !valid {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	return p, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	d, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	return d, ok
+}
+
+func parseWeekdays(s string, days *[7]bool) bool {
+	for _, item := range strings.Split(s, ",") {
+		fromTo := strings.Split(item, "-")
+		if len(fromTo) > 2 {
+			return false
+		}
+
+		from, ok := parseWeekday(fromTo[0])
+		if !ok {
+			return false
+		}
+
+		to := from
+		if len(fromTo) == 2 {
+			if to, ok = parseWeekday(fromTo[1]); !ok {
+				return false
+			}
+		}
+
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+func parseTimeOfDay(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return sinceMidnight(t), true
+		}
+	}
+
+	return 0, false
+}
+
+func parseTimeRange(s string, start, end *time.Duration) bool {
+	startEnd := strings.Split(s, "-")
+	if len(startEnd) != 2 {
+		return false
+	}
+
+	var ok bool
+	if *start, ok = parseTimeOfDay(startEnd[0]); !ok {
+		return false
+	}
+
+	if *end, ok = parseTimeOfDay(startEnd[1]); !ok {
+		return false
+	}
+
+	return *start != *end
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second +
+		time.Duration(t.Nanosecond())
+}
+
+func (p *predicate) Match(r *http.Request) bool {
+	now := p.getTime().In(p.location)
+
+	switch p.typ {
+	case weekday:
+		return p.days[now.Weekday()]
+	case timeOfDay:
+		t := sinceMidnight(now)
+		if p.start < p.end {
+			return t >= p.start && t < p.end
+		}
+
+		// wraps around midnight
+		return t >= p.start || t < p.end
+	default:
+		return false
+	}
+}
@@ -0,0 +1,191 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/predicates"
+)
+
+func TestName(t *testing.T) {
+	if n := NewWeekday().Name(); n != predicates.WeekdayName {
+		t.Errorf("unexpected name: %s", n)
+	}
+
+	if n := NewTimeOfDay().Name(); n != predicates.TimeOfDayName {
+		t.Errorf("unexpected name: %s", n)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	for _, tc := range []struct {
+		msg     string
+		spec    spec
+		args    []interface{}
+		isError bool
+	}{{
+		msg:     "weekday, no args",
+		spec:    weekday,
+		isError: true,
+	}, {
+		msg:     "weekday, too many args",
+		spec:    weekday,
+		args:    []interface{}{"Mon", "UTC", "foo"},
+		isError: true,
+	}, {
+		msg:     "weekday, invalid type",
+		spec:    weekday,
+		args:    []interface{}{1.0},
+		isError: true,
+	}, {
+		msg:     "weekday, invalid day",
+		spec:    weekday,
+		args:    []interface{}{"Mon-Fry"},
+		isError: true,
+	}, {
+		msg:     "weekday, invalid range",
+		spec:    weekday,
+		args:    []interface{}{"Mon-Wed-Fri"},
+		isError: true,
+	}, {
+		msg:     "weekday, invalid location",
+		spec:    weekday,
+		args:    []interface{}{"Mon-Fri", "Europe/Nowhere"},
+		isError: true,
+	}, {
+		msg:  "weekday, range",
+		spec: weekday,
+		args: []interface{}{"Mon-Fri"},
+	}, {
+		msg:  "weekday, list with location",
+		spec: weekday,
+		args: []interface{}{"mon, Wed,FRI-sun", "Europe/Berlin"},
+	}, {
+		msg:     "time of day, invalid format",
+		spec:    timeOfDay,
+		args:    []interface{}{"9am-5pm"},
+		isError: true,
+	}, {
+		msg:     "time of day, invalid hour",
+		spec:    timeOfDay,
+		args:    []interface{}{"09:00-25:00"},
+		isError: true,
+	}, {
+		msg:     "time of day, empty range",
+		spec:    timeOfDay,
+		args:    []interface{}{"09:00-09:00"},
+		isError: true,
+	}, {
+		msg:  "time of day",
+		spec: timeOfDay,
+		args: []interface{}{"09:00-17:30"},
+	}, {
+		msg:  "time of day, seconds and location",
+		spec: timeOfDay,
+		args: []interface{}{"22:00:00-02:00:30", "Europe/Berlin"},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			_, err := tc.spec.Create(tc.args)
+			if tc.isError && err == nil {
+				t.Error("failed to fail")
+			} else if !tc.isError && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2021-06-04 is a Friday
+	for _, tc := range []struct {
+		msg     string
+		spec    spec
+		args    []interface{}
+		now     time.Time
+		matches bool
+	}{{
+		msg:     "weekday, inside range",
+		spec:    weekday,
+		args:    []interface{}{"Mon-Fri"},
+		now:     time.Date(2021, 6, 4, 12, 0, 0, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:  "weekday, outside range",
+		spec: weekday,
+		args: []interface{}{"Mon-Fri"},
+		now:  time.Date(2021, 6, 5, 12, 0, 0, 0, time.UTC),
+	}, {
+		msg:     "weekday, wrap-around range",
+		spec:    weekday,
+		args:    []interface{}{"Sat-Mon"},
+		now:     time.Date(2021, 6, 6, 12, 0, 0, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:  "weekday, list",
+		spec: weekday,
+		args: []interface{}{"Mon,Wed"},
+		now:  time.Date(2021, 6, 4, 12, 0, 0, 0, time.UTC),
+	}, {
+		msg:     "weekday, in location",
+		spec:    weekday,
+		args:    []interface{}{"Sat", "Europe/Berlin"},
+		now:     time.Date(2021, 6, 4, 23, 0, 0, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:     "time of day, inside range",
+		spec:    timeOfDay,
+		args:    []interface{}{"09:00-17:00"},
+		now:     time.Date(2021, 6, 4, 9, 0, 0, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:  "time of day, end excluded",
+		spec: timeOfDay,
+		args: []interface{}{"09:00-17:00"},
+		now:  time.Date(2021, 6, 4, 17, 0, 0, 0, time.UTC),
+	}, {
+		msg:  "time of day, in location",
+		spec: timeOfDay,
+		args: []interface{}{"09:00-17:00", "Europe/Berlin"},
+		now:  time.Date(2021, 6, 4, 16, 0, 0, 0, time.UTC),
+	}, {
+		msg:     "time of day, in location, given in other location",
+		spec:    timeOfDay,
+		args:    []interface{}{"09:00-17:00", "Europe/Berlin"},
+		now:     time.Date(2021, 6, 4, 16, 0, 0, 0, berlin),
+		matches: true,
+	}, {
+		msg:     "time of day, wrap-around, before midnight",
+		spec:    timeOfDay,
+		args:    []interface{}{"22:00-02:00"},
+		now:     time.Date(2021, 6, 4, 23, 30, 0, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:     "time of day, wrap-around, after midnight",
+		spec:    timeOfDay,
+		args:    []interface{}{"22:00-02:00"},
+		now:     time.Date(2021, 6, 4, 1, 59, 59, 0, time.UTC),
+		matches: true,
+	}, {
+		msg:  "time of day, wrap-around, outside",
+		spec: timeOfDay,
+		args: []interface{}{"22:00-02:00"},
+		now:  time.Date(2021, 6, 4, 12, 0, 0, 0, time.UTC),
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			p, err := tc.spec.Create(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p.(*predicate).getTime = func() time.Time { return tc.now }
+			if m := p.Match(nil); m != tc.matches {
+				t.Errorf("unexpected match result, got: %t, expected: %t", m, tc.matches)
+			}
+		})
+	}
+}
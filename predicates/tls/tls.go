@@ -0,0 +1,210 @@
+/*
+Package tls implements custom predicates to match routes based on the
+negotiated TLS connection state of the request.
+
+The predicates never match requests received over plaintext connections.
+
+Examples:
+
+    // matches requests negotiated with one of the listed cipher suites
+    weak: TLSCipher("TLS_RSA_WITH_AES_128_CBC_SHA", "TLS_RSA_WITH_3DES_EDE_CBC_SHA") -> "https://warning.example.org";
+
+    // matches requests negotiated with TLS 1.3 or newer
+    modern: TLSVersion(">=1.3") -> "https://www.example.org";
+
+    // matches requests with a verified client certificate of the SPIFFE identity
+    payments: ClientCertSAN("spiffe://acme/payments") -> "https://payments.example.org";
+*/
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type (
+	cipherSpec        struct{}
+	versionSpec       struct{}
+	clientCertSANSpec struct{}
+
+	cipherPredicate struct {
+		suites map[uint16]bool
+	}
+
+	versionPredicate struct {
+		compare func(int) bool
+		version uint16
+	}
+
+	clientCertSANPredicate struct {
+		names map[string]bool
+	}
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewCipher creates a predicate specification, whose instances match the
+// cipher suite negotiated for the TLS connection of the request.
+//
+// The TLSCipher predicate requires one or more cipher suite names, as
+// defined by the crypto/tls package, e.g. "TLS_RSA_WITH_AES_128_CBC_SHA".
+// Unknown cipher suite names are rejected when the route is created.
+func NewCipher() routing.PredicateSpec { return &cipherSpec{} }
+
+// NewVersion creates a predicate specification, whose instances match the
+// version of the TLS connection of the request.
+//
+// The TLSVersion predicate requires a single version, one of 1.0, 1.1, 1.2
+// or 1.3, optionally prefixed with one of the comparison operators: =, >,
+// >=, < or <=. Without an operator, the version needs to be equal.
+func NewVersion() routing.PredicateSpec { return &versionSpec{} }
+
+// NewClientCertSAN creates a predicate specification, whose instances
+// match the subject alternative names of the verified client certificate
+// of the request.
+//
+// The ClientCertSAN predicate requires one or more names, and matches,
+// when one of them equals one of the URI or DNS SANs of the leaf
+// certificate. Requests without a verified client certificate don't
+// match, even when a certificate was presented.
+func NewClientCertSAN() routing.PredicateSpec { return &clientCertSANSpec{} }
+
+func (*cipherSpec) Name() string { return predicates.TLSCipherName }
+
+func (*cipherSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	for _, s := range tls.InsecureCipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	p := &cipherPredicate{suites: make(map[uint16]bool)}
+	for _, arg := range args {
+		name, ok := arg.(string)
+		if !ok {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown cipher suite %s", predicates.ErrInvalidPredicateParameters, name)
+		}
+
+		p.suites[id] = true
+	}
+
+	return p, nil
+}
+
+func (p *cipherPredicate) Match(r *http.Request) bool {
+	return r.TLS != nil && p.suites[r.TLS.CipherSuite]
+}
+
+func (*versionSpec) Name() string { return predicates.TLSVersionName }
+
+func (*versionSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) != 1 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	arg, ok := args[0].(string)
+	if !ok {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	arg = strings.TrimSpace(arg)
+	p := &versionPredicate{}
+	switch {
+	case strings.HasPrefix(arg, ">="):
+		p.compare = func(c int) bool { return c >= 0 }
+		arg = arg[2:]
+	case strings.HasPrefix(arg, "<="):
+		p.compare = func(c int) bool { return c <= 0 }
+		arg = arg[2:]
+	case strings.HasPrefix(arg, ">"):
+		p.compare = func(c int) bool { return c > 0 }
+		arg = arg[1:]
+	case strings.HasPrefix(arg, "<"):
+		p.compare = func(c int) bool { return c < 0 }
+		arg = arg[1:]
+	case strings.HasPrefix(arg, "="):
+		p.compare = func(c int) bool { return c == 0 }
+		arg = arg[1:]
+	default:
+		p.compare = func(c int) bool { return c == 0 }
+	}
+
+	v, ok := versions[strings.TrimSpace(arg)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown TLS version %s", predicates.ErrInvalidPredicateParameters, arg)
+	}
+
+	p.version = v
+	return p, nil
+}
+
+func (p *versionPredicate) Match(r *http.Request) bool {
+	if r.TLS == nil {
+		return false
+	}
+
+	return p.compare(int(r.TLS.Version) - int(p.version))
+}
+
+func (*clientCertSANSpec) Name() string { return predicates.ClientCertSANName }
+
+func (*clientCertSANSpec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) == 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	p := &clientCertSANPredicate{names: make(map[string]bool)}
+	for _, arg := range args {
+		name, ok := arg.(string)
+		if !ok || name == "" {
+			return nil, predicates.ErrInvalidPredicateParameters
+		}
+
+		p.names[name] = true
+	}
+
+	return p, nil
+}
+
+func (p *clientCertSANPredicate) Match(r *http.Request) bool {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return false
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	for _, u := range leaf.URIs {
+		if p.names[u.String()] {
+			return true
+		}
+	}
+
+	for _, dns := range leaf.DNSNames {
+		if p.names[dns] {
+			return true
+		}
+	}
+
+	return false
+}
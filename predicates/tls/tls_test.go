@@ -0,0 +1,170 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCipherArgs(t *testing.T) {
+	s := NewCipher()
+	for _, args := range [][]interface{}{
+		{},
+		{1.2},
+		{"TLS_UNKNOWN_CIPHER"},
+		{"TLS_RSA_WITH_AES_128_CBC_SHA", 3.4},
+	} {
+		if _, err := s.Create(args); err == nil {
+			t.Errorf("expected error for arguments: %v", args)
+		}
+	}
+}
+
+func TestCipherMatch(t *testing.T) {
+	s := NewCipher()
+	p, err := s.Create([]interface{}{"TLS_RSA_WITH_AES_128_CBC_SHA", "TLS_RSA_WITH_3DES_EDE_CBC_SHA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		state *tls.ConnectionState
+		match bool
+	}{{
+		name:  "plaintext",
+		match: false,
+	}, {
+		name:  "listed cipher",
+		state: &tls.ConnectionState{CipherSuite: tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+		match: true,
+	}, {
+		name:  "other cipher",
+		state: &tls.ConnectionState{CipherSuite: tls.TLS_AES_128_GCM_SHA256},
+		match: false,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if p.Match(&http.Request{TLS: tc.state}) != tc.match {
+				t.Errorf("expected match: %v", tc.match)
+			}
+		})
+	}
+}
+
+func TestVersionArgs(t *testing.T) {
+	s := NewVersion()
+	for _, args := range [][]interface{}{
+		{},
+		{1.2},
+		{"2.0"},
+		{">>1.2"},
+		{"1.2", "1.3"},
+	} {
+		if _, err := s.Create(args); err == nil {
+			t.Errorf("expected error for arguments: %v", args)
+		}
+	}
+}
+
+func TestVersionMatch(t *testing.T) {
+	s := NewVersion()
+	for _, tc := range []struct {
+		arg     string
+		version uint16
+		plain   bool
+		match   bool
+	}{
+		{arg: "1.2", version: tls.VersionTLS12, match: true},
+		{arg: "=1.2", version: tls.VersionTLS13, match: false},
+		{arg: ">=1.3", version: tls.VersionTLS13, match: true},
+		{arg: ">=1.3", version: tls.VersionTLS12, match: false},
+		{arg: ">1.1", version: tls.VersionTLS12, match: true},
+		{arg: "<1.2", version: tls.VersionTLS11, match: true},
+		{arg: "<=1.2", version: tls.VersionTLS13, match: false},
+		{arg: ">=1.0", plain: true, match: false},
+	} {
+		t.Run(tc.arg, func(t *testing.T) {
+			p, err := s.Create([]interface{}{tc.arg})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := &http.Request{}
+			if !tc.plain {
+				r.TLS = &tls.ConnectionState{Version: tc.version}
+			}
+
+			if p.Match(r) != tc.match {
+				t.Errorf("expected match: %v", tc.match)
+			}
+		})
+	}
+}
+
+func TestClientCertSANArgs(t *testing.T) {
+	s := NewClientCertSAN()
+	for _, args := range [][]interface{}{
+		{},
+		{1.2},
+		{""},
+		{"spiffe://acme/payments", 3.4},
+	} {
+		if _, err := s.Create(args); err == nil {
+			t.Errorf("expected error for arguments: %v", args)
+		}
+	}
+}
+
+func TestClientCertSANMatch(t *testing.T) {
+	s := NewClientCertSAN()
+	p, err := s.Create([]interface{}{"spiffe://acme/payments", "orders.svc.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := url.Parse("spiffe://acme/payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paymentsCert := &x509.Certificate{URIs: []*url.URL{payments}}
+	ordersCert := &x509.Certificate{DNSNames: []string{"orders.svc.internal"}}
+	otherCert := &x509.Certificate{DNSNames: []string{"other.svc.internal"}}
+
+	for _, tc := range []struct {
+		name  string
+		state *tls.ConnectionState
+		match bool
+	}{{
+		name:  "plaintext",
+		match: false,
+	}, {
+		name:  "no client certificate",
+		state: &tls.ConnectionState{},
+		match: false,
+	}, {
+		name:  "presented but not verified",
+		state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{paymentsCert}},
+		match: false,
+	}, {
+		name:  "uri san",
+		state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{paymentsCert}}},
+		match: true,
+	}, {
+		name:  "dns san",
+		state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{ordersCert}}},
+		match: true,
+	}, {
+		name:  "other san",
+		state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{otherCert}}},
+		match: false,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if p.Match(&http.Request{TLS: tc.state}) != tc.match {
+				t.Errorf("expected match: %v", tc.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Package websocket implements a predicate to match the WebSocket upgrade
+requests.
+
+The predicate matches the requests that have a Connection header
+containing the "upgrade" token, and an Upgrade header containing the
+"websocket" protocol. The header values are compared case-insensitive.
+It can be combined with other predicates, e.g. to route the WebSocket
+traffic of a path to a dedicated backend pool.
+
+Examples:
+
+    ws: Path("/ws") && WebSocket() -> <roundRobin, "http://ws1.example.org", "http://ws2.example.org">;
+    http: Path("/ws") -> "http://www.example.org";
+*/
+package websocket
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zalando/skipper/predicates"
+	"github.com/zalando/skipper/routing"
+)
+
+type (
+	spec      struct{}
+	predicate struct{}
+)
+
+// New creates a new WebSocket predicate specification. The predicate
+// doesn't accept any arguments.
+func New() routing.PredicateSpec { return &spec{} }
+
+func (*spec) Name() string { return predicates.WebSocketName }
+
+func (*spec) Create(args []interface{}) (routing.Predicate, error) {
+	if len(args) != 0 {
+		return nil, predicates.ErrInvalidPredicateParameters
+	}
+
+	return &predicate{}, nil
+}
+
+// hasToken returns true when any of the comma separated values of the
+// header is equal to the token, case-insensitive.
+func hasToken(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (*predicate) Match(r *http.Request) bool {
+	return hasToken(r.Header, "Connection", "upgrade") && hasToken(r.Header, "Upgrade", "websocket")
+}
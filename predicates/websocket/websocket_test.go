@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	if _, err := New().Create([]interface{}{"websocket"}); err == nil {
+		t.Error("expected error for arguments")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	p, err := New().Create(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		msg    string
+		header http.Header
+		match  bool
+	}{{
+		msg:    "no headers",
+		header: http.Header{},
+	}, {
+		msg:    "upgrade",
+		header: http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+		match:  true,
+	}, {
+		msg:    "case-insensitive",
+		header: http.Header{"Connection": []string{"UPGRADE"}, "Upgrade": []string{"WebSocket"}},
+		match:  true,
+	}, {
+		msg:    "multiple connection tokens",
+		header: http.Header{"Connection": []string{"keep-alive, Upgrade"}, "Upgrade": []string{"websocket"}},
+		match:  true,
+	}, {
+		msg:    "no connection upgrade",
+		header: http.Header{"Connection": []string{"keep-alive"}, "Upgrade": []string{"websocket"}},
+	}, {
+		msg:    "no upgrade header",
+		header: http.Header{"Connection": []string{"Upgrade"}},
+	}, {
+		msg:    "other protocol",
+		header: http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"h2c"}},
+	}} {
+		t.Run(tc.msg, func(t *testing.T) {
+			if m := p.Match(&http.Request{Header: tc.header}); m != tc.match {
+				t.Errorf("unexpected match result, got: %t, expected: %t", m, tc.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBackendConnectionPool(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	doc := fmt.Sprintf(`
+		default: * -> "%[1]s";
+		pool1: Path("/pool1") -> backendConnectionPool(256, 1024) -> "%[1]s";
+		pool2: Path("/pool2") -> backendConnectionPool(256, 1024) -> "%[1]s";
+		pool3: Path("/pool3") -> backendConnectionPool(128) -> "%[1]s";
+	`, backend.URL)
+
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	for _, p := range []string{"/", "/pool1", "/pool2", "/pool3"} {
+		rsp, err := http.Get(ps.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		if rsp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected %d, got %d", p, http.StatusOK, rsp.StatusCode)
+		}
+	}
+
+	bt := tp.proxy.backendTransports
+	bt.mx.Lock()
+	defer bt.mx.Unlock()
+
+	if len(bt.transports) != 2 {
+		t.Fatalf("unexpected number of pooled transports: %d", len(bt.transports))
+	}
+
+	for key, tr := range bt.transports {
+		ht := tr.transport.(*http.Transport)
+		if ht.MaxIdleConnsPerHost != key.pool.MaxIdleConnsPerHost {
+			t.Errorf("unexpected max idle connections per host, expected: %d, got: %d", key.pool.MaxIdleConnsPerHost, ht.MaxIdleConnsPerHost)
+		}
+
+		if ht.MaxConnsPerHost != key.pool.MaxConnsPerHost {
+			t.Errorf("unexpected max connections per host, expected: %d, got: %d", key.pool.MaxConnsPerHost, ht.MaxConnsPerHost)
+		}
+	}
+}
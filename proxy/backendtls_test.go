@@ -0,0 +1,154 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackendTLS(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		doc      string
+		expected int
+	}{{
+		name:     "unknown authority",
+		doc:      fmt.Sprintf(`* -> "%s"`, backend.URL),
+		expected: http.StatusBadGateway,
+	}, {
+		name:     "dedicated CA",
+		doc:      fmt.Sprintf(`* -> backendTLS("", "", "%s") -> "%s"`, caFile, backend.URL),
+		expected: http.StatusOK,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := newTestProxy(tt.doc, FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			for i := 0; i < 2; i++ {
+				rsp, err := http.Get(ps.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp.Body.Close()
+				if rsp.StatusCode != tt.expected {
+					t.Errorf("expected %d, got %d", tt.expected, rsp.StatusCode)
+				}
+			}
+
+			bt := tp.proxy.backendTransports
+			bt.mx.Lock()
+			n := len(bt.transports)
+			bt.mx.Unlock()
+
+			if tt.expected == http.StatusOK && n != 1 {
+				t.Errorf("unexpected number of pooled transports: %d", n)
+			}
+		})
+	}
+}
+
+func TestBackendSNI(t *testing.T) {
+	type received struct{ serverName, host string }
+	requests := make(chan received, 1)
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- received{serverName: r.TLS.ServerName, host: r.Host}
+	}))
+	defer backend.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the test certificate is valid for example.com
+	doc := fmt.Sprintf(
+		`* -> setRequestHeader("Host", "www.example.org") -> backendTLS("", "", "%s") -> backendSNI("example.com") -> "%s"`,
+		caFile,
+		backend.URL,
+	)
+
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	rsp, err := http.Get(ps.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rsp.StatusCode)
+	}
+
+	r := <-requests
+	if r.serverName != "example.com" || r.host != "www.example.org" {
+		t.Errorf("unexpected server name and host: %s, %s", r.serverName, r.host)
+	}
+}
+
+func TestBackendTransportsPrune(t *testing.T) {
+	bt := newBackendTransports(&http.Transport{}, func(rt http.RoundTripper) http.RoundTripper { return rt })
+	count := func() int {
+		bt.mx.Lock()
+		defer bt.mx.Unlock()
+		return len(bt.transports)
+	}
+
+	foo := backendTransportKey{tlsKey: "foo"}
+	bar := backendTransportKey{tlsKey: "bar"}
+	bt.get(foo, &tls.Config{})
+	bt.get(bar, &tls.Config{})
+	if n := count(); n != 2 {
+		t.Fatalf("unexpected number of transports: %d", n)
+	}
+
+	// both were used during the first period
+	bt.closeIdleConnections()
+	if n := count(); n != 2 {
+		t.Fatalf("unexpected number of transports: %d", n)
+	}
+
+	// e.g. the routes using bar were removed
+	bt.get(foo, &tls.Config{})
+	bt.closeIdleConnections()
+	bt.mx.Lock()
+	_, hasFoo := bt.transports[foo]
+	_, hasBar := bt.transports[bar]
+	bt.mx.Unlock()
+	if !hasFoo || hasBar {
+		t.Errorf("failed to drop the unused transport, foo: %v, bar: %v", hasFoo, hasBar)
+	}
+
+	// a new configuration with the same content uses the same transport
+	if bt.get(foo, &tls.Config{}) != bt.get(foo, &tls.Config{}) {
+		t.Error("failed to share the transport of the same configuration")
+	}
+}
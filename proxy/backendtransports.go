@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	stdlibcontext "context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	"github.com/zalando/skipper/filters"
+)
+
+// the TLS configuration is identified by the hash of its content, so that
+// the recreated configurations use the same transport after the route
+// updates
+type backendTransportKey struct {
+	tlsKey     string
+	serverName string
+	h2c        bool
+	pool       filters.ConnectionPool
+}
+
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+// backendTransports pools the transports of the backends with dedicated
+// connection settings, e.g. TLS configured by the backendTLS filter, the
+// SNI set by the backendSNI filter, the connection pool size set by the
+// backendConnectionPool filter, or HTTP/2 over cleartext connections set
+// by the h2cBackend filter. The transports are created from the
+// default transport of the proxy, and they are shared by the requests
+// with the same settings. The transports that were not used during a
+// period of closing the idle connections, e.g. because the routes using
+// them were removed, are dropped.
+type backendTransports struct {
+	base *http.Transport
+	wrap func(http.RoundTripper) http.RoundTripper
+
+	mx         sync.Mutex
+	transports map[backendTransportKey]*backendTransport
+}
+
+type backendTransport struct {
+	transport idleConnectionsCloser
+	wrapped   http.RoundTripper
+	used      bool
+}
+
+func newBackendTransports(base *http.Transport, wrap func(http.RoundTripper) http.RoundTripper) *backendTransports {
+	return &backendTransports{
+		base:       base,
+		wrap:       wrap,
+		transports: make(map[backendTransportKey]*backendTransport),
+	}
+}
+
+// creates an HTTP/2 transport, that connects to the backends over
+// cleartext connections with prior knowledge, using the dialer of the
+// default transport
+func (bt *backendTransports) newH2CTransport() *http2.Transport {
+	dial := bt.base.DialContext
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+
+	return &http2.Transport{
+		AllowHTTP:          true,
+		DisableCompression: bt.base.DisableCompression,
+		DialTLSContext: func(ctx stdlibcontext.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+	}
+}
+
+func (bt *backendTransports) newTransport(key backendTransportKey, tlsConfig *tls.Config) *http.Transport {
+	tr := bt.base.Clone()
+	if tlsConfig != nil {
+		tr.TLSClientConfig = tlsConfig
+	}
+
+	if key.serverName != "" {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		} else {
+			tr.TLSClientConfig = tr.TLSClientConfig.Clone()
+		}
+
+		tr.TLSClientConfig.ServerName = key.serverName
+	}
+
+	if key.pool.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = key.pool.MaxIdleConnsPerHost
+	}
+
+	if key.pool.MaxConnsPerHost > 0 {
+		tr.MaxConnsPerHost = key.pool.MaxConnsPerHost
+	}
+
+	return tr
+}
+
+// get returns the transport for the key, and creates it when it doesn't
+// exist yet. The TLS configuration is used only when the transport is
+// created.
+func (bt *backendTransports) get(key backendTransportKey, tlsConfig *tls.Config) http.RoundTripper {
+	bt.mx.Lock()
+	defer bt.mx.Unlock()
+
+	if t, ok := bt.transports[key]; ok {
+		t.used = true
+		return t.wrapped
+	}
+
+	t := &backendTransport{used: true}
+	if key.h2c {
+		tr := bt.newH2CTransport()
+		t.transport, t.wrapped = tr, bt.wrap(tr)
+	} else {
+		tr := bt.newTransport(key, tlsConfig)
+		t.transport, t.wrapped = tr, bt.wrap(tr)
+	}
+
+	bt.transports[key] = t
+	return t.wrapped
+}
+
+// closeIdleConnections closes the idle connections of the transports, and
+// drops the ones that were not used since the last call.
+func (bt *backendTransports) closeIdleConnections() {
+	bt.mx.Lock()
+	defer bt.mx.Unlock()
+
+	for key, t := range bt.transports {
+		t.transport.CloseIdleConnections()
+		if !t.used {
+			delete(bt.transports, key)
+			continue
+		}
+
+		t.used = false
+	}
+}
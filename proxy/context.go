@@ -35,6 +35,7 @@ type context struct {
 	originalRequest      *http.Request
 	originalResponse     *http.Response
 	outgoingHost         string
+	backendHost          string
 	debugFilterPanics    []interface{}
 	outgoingDebugRequest *http.Request
 	executionCounter     int
@@ -47,6 +48,11 @@ type context struct {
 	proxy                *Proxy
 	routeLookup          *routing.RouteLookup
 	cancelBackendContext stdlibcontext.CancelFunc
+	cancelRequestContext stdlibcontext.CancelFunc
+	failedEndpoints      map[string]bool
+	retryCount           int
+	backendTime          time.Duration
+	routeInflightDone    []func()
 }
 
 type filterMetrics struct {
@@ -236,13 +242,62 @@ func (c *context) clone() *context {
 	// preserve the original path params by cloning the set:
 	cc.pathParams = appendParams(nil, c.pathParams)
 
+	// the failed endpoints and the retries are tracked per backend request
+	cc.failedEndpoints = nil
+	cc.retryCount = 0
+
 	return &cc
 }
 
+// releases the inflight counters of the routes that the request was
+// handled by, including the loopback routes
+func (c *context) releaseRouteInflight() {
+	for _, done := range c.routeInflightDone {
+		done()
+	}
+
+	c.routeInflightDone = nil
+}
+
+// applies the deadline set by the requestTimeout filter to the context of
+// the request, counted from the start of serving the request. When a
+// deadline was already set, the earlier one applies.
+func (c *context) applyRequestTimeout() {
+	timeout, ok := c.stateBag[filters.RequestTimeout].(time.Duration)
+	if !ok {
+		return
+	}
+
+	delete(c.stateBag, filters.RequestTimeout)
+	deadline := c.startServe.Add(timeout)
+	if current, ok := c.request.Context().Deadline(); ok && !deadline.Before(current) {
+		return
+	}
+
+	rctx, cancel := stdlibcontext.WithDeadline(c.request.Context(), deadline)
+	c.request = c.request.WithContext(rctx)
+	if previous := c.cancelRequestContext; previous != nil {
+		c.cancelRequestContext = func() {
+			cancel()
+			previous()
+		}
+	} else {
+		c.cancelRequestContext = cancel
+	}
+}
+
 func (c *context) wasExecuted() bool {
 	return c.executionCounter != 0
 }
 
+func (c *context) markEndpointFailed(host string) {
+	if c.failedEndpoints == nil {
+		c.failedEndpoints = make(map[string]bool)
+	}
+
+	c.failedEndpoints[host] = true
+}
+
 func (c *context) setMetricsPrefix(prefix string) {
 	c.metrics.prefix = prefix + ".custom."
 }
@@ -255,6 +310,7 @@ func (c *context) Split() (filters.FilterContext, error) {
 	cc := c.clone()
 	cc.stateBag = map[string]interface{}{}
 	cc.responseWriter = noopFlushedResponseWriter{}
+	cc.routeInflightDone = nil
 	cc.metrics = &filterMetrics{
 		prefix: cc.metrics.prefix,
 		impl:   cc.proxy.metrics,
@@ -275,6 +331,7 @@ func (c *context) Split() (filters.FilterContext, error) {
 }
 
 func (c *context) Loopback() {
+	defer c.releaseRouteInflight()
 	err := c.proxy.do(c)
 	if c.response != nil && c.response.Body != nil {
 		if _, err := io.Copy(io.Discard, c.response.Body); err != nil {
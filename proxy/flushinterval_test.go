@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type countingFlusher struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (f *countingFlusher) Flush() { f.flushes++ }
+
+func TestCopyStreamFlushInterval(t *testing.T) {
+	for _, ti := range []struct {
+		msg      string
+		interval time.Duration
+		flushes  int
+	}{{
+		msg:      "immediate",
+		interval: -1,
+		flushes:  3,
+	}, {
+		msg:      "batched",
+		interval: time.Hour,
+		flushes:  1,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			w := &countingFlusher{ResponseRecorder: httptest.NewRecorder()}
+			r := io.MultiReader(
+				bytes.NewBufferString("foo"),
+				bytes.NewBufferString("bar"),
+				bytes.NewBufferString("baz"),
+			)
+
+			n, err := copyStream(w, r, ti.interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n != 9 || w.Body.String() != "foobarbaz" {
+				t.Errorf("unexpected body: %d, %s", n, w.Body.String())
+			}
+
+			if w.flushes != ti.flushes {
+				t.Errorf("unexpected number of flushes, got: %d, expected: %d", w.flushes, ti.flushes)
+			}
+		})
+	}
+}
+
+func TestFlushIntervalFilter(t *testing.T) {
+	payload := []byte("some data to stream")
+	s := startTestServer(payload, 3, voidCheck)
+	defer s.Close()
+
+	doc := fmt.Sprintf(`hello: Path("/hello") -> flushInterval("5ms") -> "%s"`, s.URL)
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tp.close()
+
+	r := httptest.NewRequest("GET", "https://www.example.org/hello", nil)
+	w := httptest.NewRecorder()
+	tp.proxy.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	if !bytes.Equal(w.Body.Bytes(), payload) {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGRPCErrorResponse(t *testing.T) {
+	// the backend is not listening
+	tp, err := newTestProxy(`* -> grpcStatus() -> "http://127.0.0.1:1"`, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	for _, tt := range []struct {
+		contentType string
+		status      int
+		grpcStatus  string
+	}{{
+		contentType: "application/json",
+		status:      http.StatusBadGateway,
+	}, {
+		contentType: "application/grpc",
+		status:      http.StatusOK,
+		grpcStatus:  "14",
+	}} {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req, err := http.NewRequest("POST", ps.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", tt.contentType)
+			rsp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if _, err := io.ReadAll(rsp.Body); err != nil {
+				t.Fatal(err)
+			}
+
+			if rsp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rsp.StatusCode)
+			}
+
+			if s := rsp.Trailer.Get("Grpc-Status"); s != tt.grpcStatus {
+				t.Errorf("expected gRPC status %q, got %q", tt.grpcStatus, s)
+			}
+		})
+	}
+}
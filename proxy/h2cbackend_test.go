@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestH2CBackend(t *testing.T) {
+	backend := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte("hello"))
+		w.(http.Flusher).Flush()
+		w.Header().Set("Grpc-Status", "0")
+	}), &http2.Server{}))
+	defer backend.Close()
+
+	for _, tt := range []struct {
+		name     string
+		doc      string
+		expected int
+	}{{
+		name:     "http/1.1",
+		doc:      fmt.Sprintf(`* -> "%s"`, backend.URL),
+		expected: http.StatusHTTPVersionNotSupported,
+	}, {
+		name:     "h2c",
+		doc:      fmt.Sprintf(`* -> h2cBackend() -> "%s"`, backend.URL),
+		expected: http.StatusOK,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := newTestProxy(tt.doc, FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			rsp, err := http.Get(ps.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+			if rsp.StatusCode != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, rsp.StatusCode)
+			}
+
+			if tt.expected != http.StatusOK {
+				return
+			}
+
+			b, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != "hello" {
+				t.Errorf("unexpected body: %s", string(b))
+			}
+
+			if rsp.Trailer.Get("Grpc-Status") != "0" {
+				t.Errorf("failed to forward the trailer: %v", rsp.Trailer)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package proxy
+
+import (
+	stdlibcontext "context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/filters"
+	"github.com/zalando/skipper/filters/hedge"
+	"github.com/zalando/skipper/routing"
+)
+
+type hedgeResult struct {
+	index    int
+	endpoint *routing.LBEndpoint
+	response *http.Response
+	err      error
+}
+
+// cancelBody cancels the context of the winning hedged request, when the
+// response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel stdlibcontext.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// hedgeSettings returns the hedge settings of the route, when the request
+// can be hedged: idempotent requests without a body, to load balanced
+// backends with multiple endpoints.
+func hedgeSettings(ctx *context, endpoint *routing.LBEndpoint) (*hedge.Hedge, bool) {
+	h, ok := ctx.StateBag()[filters.BackendHedge].(*hedge.Hedge)
+	if !ok || endpoint == nil {
+		return nil, false
+	}
+
+	if _, ok := ctx.StateBag()[filters.BackendIsProxyKey]; ok {
+		return nil, false
+	}
+
+	req := ctx.Request()
+	if ctx.route.BackendType != eskip.LBBackend ||
+		len(ctx.route.LBEndpoints) < 2 ||
+		!isIdempotent(req.Method) ||
+		(req.Body != nil && req.Body != http.NoBody) {
+		return nil, false
+	}
+
+	return h, true
+}
+
+// nextHedgeRequest clones the request for an endpoint selected by the load
+// balancing algorithm of the route, that was not used yet. It returns nil,
+// when no such endpoint was found.
+func nextHedgeRequest(ctx *context, req *http.Request, used map[string]bool) (*http.Request, *routing.LBEndpoint) {
+	rt := ctx.route
+	lbctx := &routing.LBContext{Request: ctx.request, Route: rt, Params: ctx.StateBag(), ExcludedEndpoints: used}
+
+	// the algorithms fall back to the excluded endpoints only when all of
+	// them were used
+	e := rt.LBAlgorithm.Apply(lbctx)
+	if used[e.Host] {
+		return nil, nil
+	}
+
+	used[e.Host] = true
+	r := req.Clone(req.Context())
+	r.URL.Scheme = e.Scheme
+	r.URL.Host = e.Host
+	return r, &e
+}
+
+// hedgedRoundTrip sends the request, and when it doesn't respond within the
+// hedge delay, or it fails, it sends the request to another endpoint, up to
+// the max attempts. The first successful response is returned together with
+// its endpoint, and the other requests are cancelled. The request of the
+// returned response is cancelled when its body is closed. When all the
+// requests fail, the endpoint of the last failed request is returned. The
+// endpoints of the failed requests are marked as failed in the context.
+func (p *Proxy) hedgedRoundTrip(ctx *context, rt http.RoundTripper, req *http.Request, endpoint *routing.LBEndpoint, h *hedge.Hedge) (*http.Response, *routing.LBEndpoint, error) {
+	used := map[string]bool{endpoint.Host: true}
+	results := make(chan hedgeResult, h.MaxAttempts)
+	var cancels []stdlibcontext.CancelFunc
+
+	send := func(r *http.Request, e *routing.LBEndpoint) {
+		rctx, cancel := stdlibcontext.WithCancel(r.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			rsp, err := rt.RoundTrip(r.WithContext(rctx))
+			results <- hedgeResult{index: index, endpoint: e, response: rsp, err: err}
+		}()
+	}
+
+	sendNext := func() bool {
+		if len(cancels) >= h.MaxAttempts {
+			return false
+		}
+
+		next, e := nextHedgeRequest(ctx, req, used)
+		if next == nil {
+			return false
+		}
+
+		// the inflight requests of the first endpoint are counted by
+		// the caller:
+		e.Metrics.IncInflightRequest()
+		p.metrics.IncCounter("hedge.requests")
+		send(next, e)
+		return true
+	}
+
+	send(req, endpoint)
+	pending := 1
+	timer := time.NewTimer(h.Delay)
+	defer timer.Stop()
+
+	var (
+		lastErr      error
+		lastEndpoint *routing.LBEndpoint
+	)
+
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.index > 0 {
+				r.endpoint.Metrics.DecInflightRequest()
+			}
+
+			if r.err != nil {
+				lastErr, lastEndpoint = r.err, r.endpoint
+				if req.Context().Err() == nil {
+					ctx.markEndpointFailed(r.endpoint.Host)
+				}
+
+				cancels[r.index]()
+				if pending == 0 && sendNext() {
+					pending++
+				}
+
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+
+			go closeHedgeResults(results, pending)
+			r.response.Body = &cancelBody{ReadCloser: r.response.Body, cancel: cancels[r.index]}
+			return r.response, r.endpoint, nil
+		case <-timer.C:
+			if sendNext() {
+				pending++
+				timer.Reset(h.Delay)
+			}
+		}
+	}
+
+	return nil, lastEndpoint, lastErr
+}
+
+// closeHedgeResults closes the responses of the cancelled requests that
+// were still pending.
+func closeHedgeResults(results <-chan hedgeResult, pending int) {
+	for i := 0; i < pending; i++ {
+		r := <-results
+		if r.index > 0 {
+			r.endpoint.Metrics.DecInflightRequest()
+		}
+
+		if r.response != nil {
+			r.response.Body.Close()
+		}
+	}
+}
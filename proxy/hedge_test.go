@@ -0,0 +1,148 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/tracing/tracingtest"
+)
+
+func TestHedgedRequests(t *testing.T) {
+	var cancelled int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			atomic.AddInt32(&cancelled, 1)
+		case <-time.After(time.Second):
+			w.Write([]byte("slow"))
+		}
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	}))
+	defer fast.Close()
+
+	for _, ti := range []struct {
+		msg         string
+		filter      string
+		method      string
+		expectSlow  bool
+		expectHedge bool
+	}{{
+		msg:        "no hedging",
+		method:     "GET",
+		expectSlow: true,
+	}, {
+		msg:         "hedged",
+		filter:      `hedge("20ms", 2) ->`,
+		method:      "GET",
+		expectHedge: true,
+	}, {
+		msg:        "non-idempotent method not hedged",
+		filter:     `hedge("20ms", 2) ->`,
+		method:     "POST",
+		expectSlow: true,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			atomic.StoreInt32(&cancelled, 0)
+			doc := fmt.Sprintf(`* -> %s <roundRobin, "%s", "%s">`, ti.filter, slow.URL, fast.URL)
+			tp, err := newTestProxy(doc, FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			var slowResponses int
+			for i := 0; i < 4; i++ {
+				req, err := http.NewRequest(ti.method, ps.URL, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				b, err := io.ReadAll(rsp.Body)
+				rsp.Body.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(b) == "slow" {
+					slowResponses++
+				}
+			}
+
+			if ti.expectSlow && slowResponses == 0 {
+				t.Error("expected slow responses")
+			} else if !ti.expectSlow && slowResponses > 0 {
+				t.Errorf("unexpected slow responses: %d", slowResponses)
+			}
+
+			if ti.expectHedge {
+				// allow the slow backend to observe the cancellation
+				time.Sleep(50 * time.Millisecond)
+				if atomic.LoadInt32(&cancelled) == 0 {
+					t.Error("failed to cancel the slow request")
+				}
+			}
+		})
+	}
+}
+
+func TestHedgedRequestsEndpoint(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer healthy.Close()
+
+	doc := fmt.Sprintf(`* -> hedge("1s", 2) -> <roundRobin, "%s", "%s">`, failing.URL, healthy.URL)
+	tracer := &tracingtest.Tracer{}
+	tp, err := newTestProxyWithParams(doc, Params{OpenTracing: &OpenTracingParams{Tracer: tracer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tp.close()
+
+	// round-robin starts at a non-deterministic index, the failing
+	// endpoint is tried first in some of the requests
+	for i := 0; i < 4; i++ {
+		tracer.Reset("")
+		req, err := http.NewRequest("GET", "https://www.example.org", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		tp.proxy.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+
+		proxySpans := tracer.FindAllSpans("proxy")
+		if len(proxySpans) != 1 {
+			t.Fatalf("invalid count of proxy spans: %d", len(proxySpans))
+		}
+
+		if e := proxySpans[0].Tags[SkipperLBEndpointTag]; e != healthy.Listener.Addr().String() {
+			t.Errorf("invalid lb endpoint tag: %v, expected: %s", e, healthy.Listener.Addr().String())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIdempotencyUnlockOnBackendError(t *testing.T) {
+	var requests int32
+	wait := make(chan struct{})
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-wait
+		}
+	}))
+	defer func() {
+		close(wait)
+		service.Close()
+	}()
+
+	doc := fmt.Sprintf(`* -> idempotency("1h") -> backendTimeout("10ms") -> "%s"`, service.URL)
+	tp, err := newTestProxy(doc, FlagsNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.close()
+
+	ps := httptest.NewServer(tp.proxy)
+	defer ps.Close()
+
+	post := func() *http.Response {
+		req, err := http.NewRequest("POST", ps.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Idempotency-Key", "key-1")
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rsp.Body.Close()
+		return rsp
+	}
+
+	if rsp := post(); rsp.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got: %d", rsp.StatusCode)
+	}
+
+	// the lock of the failed request is released, and the retry is
+	// forwarded to the backend, instead of being rejected with 409:
+	rsp := post()
+	if rsp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got: %d", rsp.StatusCode)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 backend requests, got: %d", n)
+	}
+
+	// the successful response is replayed:
+	if rsp := post(); rsp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected replayed response, got: %v", rsp.Header)
+	}
+}
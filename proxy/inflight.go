@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zalando/skipper/metrics"
+)
+
+const defaultRouteInflightMetricsInterval = time.Second
+
+// routeInflight counts the requests currently handled by the proxy per
+// route, and periodically reports the counts as gauges. The counters of
+// the routes without requests in two consecutive samples are dropped, so
+// that the removed routes don't accumulate.
+type routeInflight struct {
+	mu       sync.Mutex
+	counters map[string]*inflightCounter
+	metrics  metrics.Metrics
+	interval time.Duration
+	quit     <-chan struct{}
+}
+
+type inflightCounter struct {
+	count int64
+	idle  bool
+}
+
+func newRouteInflight(m metrics.Metrics, interval time.Duration, quit <-chan struct{}) *routeInflight {
+	if interval <= 0 {
+		interval = defaultRouteInflightMetricsInterval
+	}
+
+	ri := &routeInflight{
+		counters: make(map[string]*inflightCounter),
+		metrics:  m,
+		interval: interval,
+		quit:     quit,
+	}
+
+	go ri.run()
+	return ri
+}
+
+// inc increments the counter of the route, and returns the function that
+// decrements it. A counter is dropped only while its count is zero, and
+// both happen under the same lock, so the increments are never lost.
+func (ri *routeInflight) inc(routeID string) func() {
+	ri.mu.Lock()
+	c, ok := ri.counters[routeID]
+	if !ok {
+		c = &inflightCounter{}
+		ri.counters[routeID] = c
+	}
+
+	c.count++
+	ri.mu.Unlock()
+
+	return func() {
+		ri.mu.Lock()
+		c.count--
+		ri.mu.Unlock()
+	}
+}
+
+func (ri *routeInflight) sample() {
+	ri.mu.Lock()
+	counts := make(map[string]int64, len(ri.counters))
+	for routeID, c := range ri.counters {
+		counts[routeID] = c.count
+		if c.count == 0 && c.idle {
+			delete(ri.counters, routeID)
+		}
+
+		c.idle = c.count == 0
+	}
+
+	ri.mu.Unlock()
+
+	for routeID, n := range counts {
+		ri.metrics.UpdateGauge("routeinflight."+routeID, float64(n))
+	}
+}
+
+func (ri *routeInflight) run() {
+	for {
+		select {
+		case <-time.After(ri.interval):
+			ri.sample()
+		case <-ri.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package proxy
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/metrics/metricstest"
+)
+
+func TestRouteInflight(t *testing.T) {
+	m := &metricstest.MockMetrics{}
+	quit := make(chan struct{})
+	defer close(quit)
+
+	// long interval, the test samples manually
+	ri := newRouteInflight(m, time.Hour, quit)
+
+	done1 := ri.inc("route1")
+	done2 := ri.inc("route1")
+	done3 := ri.inc("route2")
+
+	ri.sample()
+	if v, _ := m.Gauge("routeinflight.route1"); v != 2 {
+		t.Errorf("unexpected count for route1: %v", v)
+	}
+
+	if v, _ := m.Gauge("routeinflight.route2"); v != 1 {
+		t.Errorf("unexpected count for route2: %v", v)
+	}
+
+	done1()
+	done2()
+	done3()
+
+	ri.sample()
+	if v, ok := m.Gauge("routeinflight.route1"); !ok || v != 0 {
+		t.Errorf("unexpected count for route1: %v", v)
+	}
+
+	// idle in two consecutive samples, the counter is dropped
+	ri.sample()
+	ri.mu.Lock()
+	_, ok := ri.counters["route1"]
+	ri.mu.Unlock()
+	if ok {
+		t.Error("failed to drop the idle counter")
+	}
+
+	// counting starts again after dropping
+	done := ri.inc("route1")
+	defer done()
+
+	ri.sample()
+	if v, _ := m.Gauge("routeinflight.route1"); v != 1 {
+		t.Errorf("unexpected count for route1: %v", v)
+	}
+}
+
+func TestRouteInflightSampleConcurrently(t *testing.T) {
+	m := &metricstest.MockMetrics{}
+	quit := make(chan struct{})
+	defer close(quit)
+
+	ri := newRouteInflight(m, time.Hour, quit)
+
+	const n = 100
+	var wg sync.WaitGroup
+	done := make(chan func(), n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ri.sample()
+			done <- ri.inc("route1")
+			ri.sample()
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+
+	ri.sample()
+	if v, _ := m.Gauge("routeinflight.route1"); v != n {
+		t.Errorf("unexpected count for route1: %v", v)
+	}
+
+	for d := range done {
+		d()
+	}
+}
@@ -49,3 +49,47 @@ func TestPathPatch(t *testing.T) {
 	testPatch(t, "not patched", FlagsNone, http.StatusNotFound)
 	testPatch(t, "patched", PatchPath, http.StatusOK)
 }
+
+func TestNormalizePath(t *testing.T) {
+	dc, err := routestring.New(`Path("/foo/bar") -> status(200) -> <shunt>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  builtin.MakeRegistry(),
+		DataClients:     []routing.DataClient{dc},
+	})
+	defer rt.Close()
+
+	for _, tt := range []struct {
+		title          string
+		flags          Flags
+		expectedStatus int
+	}{
+		{"not normalized", FlagsNone, http.StatusNotFound},
+		{"normalized", NormalizePath, http.StatusOK},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			p := WithParams(Params{Routing: rt, Flags: tt.flags})
+			defer p.Close()
+
+			s := httptest.NewServer(p)
+			defer s.Close()
+
+			<-rt.FirstLoad()
+
+			rsp, err := http.Get(s.URL + "//foo/./baz/%2e%2e//bar")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer rsp.Body.Close()
+
+			if rsp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rsp.StatusCode)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ot "github.com/opentracing/opentracing-go"
@@ -27,11 +28,13 @@ import (
 	al "github.com/zalando/skipper/filters/accesslog"
 	circuitfilters "github.com/zalando/skipper/filters/circuit"
 	flowidFilter "github.com/zalando/skipper/filters/flowid"
+	"github.com/zalando/skipper/filters/grpc"
 	ratelimitfilters "github.com/zalando/skipper/filters/ratelimit"
 	tracingfilter "github.com/zalando/skipper/filters/tracing"
 	"github.com/zalando/skipper/loadbalancer"
 	"github.com/zalando/skipper/logging"
 	"github.com/zalando/skipper/metrics"
+	snet "github.com/zalando/skipper/net"
 	"github.com/zalando/skipper/proxy/fastcgi"
 	"github.com/zalando/skipper/ratelimit"
 	"github.com/zalando/skipper/rfc"
@@ -46,6 +49,8 @@ const (
 	unknownRouteBackendType = "<unknown>"
 	unknownRouteBackend     = "<unknown>"
 	backendIsProxyHeader    = "X-Skipper-Proxy"
+	backendTimeHeader       = "X-Skipper-Backend-Time"
+	totalTimeHeader         = "X-Skipper-Total-Time"
 
 	// Number of loops allowed by default.
 	DefaultMaxLoopbacks = 9
@@ -101,6 +106,11 @@ const (
 	// if the reserved characters according to RFC 2616 and RFC 3986
 	// were unescaped by the parser.
 	PatchPath
+
+	// NormalizePath instructs the proxy to normalize the request path
+	// before route matching: to normalize the percent-encoding, to
+	// collapse the duplicate slashes and to remove the dot segments.
+	NormalizePath
 )
 
 // Options are deprecated alias for Flags.
@@ -232,11 +242,30 @@ type Params struct {
 	// check OpenTracingParams
 	OpenTracing *OpenTracingParams
 
+	// IdempotentRetries sets the maximum number of retries of the
+	// idempotent requests without a body to load balanced backends, when
+	// the backend connection fails before any response was received.
+	// The default 0 means that only the dial errors are retried once,
+	// independent of the request method. It can be overridden per route
+	// with the idempotentRetries filter.
+	IdempotentRetries int
+
 	// CustomHttpRoundTripperWrap provides ability to wrap http.RoundTripper created by skipper.
 	// http.RoundTripper is used for making outgoing requests (backends)
 	// It allows to add additional logic (for example tracing) by providing a wrapper function
 	// which accepts original skipper http.RoundTripper as an argument and returns a wrapped roundtripper
 	CustomHttpRoundTripperWrap func(http.RoundTripper) http.RoundTripper
+
+	// EnableRouteInflightMetrics enables counting the requests currently
+	// handled by the proxy per route, and reporting the counts as the
+	// routeinflight.<route id> gauges. It helps to find the routes that
+	// cause a growing number of goroutines, but it has a cost on every
+	// request.
+	EnableRouteInflightMetrics bool
+
+	// RouteInflightMetricsInterval sets how often the per route inflight
+	// requests are reported. Defaults to 1s.
+	RouteInflightMetricsInterval time.Duration
 }
 
 type (
@@ -297,6 +326,8 @@ func (f Flags) HopHeadersRemoval() bool { return f&HopHeadersRemoval != 0 }
 
 func (f Flags) patchPath() bool { return f&PatchPath != 0 }
 
+func (f Flags) normalizePath() bool { return f&NormalizePath != 0 }
+
 // Priority routes are custom route implementations that are matched against
 // each request before the routes in the general lookup tree.
 type PriorityRoute interface {
@@ -328,9 +359,12 @@ type Proxy struct {
 	lb                       *loadbalancer.LB
 	upgradeAuditLogOut       io.Writer
 	upgradeAuditLogErr       io.Writer
+	idempotentRetries        int
 	auditLogHook             chan struct{}
 	clientTLS                *tls.Config
 	hostname                 string
+	routeInflight            *routeInflight
+	backendTransports        *backendTransports
 }
 
 // proxyError is used to wrap errors during proxying and to indicate
@@ -343,6 +377,7 @@ type proxyError struct {
 	code             int
 	handled          bool
 	dialingFailed    bool
+	transportFailed  bool
 	additionalHeader http.Header
 }
 
@@ -370,6 +405,13 @@ func (e *proxyError) DialError() bool {
 	return e.dialingFailed
 }
 
+// TransportError returns true if the backend connection failed, e.g.
+// it was reset, before any response was received. It is safe to retry
+// a call with an idempotent method, if this returns true.
+func (e *proxyError) TransportError() bool {
+	return e.dialingFailed || e.transportFailed
+}
+
 func copyHeader(to, from http.Header) {
 	for k, v := range from {
 		to[http.CanonicalHeaderKey(k)] = v
@@ -410,10 +452,70 @@ func (f *flusher) Write(p []byte) (n int, err error) {
 	return
 }
 
-func copyStream(to flushedResponseWriter, from io.Reader) (int64, error) {
+// intervalFlusher flushes the written data at most once per interval.
+type intervalFlusher struct {
+	mu       sync.Mutex
+	w        flushedResponseWriter
+	interval time.Duration
+	timer    *time.Timer
+	pending  bool
+}
+
+func (f *intervalFlusher) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.w.Write(p)
+	if err != nil || f.pending {
+		return n, err
+	}
+
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.interval, f.delayedFlush)
+	} else {
+		f.timer.Reset(f.interval)
+	}
+
+	f.pending = true
+	return n, nil
+}
+
+func (f *intervalFlusher) delayedFlush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.pending {
+		f.w.Flush()
+		f.pending = false
+	}
+}
+
+func (f *intervalFlusher) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+
+	if f.pending {
+		f.w.Flush()
+		f.pending = false
+	}
+}
+
+// copyStream copies the response body to the client. When the flush
+// interval is positive, it flushes at most once per interval, otherwise
+// after every write.
+func copyStream(to flushedResponseWriter, from io.Reader, flushInterval time.Duration) (int64, error) {
 	b := make([]byte, proxyBufferSize)
+	if flushInterval <= 0 {
+		return io.CopyBuffer(&flusher{to}, from, b)
+	}
 
-	return io.CopyBuffer(&flusher{to}, from, b)
+	f := &intervalFlusher{w: to, interval: flushInterval}
+	defer f.stop()
+	return io.CopyBuffer(f, from, b)
 }
 
 func schemeFromRequest(r *http.Request) string {
@@ -475,7 +577,9 @@ func mapRequest(ctx *context, requestContext stdlibcontext.Context, removeHopHea
 		setRequestURLFromRequest(u, r)
 		setRequestURLForDynamicBackend(u, stateBag)
 	case eskip.LBBackend:
-		endpoint = setRequestURLForLoadBalancedBackend(u, rt, &routing.LBContext{Request: r, Route: rt, Params: stateBag})
+		// when retrying, the algorithms avoid the endpoints that failed already
+		lbctx := &routing.LBContext{Request: r, Route: rt, Params: stateBag, ExcludedEndpoints: ctx.failedEndpoints}
+		endpoint = setRequestURLForLoadBalancedBackend(u, rt, lbctx)
 	default:
 		u.Scheme = rt.Scheme
 		u.Host = rt.Host
@@ -492,6 +596,12 @@ func mapRequest(ctx *context, requestContext stdlibcontext.Context, removeHopHea
 	}
 
 	rr.ContentLength = r.ContentLength
+	rr.GetBody = r.GetBody
+
+	// the trailers of the incoming request are set after its body was
+	// read, and sent to the backend after the body
+	rr.Trailer = r.Trailer
+
 	if removeHopHeaders {
 		rr.Header = cloneHeaderExcluding(r.Header, hopHeaders)
 	} else {
@@ -623,6 +733,8 @@ func WithParams(p Params) *Proxy {
 		Proxy:                 proxyFromHeader,
 	}
 
+	bt := newBackendTransports(tr, p.CustomHttpRoundTripperWrap)
+
 	quit := make(chan struct{})
 	// We need this to reliably fade on DNS change, which is right
 	// now not fixed with IdleConnTimeout in the http.Transport.
@@ -633,6 +745,7 @@ func WithParams(p Params) *Proxy {
 				select {
 				case <-time.After(p.CloseIdleConnsPeriod):
 					tr.CloseIdleConnections()
+					bt.closeIdleConnections()
 				case <-quit:
 					return
 				}
@@ -673,6 +786,11 @@ func WithParams(p Params) *Proxy {
 
 	hostname := os.Getenv("HOSTNAME")
 
+	var ri *routeInflight
+	if p.EnableRouteInflightMetrics {
+		ri = newRouteInflight(m, p.RouteInflightMetricsInterval, quit)
+	}
+
 	return &Proxy{
 		routing:                  p.Routing,
 		roundTripper:             p.CustomHttpRoundTripperWrap(tr),
@@ -695,6 +813,9 @@ func WithParams(p Params) *Proxy {
 		upgradeAuditLogErr:       os.Stderr,
 		clientTLS:                tr.TLSClientConfig,
 		hostname:                 hostname,
+		idempotentRetries:        p.IdempotentRetries,
+		routeInflight:            ri,
+		backendTransports:        bt,
 	}
 }
 
@@ -746,6 +867,7 @@ func (p *Proxy) applyFiltersToRequest(f []*routing.RouteFilter, ctx *context) []
 		tryCatch(func() {
 			ctx.setMetricsPrefix(fi.Name)
 			fi.Request(ctx)
+			ctx.applyRequestTimeout()
 			p.metrics.MeasureFilterRequest(fi.Name, start)
 		}, func(err interface{}, stack string) {
 			if p.flags.Debug() {
@@ -801,6 +923,23 @@ func (p *Proxy) applyFiltersToResponse(filters []*routing.RouteFilter, ctx *cont
 	p.metrics.MeasureAllFiltersResponse(ctx.route.Id, filtersStart)
 }
 
+func formatMilliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// sets the timing headers, when enabled by the timingHeaders filter
+func setTimingHeaders(ctx *context) {
+	if enabled, _ := ctx.StateBag()[filters.TimingHeaders].(bool); !enabled {
+		return
+	}
+
+	if ctx.backendTime > 0 {
+		ctx.response.Header.Set(backendTimeHeader, formatMilliseconds(ctx.backendTime))
+	}
+
+	ctx.response.Header.Set(totalTimeHeader, formatMilliseconds(time.Since(ctx.startServe)))
+}
+
 // addBranding overwrites any existing `X-Powered-By` or `Server` header from headerMap
 func addBranding(headerMap http.Header) {
 	if headerMap.Get("Server") == "" {
@@ -823,6 +962,11 @@ func (p *Proxy) lookupRoute(ctx *context) (rt *routing.Route, params map[string]
 func (p *Proxy) sendError(c *context, id string, code int) {
 	addBranding(c.responseWriter.Header())
 
+	if grpcErrors, _ := c.StateBag()[filters.GRPCErrors].(bool); grpcErrors {
+		p.sendGRPCError(c, id, code)
+		return
+	}
+
 	text := http.StatusText(code) + "\n"
 
 	c.responseWriter.Header().Set("Content-Length", strconv.Itoa(len(text)))
@@ -840,11 +984,33 @@ func (p *Proxy) sendError(c *context, id string, code int) {
 	)
 }
 
+// sendGRPCError sends the error as a gRPC response, with the status in the
+// trailers, because the gRPC clients misinterpret the HTTP error statuses.
+func (p *Proxy) sendGRPCError(c *context, id string, code int) {
+	h := c.responseWriter.Header()
+	h.Set("Content-Type", "application/grpc")
+	c.responseWriter.WriteHeader(http.StatusOK)
+	for k, v := range grpc.Trailer(code) {
+		h[http.TrailerPrefix+k] = v
+	}
+
+	p.metrics.MeasureServe(
+		id,
+		c.metricsHost(),
+		c.request.Method,
+		code,
+		c.startServe,
+	)
+}
+
 func (p *Proxy) makeUpgradeRequest(ctx *context, req *http.Request) error {
 	backendURL := req.URL
 
 	reverseProxy := httputil.NewSingleHostReverseProxy(backendURL)
 	reverseProxy.FlushInterval = p.flushInterval
+	if flushInterval, ok := ctx.StateBag()[filters.ResponseFlushInterval].(time.Duration); ok {
+		reverseProxy.FlushInterval = flushInterval
+	}
 	upgradeProxy := upgradeProxy{
 		backendAddr:     backendURL,
 		reverseProxy:    reverseProxy,
@@ -868,6 +1034,8 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 		return nil, &proxyError{err: fmt.Errorf("could not map backend request: %w", err)}
 	}
 
+	ctx.backendHost = req.URL.Host
+
 	if res, ok := p.rejectBackend(ctx, req); ok {
 		return res, nil
 	}
@@ -903,7 +1071,11 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 	p.tracing.
 		setTag(ctx.proxySpan, SpanKindTag, SpanKindClient).
 		setTag(ctx.proxySpan, SkipperRouteIDTag, ctx.route.Id).
+		setTag(ctx.proxySpan, SkipperRetryCountTag, ctx.retryCount).
 		setTag(ctx.proxySpan, HTTPUrlTag, u.String())
+	if endpoint != nil {
+		p.tracing.setTag(ctx.proxySpan, SkipperLBEndpointTag, endpoint.Host)
+	}
 	p.setCommonSpanInfo(u, req, ctx.proxySpan)
 
 	carrier := ot.HTTPHeadersCarrier(req.Header)
@@ -915,12 +1087,30 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 	ctx.proxySpan.LogKV("http_roundtrip", StartEvent)
 	req = injectClientTrace(req, ctx.proxySpan)
 
-	response, err := roundTripper.RoundTrip(req)
+	var response *http.Response
+	if h, ok := hedgeSettings(ctx, endpoint); ok {
+		var hedged *routing.LBEndpoint
+		response, hedged, err = p.hedgedRoundTrip(ctx, roundTripper, req, endpoint, h)
+		if hedged != nil && hedged.Host != endpoint.Host {
+			// attributing the response or the error to the endpoint
+			// that actually returned it:
+			endpoint = hedged
+			ctx.backendHost = endpoint.Host
+			p.tracing.setTag(ctx.proxySpan, SkipperLBEndpointTag, endpoint.Host)
+		}
+	} else {
+		response, err = roundTripper.RoundTrip(req)
+	}
 
 	ctx.proxySpan.LogKV("http_roundtrip", EndEvent)
 	if err != nil {
 		p.tracing.setTag(ctx.proxySpan, ErrorTag, true)
 
+		// the endpoint is not to blame when the client cancelled the request
+		if endpoint != nil && req.Context().Err() == nil {
+			ctx.markEndpointFailed(endpoint.Host)
+		}
+
 		// Check if the request has been cancelled or timed out
 		// The roundtrip error `err` may be different:
 		// - for `Canceled` it could be either the same `context canceled` or `unexpected EOF` (net.OpError)
@@ -936,6 +1126,14 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 
 		ctx.proxySpan.LogKV("event", "error", "message", err.Error())
 
+		if errors.Is(err, filters.ErrRequestBodyTooLarge) {
+			return nil, &proxyError{err: fmt.Errorf("request body too large for backend roundtrip to %s: %w", req.URL.Host, err), code: http.StatusRequestEntityTooLarge}
+		}
+
+		if errors.Is(err, filters.ErrInvalidRequestBody) {
+			return nil, &proxyError{err: fmt.Errorf("invalid request body for backend roundtrip to %s: %w", req.URL.Host, err), code: http.StatusBadRequest}
+		}
+
 		if perr, ok := err.(*proxyError); ok {
 			//p.lb.AddHealthcheck(ctx.route.Backend)
 			perr.err = fmt.Errorf("failed to do backend roundtrip to %s: %w", req.URL.Host, perr.err)
@@ -950,10 +1148,17 @@ func (p *Proxy) makeBackendRequest(ctx *context, requestContext stdlibcontext.Co
 				status = http.StatusServiceUnavailable
 			}
 			p.tracing.setTag(ctx.proxySpan, HTTPStatusCodeTag, uint16(status))
-			return nil, &proxyError{err: fmt.Errorf("net.Error during backend roundtrip to %s: timeout=%v temporary='%v': %w", req.URL.Host, nerr.Timeout(), nerr.Temporary(), err), code: status}
+			return nil, &proxyError{
+				err:             fmt.Errorf("net.Error during backend roundtrip to %s: timeout=%v temporary='%v': %w", req.URL.Host, nerr.Timeout(), nerr.Temporary(), err),
+				code:            status,
+				transportFailed: !nerr.Timeout(),
+			}
 		}
 
-		return nil, &proxyError{err: fmt.Errorf("unexpected error from Go stdlib net/http package during roundtrip: %w", err)}
+		return nil, &proxyError{
+			err:             fmt.Errorf("unexpected error from Go stdlib net/http package during roundtrip: %w", err),
+			transportFailed: errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF),
+		}
 	}
 	p.tracing.setTag(ctx.proxySpan, HTTPStatusCodeTag, uint16(response.StatusCode))
 	return response, nil
@@ -984,6 +1189,22 @@ func (p *Proxy) getRoundTripper(ctx *context, req *http.Request) (http.RoundTrip
 
 		return rt, nil
 	default:
+		if h2c, _ := ctx.StateBag()[filters.BackendH2C].(bool); h2c && req.URL.Scheme == "http" {
+			return p.backendTransports.get(backendTransportKey{h2c: true}, nil), nil
+		}
+
+		key := backendTransportKey{}
+		var config *tls.Config
+		if c, ok := ctx.StateBag()[filters.BackendTLS].(*filters.BackendTLSConfig); ok {
+			key.tlsKey, config = c.Key, c.Config
+		}
+
+		key.serverName, _ = ctx.StateBag()[filters.BackendSNI].(string)
+		key.pool, _ = ctx.StateBag()[filters.BackendConnectionPool].(filters.ConnectionPool)
+		if key != (backendTransportKey{}) {
+			return p.backendTransports.get(key, config), nil
+		}
+
 		return p.roundTripper, nil
 	}
 }
@@ -1004,9 +1225,9 @@ func (p *Proxy) rejectBackend(ctx *context, req *http.Request) (*http.Response,
 	return nil, false
 }
 
-func (p *Proxy) checkBreaker(c *context) (func(bool), bool) {
+func (p *Proxy) checkBreaker(c *context) (*circuit.Breaker, func(bool), bool) {
 	if p.breakers == nil {
-		return nil, true
+		return nil, nil, true
 	}
 
 	settings, _ := c.stateBag[circuitfilters.RouteSettingsKey].(circuit.BreakerSettings)
@@ -1014,7 +1235,7 @@ func (p *Proxy) checkBreaker(c *context) (func(bool), bool) {
 
 	b := p.breakers.Get(settings)
 	if b == nil {
-		return nil, true
+		return nil, nil, true
 	}
 
 	done, ok := b.Allow()
@@ -1022,7 +1243,7 @@ func (p *Proxy) checkBreaker(c *context) (func(bool), bool) {
 		// consume the body to prevent goroutine leaks
 		io.Copy(io.Discard, c.request.Body)
 	}
-	return done, ok
+	return b, done, ok
 }
 
 func newRatelimitError(settings ratelimit.Settings, retryAfter int) error {
@@ -1043,6 +1264,12 @@ func (p *Proxy) do(ctx *context) error {
 		for _, done := range pendingLIFO {
 			done()
 		}
+
+		// the response filters were not executed, e.g. due to a failed backend request:
+		if unlock, ok := ctx.StateBag()[filters.IdempotencyUnlock].(func()); ok {
+			delete(ctx.StateBag(), filters.IdempotencyUnlock)
+			unlock()
+		}
 	}()
 
 	// proxy global setting
@@ -1068,6 +1295,10 @@ func (p *Proxy) do(ctx *context) error {
 	}
 
 	ctx.applyRoute(route, params, p.flags.PreserveHost())
+	if p.routeInflight != nil {
+		// released only after the response was served, see ServeHTTP
+		ctx.routeInflightDone = append(ctx.routeInflightDone, p.routeInflight.inc(route.Id))
+	}
 
 	processedFilters := p.applyFiltersToRequest(ctx.route.Filters, ctx)
 
@@ -1084,12 +1315,16 @@ func (p *Proxy) do(ctx *context) error {
 		ctx.ensureDefaultResponse()
 	} else if ctx.route.BackendType == eskip.LoopBackend {
 		loopCTX := ctx.clone()
-		if err := p.do(loopCTX); err != nil {
+		err := p.do(loopCTX)
+		ctx.routeInflightDone = loopCTX.routeInflightDone
+		if err != nil {
 			return err
 		}
 
 		ctx.setResponse(loopCTX.response, p.flags.PreserveOriginal())
 		ctx.proxySpan = loopCTX.proxySpan
+		ctx.backendTime = loopCTX.backendTime
+		ctx.cancelRequestContext = loopCTX.cancelRequestContext
 	} else if p.flags.Debug() {
 		debugReq, _, err := mapRequest(ctx, ctx.request.Context(), p.flags.HopHeadersRemoval())
 		if err != nil {
@@ -1100,7 +1335,7 @@ func (p *Proxy) do(ctx *context) error {
 		ctx.setResponse(&http.Response{Header: make(http.Header)}, p.flags.PreserveOriginal())
 	} else {
 
-		done, allow := p.checkBreaker(ctx)
+		breaker, done, allow := p.checkBreaker(ctx)
 		if !allow {
 			tracing.LogKV("circuit_breaker", "open", ctx.request.Context())
 			return errCircuitBreakerOpen
@@ -1120,26 +1355,32 @@ func (p *Proxy) do(ctx *context) error {
 
 			p.metrics.IncErrorsBackend(ctx.route.Id)
 
-			if retryable(ctx, perr) {
+			maxRetries := p.maxIdempotentRetries(ctx)
+			for retries := 0; perr != nil; retries++ {
+				if !retryable(ctx, perr, retries, maxRetries) {
+					if retries > 0 {
+						p.log.Errorf("Failed to retry backend request: %v", perr)
+						if perr.code >= http.StatusInternalServerError {
+							p.metrics.MeasureBackend5xx(backendStart)
+						}
+					}
+
+					return perr
+				}
+
 				if ctx.proxySpan != nil {
 					ctx.proxySpan.Finish()
 					ctx.proxySpan = nil
 				}
 
-				tracing.LogKV("retry", ctx.route.Id, ctx.Request().Context())
-
-				perr = nil
-				var perr2 *proxyError
-				rsp, perr2 = p.makeBackendRequest(ctx, backendContext)
-				if perr2 != nil {
-					p.log.Errorf("Failed to retry backend request: %v", perr2)
-					if perr2.code >= http.StatusInternalServerError {
-						p.metrics.MeasureBackend5xx(backendStart)
-					}
-					return perr2
+				if err := resetRequestBody(ctx.request); err != nil {
+					p.log.Errorf("Failed to replay the request body: %v", err)
+					return perr
 				}
-			} else {
-				return perr
+
+				tracing.LogKV("retry", ctx.route.Id, ctx.Request().Context())
+				ctx.retryCount = retries + 1
+				rsp, perr = p.makeBackendRequest(ctx, backendContext)
 			}
 		}
 
@@ -1148,10 +1389,11 @@ func (p *Proxy) do(ctx *context) error {
 		}
 
 		if done != nil {
-			done(rsp.StatusCode < http.StatusInternalServerError)
+			done(!breaker.Failure(rsp.StatusCode))
 		}
 
 		ctx.setResponse(rsp, p.flags.PreserveOriginal())
+		ctx.backendTime = time.Since(backendStart)
 		p.metrics.MeasureBackend(ctx.route.Id, backendStart)
 		p.metrics.MeasureBackendHost(ctx.route.Host, backendStart)
 	}
@@ -1161,11 +1403,57 @@ func (p *Proxy) do(ctx *context) error {
 	return nil
 }
 
-func retryable(ctx *context, perr *proxyError) bool {
+// retryable decides whether a failed backend request can be retried.
+// Dial errors are retried once for every method. When the max retries of
+// the idempotent requests is set, the idempotent requests are retried on
+// the transport errors, too, up to the max retries. The requests with a
+// body are retried only when the body was buffered, and can be replayed.
+func retryable(ctx *context, perr *proxyError, retries, maxRetries int) bool {
 	req := ctx.Request()
-	return perr.code != 499 && perr.DialError() &&
-		ctx.route.BackendType == eskip.LBBackend &&
-		req != nil && (req.Body == nil || req.Body == http.NoBody)
+	if req == nil || perr.code == 499 || req.Context().Err() != nil ||
+		ctx.route.BackendType != eskip.LBBackend ||
+		(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return false
+	}
+
+	if retries < maxRetries && isIdempotent(req.Method) && perr.TransportError() {
+		return true
+	}
+
+	return retries == 0 && perr.DialError()
+}
+
+// resetRequestBody replaces the consumed body of a request with a new
+// copy, when the body was buffered, e.g. by the bufferRequestBody filter.
+func resetRequestBody(r *http.Request) error {
+	if r.GetBody == nil {
+		return nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+
+	r.Body = body
+	return nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Proxy) maxIdempotentRetries(ctx *context) int {
+	if retries, ok := ctx.StateBag()[filters.IdempotentRetries].(int); ok {
+		return retries
+	}
+
+	return p.idempotentRetries
 }
 
 func (p *Proxy) serveResponse(ctx *context) {
@@ -1183,9 +1471,14 @@ func (p *Proxy) serveResponse(ctx *context) {
 
 	start := time.Now()
 	p.tracing.logStreamEvent(ctx.proxySpan, StreamHeadersEvent, StartEvent)
+	setTimingHeaders(ctx)
 	copyHeader(ctx.responseWriter.Header(), ctx.response.Header)
 
-	if err := ctx.Request().Context().Err(); err != nil {
+	if err := ctx.Request().Context().Err(); err == stdlibcontext.DeadlineExceeded && ctx.cancelRequestContext != nil {
+		// the deadline set by the requestTimeout filter was exceeded
+		p.log.Infof("Request timeout: %v", err)
+		ctx.response.StatusCode = http.StatusGatewayTimeout
+	} else if err != nil {
 		// deadline exceeded or canceled in stdlib, client closed request
 		// see https://github.com/zalando/skipper/pull/864
 		p.log.Infof("Client request: %v", err)
@@ -1199,7 +1492,8 @@ func (p *Proxy) serveResponse(ctx *context) {
 	ctx.responseWriter.Flush()
 	p.tracing.logStreamEvent(ctx.proxySpan, StreamHeadersEvent, EndEvent)
 
-	n, err := copyStream(ctx.responseWriter, ctx.response.Body)
+	flushInterval, _ := ctx.StateBag()[filters.ResponseFlushInterval].(time.Duration)
+	n, err := copyStream(ctx.responseWriter, ctx.response.Body, flushInterval)
 	p.tracing.logStreamEvent(ctx.proxySpan, StreamBodyEvent, strconv.FormatInt(n, 10))
 	if err != nil {
 		p.metrics.IncErrorsStreaming(ctx.route.Id)
@@ -1208,6 +1502,12 @@ func (p *Proxy) serveResponse(ctx *context) {
 		p.tracing.setTag(ctx.proxySpan, StreamBodyEvent, StreamBodyError)
 		p.tracing.logStreamEvent(ctx.proxySpan, StreamBodyEvent, fmt.Sprintf("Failed to stream response: %v", err))
 	} else {
+		// the trailers of the backend response are available after the
+		// body was read
+		for k, v := range ctx.response.Trailer {
+			ctx.responseWriter.Header()[http.TrailerPrefix+k] = v
+		}
+
 		p.metrics.MeasureResponse(ctx.response.StatusCode, ctx.request.Method, ctx.route.Id, start)
 	}
 	p.metrics.MeasureServe(ctx.route.Id, ctx.metricsHost(), ctx.request.Method, ctx.response.StatusCode, ctx.startServe)
@@ -1310,9 +1610,14 @@ func stripPort(address string) string {
 	return address
 }
 
-// The remote address of the client. When the 'X-Forwarded-For'
-// header is set, then it is used instead.
+// The remote address of the client. When the client IP was resolved
+// from the trusted proxies, or the 'X-Forwarded-For' header is set, then
+// it is used instead.
 func remoteAddr(r *http.Request) string {
+	if ip := snet.ClientIP(r); ip != nil {
+		return ip.String()
+	}
+
 	ff := r.Header.Get("X-Forwarded-For")
 	if ff != "" {
 		return ff
@@ -1379,13 +1684,19 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		statusCode := lw.GetCode()
 
-		if shouldLog(statusCode, accessLogEnabled) {
+		if shouldLog(statusCode, accessLogEnabled) && !al.SampledOut(statusCode, ctx.stateBag) {
 			entry := &logging.AccessEntry{
-				Request:      r,
+				Request:      al.Redact(r, ctx.stateBag),
 				ResponseSize: lw.GetBytes(),
 				StatusCode:   statusCode,
 				RequestTime:  ctx.startServe,
 				Duration:     time.Since(ctx.startServe),
+				BackendHost:  ctx.backendHost,
+				ClientIP:     snet.ClientIP(r),
+			}
+
+			if ctx.route != nil {
+				entry.RouteID = ctx.route.Id
 			}
 
 			additionalData, _ := ctx.stateBag[al.AccessLogAdditionalDataKey].(map[string]interface{})
@@ -1399,6 +1710,10 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if p.flags.normalizePath() {
+		rfc.NormalizeURLPath(r.URL)
+	}
+
 	if p.flags.patchPath() {
 		r.URL.Path = rfc.PatchPath(r.URL.Path, r.URL.RawPath)
 	}
@@ -1414,6 +1729,10 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx.tracer = p.tracing.tracer
 	ctx.initialSpan = span
 
+	// the routes count the request as inflight until the response was
+	// served and the body closed
+	defer ctx.releaseRouteInflight()
+
 	defer func() {
 		if ctx.response != nil && ctx.response.Body != nil {
 			err := ctx.response.Body.Close()
@@ -1434,6 +1753,10 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if ctx.cancelBackendContext != nil {
 		ctx.cancelBackendContext()
 	}
+
+	if ctx.cancelRequestContext != nil {
+		ctx.cancelRequestContext()
+	}
 }
 
 // Close causes the proxy to stop closing idle
@@ -1976,6 +1976,66 @@ func TestEnableAccessLogWithFilter(t *testing.T) {
 	}
 }
 
+func TestAccessLogSampling(t *testing.T) {
+	for _, ti := range []struct {
+		msg          string
+		filter       string
+		responseCode int
+		shouldLog    bool
+	}{
+		{
+			msg:          "sampled-in",
+			filter:       "accessLogSampling(1)",
+			responseCode: 200,
+			shouldLog:    true,
+		},
+		{
+			msg:          "sampled-out",
+			filter:       "accessLogSampling(0)",
+			responseCode: 200,
+			shouldLog:    false,
+		},
+		{
+			msg:          "error-never-sampled-out",
+			filter:       "accessLogSampling(0)",
+			responseCode: 500,
+			shouldLog:    true,
+		},
+	} {
+		t.Run(ti.msg, func(t *testing.T) {
+			var buf bytes.Buffer
+			logging.Init(logging.Options{
+				AccessLogOutput: &buf})
+
+			response := "7 bytes"
+
+			u, _ := url.ParseRequestURI("https://www.example.org/hello")
+			r := &http.Request{
+				URL:    u,
+				Method: "GET",
+				Header: http.Header{"Connection": []string{"token"}}}
+			w := httptest.NewRecorder()
+
+			doc := fmt.Sprintf(`hello: Path("/hello") -> %s -> status(%d) -> inlineContent("%s") -> <shunt>`, ti.filter, ti.responseCode, response)
+
+			tp, err := newTestProxy(doc, FlagsNone)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			defer tp.close()
+
+			tp.proxy.ServeHTTP(w, r)
+
+			output := buf.String()
+			if ti.shouldLog != strings.Contains(output, fmt.Sprintf(`"%s - -" %d %d "-" "-"`, r.Method, ti.responseCode, len(response))) {
+				t.Error("unexpected access log", output)
+			}
+		})
+	}
+}
+
 func TestAccessLogOnFailedRequest(t *testing.T) {
 	var buf bytes.Buffer
 	logging.Init(logging.Options{
@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer backend.Close()
+
+	for _, tt := range []struct {
+		title  string
+		routes string
+		status int
+	}{{
+		title:  "backend faster than the timeout",
+		routes: `* -> requestTimeout("5s") -> status(204) -> <shunt>`,
+		status: http.StatusNoContent,
+	}, {
+		title:  "backend slower than the timeout",
+		routes: `* -> requestTimeout("30ms") -> "` + backend.URL + `"`,
+		status: http.StatusGatewayTimeout,
+	}, {
+		title:  "shorter backend timeout",
+		routes: `* -> requestTimeout("5s") -> backendTimeout("30ms") -> "` + backend.URL + `"`,
+		status: http.StatusGatewayTimeout,
+	}, {
+		title: "loopback",
+		routes: `
+			main: * -> requestTimeout("30ms") -> setPath("/loop") -> <loopback>;
+			loop: Path("/loop") -> requestTimeout("5s") -> "` + backend.URL + `"`,
+		status: http.StatusGatewayTimeout,
+	}} {
+		t.Run(tt.title, func(t *testing.T) {
+			tp, err := newTestProxy(tt.routes, FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			start := time.Now()
+			w := httptest.NewRecorder()
+			tp.proxy.ServeHTTP(w, httptest.NewRequest("GET", "http://example.org/", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+
+			if d := time.Since(start); d > 500*time.Millisecond {
+				t.Errorf("request took too long: %v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newResettingBackend returns a backend that closes the connections
+// after reading the request, without sending a response.
+func newResettingBackend(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		conn.Close()
+	}))
+}
+
+func TestIdempotentRetries(t *testing.T) {
+	failing := newResettingBackend(t)
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer healthy.Close()
+
+	for _, ti := range []struct {
+		msg            string
+		filters        string
+		retries        int
+		method         string
+		expectFailures bool
+	}{{
+		msg:            "no retries",
+		method:         "GET",
+		expectFailures: true,
+	}, {
+		msg:     "retries idempotent method",
+		retries: 1,
+		method:  "GET",
+	}, {
+		msg:            "does not retry non-idempotent method",
+		retries:        1,
+		method:         "POST",
+		expectFailures: true,
+	}, {
+		msg:     "retries enabled by filter",
+		filters: "idempotentRetries(1) ->",
+		method:  "DELETE",
+	}, {
+		msg:            "retries disabled by filter",
+		filters:        "idempotentRetries(0) ->",
+		retries:        1,
+		method:         "GET",
+		expectFailures: true,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			doc := fmt.Sprintf(`* -> %s <roundRobin, "%s", "%s">`, ti.filters, failing.URL, healthy.URL)
+			tp, err := newTestProxyWithParams(doc, Params{IdempotentRetries: ti.retries})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			var failures int
+			for i := 0; i < 10; i++ {
+				req, err := http.NewRequest(ti.method, ps.URL, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp.Body.Close()
+				if rsp.StatusCode != http.StatusOK {
+					failures++
+				}
+			}
+
+			if ti.expectFailures && failures == 0 {
+				t.Error("expected failures")
+			} else if !ti.expectFailures && failures > 0 {
+				t.Errorf("unexpected failures: %d", failures)
+			}
+		})
+	}
+}
+
+func TestRetryableIdempotentMethods(t *testing.T) {
+	for _, m := range []string{"GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE"} {
+		if !isIdempotent(m) {
+			t.Errorf("expected idempotent method: %s", m)
+		}
+	}
+
+	for _, m := range []string{"POST", "PATCH", "CONNECT"} {
+		if isIdempotent(m) {
+			t.Errorf("unexpected idempotent method: %s", m)
+		}
+	}
+}
+
+func TestRetryBufferedRequestBody(t *testing.T) {
+	failing := newResettingBackend(t)
+	defer failing.Close()
+
+	const content = "Hello, world!"
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if string(b) != content || r.ContentLength != int64(len(content)) {
+			t.Errorf("unexpected body, expected: %s, got: %s, content length: %d", content, b, r.ContentLength)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer healthy.Close()
+
+	for _, ti := range []struct {
+		msg            string
+		filters        string
+		expectFailures bool
+	}{{
+		msg:            "does not retry requests with a body",
+		expectFailures: true,
+	}, {
+		msg:     "retries requests with a buffered body",
+		filters: `bufferRequestBody("1k") ->`,
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			doc := fmt.Sprintf(`* -> %s <roundRobin, "%s", "%s">`, ti.filters, failing.URL, healthy.URL)
+			tp, err := newTestProxyWithParams(doc, Params{IdempotentRetries: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer tp.close()
+
+			ps := httptest.NewServer(tp.proxy)
+			defer ps.Close()
+
+			var failures int
+			for i := 0; i < 10; i++ {
+				req, err := http.NewRequest("PUT", ps.URL, strings.NewReader(content))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rsp.Body.Close()
+				if rsp.StatusCode != http.StatusOK {
+					failures++
+				}
+			}
+
+			if ti.expectFailures && failures == 0 {
+				t.Error("expected failures")
+			} else if !ti.expectFailures && failures > 0 {
+				t.Errorf("unexpected failures: %d", failures)
+			}
+		})
+	}
+}
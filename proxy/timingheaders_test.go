@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTimingHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer backend.Close()
+
+	for _, tt := range []struct {
+		title       string
+		routes      string
+		backendTime bool
+		totalTime   bool
+	}{{
+		title:  "disabled",
+		routes: `* -> "` + backend.URL + `"`,
+	}, {
+		title:       "enabled",
+		routes:      `* -> timingHeaders() -> "` + backend.URL + `"`,
+		backendTime: true,
+		totalTime:   true,
+	}, {
+		title:     "shunt",
+		routes:    `* -> timingHeaders() -> status(204) -> <shunt>`,
+		totalTime: true,
+	}, {
+		title: "loopback",
+		routes: `
+			main: * -> timingHeaders() -> setPath("/loop") -> <loopback>;
+			loop: Path("/loop") -> "` + backend.URL + `"`,
+		backendTime: true,
+		totalTime:   true,
+	}} {
+		t.Run(tt.title, func(t *testing.T) {
+			tp, err := newTestProxy(tt.routes, FlagsNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tp.close()
+
+			w := httptest.NewRecorder()
+			tp.proxy.ServeHTTP(w, httptest.NewRequest("GET", "http://example.org/", nil))
+
+			for _, h := range []struct {
+				name   string
+				expect bool
+			}{{backendTimeHeader, tt.backendTime}, {totalTimeHeader, tt.totalTime}} {
+				v := w.Header().Get(h.name)
+				if !h.expect {
+					if v != "" {
+						t.Errorf("unexpected header %s: %s", h.name, v)
+					}
+
+					continue
+				}
+
+				if ms, err := strconv.ParseFloat(v, 64); err != nil || ms <= 0 {
+					t.Errorf("invalid header %s: %s", h.name, v)
+				}
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ const (
 	HTTPPathTag           = "http.path"
 	HTTPUrlTag            = "http.url"
 	HTTPStatusCodeTag     = "http.status_code"
+	SkipperLBEndpointTag  = "skipper.lb_endpoint"
+	SkipperRetryCountTag  = "skipper.retry_count"
 	SkipperRouteIDTag     = "skipper.route_id"
 	SpanKindTag           = "span.kind"
 
@@ -280,7 +280,9 @@ func TestTracingProxySpan(t *testing.T) {
 	verifyTag(t, span, HTTPHostTag, backendAddr)
 	verifyTag(t, span, FlowIDTag, "test-flow-id")
 	verifyTag(t, span, HTTPStatusCodeTag, uint16(204))
+	verifyTag(t, span, SkipperRetryCountTag, 0)
 	verifyNoTag(t, span, HTTPRemoteIPTag)
+	verifyNoTag(t, span, SkipperLBEndpointTag)
 }
 
 func TestTracingProxySpanWithRetry(t *testing.T) {
@@ -332,6 +334,15 @@ func TestTracingProxySpanWithRetry(t *testing.T) {
 			return false
 		}
 
+		if proxySpans[0].Tags[SkipperRetryCountTag] != 0 || proxySpans[1].Tags[SkipperRetryCountTag] != 1 {
+			t.Errorf("invalid retry count tags: %v, %v", proxySpans[0].Tags[SkipperRetryCountTag], proxySpans[1].Tags[SkipperRetryCountTag])
+		}
+
+		failed, served := proxySpans[0].Tags[SkipperLBEndpointTag], proxySpans[1].Tags[SkipperLBEndpointTag]
+		if failed != s0.Listener.Addr().String() || served != s1.Listener.Addr().String() {
+			t.Errorf("invalid lb endpoint tags: %v, %v", failed, served)
+		}
+
 		for _, s := range proxySpans {
 			if s.FinishTime.Sub(s.StartTime) >= responseStreamDelay {
 				return true
@@ -0,0 +1,274 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/zalando/skipper/net"
+)
+
+// leakyBucket implements a leaky bucket limiter, where each bucket can hold
+// a maximum number of requests, and it drains with a constant rate. The
+// level of a bucket is stored as the time when it gets empty, which is the
+// same as the theoretical arrival time of the generic cell rate algorithm.
+type leakyBucket struct {
+	mu       sync.Mutex
+	capacity time.Duration
+	emission time.Duration
+	emptyAt  map[string]time.Time
+	quit     chan struct{}
+	once     sync.Once
+}
+
+// newLeakyBucket creates a leaky bucket limiter from the settings, where
+// MaxHits is the capacity of the buckets and TimeWindow is the time it
+// takes a full bucket to drain. The empty buckets are removed
+// periodically, with the clean interval.
+func newLeakyBucket(s Settings) *leakyBucket {
+	l := &leakyBucket{
+		capacity: s.TimeWindow,
+		emission: leakyBucketEmission(s),
+		emptyAt:  make(map[string]time.Time),
+		quit:     make(chan struct{}),
+	}
+
+	cleanInterval := s.CleanInterval
+	if cleanInterval <= 0 {
+		cleanInterval = DefaultCleanInterval
+	}
+
+	go l.clean(cleanInterval)
+	return l
+}
+
+// leakyBucketEmission returns the time it takes one request to leak from
+// the bucket.
+func leakyBucketEmission(s Settings) time.Duration {
+	e := s.TimeWindow / time.Duration(s.MaxHits)
+	if e <= 0 {
+		e = 1
+	}
+
+	return e
+}
+
+func (l *leakyBucket) clean(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case now := <-t.C:
+			l.mu.Lock()
+			for key, emptyAt := range l.emptyAt {
+				if emptyAt.Before(now) {
+					delete(l.emptyAt, key)
+				}
+			}
+
+			l.mu.Unlock()
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// deltaFrom returns the duration until the next request fits in the bucket,
+// and the time when the bucket gets empty after adding the next request.
+// It expects the lock to be held.
+func (l *leakyBucket) deltaFrom(key string, now time.Time) (time.Duration, time.Time) {
+	emptyAt := l.emptyAt[key]
+	if emptyAt.Before(now) {
+		emptyAt = now
+	}
+
+	next := emptyAt.Add(l.emission)
+	return next.Sub(now) - l.capacity, next
+}
+
+// Allow adds the request to the bucket of the key if it has room for it.
+func (l *leakyBucket) Allow(key string) bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delta, next := l.deltaFrom(key, now)
+	if delta > 0 {
+		return false
+	}
+
+	l.emptyAt[key] = next
+	return true
+}
+
+// Close stops the cleanup of the idle buckets.
+func (l *leakyBucket) Close() {
+	l.once.Do(func() { close(l.quit) })
+}
+
+// Delta returns the duration until the bucket of the key has room for the
+// next request, negative values mean that it has room already.
+func (l *leakyBucket) Delta(key string) time.Duration {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delta, _ := l.deltaFrom(key, now)
+	return delta
+}
+
+// Oldest is not tracked by the leaky bucket, it returns the zero time.
+func (*leakyBucket) Oldest(string) time.Time { return time.Time{} }
+
+// Resize is noop to implement the limiter interface
+func (*leakyBucket) Resize(string, int) {}
+
+// RetryAfter returns the number of seconds, rounded up, until the bucket
+// of the key has room for the next request.
+func (l *leakyBucket) RetryAfter(key string) int {
+	return retryAfterSeconds(l.Delta(key))
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// the leaky buckets are stored with a different prefix than the sliding
+// windows of the cluster ratelimits, because the types of the redis values
+// differ
+const leakyBucketKeyFormat = "leakybucket.%s.%s"
+
+// clusterLeakyBucketScript implements the leaky bucket in redis, storing
+// the time when the bucket gets empty in microseconds. It returns the
+// duration in microseconds until the next request fits in the bucket,
+// where non-positive values mean that the request was allowed. When
+// ARGV[4] is 0, it only returns the duration, without adding the request.
+const clusterLeakyBucketScript = `
+local now = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3])
+local add = ARGV[4] == "1"
+
+local emptyAt = now
+local stored = redis.call("GET", KEYS[1])
+if stored then
+	emptyAt = math.max(tonumber(stored), now)
+end
+
+local next = emptyAt + emission
+local delta = next - now - capacity
+if delta > 0 or not add then
+	return delta
+end
+
+-- formatting explicitly, because the default number format of Lua
+-- would lose precision
+redis.call("SET", KEYS[1], string.format("%d", next), "PX", string.format("%d", math.ceil((next - now) / 1000)))
+return delta
+`
+
+// clusterLeakyBucket shares the leaky buckets between the skipper
+// instances by storing them in redis.
+type clusterLeakyBucket struct {
+	group      string
+	capacity   time.Duration
+	emission   time.Duration
+	ringClient *net.RedisRingClient
+	script     *net.RedisScript
+}
+
+// newClusterLeakyBucket creates a leaky bucket limiter shared across the
+// cluster. It requires redis, and it falls back to the instance local
+// leaky bucket when redis is not configured.
+func newClusterLeakyBucket(s Settings, ring *net.RedisRingClient) limiter {
+	if !ring.RingConfigured() {
+		log.Warningf("Redis is not configured, using instance local leaky bucket for group: %s", s.Group)
+		return newLeakyBucket(s)
+	}
+
+	return &clusterLeakyBucket{
+		group:      s.Group,
+		capacity:   s.TimeWindow,
+		emission:   leakyBucketEmission(s),
+		ringClient: ring,
+		script:     ring.NewScript(clusterLeakyBucketScript),
+	}
+}
+
+func (c *clusterLeakyBucket) run(ctx context.Context, key string, add bool) (time.Duration, error) {
+	addArg := 0
+	if add {
+		addArg = 1
+	}
+
+	res, err := c.ringClient.RunScript(
+		ctx,
+		c.script,
+		[]string{fmt.Sprintf(leakyBucketKeyFormat, c.group, getHashedKey(key))},
+		time.Now().UnixNano()/int64(time.Microsecond),
+		c.emission.Microseconds(),
+		c.capacity.Microseconds(),
+		addArg,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	delta, ok := res.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected leaky bucket script result: %v", res)
+	}
+
+	return time.Duration(delta) * time.Microsecond, nil
+}
+
+// AllowContext adds the request to the shared bucket of the key if it has
+// room for it. In case of redis failures, it allows the request.
+func (c *clusterLeakyBucket) AllowContext(ctx context.Context, key string) bool {
+	delta, err := c.run(ctx, key, true)
+	if err != nil {
+		log.Errorf("Failed to check the leaky bucket in redis: %v", err)
+		return true
+	}
+
+	return delta <= 0
+}
+
+// Allow is like AllowContext, but not using a context.
+func (c *clusterLeakyBucket) Allow(key string) bool {
+	return c.AllowContext(context.Background(), key)
+}
+
+// Close can not decide to teardown redis ring, because it is not the
+// owner of it.
+func (*clusterLeakyBucket) Close() {}
+
+// Delta returns the duration until the shared bucket of the key has room
+// for the next request, negative values mean that it has room already.
+func (c *clusterLeakyBucket) Delta(key string) time.Duration {
+	delta, err := c.run(context.Background(), key, false)
+	if err != nil {
+		log.Errorf("Failed to get the leaky bucket delta from redis: %v", err)
+		return 0
+	}
+
+	return delta
+}
+
+// Oldest is not tracked by the leaky bucket, it returns the zero time.
+func (*clusterLeakyBucket) Oldest(string) time.Time { return time.Time{} }
+
+// Resize is noop to implement the limiter interface
+func (*clusterLeakyBucket) Resize(string, int) {}
+
+// RetryAfter returns the number of seconds, rounded up, until the shared
+// bucket of the key has room for the next request.
+func (c *clusterLeakyBucket) RetryAfter(key string) int {
+	return retryAfterSeconds(c.Delta(key))
+}
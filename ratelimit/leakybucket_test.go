@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zalando/skipper/net"
+	"github.com/zalando/skipper/net/redistest"
+)
+
+func testLeakyBucket(t *testing.T, l limiter) {
+	// capacity 3, leaking one request per 100ms
+	for i := 0; i < 3; i++ {
+		if !l.Allow("foo") {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+
+	if l.Allow("foo") {
+		t.Fatal("request should be limited when the bucket is full")
+	}
+
+	if d := l.Delta("foo"); d <= 0 || d > 100*time.Millisecond {
+		t.Errorf("unexpected delta: %v", d)
+	}
+
+	if r := l.RetryAfter("foo"); r != 1 {
+		t.Errorf("unexpected retry after: %d", r)
+	}
+
+	if !l.Allow("bar") {
+		t.Error("request with a different key should be allowed")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if !l.Allow("foo") {
+		t.Error("request should be allowed after leaking")
+	}
+
+	if l.Allow("foo") {
+		t.Error("request should be limited after filling the bucket again")
+	}
+}
+
+func TestLeakyBucket(t *testing.T) {
+	l := newLeakyBucket(Settings{
+		Type:       LeakyBucketRatelimit,
+		MaxHits:    3,
+		TimeWindow: 300 * time.Millisecond,
+	})
+	defer l.Close()
+
+	testLeakyBucket(t, l)
+}
+
+func TestLeakyBucketClean(t *testing.T) {
+	l := newLeakyBucket(Settings{
+		Type:          LeakyBucketRatelimit,
+		MaxHits:       3,
+		TimeWindow:    30 * time.Millisecond,
+		CleanInterval: 50 * time.Millisecond,
+	})
+	defer l.Close()
+
+	l.Allow("foo")
+	time.Sleep(200 * time.Millisecond)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.emptyAt) != 0 {
+		t.Errorf("failed to clean the empty buckets: %v", l.emptyAt)
+	}
+}
+
+func TestClusterLeakyBucket(t *testing.T) {
+	redisAddr, done := redistest.NewTestRedis(t)
+	defer done()
+
+	ringClient := net.NewRedisRingClient(&net.RedisOptions{Addrs: []string{redisAddr}})
+	defer ringClient.Close()
+
+	s := Settings{
+		Type:       ClusterLeakyBucketRatelimit,
+		MaxHits:    3,
+		TimeWindow: 300 * time.Millisecond,
+		Group:      "leaky",
+	}
+
+	l := newClusterLeakyBucket(s, ringClient)
+	if _, ok := l.(*clusterLeakyBucket); !ok {
+		t.Fatalf("unexpected limiter: %T", l)
+	}
+
+	testLeakyBucket(t, l)
+
+	// another instance shares the bucket
+	other := newClusterLeakyBucket(s, ringClient)
+	if other.Allow("foo") {
+		t.Error("request should be limited by the shared bucket")
+	}
+}
+
+func TestClusterLeakyBucketSharedGroup(t *testing.T) {
+	redisAddr, done := redistest.NewTestRedis(t)
+	defer done()
+
+	ringClient := net.NewRedisRingClient(&net.RedisOptions{Addrs: []string{redisAddr}})
+	defer ringClient.Close()
+
+	s := Settings{
+		Type:       ClusterServiceRatelimit,
+		MaxHits:    3,
+		TimeWindow: time.Minute,
+		Group:      "shared",
+	}
+
+	rl := newClusterRateLimiterRedis(s, ringClient, s.Group)
+	if !rl.Allow("foo") {
+		t.Fatal("request should be allowed by the cluster ratelimit")
+	}
+
+	s.Type = ClusterLeakyBucketRatelimit
+	l := newClusterLeakyBucket(s, ringClient).(*clusterLeakyBucket)
+	if _, err := l.run(context.Background(), "foo", true); err != nil {
+		t.Errorf("leaky bucket should not share the key with the cluster ratelimit: %v", err)
+	}
+}
+
+func TestClusterLeakyBucketWithoutRedis(t *testing.T) {
+	l := newClusterLeakyBucket(Settings{
+		Type:       ClusterLeakyBucketRatelimit,
+		MaxHits:    3,
+		TimeWindow: 300 * time.Millisecond,
+		Group:      "leaky",
+	}, net.NewRedisRingClient(nil))
+	defer l.Close()
+
+	if _, ok := l.(*leakyBucket); !ok {
+		t.Fatalf("unexpected limiter: %T", l)
+	}
+}
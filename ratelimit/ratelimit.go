@@ -25,6 +25,11 @@ const (
 	// long a client should wait before making a new request
 	RetryAfterHeader = "Retry-After"
 
+	// ResetMillisHeader is the name of the optional header which indicates
+	// in milliseconds how long a client should wait before making a new
+	// request
+	ResetMillisHeader = "X-Rate-Limit-Reset-Ms"
+
 	// Deprecated, use filters.RatelimitName instead
 	ServiceRatelimitName = filters.RatelimitName
 
@@ -126,6 +131,18 @@ const (
 
 	// DisableRatelimit is used to disable rate limit
 	DisableRatelimit
+
+	// LeakyBucketRatelimit is used to have a leaky bucket rate
+	// limit per user for a backend, which is calculated within
+	// each instance. MaxHits is the capacity of the bucket, and
+	// TimeWindow is the time it takes a full bucket to drain,
+	// so the leak rate is MaxHits / TimeWindow.
+	LeakyBucketRatelimit
+
+	// ClusterLeakyBucketRatelimit is like LeakyBucketRatelimit,
+	// but the buckets are shared by the whole skipper fleet,
+	// needs redis to be configured with -swarm-redis-urls.
+	ClusterLeakyBucketRatelimit
 )
 
 func (rt RatelimitType) String() string {
@@ -142,6 +159,10 @@ func (rt RatelimitType) String() string {
 		return LocalRatelimitName
 	case ServiceRatelimit:
 		return filters.RatelimitName
+	case LeakyBucketRatelimit:
+		return filters.LeakyBucketRatelimitName
+	case ClusterLeakyBucketRatelimit:
+		return filters.ClusterLeakyBucketRatelimitName
 	default:
 		return filters.UnknownRatelimitName
 
@@ -217,6 +238,81 @@ func (h HeaderLookuper) String() string {
 	return "HeaderLookuper"
 }
 
+// QueryLookuper implements Lookuper interface and will select a bucket
+// by a query parameter.
+type QueryLookuper struct {
+	key string
+}
+
+// NewQueryLookuper returns QueryLookuper configured to lookup the query
+// parameter named k
+func NewQueryLookuper(k string) QueryLookuper {
+	return QueryLookuper{key: k}
+}
+
+// Lookup returns the first value of the query parameter.
+func (q QueryLookuper) Lookup(req *http.Request) string {
+	return req.URL.Query().Get(q.key)
+}
+
+func (q QueryLookuper) String() string {
+	return "QueryLookuper"
+}
+
+// PathLookuper implements Lookuper interface and will select a bucket
+// by the request path.
+type PathLookuper struct{}
+
+// NewPathLookuper returns a PathLookuper.
+func NewPathLookuper() PathLookuper {
+	return PathLookuper{}
+}
+
+// Lookup returns the path of the request.
+func (PathLookuper) Lookup(req *http.Request) string {
+	return req.URL.Path
+}
+
+func (PathLookuper) String() string {
+	return "PathLookuper"
+}
+
+// MethodLookuper implements Lookuper interface and will select a bucket
+// by the request method.
+type MethodLookuper struct{}
+
+// NewMethodLookuper returns a MethodLookuper.
+func NewMethodLookuper() MethodLookuper {
+	return MethodLookuper{}
+}
+
+// Lookup returns the method of the request.
+func (MethodLookuper) Lookup(req *http.Request) string {
+	return req.Method
+}
+
+func (MethodLookuper) String() string {
+	return "MethodLookuper"
+}
+
+// HostLookuper implements Lookuper interface and will select a bucket
+// by the request host.
+type HostLookuper struct{}
+
+// NewHostLookuper returns a HostLookuper.
+func NewHostLookuper() HostLookuper {
+	return HostLookuper{}
+}
+
+// Lookup returns the host of the request.
+func (HostLookuper) Lookup(req *http.Request) string {
+	return req.Host
+}
+
+func (HostLookuper) String() string {
+	return "HostLookuper"
+}
+
 // Lookupers is a slice of Lookuper, required to get a hashable member
 // in the TupleLookuper.
 type Lookupers []Lookuper
@@ -253,6 +349,55 @@ func (t TupleLookuper) String() string {
 	return "TupleLookuper"
 }
 
+// CompositeLookuper implements Lookuper interface and will select a
+// bucket that is defined by all combined Lookupers. Unlike the
+// TupleLookuper, the parts of the key are kept distinguishable.
+type CompositeLookuper struct {
+	// pointer is required to be hashable from Registry lookup table
+	l *Lookupers
+}
+
+// NewCompositeLookuper returns CompositeLookuper configured to lookup
+// the combined result of all given Lookuper
+func NewCompositeLookuper(args ...Lookuper) CompositeLookuper {
+	var ls Lookupers = args
+	return CompositeLookuper{l: &ls}
+}
+
+// Lookup returns the combined string of all Lookupers part of the
+// composite key. Every part is prefixed with its length, such that
+// different combinations cannot result in the same key, e.g. "acme/api"
+// and "/x" versus "acme" and "/api/x". When none of the Lookupers finds
+// data in the request, it returns an empty string.
+func (c CompositeLookuper) Lookup(req *http.Request) string {
+	if c.l == nil {
+		return ""
+	}
+
+	var (
+		buf   bytes.Buffer
+		found bool
+	)
+
+	for _, l := range *(c.l) {
+		v := l.Lookup(req)
+		found = found || v != ""
+		buf.WriteString(strconv.Itoa(len(v)))
+		buf.WriteByte(':')
+		buf.WriteString(v)
+	}
+
+	if !found {
+		return ""
+	}
+
+	return buf.String()
+}
+
+func (c CompositeLookuper) String() string {
+	return "CompositeLookuper"
+}
+
 // RoundRobinLookuper matches one of n buckets selected by round robin algorithm
 type RoundRobinLookuper struct {
 	// pointer is required to be hashable from Registry lookup table
@@ -322,6 +467,10 @@ func (s Settings) String() string {
 		return fmt.Sprintf("ratelimit(type=clusterService,max-hits=%d,time-window=%s,group=%s)", s.MaxHits, s.TimeWindow, s.Group)
 	case ClusterClientRatelimit:
 		return fmt.Sprintf("ratelimit(type=clusterClient,max-hits=%d,time-window=%s,group=%s)", s.MaxHits, s.TimeWindow, s.Group)
+	case LeakyBucketRatelimit:
+		return fmt.Sprintf("ratelimit(type=leakyBucket,max-hits=%d,time-window=%s)", s.MaxHits, s.TimeWindow)
+	case ClusterLeakyBucketRatelimit:
+		return fmt.Sprintf("ratelimit(type=clusterLeakyBucket,max-hits=%d,time-window=%s,group=%s)", s.MaxHits, s.TimeWindow, s.Group)
 	default:
 		return "non"
 	}
@@ -413,6 +562,28 @@ func (l *Ratelimit) Delta(s string) time.Duration {
 	return l.impl.Delta(s)
 }
 
+// ResetDelta returns the duration until the next request of s is allowed,
+// with sub-second precision. The Delta of the circular buffer based limiters
+// is the span of the tracked requests, so for them it is calculated from the
+// oldest tracked request, the same way as RetryAfter.
+func (l *Ratelimit) ResetDelta(s string) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	switch l.impl.(type) {
+	case *circularbuffer.CircularBuffer, *circularbuffer.ClientRateLimiter, *clusterLimitSwim:
+		oldest := l.impl.Oldest(s)
+		if oldest.IsZero() {
+			return 0
+		}
+
+		return time.Until(oldest.Add(l.settings.TimeWindow))
+	default:
+		return l.impl.Delta(s)
+	}
+}
+
 func (l *Ratelimit) Resize(s string, i int) {
 	l.impl.Resize(s, i)
 }
@@ -462,6 +633,10 @@ func newRatelimit(s Settings, sw Swarmer, redisRing *net.RedisRingClient) *Ratel
 			fallthrough
 		case ClusterClientRatelimit:
 			impl = newClusterRateLimiter(s, sw, redisRing, s.Group)
+		case LeakyBucketRatelimit:
+			impl = newLeakyBucket(s)
+		case ClusterLeakyBucketRatelimit:
+			impl = newClusterLeakyBucket(s, redisRing)
 		default:
 			impl = voidRatelimit{}
 		}
@@ -213,6 +213,54 @@ func TestTupleLookuper(t *testing.T) {
 	})
 }
 
+func TestCompositeLookuper(t *testing.T) {
+	t.Run("parts are not ambiguous", func(t *testing.T) {
+		compositeLookuper := NewCompositeLookuper(NewHeaderLookuper("X-Tenant"), NewPathLookuper())
+
+		r1, _ := http.NewRequest("GET", "/x", nil)
+		r1.Header.Set("X-Tenant", "acme/api")
+		r2, _ := http.NewRequest("GET", "/api/x", nil)
+		r2.Header.Set("X-Tenant", "acme")
+		if compositeLookuper.Lookup(r1) == compositeLookuper.Lookup(r2) {
+			t.Errorf("Different parts result in the same key: %s", compositeLookuper.Lookup(r1))
+		}
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/foo", nil)
+		compositeLookuper := NewCompositeLookuper(NewHeaderLookuper("X-Missing"), NewQueryLookuper("missing"))
+		if k := compositeLookuper.Lookup(req); k != "" {
+			t.Errorf("Unexpected key: %q", k)
+		}
+	})
+}
+
+func TestRequestLookupers(t *testing.T) {
+	req, err := http.NewRequest("POST", "https://example.org/foo?tenant=acme", nil)
+	if err != nil {
+		t.Fatalf("Could not create request: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		lookuper Lookuper
+		expected string
+	}{
+		{"query", NewQueryLookuper("tenant"), "acme"},
+		{"missing query", NewQueryLookuper("user"), ""},
+		{"path", NewPathLookuper(), "/foo"},
+		{"method", NewMethodLookuper(), "POST"},
+		{"host", NewHostLookuper(), "example.org"},
+		{"composite", NewCompositeLookuper(NewQueryLookuper("tenant"), NewPathLookuper()), "4:acme4:/foo"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.lookuper.Lookup(req); got != tc.expected {
+				t.Errorf("Failed to lookup request, expected: %q, got: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestRoundRobinLookuper(t *testing.T) {
 	for _, tc := range []struct {
 		n, concurrency, iterations int
@@ -0,0 +1,122 @@
+package rfc
+
+import (
+	"net/url"
+	"strings"
+)
+
+const upperhex = "0123456789ABCDEF"
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' ||
+		'A' <= c && c <= 'Z' ||
+		'0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// normalizes the percent-encoding according to RFC 3986 6.2.2.2: the
+// encoded unreserved characters are decoded, and the hex digits of the
+// other encoded characters are converted to upper case
+func normalizeEncoding(p string) string {
+	if !strings.Contains(p, "%") {
+		return p
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] != '%' || i+2 >= len(p) {
+			b.WriteByte(p[i])
+			continue
+		}
+
+		h, ok1 := unhex(p[i+1])
+		l, ok2 := unhex(p[i+2])
+		if !ok1 || !ok2 {
+			b.WriteByte(p[i])
+			continue
+		}
+
+		if c := h<<4 | l; isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(upperhex[h])
+			b.WriteByte(upperhex[l])
+		}
+
+		i += 2
+	}
+
+	return b.String()
+}
+
+// NormalizePath returns the normalized form of an escaped, absolute
+// request path. It normalizes the percent-encoding, collapses the
+// duplicate slashes, and removes the dot segments according to RFC 3986
+// 5.2.4. The ".." segments never go above the root. The encoded slashes
+// are not treated as segment separators. Paths that are not absolute,
+// e.g. "*", are returned unchanged.
+//
+// Example:
+//
+// 	//foo/./bar/%2e%2E/../%7ebaz%2f -> /~baz%2F
+//
+func NormalizePath(escaped string) string {
+	if !strings.HasPrefix(escaped, "/") {
+		return escaped
+	}
+
+	segments := strings.Split(normalizeEncoding(escaped), "/")[1:]
+	result := make([]string, 0, len(segments))
+	trailingSlash := false
+	for _, s := range segments {
+		trailingSlash = true
+		switch s {
+		case "", ".":
+		case "..":
+			if len(result) > 0 {
+				result = result[:len(result)-1]
+			}
+		default:
+			result = append(result, s)
+			trailingSlash = false
+		}
+	}
+
+	if len(result) == 0 {
+		return "/"
+	}
+
+	p := "/" + strings.Join(result, "/")
+	if trailingSlash {
+		p += "/"
+	}
+
+	return p
+}
+
+// NormalizeURLPath normalizes the path of the URL, see NormalizePath,
+// and sets both the decoded and the raw path.
+func NormalizeURLPath(u *url.URL) {
+	escaped := NormalizePath(u.EscapedPath())
+	p, err := url.PathUnescape(escaped)
+	if err != nil {
+		return
+	}
+
+	u.Path = p
+	u.RawPath = escaped
+}
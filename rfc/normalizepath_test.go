@@ -0,0 +1,84 @@
+package rfc
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	for _, test := range []struct{ title, path, expected string }{{
+		title:    "root",
+		path:     "/",
+		expected: "/",
+	}, {
+		title:    "not absolute",
+		path:     "*",
+		expected: "*",
+	}, {
+		title:    "already normalized",
+		path:     "/foo/bar",
+		expected: "/foo/bar",
+	}, {
+		title:    "duplicate slashes",
+		path:     "//foo///bar//",
+		expected: "/foo/bar/",
+	}, {
+		title:    "dot segments",
+		path:     "/foo/./bar/../baz",
+		expected: "/foo/baz",
+	}, {
+		title:    "trailing dot segment",
+		path:     "/foo/bar/..",
+		expected: "/foo/",
+	}, {
+		title:    "above root",
+		path:     "/../../etc/passwd",
+		expected: "/etc/passwd",
+	}, {
+		title:    "encoded dot segments",
+		path:     "/foo/%2e%2E/%2E/bar",
+		expected: "/bar",
+	}, {
+		title:    "encoded unreserved characters",
+		path:     "/%7efoo/%41%2d",
+		expected: "/~foo/A-",
+	}, {
+		title:    "encoded reserved characters",
+		path:     "/foo%2fbar/%3a/../baz%20qux",
+		expected: "/foo%2Fbar/baz%20qux",
+	}, {
+		title:    "encoded slash is not a separator",
+		path:     "/foo%2F..%2Fbar",
+		expected: "/foo%2F..%2Fbar",
+	}, {
+		title:    "invalid encoding",
+		path:     "/foo%zz/%4",
+		expected: "/foo%zz/%4",
+	}} {
+		t.Run(test.title, func(t *testing.T) {
+			if p := NormalizePath(test.path); p != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, p)
+			}
+		})
+	}
+}
+
+func TestNormalizeURLPath(t *testing.T) {
+	u, err := url.Parse("https://www.example.org//foo/../bar%2fbaz/%7equx?a=b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NormalizeURLPath(u)
+	if u.Path != "/bar/baz/~qux" {
+		t.Errorf("invalid path: %s", u.Path)
+	}
+
+	if u.EscapedPath() != "/bar%2Fbaz/~qux" {
+		t.Errorf("invalid escaped path: %s", u.EscapedPath())
+	}
+
+	if u.String() != "https://www.example.org/bar%2Fbaz/~qux?a=b" {
+		t.Errorf("invalid URL: %s", u.String())
+	}
+}
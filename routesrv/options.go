@@ -54,6 +54,10 @@ type Options struct {
 	// KubernetesIngressV1 will switch the dataclient to read ingress v1 resources, instead of v1beta1
 	KubernetesIngressV1 bool
 
+	// KubernetesEnableEndpointSlices will switch the dataclient to read the backend endpoints
+	// from the EndpointSlice resources, instead of the Endpoints
+	KubernetesEnableEndpointSlices bool
+
 	// KubernetesIngressClass is a regular expression, that will make
 	// skipper load only the ingress resources that have a matching
 	// kubernetes.io/ingress.class annotation. For backwards compatibility,
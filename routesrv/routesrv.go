@@ -52,6 +52,7 @@ func New(opts Options) (*RouteServer, error) {
 		BackendNameTracingTag:             opts.OpenTracingBackendNameTag,
 		DefaultFiltersDir:                 opts.DefaultFiltersDir,
 		KubernetesIngressV1:               opts.KubernetesIngressV1,
+		KubernetesEnableEndpointSlices:    opts.KubernetesEnableEndpointSlices,
 		KubernetesInCluster:               opts.KubernetesInCluster,
 		KubernetesURL:                     opts.KubernetesURL,
 		KubernetesNamespace:               opts.KubernetesNamespace,
@@ -20,7 +20,10 @@ const (
 	incomingUpdate
 )
 
-var errInvalidWeightParams = errors.New("invalid argument for the Weight predicate")
+var (
+	errInvalidWeightParams        = errors.New("invalid argument for the Weight predicate")
+	errInvalidTrailingSlashParams = errors.New("invalid argument for the TrailingSlash predicate, expected \"ignore\" or \"strict\"")
+)
 
 func (it incomingType) String() string {
 	switch it {
@@ -251,6 +254,8 @@ func isTreePredicate(name string) bool {
 		return true
 	case predicates.PathName:
 		return true
+	case predicates.TrailingSlashName:
+		return true
 	default:
 		return false
 	}
@@ -405,6 +410,22 @@ func processPathOrSubTree(p *eskip.Predicate) (string, error) {
 	return "", predicates.ErrInvalidPredicateParameters
 }
 
+// returns the trailing slash matching mode if it is a valid definition
+func processTrailingSlash(p *eskip.Predicate) (trailingSlashMode, error) {
+	if len(p.Args) != 1 {
+		return trailingSlashDefault, errInvalidTrailingSlashParams
+	}
+
+	switch p.Args[0] {
+	case "ignore":
+		return trailingSlashIgnore, nil
+	case "strict":
+		return trailingSlashStrict, nil
+	default:
+		return trailingSlashDefault, errInvalidTrailingSlashParams
+	}
+}
+
 func validTreePredicates(predicateList []*eskip.Predicate) bool {
 	var has bool
 	for _, p := range predicateList {
@@ -444,6 +465,12 @@ func processTreePredicates(r *Route, predicateList []*eskip.Predicate) error {
 				return err
 			}
 			r.pathSubtree = pst
+		case predicates.TrailingSlashName:
+			ts, err := processTrailingSlash(p)
+			if err != nil {
+				return err
+			}
+			r.trailingSlash = ts
 		}
 	}
 
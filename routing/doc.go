@@ -16,6 +16,17 @@ met.
 (The regular expression conditions for the path, 'PathRegexp', are
 applied only in step 2.)
 
+In step 2, the built-in conditions (Method, Host, PathRegexp, Header and
+HeaderRegexp) are checked first, regardless of their position in the
+route definition. These conditions have no side effects, so this doesn't
+change the result of the matching. The custom predicates are evaluated
+after them, from left to right, in the order of the route definition, and
+the evaluation stops at the first one that doesn't match. This way, the
+cheap custom predicates can be put in front of the expensive ones. The
+route definitions keep the order of the custom predicates also when they
+are processed by the pre-processors that use the canonical form of the
+routes.
+
 The matching conditions and the built-in filters that use regular
 expressions, use the go stdlib regexp, which uses re2:
 
@@ -0,0 +1,254 @@
+package routing
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/dimfeld/httppath"
+
+	"github.com/zalando/skipper/predicates"
+)
+
+// ExplainRequest describes the sample request to be matched by the
+// explain endpoint.
+type ExplainRequest struct {
+
+	// Method of the sample request, defaults to GET.
+	Method string `json:"method,omitempty"`
+
+	// URL of the sample request. Typically only the path and the query,
+	// when the host is absolute, it is used as the host of the request.
+	URL string `json:"url"`
+
+	// Host overrides the host of the sample request.
+	Host string `json:"host,omitempty"`
+
+	// Headers of the sample request.
+	Headers http.Header `json:"headers,omitempty"`
+
+	// RemoteAddr of the sample request, used e.g. by the Source
+	// predicates.
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+
+	// RouteIDs optionally limits the explained routes. The matched
+	// route is not affected by this list.
+	RouteIDs []string `json:"routeIds,omitempty"`
+}
+
+// PredicateExplanation contains the result of evaluating a single
+// predicate of a route against the sample request.
+type PredicateExplanation struct {
+	Name    string        `json:"name"`
+	Args    []interface{} `json:"args"`
+	Matched bool          `json:"matched"`
+}
+
+// RouteExplanation contains the results of evaluating the predicates
+// of a route against the sample request, in the order how the routing
+// evaluates them.
+type RouteExplanation struct {
+	ID         string                 `json:"id"`
+	Matched    bool                   `json:"matched"`
+	Predicates []PredicateExplanation `json:"predicates"`
+}
+
+// Explanation describes why a sample request matched a route. The
+// routes are listed in the order of the route definitions.
+type Explanation struct {
+	MatchedRoute string             `json:"matchedRoute,omitempty"`
+	Routes       []RouteExplanation `json:"routes"`
+}
+
+// checks the path conditions of a leaf alone, by creating a matcher
+// containing only the path or path subtree of the route
+func (m *matcher) explainPath(l *leafMatcher, req *http.Request) bool {
+	r := &Route{path: l.route.path, pathSubtree: l.route.pathSubtree}
+	pm, errs := newMatcher([]*Route{r}, m.matchingOptions)
+	if len(errs) > 0 {
+		return false
+	}
+
+	matched, _ := pm.match(req)
+	return matched != nil
+}
+
+func (m *matcher) explainLeaf(l *leafMatcher, req *http.Request) []PredicateExplanation {
+	var pe []PredicateExplanation
+	add := func(name string, matched bool, args ...interface{}) {
+		pe = append(pe, PredicateExplanation{Name: name, Args: args, Matched: matched})
+	}
+
+	switch {
+	case l.route.path != "":
+		add(predicates.PathName, m.explainPath(l, req), l.route.path)
+	case l.route.pathSubtree != "":
+		add(predicates.PathSubtreeName, m.explainPath(l, req), l.route.pathSubtree)
+	}
+
+	for _, rx := range l.hostRxs {
+		add(predicates.HostName, rx.MatchString(req.Host), rx.String())
+	}
+
+	for _, rx := range l.pathRxs {
+		add(predicates.PathRegexpName, rx.MatchString(httppath.Clean(req.URL.Path)), rx.String())
+	}
+
+	if l.method != "" {
+		add(predicates.MethodName, l.method == req.Method, l.method)
+	}
+
+	for _, k := range sortedKeys(l.headersExact) {
+		v := l.headersExact[k]
+		add(predicates.HeaderName, matchHeader(req.Header, k, func(val string) bool { return val == v }), k, v)
+	}
+
+	for _, k := range sortedRegexpKeys(l.headersRegexp) {
+		for _, rx := range l.headersRegexp[k] {
+			add(predicates.HeaderRegexpName, matchHeader(req.Header, k, rx.MatchString), k, rx.String())
+		}
+	}
+
+	// the custom predicates are created in the order of their
+	// definitions, skipping the Weight and the tree predicates
+	var i int
+	for _, def := range l.route.Route.Predicates {
+		if def.Name == predicates.WeightName || isTreePredicate(def.Name) {
+			continue
+		}
+
+		if i >= len(l.predicates) {
+			break
+		}
+
+		add(def.Name, l.predicates[i].Match(req), def.Args...)
+		i++
+	}
+
+	return pe
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedRegexpKeys(m map[string][]*regexp.Regexp) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *matcher) explain(req *http.Request, routeIDs []string) *Explanation {
+	var e Explanation
+	if r, _ := m.match(req); r != nil {
+		e.MatchedRoute = r.Id
+	}
+
+	var filter map[string]bool
+	if len(routeIDs) > 0 {
+		filter = make(map[string]bool)
+		for _, id := range routeIDs {
+			filter[id] = true
+		}
+	}
+
+	e.Routes = []RouteExplanation{}
+	for _, l := range m.leaves {
+		if filter != nil && !filter[l.route.Id] {
+			continue
+		}
+
+		pe := m.explainLeaf(l, req)
+		matched := true
+		for _, p := range pe {
+			matched = matched && p.Matched
+		}
+
+		e.Routes = append(e.Routes, RouteExplanation{
+			ID:         l.route.Id,
+			Matched:    matched,
+			Predicates: pe,
+		})
+	}
+
+	return &e
+}
+
+// Explain matches a sample request in the current routing tree, and
+// returns the id of the matched route, together with the results of
+// evaluating each predicate of the routes. The request is not proxied
+// and no filters are executed.
+func (r *Routing) Explain(req *http.Request, routeIDs ...string) *Explanation {
+	rt := r.routeTable.Load().(*routeTable)
+	return rt.m.explain(req, routeIDs)
+}
+
+type explainHandler struct {
+	routing *Routing
+}
+
+// ExplainHandler returns an http handler, that accepts a sample request
+// description as a JSON encoded ExplainRequest in a POST request, and
+// responds with the JSON encoded Explanation of matching it.
+func (r *Routing) ExplainHandler() http.Handler {
+	return &explainHandler{routing: r}
+}
+
+func (h *explainHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var er ExplainRequest
+	if err := json.NewDecoder(req.Body).Decode(&er); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if er.Method == "" {
+		er.Method = "GET"
+	}
+
+	if er.URL == "" {
+		er.URL = "/"
+	}
+
+	sample, err := http.NewRequest(er.Method, er.URL, nil)
+	if err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if er.Host != "" {
+		sample.Host = er.Host
+	}
+
+	for k, vs := range er.Headers {
+		for _, v := range vs {
+			sample.Header.Add(k, v)
+		}
+	}
+
+	sample.RemoteAddr = er.RemoteAddr
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.routing.Explain(sample, er.RouteIDs...)); err != nil {
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+	}
+}
@@ -0,0 +1,149 @@
+package routing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/skipper/routing"
+	"github.com/zalando/skipper/routing/testdataclient"
+)
+
+const explainRoutes = `
+	foo: Path("/foo") && Method("POST") -> <shunt>;
+	bar: Path("/foo") && Header("X-Test", "bar") && CustomPredicate("bar") -> <shunt>;
+	baz: PathSubtree("/") && Host("^www[.]example[.]org$") -> <shunt>;
+`
+
+func checkExplanation(t *testing.T, e *routing.Explanation, matchedRoute string, matched map[string][]bool) {
+	t.Helper()
+
+	if e.MatchedRoute != matchedRoute {
+		t.Errorf("invalid matched route, got: %s, expected: %s", e.MatchedRoute, matchedRoute)
+	}
+
+	if len(e.Routes) != len(matched) {
+		t.Fatalf("invalid number of explained routes: %d", len(e.Routes))
+	}
+
+	for _, r := range e.Routes {
+		expected, ok := matched[r.ID]
+		if !ok {
+			t.Errorf("unexpected route: %s", r.ID)
+			continue
+		}
+
+		if len(r.Predicates) != len(expected) {
+			t.Errorf("invalid number of predicates for %s: %d", r.ID, len(r.Predicates))
+			continue
+		}
+
+		all := true
+		for i, p := range r.Predicates {
+			if p.Matched != expected[i] {
+				t.Errorf("invalid predicate result for %s, %s: %v", r.ID, p.Name, p.Matched)
+			}
+
+			all = all && expected[i]
+		}
+
+		if r.Matched != all {
+			t.Errorf("invalid route result for %s: %v", r.ID, r.Matched)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dc, err := testdataclient.NewDoc(explainRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := newTestRoutingWithPredicates([]routing.PredicateSpec{&predicate{}}, dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tr.close()
+
+	t.Run("matched", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "https://www.example.org/foo", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("X-Test", "bar")
+		req.Header.Set(predicateHeader, "bar")
+
+		checkExplanation(t, tr.routing.Explain(req), "bar", map[string][]bool{
+			"foo": {true, false},
+			"bar": {true, true, true},
+			"baz": {true, true},
+		})
+	})
+
+	t.Run("fallback to the subtree", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "https://www.example.org/foo", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkExplanation(t, tr.routing.Explain(req), "baz", map[string][]bool{
+			"foo": {true, false},
+			"bar": {true, false, false},
+			"baz": {true, true},
+		})
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "https://api.example.org/bar", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkExplanation(t, tr.routing.Explain(req, "foo", "baz"), "", map[string][]bool{
+			"foo": {false, false},
+			"baz": {true, false},
+		})
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		body, err := json.Marshal(routing.ExplainRequest{
+			Method: "POST",
+			URL:    "/foo",
+			Host:   "api.example.org",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest("POST", "/routes/explain", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		tr.routing.ExplainHandler().ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("invalid status code: %d", w.Code)
+		}
+
+		var e routing.Explanation
+		if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+
+		checkExplanation(t, &e, "foo", map[string][]bool{
+			"foo": {true, true},
+			"bar": {true, false, false},
+			"baz": {true, false},
+		})
+	})
+
+	t.Run("handler method not allowed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/routes/explain", nil)
+		w := httptest.NewRecorder()
+		tr.routing.ExplainHandler().ServeHTTP(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("invalid status code: %d", w.Code)
+		}
+	})
+}
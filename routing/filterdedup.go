@@ -0,0 +1,105 @@
+package routing
+
+import "github.com/zalando/skipper/eskip"
+
+// FilterMerge merges the repeated instances of a filter in a route into a
+// single filter. It receives the instances in the order of the route. When
+// it returns nil, all the instances are removed from the route.
+type FilterMerge func([]*eskip.Filter) *eskip.Filter
+
+// KeepFirstFilter is a FilterMerge that keeps only the first instance of
+// the repeated filters.
+func KeepFirstFilter(f []*eskip.Filter) *eskip.Filter { return f[0] }
+
+// KeepLastFilter is a FilterMerge that keeps only the last instance of the
+// repeated filters.
+func KeepLastFilter(f []*eskip.Filter) *eskip.Filter { return f[len(f)-1] }
+
+// FilterNames returns a function that selects the filters with any of the
+// provided names, to be used with DedupFilters.
+func FilterNames(names ...string) func(*eskip.Filter) bool {
+	m := make(map[string]bool)
+	for _, n := range names {
+		m[n] = true
+	}
+
+	return func(f *eskip.Filter) bool { return m[f.Name] }
+}
+
+// DedupFilters collapses the repeated filters with the same name into a
+// single filter, returned by the merge function. Only the filters selected
+// by the collapse function are considered. The merged filter takes the
+// place of the last instance, while the other filters keep their order.
+// The input slice is not modified. When no filter was collapsed, the
+// input slice is returned.
+func DedupFilters(filters []*eskip.Filter, collapse func(*eskip.Filter) bool, merge FilterMerge) []*eskip.Filter {
+	groups := make(map[string][]*eskip.Filter)
+	last := make(map[string]int)
+	selected := make([]bool, len(filters))
+	for i, f := range filters {
+		if collapse(f) {
+			groups[f.Name] = append(groups[f.Name], f)
+			last[f.Name] = i
+			selected[i] = true
+		}
+	}
+
+	var repeated bool
+	for _, g := range groups {
+		if len(g) > 1 {
+			repeated = true
+			break
+		}
+	}
+
+	if !repeated {
+		return filters
+	}
+
+	result := make([]*eskip.Filter, 0, len(filters))
+	for i, f := range filters {
+		if !selected[i] || len(groups[f.Name]) == 1 {
+			result = append(result, f)
+			continue
+		}
+
+		if i != last[f.Name] {
+			continue
+		}
+
+		if merged := merge(groups[f.Name]); merged != nil {
+			result = append(result, merged)
+		}
+	}
+
+	return result
+}
+
+type filterDedup struct {
+	collapse func(*eskip.Filter) bool
+	merge    FilterMerge
+}
+
+// NewFilterDedup creates a PreProcessor that collapses the repeated
+// filters of each route with DedupFilters. The routes with repeated
+// filters are replaced by a copy, the input routes are not modified.
+func NewFilterDedup(collapse func(*eskip.Filter) bool, merge FilterMerge) PreProcessor {
+	return &filterDedup{collapse: collapse, merge: merge}
+}
+
+func (d *filterDedup) Do(routes []*eskip.Route) []*eskip.Route {
+	result := make([]*eskip.Route, len(routes))
+	for i, r := range routes {
+		filters := DedupFilters(r.Filters, d.collapse, d.merge)
+		if len(filters) == len(r.Filters) {
+			result[i] = r
+			continue
+		}
+
+		rr := *r
+		rr.Filters = filters
+		result[i] = &rr
+	}
+
+	return result
+}
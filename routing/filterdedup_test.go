@@ -0,0 +1,138 @@
+package routing_test
+
+import (
+	"testing"
+
+	"github.com/zalando/skipper/eskip"
+	"github.com/zalando/skipper/routing"
+)
+
+func TestDedupFilters(t *testing.T) {
+	mergeArgs := func(f []*eskip.Filter) *eskip.Filter {
+		merged := &eskip.Filter{Name: f[0].Name}
+		for _, fi := range f {
+			merged.Args = append(merged.Args, fi.Args...)
+		}
+
+		return merged
+	}
+
+	removeAll := func([]*eskip.Filter) *eskip.Filter { return nil }
+
+	for _, tt := range []struct {
+		name     string
+		filters  string
+		collapse []string
+		merge    routing.FilterMerge
+		expected string
+	}{{
+		name:     "no filters",
+		filters:  "",
+		collapse: []string{"foo"},
+		merge:    routing.KeepLastFilter,
+		expected: "",
+	}, {
+		name:     "no repeated filters",
+		filters:  `foo(1) -> bar(2)`,
+		collapse: []string{"foo", "bar"},
+		merge:    routing.KeepLastFilter,
+		expected: `foo(1) -> bar(2)`,
+	}, {
+		name:     "keep last",
+		filters:  `foo(1) -> bar(2) -> foo(3) -> baz(4)`,
+		collapse: []string{"foo"},
+		merge:    routing.KeepLastFilter,
+		expected: `bar(2) -> foo(3) -> baz(4)`,
+	}, {
+		name:     "keep first",
+		filters:  `foo(1) -> bar(2) -> foo(3) -> baz(4)`,
+		collapse: []string{"foo"},
+		merge:    routing.KeepFirstFilter,
+		expected: `bar(2) -> foo(1) -> baz(4)`,
+	}, {
+		name:     "not selected filters are preserved",
+		filters:  `foo(1) -> bar(2) -> foo(3) -> bar(4)`,
+		collapse: []string{"foo"},
+		merge:    routing.KeepLastFilter,
+		expected: `bar(2) -> foo(3) -> bar(4)`,
+	}, {
+		name:     "multiple names collapsed separately",
+		filters:  `foo(1) -> bar(2) -> foo(3) -> bar(4) -> baz(5)`,
+		collapse: []string{"foo", "bar"},
+		merge:    routing.KeepLastFilter,
+		expected: `foo(3) -> bar(4) -> baz(5)`,
+	}, {
+		name:     "merge arguments",
+		filters:  `foo(1) -> bar(2) -> foo(3)`,
+		collapse: []string{"foo"},
+		merge:    mergeArgs,
+		expected: `bar(2) -> foo(1, 3)`,
+	}, {
+		name:     "merge removes the filters",
+		filters:  `foo(1) -> bar(2) -> foo(3)`,
+		collapse: []string{"foo"},
+		merge:    removeAll,
+		expected: `bar(2)`,
+	}, {
+		name:     "single instance is not merged",
+		filters:  `foo(1) -> bar(2)`,
+		collapse: []string{"foo"},
+		merge:    removeAll,
+		expected: `foo(1) -> bar(2)`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := eskip.ParseFilters(tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			original := eskip.CopyFilters(filters)
+			result := routing.DedupFilters(filters, routing.FilterNames(tt.collapse...), tt.merge)
+
+			expected, err := eskip.ParseFilters(tt.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if r, e := eskip.String(&eskip.Route{Filters: result}), eskip.String(&eskip.Route{Filters: expected}); r != e {
+				t.Errorf("unexpected filters, expected: %s, got: %s", e, r)
+			}
+
+			if eskip.String(&eskip.Route{Filters: filters}) != eskip.String(&eskip.Route{Filters: original}) {
+				t.Error("the input filters were modified")
+			}
+		})
+	}
+}
+
+func TestFilterDedupPreProcessor(t *testing.T) {
+	routes, err := eskip.Parse(`
+		r0: * -> foo(1) -> bar(2) -> foo(3) -> <shunt>;
+		r1: * -> foo(1) -> bar(2) -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := eskip.Parse(`
+		r0: * -> bar(2) -> foo(3) -> <shunt>;
+		r1: * -> foo(1) -> bar(2) -> <shunt>;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r0Filters := routes[0].Filters
+	result := routing.NewFilterDedup(routing.FilterNames("foo"), routing.KeepLastFilter).Do(routes)
+	if !eskip.EqLists(result, expected) {
+		t.Errorf("unexpected routes, expected: %s, got: %s", eskip.String(expected...), eskip.String(result...))
+	}
+
+	if len(routes[0].Filters) != len(r0Filters) {
+		t.Error("the input route was modified")
+	}
+
+	if result[1] != routes[1] {
+		t.Error("the unchanged route was copied")
+	}
+}
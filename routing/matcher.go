@@ -40,6 +40,14 @@ type leafMatcher struct {
 	headersRegexp        map[string][]*regexp.Regexp
 	predicates           []Predicate
 	route                *Route
+
+	// the leaf was added to the path with the trailing slash toggled
+	trailingSlashVariant bool
+
+	// when ignoring the trailing slashes globally, the route requires
+	// the trailing slash of the request path to match
+	strictTrailingSlash bool
+	trailingSlash       bool
 }
 
 type leafMatchers []*leafMatcher
@@ -61,9 +69,27 @@ func leafWeight(l *leafMatcher) int {
 }
 
 // Sorting of leaf matchers:
-func (ls leafMatchers) Len() int           { return len(ls) }
-func (ls leafMatchers) Swap(i, j int)      { ls[i], ls[j] = ls[j], ls[i] }
-func (ls leafMatchers) Less(i, j int) bool { return leafWeight(ls[i]) > leafWeight(ls[j]) }
+func (ls leafMatchers) Len() int      { return len(ls) }
+func (ls leafMatchers) Swap(i, j int) { ls[i], ls[j] = ls[j], ls[i] }
+
+// Leaves with higher weight come first. The order of leaves with equal
+// weight would depend on the order of the incoming route definitions,
+// which is not stable across the updates, therefore the route id is
+// used as a tie breaker.
+func (ls leafMatchers) Less(i, j int) bool {
+	wi, wj := leafWeight(ls[i]), leafWeight(ls[j])
+	if wi != wj {
+		return wi > wj
+	}
+
+	// the leaves matching the path as defined come before the ones
+	// matching it with the trailing slash toggled
+	if ls[i].trailingSlashVariant != ls[j].trailingSlashVariant {
+		return ls[j].trailingSlashVariant
+	}
+
+	return ls[i].route.Id < ls[j].route.Id
+}
 
 type pathMatcher struct {
 	leaves leafMatchers
@@ -74,6 +100,10 @@ type matcher struct {
 	paths           *pathmux.Tree
 	rootLeaves      leafMatchers
 	matchingOptions MatchingOptions
+
+	// all the valid leaves in the order of the route definitions,
+	// used only to explain the matching
+	leaves leafMatchers
 }
 
 // An error created if a route definition cannot be processed.
@@ -283,6 +313,19 @@ func addLeafToPath(pms map[string]*pathMatcher, path string, l *leafMatcher) {
 	pm.leaves = append(pm.leaves, l)
 }
 
+func hasTrailingSlash(path string) bool {
+	return len(path) > 1 && path[len(path)-1] == '/'
+}
+
+// returns the path with the trailing slash added or removed
+func toggleTrailingSlash(path string) string {
+	if hasTrailingSlash(path) {
+		return path[:len(path)-1]
+	}
+
+	return path + "/"
+}
+
 func addSubtreeLeafsToPath(pms map[string]*pathMatcher, path string, l *leafMatcher, o MatchingOptions) {
 	basePath := freeWildcardRx.ReplaceAllLiteralString(path, "")
 	basePath = strings.TrimSuffix(basePath, "/")
@@ -301,7 +344,9 @@ func addSubtreeLeafsToPath(pms map[string]*pathMatcher, path string, l *leafMatc
 // constructs a matcher based on the provided definitions.
 //
 // If `ignoreTrailingSlash` is true, the matcher handles
-// paths with or without a trailing slash equally.
+// paths with or without a trailing slash equally. The
+// TrailingSlash() predicate of a route overrides it for
+// the Path predicate of the route.
 //
 // It constructs the route definition into a trie structure
 // based on their path condition, if any, and puts the routes
@@ -313,6 +358,7 @@ func newMatcher(rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
 	var (
 		errors     []*definitionError
 		rootLeaves leafMatchers
+		leaves     leafMatchers
 	)
 
 	pathMatchers := make(map[string]*pathMatcher)
@@ -331,6 +377,8 @@ func newMatcher(rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
 			continue
 		}
 
+		leaves = append(leaves, l)
+
 		if r.pathSubtree != "" {
 			addSubtreeLeafsToPath(pathMatchers, path, l, o)
 			continue
@@ -341,9 +389,19 @@ func newMatcher(rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
 			continue
 		}
 
-		if o.ignoreTrailingSlash() {
+		ignore := r.trailingSlash.ignore(o)
+		switch {
+		case o.ignoreTrailingSlash():
+			// the request paths are matched without the trailing slash
+			l.strictTrailingSlash = !ignore && !l.hasFreeWildcardParam
+			l.trailingSlash = hasTrailingSlash(path)
 			path = trimTrailingSlash(path)
+		case ignore && !l.hasFreeWildcardParam && path != "/":
+			lv := *l
+			lv.trailingSlashVariant = true
+			addLeafToPath(pathMatchers, toggleTrailingSlash(path), &lv)
 		}
+
 		addLeafToPath(pathMatchers, path, l)
 	}
 
@@ -353,7 +411,7 @@ func newMatcher(rs []*Route, o MatchingOptions) (*matcher, []*definitionError) {
 	// sort root leaves during construction time, based on their priority
 	sort.Stable(rootLeaves)
 
-	return &matcher{pathTree, rootLeaves, o}, errors
+	return &matcher{pathTree, rootLeaves, o, leaves}, errors
 }
 
 // matches a path in the path trie structure.
@@ -449,6 +507,10 @@ func matchLeaf(l *leafMatcher, req *http.Request, path, exactPath string) bool {
 		return false
 	}
 
+	if l.strictTrailingSlash && l.trailingSlash != hasTrailingSlash(exactPath) {
+		return false
+	}
+
 	if l.method != "" && l.method != req.Method {
 		return false
 	}
@@ -1052,6 +1052,108 @@ func TestFreeWildcardParamWithSlash(t *testing.T) {
 	}
 }
 
+func TestTrailingSlashPredicate(t *testing.T) {
+	for _, tt := range []struct {
+		title   string
+		doc     string
+		options MatchingOptions
+		path    string
+		route   string
+		params  map[string]string
+	}{{
+		title: "ignore, request with slash",
+		doc:   `foo: Path("/foo") && TrailingSlash("ignore") -> <shunt>`,
+		path:  "/foo/",
+		route: "foo",
+	}, {
+		title: "ignore, request without slash",
+		doc:   `foo: Path("/foo/") && TrailingSlash("ignore") -> <shunt>`,
+		path:  "/foo",
+		route: "foo",
+	}, {
+		title: "default, not ignored",
+		doc:   `foo: Path("/foo") -> <shunt>`,
+		path:  "/foo/",
+	}, {
+		title: "exact path preferred",
+		doc: `foo: Path("/foo") && TrailingSlash("ignore") -> <shunt>;
+		      fooSlash: Path("/foo/") -> <shunt>`,
+		path:  "/foo/",
+		route: "fooSlash",
+	}, {
+		title:  "ignore, with path params",
+		doc:    `foo: Path("/foo/:id") && TrailingSlash("ignore") -> <shunt>`,
+		path:   "/foo/bar/",
+		route:  "foo",
+		params: map[string]string{"id": "bar"},
+	}, {
+		title: "ignore, with path subtree",
+		doc:   `foo: PathSubtree("/foo") && TrailingSlash("ignore") -> <shunt>`,
+		path:  "/foo/",
+		route: "foo",
+	}, {
+		title:   "strict, global ignore",
+		doc:     `foo: Path("/foo") && TrailingSlash("strict") -> <shunt>`,
+		options: IgnoreTrailingSlash,
+		path:    "/foo/",
+	}, {
+		title:   "strict, global ignore, exact match",
+		doc:     `foo: Path("/foo/") && TrailingSlash("strict") -> <shunt>`,
+		options: IgnoreTrailingSlash,
+		path:    "/foo/",
+		route:   "foo",
+	}, {
+		title: "strict, global ignore, other route",
+		doc: `foo: Path("/foo") && TrailingSlash("strict") -> <shunt>;
+		      fooSlash: Path("/foo/") -> <shunt>`,
+		options: IgnoreTrailingSlash,
+		path:    "/foo/",
+		route:   "fooSlash",
+	}, {
+		title:   "strict, global ignore, with path params",
+		doc:     `foo: Path("/foo/:id") && TrailingSlash("strict") -> <shunt>`,
+		options: IgnoreTrailingSlash,
+		path:    "/foo/bar/",
+	}} {
+		t.Run(tt.title, func(t *testing.T) {
+			m, err := docToMatcherOpts(tt.doc, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, params := m.match(&http.Request{URL: &url.URL{Path: tt.path}})
+			if tt.route == "" {
+				if r != nil {
+					t.Fatalf("unexpected match: %s", r.Id)
+				}
+
+				return
+			}
+
+			if r == nil || r.Id != tt.route {
+				t.Fatalf("failed to match %s", tt.route)
+			}
+
+			for k, v := range tt.params {
+				if params[k] != v {
+					t.Errorf("invalid param %s: %q, expected: %q", k, params[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidTrailingSlashPredicate(t *testing.T) {
+	rs, err := docToRoutes(`Path("/foo") && TrailingSlash("sometimes") -> <shunt>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rs) != 0 {
+		t.Error("failed to fail")
+	}
+}
+
 func TestHeaderMatchCaseInsensitive(t *testing.T) {
 	m, err := docToMatcher(`Header("some-header", "some-value") -> "https://example.org"`)
 	if err != nil {
@@ -1309,3 +1411,75 @@ func BenchmarkConstructionMass(b *testing.B) {
 		}
 	}
 }
+
+type recordPredicateSpec struct {
+	calls []string
+}
+
+type recordPredicate struct {
+	spec   *recordPredicateSpec
+	name   string
+	result bool
+}
+
+func (s *recordPredicateSpec) Name() string { return "Record" }
+
+func (s *recordPredicateSpec) Create(args []interface{}) (Predicate, error) {
+	return &recordPredicate{spec: s, name: args[0].(string), result: args[1].(string) == "true"}, nil
+}
+
+func (p *recordPredicate) Match(*http.Request) bool {
+	p.spec.calls = append(p.spec.calls, p.name)
+	return p.result
+}
+
+func TestPredicateEvaluationOrder(t *testing.T) {
+	defs, err := eskip.Parse(`
+		Path("/foo") &&
+		Record("b", "true") &&
+		Method("GET") &&
+		Record("a", "false") &&
+		Record("c", "true")
+		-> <shunt>
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pre-processors may return canonical copies of the routes:
+	defs = eskip.CopyRoutes(defs)
+
+	spec := &recordPredicateSpec{}
+	routes, invalid := processRouteDefs(Options{Predicates: []PredicateSpec{spec}}, nil, defs)
+	if len(invalid) != 0 {
+		t.Fatal("failed to process route definitions")
+	}
+
+	m, err := newTestMatcher(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		method, path string
+		expect       []string
+	}{{
+		method: "GET",
+		path:   "/foo",
+		expect: []string{"b", "a"},
+	}, {
+		method: "POST",
+		path:   "/foo",
+	}, {
+		method: "GET",
+		path:   "/bar",
+	}} {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			spec.calls = nil
+			m.match(&http.Request{Method: test.method, URL: &url.URL{Path: test.path}})
+			if fmt.Sprint(spec.calls) != fmt.Sprint(test.expect) {
+				t.Errorf("unexpected evaluation order, expected: %v, got: %v", test.expect, spec.calls)
+			}
+		})
+	}
+}
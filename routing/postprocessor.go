@@ -0,0 +1,115 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamedPostProcessor is an optional interface of the post-processors.
+// The named post-processors can be referenced as dependencies by the
+// other post-processors.
+type NamedPostProcessor interface {
+	PostProcessor
+
+	// PostProcessorName returns the name referenced by the
+	// dependent post-processors.
+	PostProcessorName() string
+}
+
+// DependentPostProcessor is an optional interface of the
+// post-processors, declaring which other post-processors they need to
+// run after.
+type DependentPostProcessor interface {
+	PostProcessor
+
+	// RunsAfter returns the names of the post-processors that need to
+	// run before this post-processor. The names not matching any of the
+	// configured post-processors are ignored.
+	RunsAfter() []string
+}
+
+func postProcessorName(p PostProcessor) string {
+	if np, ok := p.(NamedPostProcessor); ok {
+		return np.PostProcessorName()
+	}
+
+	return ""
+}
+
+// SortPostProcessors orders the post-processors such that each of them
+// runs after the post-processors that it depends on. Otherwise, the
+// original order is preserved, so the post-processors without declared
+// dependencies run in the order of the slice. It returns an error when
+// the dependencies contain a cycle, or when a name is used by multiple
+// post-processors.
+func SortPostProcessors(p []PostProcessor) ([]PostProcessor, error) {
+	index := make(map[string]int)
+	for i, pi := range p {
+		name := postProcessorName(pi)
+		if name == "" {
+			continue
+		}
+
+		if _, exists := index[name]; exists {
+			return nil, fmt.Errorf("duplicate post-processor name: %s", name)
+		}
+
+		index[name] = i
+	}
+
+	dependencies := make([][]int, len(p))
+	for i, pi := range p {
+		dp, ok := pi.(DependentPostProcessor)
+		if !ok {
+			continue
+		}
+
+		for _, name := range dp.RunsAfter() {
+			if j, ok := index[name]; ok {
+				dependencies[i] = append(dependencies[i], j)
+			}
+		}
+	}
+
+	// selecting always the first post-processor whose dependencies
+	// already run, to keep the original order where possible
+	sorted := make([]PostProcessor, 0, len(p))
+	done := make([]bool, len(p))
+	for len(sorted) < len(p) {
+		next := -1
+		for i := range p {
+			if done[i] {
+				continue
+			}
+
+			ready := true
+			for _, j := range dependencies[i] {
+				if !done[j] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			var blocked []string
+			for i, pi := range p {
+				if !done[i] {
+					blocked = append(blocked, fmt.Sprintf("%d:%s", i, postProcessorName(pi)))
+				}
+			}
+
+			return nil, fmt.Errorf("cyclic post-processor dependencies, unable to order: %s", strings.Join(blocked, ", "))
+		}
+
+		done[next] = true
+		sorted = append(sorted, p[next])
+	}
+
+	return sorted, nil
+}
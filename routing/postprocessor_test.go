@@ -0,0 +1,124 @@
+package routing_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalando/skipper/routing"
+)
+
+type testPostProcessor struct {
+	name      string
+	runsAfter []string
+}
+
+type namedPostProcessor struct{ *testPostProcessor }
+
+type dependentPostProcessor struct{ *testPostProcessor }
+
+type namedDependentPostProcessor struct{ *testPostProcessor }
+
+func (p *testPostProcessor) Do(r []*routing.Route) []*routing.Route { return r }
+
+func (p namedPostProcessor) PostProcessorName() string { return p.name }
+
+func (p dependentPostProcessor) RunsAfter() []string { return p.runsAfter }
+
+func (p namedDependentPostProcessor) PostProcessorName() string { return p.name }
+
+func (p namedDependentPostProcessor) RunsAfter() []string { return p.runsAfter }
+
+func named(name string) routing.PostProcessor {
+	return namedPostProcessor{&testPostProcessor{name: name}}
+}
+
+func dependent(runsAfter ...string) routing.PostProcessor {
+	return dependentPostProcessor{&testPostProcessor{runsAfter: runsAfter}}
+}
+
+func namedDependent(name string, runsAfter ...string) routing.PostProcessor {
+	return namedDependentPostProcessor{&testPostProcessor{name: name, runsAfter: runsAfter}}
+}
+
+func TestSortPostProcessors(t *testing.T) {
+	plain := &testPostProcessor{}
+	a := named("a")
+	b := named("b")
+	afterA := dependent("a")
+	afterB := dependent("b")
+	afterAB := dependent("a", "b")
+	afterUnknown := dependent("unknown")
+	cAfterB := namedDependent("c", "b")
+	bAfterC := namedDependent("b", "c")
+	aAfterA := namedDependent("a", "a")
+
+	for _, tt := range []struct {
+		name     string
+		input    []routing.PostProcessor
+		expected []routing.PostProcessor
+		err      bool
+	}{{
+		name: "empty",
+	}, {
+		name:     "no declarations",
+		input:    []routing.PostProcessor{plain, a, b},
+		expected: []routing.PostProcessor{plain, a, b},
+	}, {
+		name:     "dependency already satisfied",
+		input:    []routing.PostProcessor{a, afterA, b},
+		expected: []routing.PostProcessor{a, afterA, b},
+	}, {
+		name:     "moved after the dependency",
+		input:    []routing.PostProcessor{afterB, plain, a, b},
+		expected: []routing.PostProcessor{plain, a, b, afterB},
+	}, {
+		name:     "multiple dependencies",
+		input:    []routing.PostProcessor{afterAB, b, plain, a},
+		expected: []routing.PostProcessor{b, plain, a, afterAB},
+	}, {
+		name:     "transitive dependencies",
+		input:    []routing.PostProcessor{afterA, cAfterB, b, namedDependent("a", "c")},
+		expected: []routing.PostProcessor{b, cAfterB, namedDependent("a", "c"), afterA},
+	}, {
+		name:     "unknown dependency ignored",
+		input:    []routing.PostProcessor{afterUnknown, a},
+		expected: []routing.PostProcessor{afterUnknown, a},
+	}, {
+		name:  "cycle",
+		input: []routing.PostProcessor{plain, cAfterB, bAfterC},
+		err:   true,
+	}, {
+		name:  "self dependency",
+		input: []routing.PostProcessor{aAfterA},
+		err:   true,
+	}, {
+		name:  "duplicate name",
+		input: []routing.PostProcessor{a, named("a")},
+		err:   true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := routing.SortPostProcessors(tt.input)
+			if tt.err {
+				if err == nil {
+					t.Error("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(sorted) != len(tt.expected) {
+				t.Fatalf("unexpected length, expected: %d, got: %d", len(tt.expected), len(sorted))
+			}
+
+			for i := range sorted {
+				if !reflect.DeepEqual(sorted[i], tt.expected[i]) {
+					t.Errorf("unexpected post-processor at %d, expected: %v, got: %v", i, tt.expected[i], sorted[i])
+				}
+			}
+		})
+	}
+}
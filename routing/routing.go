@@ -45,6 +45,29 @@ func (o MatchingOptions) ignoreTrailingSlash() bool {
 	return o&IgnoreTrailingSlash > 0
 }
 
+// trailingSlashMode overrides the trailing slash matching of a route,
+// set by the TrailingSlash() predicate.
+type trailingSlashMode int
+
+const (
+	trailingSlashDefault trailingSlashMode = iota
+	trailingSlashIgnore
+	trailingSlashStrict
+)
+
+// tells whether the route ignores the trailing slashes, when the
+// matching options are applied
+func (m trailingSlashMode) ignore(o MatchingOptions) bool {
+	switch m {
+	case trailingSlashIgnore:
+		return true
+	case trailingSlashStrict:
+		return false
+	default:
+		return o.ignoreTrailingSlash()
+	}
+}
+
 // DataClient instances provide data sources for
 // route definitions.
 type DataClient interface {
@@ -119,7 +142,9 @@ type Options struct {
 	// PreProcessors contains custom eskip.Route pre-processors.
 	PreProcessors []PreProcessor
 
-	// PostProcessors contains custom route post-processors.
+	// PostProcessors contains custom route post-processors. They
+	// run in the order of the slice, unless they declare dependencies
+	// by implementing DependentPostProcessor. See SortPostProcessors.
 	PostProcessors []PostProcessor
 
 	// SignalFirstLoad enables signaling on the first load
@@ -167,6 +192,18 @@ type LBEndpoint struct {
 	// Detected represents the time when skipper instances first detected a new LB endpoint. This detection
 	// time is used for the fade-in feature of the round-robin and random LB algorithms.
 	Detected time.Time
+
+	// Weight is the relative weight of the endpoint used by the weightedRandom LB algorithm.
+	// It is set by the weight query parameter of the endpoint address, and defaults to 1.
+	Weight float64
+
+	// RampStart, when set, is used as the start of the fade-in instead of the detection time.
+	// It is set by the rampStart query parameter of the endpoint address.
+	RampStart time.Time
+
+	// Zone is the topology zone of the endpoint, used by the zoneAware LB algorithm.
+	// It is set by the zone query parameter of the endpoint address.
+	Zone string
 }
 
 // LBAlgorithm implementations apply a load balancing algorithm
@@ -181,6 +218,13 @@ type LBContext struct {
 	Request *http.Request
 	Route   *Route
 	Params  map[string]interface{}
+
+	// ExcludedEndpoints contains the hosts of the endpoints that should
+	// not be selected for the request when there are other ones, e.g.
+	// the endpoints that failed already when retrying. It is optional.
+	// When all the endpoints are excluded, the algorithms fall back to
+	// all of them.
+	ExcludedEndpoints map[string]bool
 }
 
 // NewLBContext is used to create a new LBContext, to pass data to the
@@ -208,6 +252,10 @@ type Route struct {
 	// path predicate matching a subtree
 	pathSubtree string
 
+	// trailing slash matching of the route, received from the
+	// TrailingSlash() predicate
+	trailingSlash trailingSlashMode
+
 	// The backend scheme and host.
 	Scheme, Host string
 
@@ -273,6 +321,12 @@ func New(o Options) *Routing {
 		o.Log = &logging.DefaultLog{}
 	}
 
+	if pp, err := SortPostProcessors(o.PostProcessors); err != nil {
+		o.Log.Errorf("Failed to order the post-processors, using the configured order: %v", err)
+	} else {
+		o.PostProcessors = pp
+	}
+
 	r := &Routing{log: o.Log, firstLoad: make(chan struct{}), quit: make(chan struct{})}
 	if !o.SignalFirstLoad {
 		close(r.firstLoad)
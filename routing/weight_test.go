@@ -1,7 +1,10 @@
 package routing
 
 import (
+	"net/http"
 	"testing"
+
+	"github.com/zalando/skipper/eskip"
 )
 
 func TestWeightArgs(t *testing.T) {
@@ -50,3 +53,64 @@ func TestWeightArgs(t *testing.T) {
 		}()
 	}
 }
+
+func TestEqualWeightTieBreak(t *testing.T) {
+	routes := func(ids ...string) []*Route {
+		var rs []*Route
+		for _, id := range ids {
+			rs = append(rs, &Route{Route: eskip.Route{Id: id, Method: "GET"}, path: "/test"})
+			rs = append(rs, &Route{Route: eskip.Route{Id: id + "_root", Method: "GET"}})
+		}
+
+		return rs
+	}
+
+	for _, ti := range []struct {
+		msg      string
+		routes   []*Route
+		path     string
+		expected string
+	}{{
+		"path, defined in order",
+		routes("route1", "route2", "route3"),
+		"/test",
+		"route1",
+	}, {
+		"path, defined in reverse order",
+		routes("route3", "route2", "route1"),
+		"/test",
+		"route1",
+	}, {
+		"root, defined in order",
+		routes("route1", "route2", "route3"),
+		"/other",
+		"route1_root",
+	}, {
+		"root, defined in reverse order",
+		routes("route3", "route2", "route1"),
+		"/other",
+		"route1_root",
+	}, {
+		"higher weight wins",
+		append(routes("route1"), &Route{Route: eskip.Route{Id: "route2", Method: "GET"}, path: "/test", weight: 1}),
+		"/test",
+		"route2",
+	}} {
+		t.Run(ti.msg, func(t *testing.T) {
+			m, errs := newMatcher(ti.routes, MatchingOptionsNone)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			req, err := http.NewRequest("GET", "https://www.example.org"+ti.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, _ := m.match(req)
+			if r == nil || r.Id != ti.expected {
+				t.Errorf("invalid route matched: %v", r)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// fifoQueue implements a bounded first in first out queue with a
+// maximum concurrency, following the same semantics as jobqueue.Stack,
+// with the difference that the waiting jobs are served in the order of
+// their arrival, and when the queue is full, the new jobs are rejected.
+type fifoQueue struct {
+	mu      sync.Mutex
+	config  Config
+	active  int
+	waiting *list.List
+	closed  bool
+}
+
+func newFIFOQueue(c Config) *fifoQueue {
+	return &fifoQueue{
+		config:  c,
+		waiting: list.New(),
+	}
+}
+
+func (q *fifoQueue) maxConcurrency() int {
+	if q.config.MaxConcurrency <= 0 {
+		return 1
+	}
+
+	return q.config.MaxConcurrency
+}
+
+func (q *fifoQueue) full() bool {
+	return q.config.MaxQueueSize > 0 && q.waiting.Len() >= q.config.MaxQueueSize
+}
+
+func (q *fifoQueue) done() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.active--
+			q.dequeue()
+		})
+	}
+}
+
+// dequeue starts the waiting jobs in the order of their arrival, as long as the
+// concurrency allows it. It expects the lock to be held.
+func (q *fifoQueue) dequeue() {
+	for q.waiting.Len() > 0 && q.active < q.maxConcurrency() {
+		ready := q.waiting.Remove(q.waiting.Front()).(chan error)
+		q.active++
+		ready <- nil
+	}
+}
+
+// Wait blocks until the job can be started, or returns an error when the queue
+// is full, the timeout was reached or the queue was closed.
+func (q *fifoQueue) Wait() (func(), error) {
+	return q.WaitContext(context.Background())
+}
+
+// WaitContext is like Wait, but it also returns the error of the context,
+// when the context is done before the job can be started.
+func (q *fifoQueue) WaitContext(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil, ErrQueueClosed
+	}
+
+	if q.waiting.Len() == 0 && q.active < q.maxConcurrency() {
+		q.active++
+		q.mu.Unlock()
+		return q.done(), nil
+	}
+
+	if q.full() {
+		q.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	ready := make(chan error, 1)
+	e := q.waiting.PushBack(ready)
+	timeout := q.config.Timeout
+	q.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			return nil, err
+		}
+
+		return q.done(), nil
+	case <-expired:
+		return q.abandon(e, ready, ErrQueueTimeout)
+	case <-ctx.Done():
+		return q.abandon(e, ready, ctx.Err())
+	}
+}
+
+// removes a waiting job from the queue, unless it was started or rejected
+// in the meantime
+func (q *fifoQueue) abandon(e *list.Element, ready chan error, reason error) (func(), error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// the job may have been started or rejected while acquiring the lock
+	select {
+	case err := <-ready:
+		if err != nil {
+			return nil, err
+		}
+
+		return q.done(), nil
+	default:
+	}
+
+	q.waiting.Remove(e)
+	return nil, reason
+}
+
+// Status returns the current status of the queue.
+func (q *fifoQueue) Status() QueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStatus{
+		ActiveRequests: q.active,
+		QueuedRequests: q.waiting.Len(),
+		Closed:         q.closed,
+	}
+}
+
+// Reconfigure applies the new configuration. When the queue size was
+// decreased, the already waiting jobs are kept until they are started or
+// time out, and the new size is enforced only for the newly arriving jobs.
+func (q *fifoQueue) Reconfigure(c Config) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.config = c
+	q.dequeue()
+}
+
+// Close rejects the waiting jobs and all the jobs arriving later. The
+// already started jobs are not affected.
+func (q *fifoQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	for q.waiting.Len() > 0 {
+		ready := q.waiting.Remove(q.waiting.Front()).(chan error)
+		ready <- ErrQueueClosed
+	}
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func waitForFIFOStatus(t *testing.T, q *fifoQueue, s QueueStatus) {
+	timeout := time.After(120 * time.Millisecond)
+	for {
+		if q.Status() == s {
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("failed to reach status, want: %v, got: %v", s, q.Status())
+		default:
+		}
+	}
+}
+
+func TestFIFOOrder(t *testing.T) {
+	q := newFIFOQueue(Config{MaxConcurrency: 1, MaxQueueSize: 3})
+	done, err := q.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			d, err := q.Wait()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			order <- i
+			d()
+		}(i)
+
+		waitForFIFOStatus(t, q, QueueStatus{ActiveRequests: 1, QueuedRequests: i + 1})
+	}
+
+	if _, err := q.Wait(); err != ErrQueueFull {
+		t.Fatalf("expected full queue error, got: %v", err)
+	}
+
+	done()
+	for i := 0; i < 3; i++ {
+		if got := <-order; got != i {
+			t.Errorf("unexpected order, want: %d, got: %d", i, got)
+		}
+	}
+
+	waitForFIFOStatus(t, q, QueueStatus{})
+}
+
+func TestFIFOTimeout(t *testing.T) {
+	q := newFIFOQueue(Config{MaxConcurrency: 1, MaxQueueSize: 1, Timeout: 10 * time.Millisecond})
+	done, err := q.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer done()
+	if _, err := q.Wait(); err != ErrQueueTimeout {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+
+	waitForFIFOStatus(t, q, QueueStatus{ActiveRequests: 1})
+}
+
+func TestFIFOContext(t *testing.T) {
+	q := newFIFOQueue(Config{MaxConcurrency: 1, MaxQueueSize: 1, Timeout: time.Second})
+	done, err := q.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer done()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded error, got: %v", err)
+	}
+
+	waitForFIFOStatus(t, q, QueueStatus{ActiveRequests: 1})
+	if _, err := q.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded error, got: %v", err)
+	}
+}
+
+func TestFIFOClose(t *testing.T) {
+	q := newFIFOQueue(Config{MaxConcurrency: 1, MaxQueueSize: 1})
+	done, err := q.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		_, err := q.Wait()
+		errs <- err
+	}()
+
+	waitForFIFOStatus(t, q, QueueStatus{ActiveRequests: 1, QueuedRequests: 1})
+	q.Close()
+	if err := <-errs; err != ErrQueueClosed {
+		t.Errorf("expected closed error, got: %v", err)
+	}
+
+	done()
+	waitForFIFOStatus(t, q, QueueStatus{Closed: true})
+}
@@ -1,25 +1,51 @@
 // Package scheduler provides a registry to be used as a postprocessor for the routes
-// that use a LIFO filter.
+// that use a LIFO or a FIFO filter.
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aryszka/jobqueue"
 	log "github.com/sirupsen/logrus"
-	"github.com/zalando/skipper/eskip"
 	"github.com/zalando/skipper/filters"
 	"github.com/zalando/skipper/metrics"
 	"github.com/zalando/skipper/routing"
 )
 
+// PostProcessorName is the name of the Registry as a routing
+// post-processor.
+const PostProcessorName = "scheduler"
+
 // note: Config must stay comparable because it is used to detect changes in route specific LIFO config
 
 const (
 	// Key used during routing to pass lifo values from the filters to the proxy.
+	// It is used by the fifo filters, too.
 	LIFOKey = "lifo"
+
+	// LIFOPriorityKey is the key used in the state bag to mark the requests
+	// that can use the reserved priority concurrency of the queue. It is
+	// set by the lifoPriority filter.
+	LIFOPriorityKey = "lifo:priority"
+)
+
+var (
+	// ErrQueueFull is returned by the queue when a request was rejected,
+	// because the queue reached its maximum size.
+	ErrQueueFull = errors.New("queue full")
+
+	// ErrQueueTimeout is returned by the queue when a request was rejected,
+	// because it could not be scheduled within the configured timeout.
+	ErrQueueTimeout = errors.New("queue timeout")
+
+	// ErrQueueClosed is returned by the queue when a request was rejected,
+	// because the queue was closed.
+	ErrQueueClosed = errors.New("queue closed")
 )
 
 // Config can be used to provide configuration of the registry.
@@ -40,6 +66,16 @@ type Config struct {
 	// CloseTimeout sets a maximum duration for how long the queue can wait
 	// for the active and queued jobs to finish. Defaults to infinite.
 	CloseTimeout time.Duration
+
+	// PriorityConcurrency defines how many priority requests are allowed
+	// to run concurrently in reserved slots, in addition to MaxConcurrency.
+	// It can be used for lightweight requests, e.g. health checks, that
+	// should not wait behind the regular traffic. It is not a priority
+	// queue: when all the reserved slots are taken, the priority requests
+	// wait in the queue like the regular ones, without being dequeued
+	// first. The requests in the reserved slots are not included in the
+	// queue status. Defaults to 0.
+	PriorityConcurrency int
 }
 
 // QueueStatus reports the current status of a queue. It can be used for metrics.
@@ -55,25 +91,33 @@ type QueueStatus struct {
 	Closed bool
 }
 
-// Queue objects implement a LIFO queue for handling requests, with a maximum allowed
-// concurrency and queue size. Currently, they can be used from the lifo and lifoGroup
-// filters in the filters/scheduler package only.
+// Queue objects implement a LIFO or a FIFO queue for handling requests, with a maximum
+// allowed concurrency and queue size. Currently, they can be used from the lifo, lifoGroup,
+// fifo and fifoGroup filters in the filters/scheduler package only.
 type Queue struct {
+	mu                       sync.Mutex
 	queue                    *jobqueue.Stack
+	fifo                     *fifoQueue
+	priority                 chan struct{}
 	config                   Config
+	draining                 bool
 	metrics                  metrics.Metrics
+	fullEvents               int64
 	activeRequestsMetricsKey string
 	errorFullMetricsKey      string
 	errorOtherMetricsKey     string
 	errorTimeoutMetricsKey   string
 	queuedRequestsMetricsKey string
+	fullEventsMetricsKey     string
+	waitMetricsKey           string
+	waitTimeoutMetricsKey    string
 }
 
 // Options provides options for the registry.
 type Options struct {
 
 	// MetricsUpdateTimeout defines the frequence of how often the LIFO metrics
-	// are updated when they are enabled. Defaults to 1s.
+	// are sampled and updated when they are enabled. Defaults to 1s.
 	MetricsUpdateTimeout time.Duration
 
 	// EnableRouteLIFOMetrics enables collecting metrics about the LIFO queues.
@@ -91,7 +135,9 @@ type Options struct {
 // for regularly take snapshots of the active lifo queues and update the corresponding
 // metrics. This goroutine is started when the first lifo filter is detected and returns
 // when the registry is closed. Individual metrics objects (keys) are used for each
-// lifo filter, and one for each lifo group defined by the lifoGroup filter.
+// lifo filter, and one for each lifo group defined by the lifoGroup filter. The sampled
+// gauges are the number of the active and the queued requests, and the number of the
+// requests rejected due to a full queue since the previous sample.
 //
 type Registry struct {
 	options   Options
@@ -129,17 +175,55 @@ type GroupedLIFOFilter interface {
 	HasConfig() bool
 }
 
+// FIFOFilter is an extension of the LIFOFilter interface for filters that
+// need the queued requests to be handled in the order of their arrival.
+// Both the single and the grouped filters can implement it.
+type FIFOFilter interface {
+	LIFOFilter
+
+	// FIFO indicates that the filter requires a first in first out queue.
+	FIFO() bool
+}
+
 // Wait blocks until a request can be processed or needs to be rejected.
 // When it can be processed, calling done indicates that it has finished.
 // It is mandatory to call done() the request was processed. When the
-// request needs to be rejected, an error will be returned.
+// request needs to be rejected, an error will be returned: ErrQueueFull
+// when the queue reached its maximum size, ErrQueueTimeout when the request
+// could not be scheduled in time, or ErrQueueClosed.
 func (q *Queue) Wait() (done func(), err error) {
-	done, err = q.queue.Wait()
-	if q.metrics != nil && err != nil {
+	return q.WaitContext(context.Background())
+}
+
+// WaitContext is like Wait, but it also returns the error of the context,
+// when the context is done before the request can be processed, e.g.
+// because the deadline of the request was exceeded.
+func (q *Queue) WaitContext(ctx context.Context) (done func(), err error) {
+	start := time.Now()
+	if q.fifo != nil {
+		done, err = q.fifo.WaitContext(ctx)
+	} else if q.drainingFull() {
+		err = ErrQueueFull
+	} else {
+		// the LIFO queue cannot be canceled, the context is checked
+		// when the request was scheduled
+		done, err = q.queue.Wait()
+		err = stackError(err)
+		if err == nil && ctx.Err() != nil {
+			done()
+			done, err = nil, ctx.Err()
+		}
+	}
+
+	if q.metrics != nil {
 		switch err {
-		case jobqueue.ErrStackFull:
+		case nil:
+			q.metrics.MeasureSince(q.waitMetricsKey, start)
+		case ErrQueueFull:
+			atomic.AddInt64(&q.fullEvents, 1)
 			q.metrics.IncCounter(q.errorFullMetricsKey)
-		case jobqueue.ErrTimeout:
+		case ErrQueueTimeout:
+			q.metrics.MeasureSince(q.waitTimeoutMetricsKey, start)
 			q.metrics.IncCounter(q.errorTimeoutMetricsKey)
 		default:
 			q.metrics.IncCounter(q.errorOtherMetricsKey)
@@ -149,8 +233,52 @@ func (q *Queue) Wait() (done func(), err error) {
 	return done, err
 }
 
+// WaitPriority is like WaitContext, but it takes one of the reserved
+// priority slots first, configured by PriorityConcurrency. When none of
+// them is free, the request waits in the queue with the same order as the
+// ones coming from WaitContext.
+func (q *Queue) WaitPriority(ctx context.Context) (done func(), err error) {
+	q.mu.Lock()
+	slots := q.priority
+	q.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+		return q.WaitContext(ctx)
+	}
+}
+
+func prioritySlots(c Config) chan struct{} {
+	if c.PriorityConcurrency <= 0 {
+		return nil
+	}
+
+	return make(chan struct{}, c.PriorityConcurrency)
+}
+
+// stackError maps the errors of the jobqueue package to the errors of the
+// scheduler.
+func stackError(err error) error {
+	switch err {
+	case jobqueue.ErrStackFull:
+		return ErrQueueFull
+	case jobqueue.ErrTimeout:
+		return ErrQueueTimeout
+	case jobqueue.ErrClosed:
+		return ErrQueueClosed
+	default:
+		return err
+	}
+}
+
 // Status returns the current status of a queue.
 func (q *Queue) Status() QueueStatus {
+	if q.fifo != nil {
+		return q.fifo.Status()
+	}
+
 	st := q.queue.Status()
 	return QueueStatus{
 		ActiveRequests: st.ActiveJobs,
@@ -161,18 +289,75 @@ func (q *Queue) Status() QueueStatus {
 
 // Config returns the configuration that the queue was created with.
 func (q *Queue) Config() Config {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	return q.config
 }
 
-func (q *Queue) reconfigure() {
-	q.queue.Reconfigure(jobqueue.Options{
-		MaxConcurrency: q.config.MaxConcurrency,
-		MaxStackSize:   q.config.MaxQueueSize,
-		Timeout:        q.config.Timeout,
-	})
+func stackOptions(c Config) jobqueue.Options {
+	return jobqueue.Options{
+		MaxConcurrency: c.MaxConcurrency,
+		MaxStackSize:   c.MaxQueueSize,
+		Timeout:        c.Timeout,
+	}
+}
+
+// reconfigure applies the config to the queue when it has changed. When the
+// queue size was decreased below the number of the currently queued requests,
+// the stack keeps its size until the queued requests were drained, in order to
+// avoid dropping them, and only the newly arriving requests are rejected.
+func (q *Queue) reconfigure(c Config) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.config == c {
+		return
+	}
+
+	if q.config.PriorityConcurrency != c.PriorityConcurrency {
+		q.priority = prioritySlots(c)
+	}
+
+	q.config = c
+	if q.fifo != nil {
+		q.fifo.Reconfigure(c)
+		return
+	}
+
+	o := stackOptions(c)
+	queued := q.queue.Status().QueuedJobs
+	q.draining = o.MaxStackSize > 0 && queued > o.MaxStackSize
+	if q.draining {
+		o.MaxStackSize = queued
+	}
+
+	q.queue.Reconfigure(o)
+}
+
+// drainingFull tells whether a new request needs to be rejected, because the
+// queue is still draining the requests queued before its size was decreased.
+// Once they were drained, it applies the decreased size.
+func (q *Queue) drainingFull() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.draining {
+		return false
+	}
+
+	if q.queue.Status().QueuedJobs > q.config.MaxQueueSize {
+		return true
+	}
+
+	q.draining = false
+	q.queue.Reconfigure(stackOptions(q.config))
+	return false
 }
 
 func (q *Queue) close() {
+	if q.fifo != nil {
+		q.fifo.Close()
+		return
+	}
+
 	q.queue.Close()
 }
 
@@ -194,15 +379,15 @@ func NewRegistry() *Registry {
 	return RegistryWith(Options{})
 }
 
-func (r *Registry) newQueue(name string, c Config) *Queue {
-	q := &Queue{
-		config: c,
+func (r *Registry) newQueue(name string, c Config, fifo bool) *Queue {
+	q := &Queue{config: c, priority: prioritySlots(c)}
+	prefix := "lifo"
+	if fifo {
+		prefix = "fifo"
+		q.fifo = newFIFOQueue(c)
+	} else {
 		// renaming Stack -> Queue in the jobqueue project will follow
-		queue: jobqueue.With(jobqueue.Options{
-			MaxConcurrency: c.MaxConcurrency,
-			MaxStackSize:   c.MaxQueueSize,
-			Timeout:        c.Timeout,
-		}),
+		q.queue = jobqueue.With(stackOptions(c))
 	}
 
 	if r.options.EnableRouteLIFOMetrics {
@@ -210,11 +395,14 @@ func (r *Registry) newQueue(name string, c Config) *Queue {
 			name = "unknown"
 		}
 
-		q.activeRequestsMetricsKey = fmt.Sprintf("lifo.%s.active", name)
-		q.queuedRequestsMetricsKey = fmt.Sprintf("lifo.%s.queued", name)
-		q.errorFullMetricsKey = fmt.Sprintf("lifo.%s.error.full", name)
-		q.errorOtherMetricsKey = fmt.Sprintf("lifo.%s.error.other", name)
-		q.errorTimeoutMetricsKey = fmt.Sprintf("lifo.%s.error.timeout", name)
+		q.activeRequestsMetricsKey = fmt.Sprintf("%s.%s.active", prefix, name)
+		q.queuedRequestsMetricsKey = fmt.Sprintf("%s.%s.queued", prefix, name)
+		q.errorFullMetricsKey = fmt.Sprintf("%s.%s.error.full", prefix, name)
+		q.errorOtherMetricsKey = fmt.Sprintf("%s.%s.error.other", prefix, name)
+		q.errorTimeoutMetricsKey = fmt.Sprintf("%s.%s.error.timeout", prefix, name)
+		q.fullEventsMetricsKey = fmt.Sprintf("%s.%s.full", prefix, name)
+		q.waitMetricsKey = fmt.Sprintf("%s.%s.wait", prefix, name)
+		q.waitTimeoutMetricsKey = fmt.Sprintf("%s.%s.wait.timeout", prefix, name)
 		q.metrics = r.options.Metrics
 		r.measure()
 	}
@@ -222,38 +410,24 @@ func (r *Registry) newQueue(name string, c Config) *Queue {
 	return q
 }
 
-// Returns routing.PreProcessor that ensures single lifo filter instance per route
+// PostProcessorName implements routing.NamedPostProcessor. The custom
+// post-processors depending on the queues of the scheduler filters can
+// reference this name in their declared dependencies.
+func (r *Registry) PostProcessorName() string { return PostProcessorName }
+
+// Returns routing.PreProcessor that ensures single lifo and single fifo filter instance per route
 //
 // Registry can not implement routing.PreProcessor directly due to unfortunate method name clash with routing.PostProcessor
 func (r *Registry) PreProcessor() routing.PreProcessor {
-	return registryPreProcessor{}
+	return routing.NewFilterDedup(
+		routing.FilterNames(filters.LifoName, filters.FifoName),
+		routing.KeepLastFilter,
+	)
 }
 
-type registryPreProcessor struct{}
-
-func (registryPreProcessor) Do(routes []*eskip.Route) []*eskip.Route {
-	for _, r := range routes {
-		lifoCount := 0
-		for _, f := range r.Filters {
-			if f.Name == filters.LifoName {
-				lifoCount++
-			}
-		}
-		// remove all but last lifo instances
-		if lifoCount > 1 {
-			old := r.Filters
-			r.Filters = make([]*eskip.Filter, 0, len(old)-lifoCount+1)
-			for _, f := range old {
-				if lifoCount > 1 && f.Name == filters.LifoName {
-					log.Debugf("Removing non-last %v from %s", f, r.Id)
-					lifoCount--
-				} else {
-					r.Filters = append(r.Filters, f)
-				}
-			}
-		}
-	}
-	return routes
+func isFIFO(f LIFOFilter) bool {
+	ff, ok := f.(FIFOFilter)
+	return ok && ff.FIFO()
 }
 
 // Do implements routing.PostProcessor and sets the queue for the scheduler filters.
@@ -266,11 +440,15 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 
 	for i, ri := range routes {
 		rr[i] = ri
-		var lifoCount int
+		var lifoCount, fifoCount int
 		for _, fi := range ri.Filters {
 			if glf, ok := fi.Filter.(GroupedLIFOFilter); ok {
-				groupName := glf.Group()
-				groups[groupName] = append(groups[groupName], glf)
+				key := fmt.Sprintf("group-lifo::%s", glf.Group())
+				if isFIFO(glf) {
+					key = fmt.Sprintf("group-fifo::%s", glf.Group())
+				}
+
+				groups[key] = append(groups[key], glf)
 				continue
 			}
 
@@ -279,23 +457,27 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 				continue
 			}
 
-			lifoCount++
-			var q *Queue
+			fifo := isFIFO(lf)
 			key := fmt.Sprintf("lifo::%s", ri.Id)
+			if fifo {
+				fifoCount++
+				key = fmt.Sprintf("fifo::%s", ri.Id)
+			} else {
+				lifoCount++
+			}
+
+			var q *Queue
 			existingKeys[key] = true
 			c := lf.Config()
 			qi, ok := r.queues.Load(key)
 			if ok {
 				// Will not reach here if routes were pre-processed
 				// because key is derived from the unique route id and
-				// pre-processor ensures single lifo filter instance per route
+				// pre-processor ensures single lifo and fifo filter instance per route
 				q = qi.(*Queue)
-				if q.config != c {
-					q.config = c
-					q.reconfigure()
-				}
+				q.reconfigure(c)
 			} else {
-				q = r.newQueue(ri.Id, c)
+				q = r.newQueue(ri.Id, c, fifo)
 				r.queues.Store(key, q)
 			}
 
@@ -305,21 +487,28 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 		if lifoCount > 1 {
 			log.Warnf("Found multiple lifo filters on route: %q", ri.Id)
 		}
+
+		if fifoCount > 1 {
+			log.Warnf("Found multiple fifo filters on route: %q", ri.Id)
+		}
 	}
 
-	for name, group := range groups {
+	for key, group := range groups {
 		var (
 			c           Config
 			foundConfig bool
 		)
 
+		name := group[0].Group()
+		fifo := isFIFO(group[0])
+
 		for _, glf := range group {
 			if !glf.HasConfig() {
 				continue
 			}
 
 			if foundConfig && glf.Config() != c {
-				log.Warnf("Found mismatching configuration for the LIFO group: %s", name)
+				log.Warnf("Found mismatching configuration for the scheduler group: %s", name)
 				continue
 			}
 
@@ -328,17 +517,13 @@ func (r *Registry) Do(routes []*routing.Route) []*routing.Route {
 		}
 
 		var q *Queue
-		key := fmt.Sprintf("group-lifo::%s", name)
 		existingKeys[key] = true
 		qi, ok := r.queues.Load(key)
 		if ok {
 			q = qi.(*Queue)
-			if q.config != c {
-				q.config = c
-				q.reconfigure()
-			}
+			q.reconfigure(c)
 		} else {
-			q = r.newQueue(name, c)
+			q = r.newQueue(name, c, fifo)
 			r.queues.Store(key, q)
 		}
 
@@ -368,10 +553,7 @@ func (r *Registry) measure() {
 	go func() {
 		for {
 			r.queues.Range(func(_, value interface{}) bool {
-				q := value.(*Queue)
-				s := q.Status()
-				r.options.Metrics.UpdateGauge(q.activeRequestsMetricsKey, float64(s.ActiveRequests))
-				r.options.Metrics.UpdateGauge(q.queuedRequestsMetricsKey, float64(s.QueuedRequests))
+				r.sample(value.(*Queue))
 				return true
 			})
 
@@ -384,6 +566,15 @@ func (r *Registry) measure() {
 	}()
 }
 
+// sample updates the gauges of a single queue. The full events are reported as the
+// number of the requests rejected due to a full queue since the previous sample.
+func (r *Registry) sample(q *Queue) {
+	s := q.Status()
+	r.options.Metrics.UpdateGauge(q.activeRequestsMetricsKey, float64(s.ActiveRequests))
+	r.options.Metrics.UpdateGauge(q.queuedRequestsMetricsKey, float64(s.QueuedRequests))
+	r.options.Metrics.UpdateGauge(q.fullEventsMetricsKey, float64(atomic.SwapInt64(&q.fullEvents, 0)))
+}
+
 // Close closes the registry, including gracefull tearing down the stored
 // queues.
 func (r *Registry) Close() {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/zalando/skipper/filters/builtin"
 	"github.com/zalando/skipper/filters/filtertest"
+	"github.com/zalando/skipper/metrics/metricstest"
 	"github.com/zalando/skipper/routing"
 	"github.com/zalando/skipper/routing/testdataclient"
 	"github.com/zalando/skipper/scheduler"
@@ -67,6 +68,22 @@ func TestScheduler(t *testing.T) {
 			paths:   [][]string{{"r4"}, {"r5"}},
 			wantErr: false,
 		},
+		{
+			name:    "one scheduler filter fifo",
+			doc:     `f2: * -> fifo(10, 12, "10s") -> "http://www.example.org"`,
+			wantErr: false,
+		},
+		{
+			name:    "one scheduler filter fifoGroup",
+			doc:     `fg2: * -> fifoGroup("fg2", 10, 12, "10s") -> "http://www.example.org"`,
+			wantErr: false,
+		},
+		{
+			name:    "lifoGroup and fifoGroup with the same name do not share the queue",
+			doc:     `r8: Path("/r8") -> lifoGroup("g8", 10, 12, "10s") -> "http://www.example.org"; r9: Path("/r9") -> fifoGroup("g8", 10, 12, "10s") -> "http://www.example.org";`,
+			paths:   [][]string{{"r8"}, {"r9"}},
+			wantErr: false,
+		},
 		{
 			name:    "multiple routes with same grouping do use the same configuration",
 			doc:     `r6: Path("/r6") -> setPath("/bar") -> lifoGroup("r6", 10, 12, "10s") -> "http://www.example.org"; r7: Path("/r7") -> setPath("/foo") -> lifoGroup("r6", 10, 12, "10s")  -> setRequestHeader("X-Foo", "bar")-> "http://www.example.org";`,
@@ -196,6 +213,21 @@ func TestConfig(t *testing.T) {
 		}
 	}
 
+	waitForConfig := func(t *testing.T, q *scheduler.Queue, c scheduler.Config) {
+		timeout := time.After(120 * time.Millisecond)
+		for {
+			if q.Config() == c {
+				return
+			}
+
+			select {
+			case <-timeout:
+				t.Fatal("failed to reach config")
+			default:
+			}
+		}
+	}
+
 	initTest := func(doc string) (*routing.Routing, *testdataclient.Client, func()) {
 		cli, err := testdataclient.NewDoc(doc)
 		if err != nil {
@@ -272,13 +304,20 @@ func TestConfig(t *testing.T) {
 		q := f.Filter.(scheduler.LIFOFilter).GetQueue()
 		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 2})
 
-		// change the configuration, should decrease the queue size:
+		// change the configuration, should decrease the queue size, but keep the
+		// already queued requests:
 		const updateDoc = `route: * -> lifo(2, 1) -> <shunt>`
 		if err := dc.UpdateDoc(updateDoc, nil); err != nil {
 			t.Fatal(err)
 		}
 
-		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 1})
+		waitForConfig(t, q, scheduler.Config{MaxConcurrency: 2, MaxQueueSize: 1, Timeout: 10 * time.Second})
+		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 2})
+
+		// the new requests are rejected until the queue was drained:
+		_, err := q.Wait()
+		assert.Equal(t, scheduler.ErrQueueFull, err)
+		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 2})
 	})
 
 	t.Run("update group config", func(t *testing.T) {
@@ -308,7 +347,8 @@ func TestConfig(t *testing.T) {
 		q := f1.Filter.(scheduler.LIFOFilter).GetQueue()
 		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 2})
 
-		// change the configuration, should decrease the queue size:
+		// change the configuration, should decrease the queue size, but keep the
+		// already queued requests:
 		const updateDoc = `
 			g1: Path("/one") -> lifoGroup("g", 2, 1) -> <shunt>;
 			g2: Path("/two") -> lifoGroup("g") -> <shunt>;
@@ -318,7 +358,11 @@ func TestConfig(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 1})
+		waitForConfig(t, q, scheduler.Config{MaxConcurrency: 2, MaxQueueSize: 1, Timeout: 10 * time.Second})
+		waitForStatus(t, q, scheduler.QueueStatus{ActiveRequests: 2, QueuedRequests: 2})
+
+		_, err := q.Wait()
+		assert.Equal(t, scheduler.ErrQueueFull, err)
 	})
 
 	t.Run("queue gets closed when removed", func(t *testing.T) {
@@ -343,6 +387,177 @@ func TestConfig(t *testing.T) {
 	})
 }
 
+func TestQueueErrors(t *testing.T) {
+	cli, err := testdataclient.NewDoc(`
+		l: Path("/lifo") -> lifo(1, 1, "10ms") -> <shunt>;
+		f: Path("/fifo") -> fifo(1, 1, "1s") -> <shunt>;
+	`)
+	require.NoError(t, err)
+
+	reg := scheduler.NewRegistry()
+	defer reg.Close()
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  builtin.MakeRegistry(),
+		DataClients:     []routing.DataClient{cli},
+		PostProcessors:  []routing.PostProcessor{reg},
+	})
+	defer rt.Close()
+	<-rt.FirstLoad()
+
+	getQueue := func(path string) *scheduler.Queue {
+		r, _ := rt.Route(&http.Request{URL: &url.URL{Path: path}})
+		require.NotNil(t, r)
+		return r.Filters[0].Filter.(scheduler.LIFOFilter).GetQueue()
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		q := getQueue("/lifo")
+		done, err := q.Wait()
+		require.NoError(t, err)
+		defer done()
+
+		_, err = q.Wait()
+		assert.Equal(t, scheduler.ErrQueueTimeout, err)
+	})
+
+	t.Run("full", func(t *testing.T) {
+		q := getQueue("/fifo")
+		done, err := q.Wait()
+		require.NoError(t, err)
+		defer done()
+
+		go q.Wait()
+		require.Eventually(t, func() bool {
+			return q.Status().QueuedRequests == 1
+		}, 120*time.Millisecond, time.Millisecond)
+
+		_, err = q.Wait()
+		assert.Equal(t, scheduler.ErrQueueFull, err)
+	})
+}
+
+func TestLIFOQueueClosed(t *testing.T) {
+	cli, err := testdataclient.NewDoc(`* -> lifo(1, 1, "10s") -> <shunt>`)
+	require.NoError(t, err)
+
+	reg := scheduler.NewRegistry()
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  builtin.MakeRegistry(),
+		DataClients:     []routing.DataClient{cli},
+		PostProcessors:  []routing.PostProcessor{reg},
+	})
+	defer rt.Close()
+	<-rt.FirstLoad()
+
+	r, _ := rt.Route(&http.Request{URL: &url.URL{}})
+	require.NotNil(t, r)
+	q := r.Filters[0].Filter.(scheduler.LIFOFilter).GetQueue()
+
+	done, err := q.Wait()
+	require.NoError(t, err)
+	defer done()
+
+	// the queue is closing while the active request is not done:
+	reg.Close()
+	_, err = q.Wait()
+	assert.ErrorIs(t, err, scheduler.ErrQueueClosed)
+}
+
+func TestMetrics(t *testing.T) {
+	waitForGauge := func(t *testing.T, m *metricstest.MockMetrics, key string, value float64) {
+		timeout := time.After(120 * time.Millisecond)
+		for {
+			if v, ok := m.Gauge(key); ok && v == value {
+				return
+			}
+
+			select {
+			case <-timeout:
+				t.Fatalf("failed to reach gauge value: %s=%v", key, value)
+			default:
+			}
+		}
+	}
+
+	cli, err := testdataclient.NewDoc(`route: * -> lifo(1, 1) -> <shunt>`)
+	require.NoError(t, err)
+
+	m := &metricstest.MockMetrics{}
+	reg := scheduler.RegistryWith(scheduler.Options{
+		Metrics:                m,
+		EnableRouteLIFOMetrics: true,
+		MetricsUpdateTimeout:   10 * time.Millisecond,
+	})
+	defer reg.Close()
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  builtin.MakeRegistry(),
+		DataClients:     []routing.DataClient{cli},
+		PostProcessors:  []routing.PostProcessor{reg},
+	})
+	defer rt.Close()
+	<-rt.FirstLoad()
+
+	req := &http.Request{URL: &url.URL{}}
+	r, _ := rt.Route(req)
+	f := r.Filters[0]
+
+	// fill up the queue:
+	go f.Request(&filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})})
+	go f.Request(&filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})})
+
+	waitForGauge(t, m, "lifo.route.active", 1)
+	waitForGauge(t, m, "lifo.route.queued", 1)
+
+	// one of the queued requests gets rejected due to the full queue:
+	go f.Request(&filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})})
+	waitForGauge(t, m, "lifo.route.full", 1)
+	waitForGauge(t, m, "lifo.route.full", 0)
+}
+
+func TestWaitMetrics(t *testing.T) {
+	cli, err := testdataclient.NewDoc(`route: * -> lifo(1, 1, "20ms") -> <shunt>`)
+	require.NoError(t, err)
+
+	m := &metricstest.MockMetrics{}
+	reg := scheduler.RegistryWith(scheduler.Options{
+		Metrics:                m,
+		EnableRouteLIFOMetrics: true,
+	})
+	defer reg.Close()
+
+	rt := routing.New(routing.Options{
+		SignalFirstLoad: true,
+		FilterRegistry:  builtin.MakeRegistry(),
+		DataClients:     []routing.DataClient{cli},
+		PostProcessors:  []routing.PostProcessor{reg},
+	})
+	defer rt.Close()
+	<-rt.FirstLoad()
+
+	req := &http.Request{URL: &url.URL{}}
+	r, _ := rt.Route(req)
+	f := r.Filters[0]
+
+	// the first request is admitted and occupies the single slot:
+	admitted := &filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})}
+	f.Request(admitted)
+
+	// the second request times out in the queue:
+	f.Request(&filtertest.Context{FRequest: req, FStateBag: make(map[string]interface{})})
+	f.Response(admitted)
+
+	m.WithMeasures(func(measures map[string][]time.Duration) {
+		assert.Len(t, measures["lifo.route.wait"], 1)
+		assert.Len(t, measures["lifo.route.wait.timeout"], 1)
+	})
+}
+
 func TestRegistryPreProcessor(t *testing.T) {
 	fr := builtin.MakeRegistry()
 
@@ -369,6 +584,11 @@ func TestRegistryPreProcessor(t *testing.T) {
 			input:  `* -> lifo(777) -> setPath("/foo") -> lifo(999) -> lifo() -> setPath("/bar") -> <shunt>`,
 			expect: `* -> setPath("/foo") -> lifo() -> setPath("/bar") -> <shunt>`,
 		},
+		{
+			name:   "two fifos",
+			input:  `* -> fifo(777) -> lifo() -> fifo() -> setPath("/foo") -> <shunt>`,
+			expect: `* -> lifo() -> fifo() -> setPath("/foo") -> <shunt>`,
+		},
 		{
 			name:   "ignores lifoGroup",
 			input:  `* -> lifo(777) -> lifoGroup("g") -> lifo(999) -> lifo() -> setPath("/bar") -> <shunt>`,
@@ -35,6 +35,7 @@ type SecretPaths struct {
 	mu              sync.RWMutex
 	quit            chan struct{}
 	secrets         *syncmap.Map
+	names           *syncmap.Map
 	refreshInterval time.Duration
 	started         bool
 }
@@ -50,14 +51,24 @@ func NewSecretPaths(d time.Duration) *SecretPaths {
 	return &SecretPaths{
 		quit:            make(chan struct{}),
 		secrets:         &syncmap.Map{},
+		names:           &syncmap.Map{},
 		refreshInterval: d,
 		started:         false,
 	}
 }
 
-// GetSecret returns secret and if found or not for a given name.
+// GetSecret returns secret and if found or not for a given name. The
+// name can be the path of the file, or the name of the file, when it
+// is unique among the added files.
 func (sp *SecretPaths) GetSecret(s string) ([]byte, bool) {
 	dat, ok := sp.secrets.Load(s)
+	if !ok && sp.names != nil {
+		var p interface{}
+		if p, ok = sp.names.Load(s); ok {
+			dat, ok = sp.secrets.Load(p)
+		}
+	}
+
 	if !ok {
 		return nil, false
 	}
@@ -137,9 +148,25 @@ func (sp *SecretPaths) registerSecretFile(p string) error {
 		return err
 	}
 	sp.updateSecret(p, dat)
+	sp.registerSecretName(p)
 	return nil
 }
 
+// registerSecretName makes the secret available by the name of the
+// file. When multiple files have the same name, the secrets are
+// available only by their path.
+func (sp *SecretPaths) registerSecretName(p string) {
+	if sp.names == nil {
+		return
+	}
+
+	name := filepath.Base(p)
+	if _, loaded := sp.names.LoadOrStore(name, p); loaded {
+		log.Warnf("Secret name %s is not unique, use the path %s instead", name, p)
+		sp.names.Store(name, "")
+	}
+}
+
 // runRefresher refreshes all secrets, that are registered
 func (sp *SecretPaths) runRefresher() {
 	log.Infof("Run secrets path refresher every %s, but update once first", sp.refreshInterval)
@@ -162,6 +162,39 @@ func Test_SecretPaths_Add(t *testing.T) {
 	}
 }
 
+func Test_SecretPaths_GetSecretByName(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"/a/key", "/a/other", "/b/key"} {
+		if err := os.MkdirAll(dir+f[:2], 0777); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(dir+f, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sp := NewSecretPaths(time.Minute)
+	defer sp.Close()
+	for _, p := range []string{dir + "/a", dir + "/b"} {
+		if err := sp.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if sec, ok := sp.GetSecret("other"); !ok || string(sec) != "/a/other" {
+		t.Errorf("Failed to get secret by name: %v, got: %s", ok, sec)
+	}
+
+	if _, ok := sp.GetSecret("key"); ok {
+		t.Error("Unexpected secret by not unique name")
+	}
+
+	if sec, ok := sp.GetSecret(dir + "/b/key"); !ok || string(sec) != "/b/key" {
+		t.Errorf("Failed to get secret by path: %v, got: %s", ok, sec)
+	}
+}
+
 func Test_SecretPaths_Close(t *testing.T) {
 	temproot, err := os.MkdirTemp(os.TempDir(), "skipper-secrets-close")
 	if err != nil {
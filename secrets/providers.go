@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"sync"
+	"time"
+)
+
+// Providers is a SecretsReader, that looks up the secrets in multiple
+// SecretsReaders, e.g. in the credentials files and in a remote secret
+// store. The secret is returned from the first SecretsReader, that has
+// it. Example:
+//
+//    sp := NewSecretPaths(time.Minute)
+//    sr := NewProviders(sp, NewCachedSecret(vaultReader, time.Minute))
+//    b, ok := sr.GetSecret("backend-key")
+type Providers struct {
+	readers []SecretsReader
+}
+
+// NewProviders creates a SecretsReader, that looks up the secrets in
+// the given SecretsReaders, in order.
+func NewProviders(sr ...SecretsReader) *Providers {
+	return &Providers{readers: sr}
+}
+
+// GetSecret returns the secret from the first SecretsReader, that has
+// it.
+func (p *Providers) GetSecret(s string) ([]byte, bool) {
+	for _, sr := range p.readers {
+		if b, ok := sr.GetSecret(s); ok {
+			return b, true
+		}
+	}
+
+	return nil, false
+}
+
+// Close closes all the SecretsReaders.
+func (p *Providers) Close() {
+	if p == nil {
+		return
+	}
+
+	for _, sr := range p.readers {
+		sr.Close()
+	}
+}
+
+type cachedSecret struct {
+	secret  []byte
+	expires time.Time
+}
+
+// CachedSecret caches the secrets found by the wrapped SecretsReader,
+// such that remote secret stores are not called on every request. The
+// secrets not found are not cached.
+type CachedSecret struct {
+	sr  SecretsReader
+	ttl time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewCachedSecret creates a SecretsReader, that caches the secrets of
+// the given SecretsReader for the duration of ttl, after which they are
+// read again.
+func NewCachedSecret(sr SecretsReader, ttl time.Duration) *CachedSecret {
+	return &CachedSecret{
+		sr:    sr,
+		ttl:   ttl,
+		cache: make(map[string]cachedSecret),
+	}
+}
+
+// GetSecret returns the cached secret, or reads it from the wrapped
+// SecretsReader, when it is not cached or it expired.
+func (cs *CachedSecret) GetSecret(s string) ([]byte, bool) {
+	now := time.Now()
+
+	cs.mu.Lock()
+	c, ok := cs.cache[s]
+	cs.mu.Unlock()
+
+	if ok && now.Before(c.expires) {
+		return c.secret, true
+	}
+
+	b, ok := cs.sr.GetSecret(s)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !ok {
+		delete(cs.cache, s)
+		return nil, false
+	}
+
+	cs.cache[s] = cachedSecret{secret: b, expires: now.Add(cs.ttl)}
+	return b, true
+}
+
+// Close delegates to the wrapped SecretsReader.
+func (cs *CachedSecret) Close() {
+	if cs != nil {
+		cs.sr.Close()
+	}
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type countingSecretsReader struct {
+	secrets map[string][]byte
+	calls   int
+	closed  bool
+}
+
+func (sr *countingSecretsReader) GetSecret(s string) ([]byte, bool) {
+	sr.calls++
+	b, ok := sr.secrets[s]
+	return b, ok
+}
+
+func (sr *countingSecretsReader) Close() { sr.closed = true }
+
+func TestProviders(t *testing.T) {
+	first := &countingSecretsReader{secrets: map[string][]byte{"a": []byte("first-a")}}
+	second := &countingSecretsReader{secrets: map[string][]byte{"a": []byte("second-a"), "b": []byte("second-b")}}
+	p := NewProviders(first, second)
+
+	for _, tt := range []struct {
+		name   string
+		want   []byte
+		wantOk bool
+	}{
+		{name: "a", want: []byte("first-a"), wantOk: true},
+		{name: "b", want: []byte("second-b"), wantOk: true},
+		{name: "c", wantOk: false},
+	} {
+		if sec, ok := p.GetSecret(tt.name); ok != tt.wantOk || !reflect.DeepEqual(sec, tt.want) {
+			t.Errorf("Failed to get secret %s: %v, got: %s, want: %s", tt.name, ok, sec, tt.want)
+		}
+	}
+
+	p.Close()
+	if !first.closed || !second.closed {
+		t.Error("Failed to close the providers")
+	}
+}
+
+func TestCachedSecret(t *testing.T) {
+	sr := &countingSecretsReader{secrets: map[string][]byte{"a": []byte("a")}}
+	cs := NewCachedSecret(sr, 50*time.Millisecond)
+	defer cs.Close()
+
+	for i := 0; i < 3; i++ {
+		if sec, ok := cs.GetSecret("a"); !ok || string(sec) != "a" {
+			t.Fatalf("Failed to get cached secret: %v, got: %s", ok, sec)
+		}
+	}
+
+	if sr.calls != 1 {
+		t.Errorf("Failed to cache secret, calls: %d", sr.calls)
+	}
+
+	if _, ok := cs.GetSecret("b"); ok {
+		t.Error("Unexpected secret")
+	}
+
+	sr.secrets["a"] = []byte("rotated")
+	time.Sleep(60 * time.Millisecond)
+	if sec, ok := cs.GetSecret("a"); !ok || string(sec) != "rotated" {
+		t.Errorf("Failed to get rotated secret: %v, got: %s", ok, sec)
+	}
+}
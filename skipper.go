@@ -3,6 +3,7 @@ package skipper
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
@@ -23,6 +24,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/zalando/skipper/circuit"
+	"github.com/zalando/skipper/dataclients/consul"
 	"github.com/zalando/skipper/dataclients/kubernetes"
 	"github.com/zalando/skipper/dataclients/routestring"
 	"github.com/zalando/skipper/eskip"
@@ -41,17 +43,22 @@ import (
 	"github.com/zalando/skipper/metrics"
 	skpnet "github.com/zalando/skipper/net"
 	pauth "github.com/zalando/skipper/predicates/auth"
+	"github.com/zalando/skipper/predicates/contentlength"
 	"github.com/zalando/skipper/predicates/cookie"
 	"github.com/zalando/skipper/predicates/cron"
 	"github.com/zalando/skipper/predicates/forwarded"
+	"github.com/zalando/skipper/predicates/geoip"
 	"github.com/zalando/skipper/predicates/host"
 	"github.com/zalando/skipper/predicates/interval"
 	"github.com/zalando/skipper/predicates/methods"
 	"github.com/zalando/skipper/predicates/primitive"
 	"github.com/zalando/skipper/predicates/query"
+	"github.com/zalando/skipper/predicates/schedule"
 	"github.com/zalando/skipper/predicates/source"
 	"github.com/zalando/skipper/predicates/tee"
+	ptls "github.com/zalando/skipper/predicates/tls"
 	"github.com/zalando/skipper/predicates/traffic"
+	"github.com/zalando/skipper/predicates/websocket"
 	"github.com/zalando/skipper/proxy"
 	"github.com/zalando/skipper/queuelistener"
 	"github.com/zalando/skipper/ratelimit"
@@ -115,6 +122,18 @@ type Options struct {
 	// If defines the maximum number of pending connection waiting in the queue.
 	MaxTCPListenerQueue int
 
+	// ProxyProtocol enables accepting the PROXY protocol header, version 1
+	// or 2, on the listener, e.g. behind an AWS NLB. The client address
+	// from the header is used as the remote address of the requests, e.g.
+	// in the access log, in the X-Forwarded-For header and in the
+	// predicates matching the client IP.
+	ProxyProtocol bool
+
+	// ProxyProtocolTrustedNetworks contains the networks of the load
+	// balancers, that are allowed to send the PROXY protocol header. It
+	// is required when ProxyProtocol is enabled.
+	ProxyProtocolTrustedNetworks skpnet.IPNets
+
 	// List of custom filter specifications.
 	CustomFilters []filters.Spec
 
@@ -142,6 +161,19 @@ type Options struct {
 	// If set this value is used as password for etcd basic authorization.
 	EtcdPassword string
 
+	// Address of a Consul agent. When set, skipper loads the route
+	// definitions from the Consul KV store.
+	ConsulAddress string
+
+	// Consul KV path where the route definitions are stored.
+	ConsulKVPrefix string
+
+	// If set this value is used as Consul ACL token.
+	ConsulToken string
+
+	// Timeout of the requests to Consul.
+	ConsulTimeout time.Duration
+
 	// If set enables skipper to generate based on ingress resources in kubernetes cluster
 	Kubernetes bool
 
@@ -175,6 +207,10 @@ type Options struct {
 	// KubernetesIngressV1 will switch the dataclient to read ingress v1 resources, instead of v1beta1
 	KubernetesIngressV1 bool
 
+	// KubernetesEnableEndpointSlices will switch the dataclient to read the backend endpoints
+	// from the EndpointSlice resources, instead of the Endpoints
+	KubernetesEnableEndpointSlices bool
+
 	// KubernetesIngressClass is a regular expression, that will make
 	// skipper load only the ingress resources that have a matching
 	// kubernetes.io/ingress.class annotation. For backwards compatibility,
@@ -255,6 +291,11 @@ type Options struct {
 	// command this option is used when starting it with the -routes-file flag.)
 	WatchRoutesFile string
 
+	// Directory containing route definitions in files with the .eskip
+	// extension, with change watching enabled. Multiple may be given
+	// comma separated.
+	WatchRoutesDir string
+
 	// RouteURLs are URLs pointing to route definitions, in eskip format, with change watching enabled.
 	RoutesURLs []string
 
@@ -276,6 +317,11 @@ type Options struct {
 	// will apply changes to all matching routes.
 	EditRoute *eskip.Editor
 
+	// RouteTemplates expands the routes referencing the route template
+	// variables into concrete routes, before applying the other
+	// pre-processors.
+	RouteTemplates *eskip.RouteTemplates
+
 	// Deprecated. See ProxyFlags. When used together with ProxyFlags,
 	// the values will be combined with |.
 	ProxyOptions proxy.Options
@@ -343,6 +389,11 @@ type Options struct {
 	// a backend to always create a new connection.
 	DisableHTTPKeepalives bool
 
+	// IdempotentRetries sets the maximum number of retries of the
+	// idempotent requests to load balanced backends, when the backend
+	// connection fails before any response was received.
+	IdempotentRetries int
+
 	// Flag indicating to ignore trailing slashes in paths during route
 	// lookup.
 	IgnoreTrailingSlash bool
@@ -354,6 +405,10 @@ type Options struct {
 	// Specifications of custom, user defined predicates.
 	CustomPredicates []routing.PredicateSpec
 
+	// Custom route post-processors, running after the built-in ones,
+	// unless they declare dependencies. See routing.DependentPostProcessor.
+	CustomPostProcessors []routing.PostProcessor
+
 	// Custom data clients to be used together with the default etcd and Innkeeper.
 	CustomDataClients []routing.DataClient
 
@@ -486,6 +541,18 @@ type Options struct {
 	// Use custom buckets for prometheus histograms.
 	HistogramMetricBuckets []float64
 
+	// Use custom buckets for the prometheus route lookup histograms,
+	// instead of HistogramMetricBuckets.
+	RoutingHistogramMetricBuckets []float64
+
+	// Use custom buckets for the prometheus backend histograms, instead
+	// of HistogramMetricBuckets.
+	BackendHistogramMetricBuckets []float64
+
+	// Use custom buckets for the prometheus filter histograms, instead
+	// of HistogramMetricBuckets.
+	FilterHistogramMetricBuckets []float64
+
 	// The following options, for backwards compatibility, are true
 	// by default: EnableAllFiltersMetrics, EnableRouteResponseMetrics,
 	// EnableRouteBackendErrorsCounters, EnableRouteStreamingErrorsCounters,
@@ -544,6 +611,15 @@ type Options struct {
 	// Logrus logger for access logs. To enable structured logging, use AccessLogJSONEnabled.
 	AccessLogJsonFormatter *log.JSONFormatter
 
+	// AccessLogJSONFields, when set and JSON logging is enabled, defines the fields of the
+	// access log entries and their order, e.g. method, uri, status, duration, route-id and
+	// backend-host. Unknown fields are rejected on startup.
+	AccessLogJSONFields []string
+
+	// AccessLogJSONAdditionalFields lists the keys of the additional data set by the filters,
+	// e.g. by logStateBag, that are accepted in AccessLogJSONFields.
+	AccessLogJSONAdditionalFields []string
+
 	DebugListener string
 
 	// Path of certificate(s) when using TLS, mutiple may be given comma separated
@@ -552,6 +628,11 @@ type Options struct {
 	// multiple keys, the order must match the one given in CertPathTLS
 	KeyPathTLS string
 
+	// Path of the CA certificate bundle used to verify the client
+	// certificates, when using TLS. When set, the clients may present a
+	// certificate, and the presented certificates must be valid.
+	ClientCAPathTLS string
+
 	// TLS Settings for Proxy Server
 	ProxyTLS *tls.Config
 
@@ -585,6 +666,10 @@ type Options struct {
 	// BreakerSettings contain global and host specific settings for the circuit breakers.
 	BreakerSettings []circuit.BreakerSettings
 
+	// BreakerStateChangeHandler, when set, is called asynchronously whenever a circuit breaker changes its
+	// state. See circuit.Registry.OnStateChange for the ordering guarantees.
+	BreakerStateChangeHandler circuit.StateChangeHandler
+
 	// EnableRatelimiters enables the usage of the ratelimiter in the route definitions without initializing any
 	// by default. It is a shortcut for setting the RatelimitSettings to:
 	//
@@ -598,6 +683,10 @@ type Options struct {
 	// EnableRouteLIFOMetrics enables metrics for the individual route LIFO queues, if any.
 	EnableRouteLIFOMetrics bool
 
+	// EnableRouteInflightMetrics enables sampling the number of requests
+	// currently handled by the proxy for each route.
+	EnableRouteInflightMetrics bool
+
 	// OpenTracing enables opentracing
 	OpenTracing []string
 
@@ -667,6 +756,11 @@ type Options struct {
 	// unhealthy routes
 	LoadBalancerHealthCheckInterval time.Duration
 
+	// Zone sets the topology zone of the skipper instance, e.g. the
+	// availability zone. The zoneAware load balancer algorithm prefers
+	// the endpoints in the same zone.
+	Zone string
+
 	// ReverseSourcePredicate enables the automatic use of IP
 	// whitelisting in different places to use the reversed way of
 	// identifying a client IP within the X-Forwarded-For
@@ -675,6 +769,11 @@ type Options struct {
 	// header, in this case you want to set this to true.
 	ReverseSourcePredicate bool
 
+	// GeoIPDatabase sets the path of the MaxMind GeoIP2 or GeoLite2
+	// country or city database, and enables the ClientCountry
+	// predicate. The database is reloaded on SIGHUP.
+	GeoIPDatabase string
+
 	// EnableOAuth2GrantFlow, enables OAuth2 Grant Flow filter
 	EnableOAuth2GrantFlow bool
 
@@ -753,6 +852,11 @@ type Options struct {
 	// CredentialsUpdateInterval sets the interval to update secrets
 	CredentialsUpdateInterval time.Duration
 
+	// SecretsProviders are used to look up the secrets referenced by
+	// the filters, e.g. bearerinjector, when they are not found in the
+	// CredentialsPaths. The providers are closed on shutdown.
+	SecretsProviders []secrets.SecretsReader
+
 	// API Monitoring feature is active (feature toggle)
 	ApiUsageMonitoringEnable                bool
 	ApiUsageMonitoringRealmKeys             string
@@ -801,6 +905,10 @@ type Options struct {
 	// ClusterRatelimitMaxGroupShards specifies the maximum number of group shards for the clusterRatelimit filter
 	ClusterRatelimitMaxGroupShards int
 
+	// EnableRatelimitResetMillisHeader enables the X-Rate-Limit-Reset-Ms header in the responses of the
+	// ratelimit filters, telling the clients in milliseconds how long to wait before making a new request.
+	EnableRatelimitResetMillisHeader bool
+
 	testOptions
 }
 
@@ -842,6 +950,12 @@ func createDataClients(o Options, auth innkeeper.Authentication) ([]routing.Data
 		}
 	}
 
+	if o.WatchRoutesDir != "" {
+		for _, rd := range strings.Split(o.WatchRoutesDir, ",") {
+			clients = append(clients, eskipfile.WatchDir(rd))
+		}
+	}
+
 	if len(o.RoutesURLs) > 0 {
 		for _, url := range o.RoutesURLs {
 			client, err := eskipfile.RemoteWatch(&eskipfile.RemoteWatchOptions{
@@ -902,9 +1016,25 @@ func createDataClients(o Options, auth innkeeper.Authentication) ([]routing.Data
 		clients = append(clients, etcdClient)
 	}
 
+	if o.ConsulAddress != "" {
+		consulClient, err := consul.New(consul.Options{
+			Address:  o.ConsulAddress,
+			KVPrefix: o.ConsulKVPrefix,
+			Token:    o.ConsulToken,
+			Timeout:  o.ConsulTimeout,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, consulClient)
+	}
+
 	if o.Kubernetes {
 		kubernetesClient, err := kubernetes.New(kubernetes.Options{
 			KubernetesIngressV1:               o.KubernetesIngressV1,
+			KubernetesEnableEndpointSlices:    o.KubernetesEnableEndpointSlices,
 			AllowedExternalNames:              o.KubernetesAllowedExternalNames,
 			BackendNameTracingTag:             o.OpenTracingBackendNameTag,
 			DefaultFiltersDir:                 o.DefaultFiltersDir,
@@ -970,6 +1100,10 @@ func initLog(o Options) error {
 		}
 	}
 
+	if err := logging.ValidateAccessLogJSONFields(o.AccessLogJSONFields, o.AccessLogJSONAdditionalFields); err != nil {
+		return err
+	}
+
 	logging.Init(logging.Options{
 		ApplicationLogPrefix:        o.ApplicationLogPrefix,
 		ApplicationLogOutput:        logOutput,
@@ -979,6 +1113,7 @@ func initLog(o Options) error {
 		AccessLogJSONEnabled:        o.AccessLogJSONEnabled,
 		AccessLogStripQuery:         o.AccessLogStripQuery,
 		AccessLogJsonFormatter:      o.AccessLogJsonFormatter,
+		AccessLogJSONFields:         o.AccessLogJSONFields,
 	})
 
 	return nil
@@ -1012,6 +1147,21 @@ func (o *Options) tlsConfig() (*tls.Config, error) {
 		}
 		config.Certificates = append(config.Certificates, keypair)
 	}
+
+	if o.ClientCAPathTLS != "" {
+		pem, err := os.ReadFile(o.ClientCAPathTLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA certificates from %s: %w", o.ClientCAPathTLS, err)
+		}
+
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid client CA certificate found in %s", o.ClientCAPathTLS)
+		}
+
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
 	return config, nil
 }
 
@@ -1065,6 +1215,29 @@ func listen(o *Options, mtr metrics.Metrics) (net.Listener, error) {
 	})
 }
 
+// wraps the listener to accept the PROXY protocol header, when enabled
+func proxyProtocolListener(l net.Listener, o *Options) (net.Listener, error) {
+	if !o.ProxyProtocol {
+		return l, nil
+	}
+
+	if len(o.ProxyProtocolTrustedNetworks) == 0 {
+		l.Close()
+		return nil, fmt.Errorf("the PROXY protocol requires trusted networks")
+	}
+
+	timeout := o.ReadHeaderTimeoutServer
+	if timeout <= 0 {
+		timeout = o.ReadTimeoutServer
+	}
+
+	return &skpnet.ProxyProtocolListener{
+		Listener:      l,
+		Trusted:       o.ProxyProtocolTrustedNetworks,
+		HeaderTimeout: timeout,
+	}, nil
+}
+
 func listenAndServeQuit(
 	proxy http.Handler,
 	o *Options,
@@ -1123,7 +1296,26 @@ func listenAndServeQuit(
 
 	log.Infof("proxy listener on %v", o.Address)
 
-	if srv.TLSConfig != nil {
+	if srv.TLSConfig != nil && o.ProxyProtocol {
+		if o.Address == "" {
+			o.Address = ":https"
+		}
+
+		l, err := listen(o, mtr)
+		if err != nil {
+			return err
+		}
+
+		l, err = proxyProtocolListener(l, o)
+		if err != nil {
+			return err
+		}
+
+		if err := srv.ServeTLS(l, "", ""); err != http.ErrServerClosed {
+			log.Errorf("ServeTLS failed: %v", err)
+			return err
+		}
+	} else if srv.TLSConfig != nil {
 		if err := srv.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
 			log.Errorf("ListenAndServeTLS failed: %v", err)
 			return err
@@ -1136,6 +1328,11 @@ func listenAndServeQuit(
 			return err
 		}
 
+		l, err = proxyProtocolListener(l, o)
+		if err != nil {
+			return err
+		}
+
 		if err := srv.Serve(l); err != http.ErrServerClosed {
 			log.Errorf("Serve failed: %v", err)
 			return err
@@ -1202,6 +1399,9 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		EnableRouteBackendMetrics:          o.EnableRouteBackendMetrics,
 		UseExpDecaySample:                  o.MetricsUseExpDecaySample,
 		HistogramBuckets:                   o.HistogramMetricBuckets,
+		RoutingHistogramBuckets:            o.RoutingHistogramMetricBuckets,
+		BackendHistogramBuckets:            o.BackendHistogramMetricBuckets,
+		FilterHistogramBuckets:             o.FilterHistogramMetricBuckets,
 		DisableCompatibilityDefaults:       o.DisableMetricsCompatibilityDefaults,
 		PrometheusRegistry:                 o.PrometheusRegistry,
 	}
@@ -1282,13 +1482,15 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 	defer o.SecretsRegistry.Close()
 
 	sp := secrets.NewSecretPaths(o.CredentialsUpdateInterval)
-	defer sp.Close()
 	for _, p := range o.CredentialsPaths {
 		if err := sp.Add(p); err != nil {
 			log.Errorf("Failed to add credentials file: %s: %v", p, err)
 		}
 	}
 
+	sr := secrets.NewProviders(append([]secrets.SecretsReader{sp}, o.SecretsProviders...)...)
+	defer sr.Close()
+
 	tio := auth.TokenintrospectionOptions{
 		Timeout:      o.OAuthTokenintrospectionTimeout,
 		MaxIdleConns: o.IdleConnectionsPerHost,
@@ -1303,7 +1505,8 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 
 	o.CustomFilters = append(o.CustomFilters,
 		logfilter.NewAuditLog(o.MaxAuditBody),
-		auth.NewBearerInjector(sp),
+		auth.NewBearerInjector(sr),
+		auth.NewSignRequest(sr),
 		auth.NewJwtValidationWithOptions(tio),
 		auth.TokenintrospectionWithOptions(auth.NewOAuthTokenintrospectionAnyClaims, tio),
 		auth.TokenintrospectionWithOptions(auth.NewOAuthTokenintrospectionAllClaims, tio),
@@ -1313,6 +1516,7 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		auth.TokenintrospectionWithOptions(auth.NewSecureOAuthTokenintrospectionAllClaims, tio),
 		auth.TokenintrospectionWithOptions(auth.NewSecureOAuthTokenintrospectionAnyKV, tio),
 		auth.TokenintrospectionWithOptions(auth.NewSecureOAuthTokenintrospectionAllKV, tio),
+		auth.NewOAuthIntrospection(tio),
 		auth.WebhookWithOptions(who),
 		auth.NewOAuthOidcUserInfos(o.OIDCSecretsFile, o.SecretsRegistry),
 		auth.NewOAuthOidcAnyClaims(o.OIDCSecretsFile, o.SecretsRegistry),
@@ -1405,13 +1609,17 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 			o.ClusterRatelimitMaxGroupShards = 1
 		}
 
-		provider := ratelimitfilters.NewRatelimitProvider(ratelimitRegistry)
+		provider := ratelimitfilters.NewRatelimitProviderWithOptions(ratelimitRegistry, ratelimitfilters.ProviderOptions{
+			EnableResetMillisHeader: o.EnableRatelimitResetMillisHeader,
+		})
 		o.CustomFilters = append(o.CustomFilters,
 			ratelimitfilters.NewClientRatelimit(provider),
 			ratelimitfilters.NewLocalRatelimit(provider),
 			ratelimitfilters.NewRatelimit(provider),
 			ratelimitfilters.NewShardedClusterRateLimit(provider, o.ClusterRatelimitMaxGroupShards),
 			ratelimitfilters.NewClusterClientRateLimit(provider),
+			ratelimitfilters.NewLeakyBucketRatelimit(provider),
+			ratelimitfilters.NewClusterLeakyBucketRatelimit(provider),
 			ratelimitfilters.NewDisableRatelimit(provider),
 			ratelimitfilters.NewBackendRatelimit(),
 		)
@@ -1502,8 +1710,12 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		interval.NewBefore(),
 		interval.NewAfter(),
 		cron.New(),
+		schedule.NewWeekday(),
+		schedule.NewTimeOfDay(),
 		cookie.New(),
+		cookie.NewValidSigned(sr),
 		query.New(),
+		query.NewRegexp(),
 		traffic.New(),
 		primitive.NewTrue(),
 		primitive.NewFalse(),
@@ -1517,8 +1729,23 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		forwarded.NewForwardedHost(),
 		forwarded.NewForwardedProto(),
 		host.NewAny(),
+		ptls.NewCipher(),
+		ptls.NewVersion(),
+		ptls.NewClientCertSAN(),
+		contentlength.New(),
+		websocket.New(),
 	)
 
+	if o.GeoIPDatabase != "" {
+		clientCountry, err := geoip.NewClientCountry(o.GeoIPDatabase, o.ReverseSourcePredicate)
+		if err != nil {
+			log.Errorf("Failed to load GeoIP database: %v.", err)
+			return err
+		}
+
+		o.CustomPredicates = append(o.CustomPredicates, clientCountry)
+	}
+
 	// provide default value for wrapper if not defined
 	if o.CustomHttpHandlerWrap == nil {
 		o.CustomHttpHandlerWrap = func(original http.Handler) http.Handler {
@@ -1543,14 +1770,19 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		SuppressLogs:    o.SuppressRouteUpdateLogs,
 		PostProcessors: []routing.PostProcessor{
 			loadbalancer.HealthcheckPostProcessor{LB: lbInstance},
-			loadbalancer.NewAlgorithmProvider(),
+			loadbalancer.NewAlgorithmProviderWithOptions(loadbalancer.AlgorithmProviderOptions{Zone: o.Zone}),
 			schedulerRegistry,
 			builtin.NewRouteCreationMetrics(mtr),
+			builtin.NewFlushIntervalPostProcessor(),
 			fadein.NewPostProcessor(),
 		},
 		SignalFirstLoad: o.WaitFirstRouteLoad,
 	}
 
+	if o.RouteTemplates != nil {
+		ro.PreProcessors = append(ro.PreProcessors, o.RouteTemplates)
+	}
+
 	if o.DefaultFilters != nil {
 		ro.PreProcessors = append(ro.PreProcessors, o.DefaultFilters)
 	}
@@ -1569,6 +1801,11 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		ro.PreProcessors = append(ro.PreProcessors, oauthConfig.NewGrantPreprocessor())
 	}
 
+	ro.PostProcessors = append(ro.PostProcessors, o.CustomPostProcessors...)
+	if ro.PostProcessors, err = routing.SortPostProcessors(ro.PostProcessors); err != nil {
+		return err
+	}
+
 	routing := routing.New(ro)
 	defer routing.Close()
 
@@ -1593,6 +1830,8 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		TLSHandshakeTimeout:        o.TLSHandshakeTimeoutBackend,
 		MaxIdleConns:               o.MaxIdleConnsBackend,
 		DisableHTTPKeepalives:      o.DisableHTTPKeepalives,
+		IdempotentRetries:          o.IdempotentRetries,
+		EnableRouteInflightMetrics: o.EnableRouteInflightMetrics,
 		AccessLogDisabled:          o.AccessLogDisabled,
 		ClientTLS:                  o.ClientTLS,
 		CustomHttpRoundTripperWrap: o.CustomHttpRoundTripperWrap,
@@ -1601,6 +1840,7 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 
 	if o.EnableBreakers || len(o.BreakerSettings) > 0 {
 		proxyParams.CircuitBreakers = circuit.NewRegistry(o.BreakerSettings...)
+		proxyParams.CircuitBreakers.OnStateChange(o.BreakerStateChangeHandler)
 	}
 
 	if o.DebugListener != "" {
@@ -1623,6 +1863,7 @@ func run(o Options, sig chan os.Signal, idleConnsCH chan struct{}) error {
 		mux := http.NewServeMux()
 		mux.Handle("/routes", routing)
 		mux.Handle("/routes/", routing)
+		mux.Handle("/routes/explain", routing.ExplainHandler())
 
 		metricsHandler := metrics.NewHandler(mtrOpts, mtr)
 		mux.Handle("/metrics", metricsHandler)
@@ -0,0 +1,237 @@
+/*
+Package otel implements an OpenTracing tracer, that bridges the spans to
+the OpenTelemetry SDK, and exports them to an OpenTelemetry collector,
+using the OTLP/HTTP protocol.
+
+The span context is propagated with the W3C Trace Context headers
+(traceparent and tracestate) and the W3C baggage header. When
+extracting, the B3 headers are accepted as fallback, and optionally
+the B3 headers can be injected, too, to stay compatible with services
+using B3 propagation.
+
+Options:
+
+	service-name=<name>       the service.name resource attribute, defaults to skipper
+	endpoint=<url>            the OTLP/HTTP traces endpoint, defaults to http://localhost:4318/v1/traces
+	header=<key>=<value>      additional header sent to the collector, e.g. for authentication
+	tag=<key>=<value>         additional resource attribute
+	sampler-ratio=<ratio>     ratio of the sampled root spans between 0 and 1, defaults to 1
+	batch-size=<n>            maximum number of spans in an export request, defaults to 512
+	batch-timeout=<duration>  maximum delay of exporting a span, defaults to 5s
+	queue-size=<n>            maximum number of queued spans, defaults to 2048
+	propagators=<list>        comma separated list of tracecontext and b3, defaults to tracecontext
+*/
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/attribute"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	defServiceName  = "skipper"
+	defEndpoint     = "http://localhost:4318/v1/traces"
+	defBatchSize    = 512
+	defBatchTimeout = 5 * time.Second
+	defQueueSize    = 2048
+	exportTimeout   = 10 * time.Second
+	tracerName      = "github.com/zalando/skipper"
+)
+
+type options struct {
+	serviceName  string
+	endpoint     *url.URL
+	headers      map[string]string
+	tags         map[string]string
+	samplerRatio float64
+	batchSize    int
+	batchTimeout time.Duration
+	queueSize    int
+	b3           bool
+}
+
+// Tracer implements the opentracing.Tracer interface, bridging the spans
+// to an OpenTelemetry tracer provider.
+type Tracer struct {
+	*otbridge.BridgeTracer
+	provider *sdktrace.TracerProvider
+}
+
+// extractOnly accepts the headers of a propagator without injecting them
+type extractOnly struct {
+	propagation.TextMapPropagator
+}
+
+func (extractOnly) Inject(context.Context, propagation.TextMapCarrier) {}
+
+func (extractOnly) Fields() []string { return nil }
+
+func parseOptions(opts []string) (options, error) {
+	endpoint, _ := url.Parse(defEndpoint)
+	o := options{
+		serviceName:  defServiceName,
+		endpoint:     endpoint,
+		headers:      make(map[string]string),
+		tags:         make(map[string]string),
+		samplerRatio: 1,
+		batchSize:    defBatchSize,
+		batchTimeout: defBatchTimeout,
+		queueSize:    defQueueSize,
+	}
+
+	var err error
+	for _, opt := range opts {
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) == 1 {
+			return options{}, missingArg(parts[0])
+		}
+
+		switch parts[0] {
+		case "service-name":
+			o.serviceName = parts[1]
+		case "endpoint":
+			o.endpoint, err = url.ParseRequestURI(parts[1])
+			if err == nil && o.endpoint.Scheme != "http" && o.endpoint.Scheme != "https" {
+				err = fmt.Errorf("unsupported scheme: %s", o.endpoint.Scheme)
+			}
+		case "header", "tag":
+			kv := strings.SplitN(parts[1], "=", 2)
+			if len(kv) != 2 {
+				return options{}, fmt.Errorf("missing value for %s %s", parts[0], kv[0])
+			}
+
+			if parts[0] == "header" {
+				o.headers[kv[0]] = kv[1]
+			} else {
+				o.tags[kv[0]] = kv[1]
+			}
+		case "sampler-ratio":
+			o.samplerRatio, err = strconv.ParseFloat(parts[1], 64)
+			if err == nil && (o.samplerRatio < 0 || o.samplerRatio > 1) {
+				err = errors.New("out of range")
+			}
+		case "batch-size":
+			o.batchSize, err = strconv.Atoi(parts[1])
+			if err == nil && o.batchSize <= 0 {
+				err = errors.New("must be positive")
+			}
+		case "batch-timeout":
+			o.batchTimeout, err = time.ParseDuration(parts[1])
+			if err == nil && o.batchTimeout <= 0 {
+				err = errors.New("must be positive")
+			}
+		case "queue-size":
+			o.queueSize, err = strconv.Atoi(parts[1])
+			if err == nil && o.queueSize <= 0 {
+				err = errors.New("must be positive")
+			}
+		case "propagators":
+			for _, p := range strings.Split(parts[1], ",") {
+				switch strings.TrimSpace(p) {
+				case "tracecontext":
+				case "b3":
+					o.b3 = true
+				default:
+					err = fmt.Errorf("unsupported propagator: %s", p)
+				}
+			}
+		}
+
+		if err != nil {
+			return options{}, invalidArg(parts[0], err)
+		}
+	}
+
+	return o, nil
+}
+
+// InitTracer creates a tracer exporting the spans to an OpenTelemetry
+// collector.
+func InitTracer(opts []string) (opentracing.Tracer, error) {
+	o, err := parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exporterOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(o.endpoint.Host),
+		otlptracehttp.WithURLPath(o.endpoint.Path),
+		otlptracehttp.WithHeaders(o.headers),
+		otlptracehttp.WithTimeout(exportTimeout),
+	}
+
+	if o.endpoint.Scheme == "http" {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTracer(o, exporter), nil
+}
+
+func newPropagator(o options) propagation.TextMapPropagator {
+	var b3p propagation.TextMapPropagator = b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader))
+	if !o.b3 {
+		b3p = extractOnly{b3p}
+	}
+
+	// the propagators extract in order, and the last valid span context
+	// wins, so the W3C trace context is preferred over the B3 headers
+	return propagation.NewCompositeTextMapPropagator(b3p, propagation.TraceContext{}, propagation.Baggage{})
+}
+
+func newTracer(o options, exporter sdktrace.SpanExporter) *Tracer {
+	attributes := []attribute.KeyValue{semconv.ServiceName(o.serviceName)}
+	for k, v := range o.tags {
+		attributes = append(attributes, attribute.String(k, v))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attributes...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.samplerRatio))),
+		sdktrace.WithBatcher(
+			exporter,
+			sdktrace.WithMaxExportBatchSize(o.batchSize),
+			sdktrace.WithBatchTimeout(o.batchTimeout),
+			sdktrace.WithMaxQueueSize(o.queueSize),
+			sdktrace.WithExportTimeout(exportTimeout),
+		),
+	)
+
+	bridge, _ := otbridge.NewTracerPair(provider.Tracer(tracerName))
+	bridge.SetTextMapPropagator(newPropagator(o))
+	return &Tracer{BridgeTracer: bridge, provider: provider}
+}
+
+// Close exports the pending spans and stops the exporter.
+func (t *Tracer) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	t.provider.Shutdown(ctx)
+}
+
+func missingArg(opt string) error {
+	return fmt.Errorf("missing argument for %s option", opt)
+}
+
+func invalidArg(opt string, err error) error {
+	return fmt.Errorf("invalid argument for %s option: %s", opt, err)
+}
@@ -0,0 +1,328 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testOptions(t *testing.T, opts ...string) options {
+	o, err := parseOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return o
+}
+
+func TestParseOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		opts    []string
+		check   func(options) bool
+		wantErr bool
+	}{{
+		name: "defaults",
+		check: func(o options) bool {
+			return o.serviceName == defServiceName &&
+				o.endpoint.String() == defEndpoint &&
+				o.samplerRatio == 1 &&
+				o.batchSize == defBatchSize &&
+				o.batchTimeout == defBatchTimeout &&
+				o.queueSize == defQueueSize &&
+				!o.b3
+		},
+	}, {
+		name: "all options",
+		opts: []string{
+			"service-name=skipper-ingress",
+			"endpoint=https://collector:4318/v1/traces",
+			"header=Authorization=Bearer token",
+			"tag=cluster=test",
+			"sampler-ratio=0.25",
+			"batch-size=10",
+			"batch-timeout=1s",
+			"queue-size=100",
+			"propagators=tracecontext,b3",
+		},
+		check: func(o options) bool {
+			return o.serviceName == "skipper-ingress" &&
+				o.endpoint.Host == "collector:4318" &&
+				o.endpoint.Path == "/v1/traces" &&
+				o.headers["Authorization"] == "Bearer token" &&
+				o.tags["cluster"] == "test" &&
+				o.samplerRatio == 0.25 &&
+				o.batchSize == 10 &&
+				o.batchTimeout == time.Second &&
+				o.queueSize == 100 &&
+				o.b3
+		},
+	}, {
+		name:    "missing argument",
+		opts:    []string{"endpoint"},
+		wantErr: true,
+	}, {
+		name:    "unsupported endpoint scheme",
+		opts:    []string{"endpoint=grpc://collector:4317"},
+		wantErr: true,
+	}, {
+		name:    "invalid ratio",
+		opts:    []string{"sampler-ratio=2"},
+		wantErr: true,
+	}, {
+		name:    "invalid batch size",
+		opts:    []string{"batch-size=0"},
+		wantErr: true,
+	}, {
+		name:    "invalid tag",
+		opts:    []string{"tag=cluster"},
+		wantErr: true,
+	}, {
+		name:    "unsupported propagator",
+		opts:    []string{"propagators=jaeger"},
+		wantErr: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := parseOptions(tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("failed to fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(o) {
+				t.Errorf("unexpected options: %+v", o)
+			}
+		})
+	}
+}
+
+func TestExtractTraceContext(t *testing.T) {
+	tracer := newTracer(testOptions(t), tracetest.NewInMemoryExporter())
+	defer tracer.Close()
+
+	h := make(http.Header)
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Set("tracestate", "vendor=value")
+	h.Set("baggage", "user=alice,session=42;prop=1")
+	h.Set("X-B3-TraceId", "80f198ee56343ba864fe8b2a57d3eff7")
+	h.Set("X-B3-SpanId", "e457b5a2e4d86bd1")
+
+	c, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := tracer.StartSpan("test", opentracing.ChildOf(c))
+	out := make(http.Header)
+	if err := tracer.Inject(s.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := out.Get("traceparent")
+	if !strings.HasPrefix(tp, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || strings.Contains(tp, "00f067aa0ba902b7") || !strings.HasSuffix(tp, "-01") {
+		t.Errorf("unexpected traceparent: %s", tp)
+	}
+
+	if out.Get("tracestate") != "vendor=value" {
+		t.Errorf("unexpected tracestate: %s", out.Get("tracestate"))
+	}
+
+	if b := out.Get("baggage"); !strings.Contains(b, "user=alice") || !strings.Contains(b, "session=42;prop=1") {
+		t.Errorf("unexpected baggage: %s", b)
+	}
+
+	if out.Get("X-B3-TraceId") != "" {
+		t.Error("unexpected B3 headers")
+	}
+}
+
+func TestBaggageEncoding(t *testing.T) {
+	tracer := newTracer(testOptions(t), tracetest.NewInMemoryExporter())
+	defer tracer.Close()
+
+	const baggage = "user=alice%20smith%2C%20jr."
+	h := make(http.Header)
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.Set("baggage", baggage)
+
+	c, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(http.Header)
+	if err := tracer.Inject(c, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := out.Get("baggage"); b != baggage {
+		t.Errorf("unexpected baggage, expected: %s, got: %s", baggage, b)
+	}
+}
+
+func TestExtractB3(t *testing.T) {
+	tracer := newTracer(testOptions(t, "propagators=tracecontext,b3"), tracetest.NewInMemoryExporter())
+	defer tracer.Close()
+
+	for _, tt := range []struct {
+		name    string
+		headers map[string]string
+		traceID string
+		sampled string
+	}{{
+		name: "multi",
+		headers: map[string]string{
+			"X-B3-TraceId": "80f198ee56343ba864fe8b2a57d3eff7",
+			"X-B3-SpanId":  "e457b5a2e4d86bd1",
+			"X-B3-Sampled": "0",
+		},
+		traceID: "80f198ee56343ba864fe8b2a57d3eff7",
+		sampled: "0",
+	}, {
+		name: "single, 64 bit trace id",
+		headers: map[string]string{
+			"b3": "a3ce929d0e0e4736-e457b5a2e4d86bd1-1",
+		},
+		traceID: "0000000000000000a3ce929d0e0e4736",
+		sampled: "1",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+
+			c, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(h))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := make(http.Header)
+			if err := tracer.Inject(c, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(out)); err != nil {
+				t.Fatal(err)
+			}
+
+			if out.Get("X-B3-TraceId") != tt.traceID {
+				t.Errorf("unexpected trace id: %s", out.Get("X-B3-TraceId"))
+			}
+
+			if out.Get("X-B3-Sampled") != tt.sampled {
+				t.Errorf("unexpected sampling decision: %s", out.Get("X-B3-Sampled"))
+			}
+
+			if !strings.Contains(out.Get("traceparent"), tt.traceID) {
+				t.Errorf("unexpected traceparent: %s", out.Get("traceparent"))
+			}
+		})
+	}
+}
+
+func TestExtractMissingContext(t *testing.T) {
+	tracer := newTracer(testOptions(t), tracetest.NewInMemoryExporter())
+	defer tracer.Close()
+
+	h := make(http.Header)
+	h.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	if _, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(h)); err != opentracing.ErrSpanContextNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSamplerRatio(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newTracer(testOptions(t, "sampler-ratio=0"), exporter)
+	defer tracer.Close()
+
+	s := tracer.StartSpan("root")
+	child := tracer.StartSpan("child", opentracing.ChildOf(s.Context()))
+	child.Finish()
+	s.Finish()
+
+	out := make(http.Header)
+	if err := tracer.Inject(child.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(out)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(out.Get("traceparent"), "-00") {
+		t.Errorf("the child span should inherit the sampling decision: %s", out.Get("traceparent"))
+	}
+
+	if err := tracer.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("unexpected exported spans: %d", len(spans))
+	}
+}
+
+func TestExport(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newTracer(testOptions(t, "service-name=skipper-test", "batch-size=2"), exporter)
+	defer tracer.Close()
+
+	root := tracer.StartSpan("ingress")
+	ext.SpanKindRPCServer.Set(root)
+	child := tracer.StartSpan("proxy", opentracing.ChildOf(root.Context()))
+	child.SetTag("http.status_code", 503)
+	ext.Error.Set(child, true)
+	child.LogKV("event", "retry", "attempt", 1)
+	child.Finish()
+	root.Finish()
+
+	if err := tracer.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans: %d", len(spans))
+	}
+
+	proxy, ingress := spans[0], spans[1]
+	if v, ok := ingress.Resource.Set().Value("service.name"); !ok || v.AsString() != "skipper-test" {
+		t.Errorf("unexpected service name: %v", v)
+	}
+
+	if proxy.Parent.SpanID() != ingress.SpanContext.SpanID() || proxy.SpanContext.TraceID() != ingress.SpanContext.TraceID() {
+		t.Error("unexpected parent of the proxy span")
+	}
+
+	if ingress.SpanKind != trace.SpanKindServer || proxy.SpanKind != trace.SpanKindInternal {
+		t.Errorf("unexpected span kinds: %v, %v", ingress.SpanKind, proxy.SpanKind)
+	}
+
+	if proxy.Status.Code != codes.Error {
+		t.Error("failed to set the error status")
+	}
+
+	var statusCode bool
+	for _, a := range proxy.Attributes {
+		if a.Key == "http.status_code" && a.Value.AsInt64() == 503 {
+			statusCode = true
+		}
+	}
+
+	if !statusCode {
+		t.Errorf("unexpected attributes: %+v", proxy.Attributes)
+	}
+
+	if len(proxy.Events) != 1 {
+		t.Errorf("unexpected events: %+v", proxy.Events)
+	}
+}
@@ -61,6 +61,7 @@ import (
 	"github.com/zalando/skipper/tracing/tracers/instana"
 	"github.com/zalando/skipper/tracing/tracers/jaeger"
 	"github.com/zalando/skipper/tracing/tracers/lightstep"
+	"github.com/zalando/skipper/tracing/tracers/otel"
 )
 
 // InitTracer initializes an opentracing tracer. The first option item is the
@@ -83,6 +84,8 @@ func InitTracer(opts []string) (tracer ot.Tracer, err error) {
 		return jaeger.InitTracer(opts)
 	case "lightstep":
 		return lightstep.InitTracer(opts)
+	case "otel":
+		return otel.InitTracer(opts)
 	default:
 		return nil, fmt.Errorf("tracer '%s' not supported", impl)
 	}